@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/spirefy/go-codegen/generators/docs"
+	"github.com/spirefy/go-codegen/generators/goclient"
+	"github.com/spirefy/go-codegen/generators/gomodels"
+	"github.com/spirefy/go-codegen/generators/goserver"
+	"github.com/spirefy/go-codegen/generators/goworkflows"
+	"github.com/spirefy/go-codegen/generators/oas"
+	"github.com/spirefy/go-codegen/generators/python"
+	"github.com/spirefy/go-codegen/generators/typescript"
+	"github.com/spirefy/go-codegen/loaders/arazzo"
+	"github.com/spirefy/go-codegen/loaders/asyncapi"
+	"github.com/spirefy/go-codegen/loaders/graphql"
+	"github.com/spirefy/go-codegen/loaders/har"
+	"github.com/spirefy/go-codegen/loaders/jsonschema"
+	"github.com/spirefy/go-codegen/loaders/openapi"
+	"github.com/spirefy/go-codegen/loaders/postman"
+	"github.com/spirefy/go-codegen/loaders/proto"
+	"github.com/spirefy/go-codegen/loaders/swagger"
+	"github.com/spirefy/go-codegen/loaders/wsdl"
+	"github.com/spirefy/go-codegen/pipeline"
+)
+
+// builtins returns a registry with every loader and generator built in to this repository registered in-process. The
+// ids and names match the extensions declared in plugin.yaml.
+func builtins() *pipeline.Registry {
+	registry := pipeline.NewRegistry()
+
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.openapi", "openapi", openapi.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.swagger", "swagger", swagger.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.arazzo", "arazzo", arazzo.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.postman", "postman", postman.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.asyncapi", "asyncapi", asyncapi.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.proto", "proto", proto.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.graphql", "graphql", graphql.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.wsdl", "wsdl", wsdl.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.jsonschema", "jsonschema", jsonschema.Loader{})
+	registry.RegisterLoader("spirefy.plugins.codegen.loaders.har", "har", har.Loader{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.go-models", "go-models", gomodels.Generator{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.go-client", "go-client", goclient.Generator{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.go-server", "go-server", goserver.Generator{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.typescript", "typescript", typescript.Generator{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.python", "python", python.Generator{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.openapi", "openapi", oas.Generator{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.docs", "docs", docs.Generator{})
+	registry.RegisterGenerator("spirefy.plugins.codegen.generators.go-workflows", "go-workflows", goworkflows.Generator{})
+
+	return registry
+}
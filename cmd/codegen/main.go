@@ -0,0 +1,90 @@
+// Command codegen runs the codegen pipeline natively, without the Spirefy host, using the loaders and generators that are
+// built in to this repository. It takes the same options as the plugin CLI extension:
+//
+//	codegen -sources ./petstore.yaml -targets go-models -output ./gen -options go-models.package=petstore
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/spirefy/go-codegen/pipeline"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type option struct {
+	Name  string
+	Value string
+}
+
+func main() {
+	sources := flag.String("sources", "", "The comma separated list of sources to process in to resources, components and workflows")
+	targets := flag.String("targets", "", "The comma separated list of targets to generate for")
+	output := flag.String("output", "", "The directory generated files are written to")
+	options := flag.String("options", "", "The comma separated list of target.key=value options passed to the generator of each target")
+	include := flag.String("include", "", "The comma separated list of glob patterns a resource path or name must match to be generated")
+	exclude := flag.String("exclude", "", "The comma separated list of glob patterns of resource paths or names to leave out")
+	dryRun := flag.Bool("dryRun", false, "Run the generators but only report the files that would be written")
+	strict := flag.Bool("strict", false, "Fail the run on any source that can not be loaded instead of skipping it")
+	verbose := flag.Bool("v", false, "Log what the pipeline is doing")
+	flag.Parse()
+
+	// build the same name/value input the host CLI sends to the plugin so the request is parsed the same way
+	input, err := json.Marshal([]option{
+		{Name: "sources", Value: *sources},
+		{Name: "targets", Value: *targets},
+		{Name: "output", Value: *output},
+		{Name: "options", Value: *options},
+		{Name: "include", Value: *include},
+		{Name: "exclude", Value: *exclude},
+		{Name: "dryRun", Value: strconv.FormatBool(*dryRun)},
+		{Name: "strict", Value: strconv.FormatBool(*strict)},
+	})
+	if nil != err {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	registry := builtins()
+	registry.Verbose = *verbose
+
+	result, failed := pipeline.RunInput(registry, input)
+
+	for _, diagnostic := range result.Diagnostics {
+		fmt.Fprintln(os.Stderr, diagnostic.String())
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+
+	if err = write(result.Files, *dryRun); nil != err {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// write saves the generated files, or only lists them when dryRun is true
+func write(files []types.GeneratedFile, dryRun bool) error {
+	for _, file := range files {
+		if dryRun {
+			fmt.Println(file.Path)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0o755); nil != err {
+			return err
+		}
+
+		if err := os.WriteFile(file.Path, file.Content, 0o644); nil != err {
+			return err
+		}
+
+		fmt.Println(file.Path)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/spirefy/go-codegen/pipeline"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGoTargetsCompile generates the Go targets for the testdata of the loaders and vets the output with the go command,
+// in a module of its own, since the generated code only uses the standard library.
+func TestGoTargetsCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if nil != err {
+		t.Skip("the go command is not installed")
+	}
+
+	tests := []struct {
+		name    string
+		sources []string // relative to the loaders directory
+	}{
+		{"openapi with arazzo workflows", []string{"arazzo/testdata/petstore.yaml", "arazzo/testdata/adopt.arazzo.yaml"}},
+		{"openapi nested and colliding schemas", []string{"openapi/testdata/nested.yaml", "openapi/testdata/colliding-names.yaml"}},
+		{"swagger", []string{"swagger/testdata/users.json"}},
+		{"asyncapi", []string{"asyncapi/testdata/chat-v3.yaml"}},
+		{"json schema", []string{"jsonschema/testdata/order.schema.json"}},
+		{"proto", []string{"proto/testdata/acme/users/v1/users.proto"}},
+		{"graphql", []string{"graphql/testdata/schema.graphql", "graphql/testdata/items.graphql"}},
+		{"wsdl", []string{"wsdl/testdata/users.wsdl"}},
+		{"har", []string{"har/testdata/capture.har"}},
+		{"postman", []string{"postman/testdata/pets.postman_collection.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			request := &types.CodegenRequest{
+				Version:   types.CodegenRequestVersion,
+				Targets:   []string{"go-models", "go-client", "go-server", "go-workflows"},
+				OutputDir: dir,
+			}
+			for _, source := range tt.sources {
+				request.Sources = append(request.Sources, filepath.Join("..", "..", "loaders", source))
+			}
+
+			result := pipeline.Run(builtins(), request)
+			for _, d := range result.Diagnostics {
+				if d.Severity == types.SeverityError {
+					t.Errorf("%s", d)
+				}
+			}
+			if len(result.Files) <= 0 {
+				t.Error("no files were generated")
+			}
+			if t.Failed() {
+				return
+			}
+
+			if err := write(result.Files, false); nil != err {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/generated\n\ngo 1.22\n"), 0o644); nil != err {
+				t.Fatal(err)
+			}
+
+			vet := exec.Command(goBin, "vet", "./...")
+			vet.Dir = dir
+			if out, err := vet.CombinedOutput(); nil != err {
+				t.Errorf("go vet: %v\n%s", err, out)
+			}
+		})
+	}
+}
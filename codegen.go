@@ -1,11 +1,30 @@
+//go:build tinygo || wasip1
+
 package main
 
 import (
 	"encoding/json"
 	"github.com/extism/go-pdk"
-	"github.com/spirefy/go-codegen/types"
+	"github.com/spirefy/go-codegen/generators/docs"
+	"github.com/spirefy/go-codegen/generators/goclient"
+	"github.com/spirefy/go-codegen/generators/gomodels"
+	"github.com/spirefy/go-codegen/generators/goserver"
+	"github.com/spirefy/go-codegen/generators/goworkflows"
+	"github.com/spirefy/go-codegen/generators/oas"
+	"github.com/spirefy/go-codegen/generators/python"
+	"github.com/spirefy/go-codegen/generators/typescript"
+	"github.com/spirefy/go-codegen/loaders/arazzo"
+	"github.com/spirefy/go-codegen/loaders/asyncapi"
+	"github.com/spirefy/go-codegen/loaders/graphql"
+	"github.com/spirefy/go-codegen/loaders/har"
+	"github.com/spirefy/go-codegen/loaders/jsonschema"
+	"github.com/spirefy/go-codegen/loaders/openapi"
+	"github.com/spirefy/go-codegen/loaders/postman"
+	"github.com/spirefy/go-codegen/loaders/proto"
+	"github.com/spirefy/go-codegen/loaders/swagger"
+	"github.com/spirefy/go-codegen/loaders/wsdl"
+	"github.com/spirefy/go-codegen/pipeline"
 	"github.com/spirefy/go-pdk/hostfuncs"
-	"strings"
 )
 
 //export start
@@ -14,96 +33,175 @@ func start() int32 {
 	return 0
 }
 
-type loadinput struct {
-	Name  string
-	Value string
-}
-
-func load(sources string) {
-	srcs := strings.Split(sources, ",")
-	for _, src := range srcs {
-		data, err := hostfuncs.LoadFile(src)
-
-		if nil != err {
-			pdk.Log(pdk.LogDebug, "Err loading source: "+err.Error())
-		}
-
-		if nil != data && len(data) > 0 {
-			// now loop through loader extensions, and pass the first 40 or so of bytes of data to them to determine if they
-			// can load it or not
-			extensions, err := hostfuncs.GetExtensionsForExtensionPoint("spirefy.plugins.codegen.loaders")
-			if nil != err {
-				pdk.Log(pdk.LogDebug, "Problem getting extensions: "+err.Error())
-			} else {
-				if nil != extensions && len(extensions) > 0 {
-					pdk.Log(pdk.LogDebug, "We got extensions: "+string(len(extensions)))
-					for _, ext := range extensions {
-						pdk.Log(pdk.LogDebug, "extension: "+ext.Name)
-						pdk.Log(pdk.LogDebug, "id: "+ext.Id)
-						pdk.Log(pdk.LogDebug, "ep: "+ext.ExtensionPoint)
-						pdk.Log(pdk.LogDebug, "func: "+ext.Func)
-						extResp, err2 := hostfuncs.CallExtension(ext.Id, nil)
-						if nil != err2 {
-							pdk.Log(pdk.LogDebug, "error calling extnesion: "+err2.Error())
-							pdk.SetError(err2)
-						} else {
-							pdk.Log(pdk.LogDebug, "We got some response back")
-							if nil != extResp && len(extResp) > 0 {
-								pdk.Log(pdk.LogDebug, "response is > 0 ")
-								resp := types.LoadedResponse{}
-								err3 := json.Unmarshal(extResp, &resp)
-								if nil != err3 {
-									pdk.Log(pdk.LogDebug, "ERROR UNMARSHALLING: "+err3.Error())
-									pdk.SetError(err3)
-								}
-								pdk.Log(pdk.LogDebug, resp.Workflows[0].Id)
-
-							}
-						}
-					}
-				}
-			}
-		}
-	}
+// pdkHost is the pipeline.Host backed by the Spirefy host functions
+type pdkHost struct{}
+
+func (pdkHost) LoadFile(path string) ([]byte, error) {
+	return hostfuncs.LoadFile(path)
 }
 
-func generate(targets string) {
-	tgts := strings.Split(targets, ",")
-	for _, tgt := range tgts {
-		pdk.Log(pdk.LogDebug, "Target: "+tgt)
+func (pdkHost) GetExtensionsForExtensionPoint(extensionPoint string) ([]pipeline.Extension, error) {
+	exts, err := hostfuncs.GetExtensionsForExtensionPoint(extensionPoint)
+	if nil != err {
+		return nil, err
+	}
+
+	extensions := make([]pipeline.Extension, 0, len(exts))
+	for _, ext := range exts {
+		extensions = append(extensions, pipeline.Extension{Id: ext.Id, Name: ext.Name, ExtensionPoint: ext.ExtensionPoint, Func: ext.Func})
 	}
+
+	return extensions, nil
 }
 
+func (pdkHost) CallExtension(id string, input []byte) ([]byte, error) {
+	return hostfuncs.CallExtension(id, input)
+}
+
+func (pdkHost) Log(message string) {
+	pdk.Log(pdk.LogDebug, message)
+}
+
+// loadAndGenerate
+//
+// This is the function the host CLI extension calls. Its input is a types.CodegenRequest (see ParseCodegenRequest for the
+// accepted forms) and its output a types.CodegenResult with all the generated files and diagnostics. It returns 1 when
+// the diagnostics hold an error (or a warning when the request is strict) so the host can fail the build.
+//
 //export loadAndGenerate
 func loadAndGenerate() int32 {
 	pdk.Log(pdk.LogDebug, "Loading and generating code")
-	input := pdk.Input()
-	inputs := make([]loadinput, 0)
-	err := json.Unmarshal(input, &inputs)
+
+	result, failed := pipeline.RunInput(pdkHost{}, pdk.Input())
+
+	output, err := json.Marshal(result)
 	if nil != err {
-		pdk.Log(pdk.LogDebug, "Problem unmarshalling to slice of struct")
+		pdk.SetError(err)
+		return 1
 	}
 
-	var sources, targets string
+	pdk.Output(output)
 
-	for _, s := range inputs {
-		switch s.Name {
-		case "sources":
-			sources = s.Value
-		case "targets":
-			targets = s.Value
-		}
+	if failed {
+		pdk.SetErrorString("codegen failed, see diagnostics for details")
+		return 1
 	}
 
-	if len(sources) > 0 {
-		load(sources)
+	return 0
+}
+
+// serveLoader handles a call of one of the built in loader extensions declared in plugin.yaml
+func serveLoader(loader pipeline.Loader) int32 {
+	output, err := pipeline.ServeLoader(loader, pdk.Input())
+	if nil != err {
+		pdk.SetError(err)
+		return 1
 	}
 
-	if len(targets) > 0 {
-		generate(targets)
+	pdk.Output(output)
+	return 0
+}
+
+// serveGenerator handles a call of one of the built in generator extensions declared in plugin.yaml
+func serveGenerator(generator pipeline.Generator) int32 {
+	output, err := pipeline.ServeGenerator(generator, pdk.Input())
+	if nil != err {
+		pdk.SetError(err)
+		return 1
 	}
 
+	pdk.Output(output)
 	return 0
 }
 
+//export openapiLoader
+func openapiLoader() int32 {
+	return serveLoader(openapi.Loader{})
+}
+
+//export swaggerLoader
+func swaggerLoader() int32 {
+	return serveLoader(swagger.Loader{})
+}
+
+//export arazzoLoader
+func arazzoLoader() int32 {
+	return serveLoader(arazzo.Loader{})
+}
+
+//export postmanLoader
+func postmanLoader() int32 {
+	return serveLoader(postman.Loader{})
+}
+
+//export asyncapiLoader
+func asyncapiLoader() int32 {
+	return serveLoader(asyncapi.Loader{})
+}
+
+//export protoLoader
+func protoLoader() int32 {
+	return serveLoader(proto.Loader{})
+}
+
+//export graphqlLoader
+func graphqlLoader() int32 {
+	return serveLoader(graphql.Loader{})
+}
+
+//export wsdlLoader
+func wsdlLoader() int32 {
+	return serveLoader(wsdl.Loader{})
+}
+
+//export jsonschemaLoader
+func jsonschemaLoader() int32 {
+	return serveLoader(jsonschema.Loader{})
+}
+
+//export harLoader
+func harLoader() int32 {
+	return serveLoader(har.Loader{})
+}
+
+//export goModelsGenerator
+func goModelsGenerator() int32 {
+	return serveGenerator(gomodels.Generator{})
+}
+
+//export goClientGenerator
+func goClientGenerator() int32 {
+	return serveGenerator(goclient.Generator{})
+}
+
+//export goServerGenerator
+func goServerGenerator() int32 {
+	return serveGenerator(goserver.Generator{})
+}
+
+//export typescriptGenerator
+func typescriptGenerator() int32 {
+	return serveGenerator(typescript.Generator{})
+}
+
+//export pythonGenerator
+func pythonGenerator() int32 {
+	return serveGenerator(python.Generator{})
+}
+
+//export openapiGenerator
+func openapiGenerator() int32 {
+	return serveGenerator(oas.Generator{})
+}
+
+//export docsGenerator
+func docsGenerator() int32 {
+	return serveGenerator(docs.Generator{})
+}
+
+//export goWorkflowsGenerator
+func goWorkflowsGenerator() int32 {
+	return serveGenerator(goworkflows.Generator{})
+}
+
 func main() {}
@@ -0,0 +1,441 @@
+// Package docs is the built in generator of the docs target. It writes human readable reference docs of the model, as
+// markdown and/or static html pages:
+//
+//   - an index page linking to a page per Root of the resources (see types.Resources.GetResourcesByHierarchy)
+//   - a page per root documenting its resources: the summary and description, a table of the parameters, the request
+//     body and the responses with their schema and the Example of a ResponseBody. Deprecated resources and older versions
+//     (Latest is false) are in sections of their own at the end of the page
+//   - a schemas page with every defined Component, which the schemas of bodies and parameters link to
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target is the name of the target this generator writes
+const Target = "docs"
+
+// Options of the docs target
+const (
+	OptionFormat = "format" // The format the pages are written in: markdown (the default), html or all for both
+	OptionTitle  = "title"  // The title of the index page, the Owner of the resources by default
+)
+
+// Header is the first line of every generated page
+const Header = "<!-- Code generated by codegen. DO NOT EDIT. -->"
+
+// The names of the pages every run writes
+const (
+	indexPage   = "index"
+	schemasPage = "schemas"
+)
+
+// Generator implements pipeline.Generator for the docs target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	format := strings.ToLower(generators.Option(request, OptionFormat, "markdown"))
+	formats := map[string]bool{"markdown": format != "html", "html": format == "html" || format == "all"}
+
+	d := newDocs(request.Model.Components)
+	pages := d.pages(request.Model.Resources, generators.Option(request, OptionTitle, ""))
+
+	files := make([]types.GeneratedFile, 0, 2*len(pages))
+	for _, p := range pages {
+		if formats["markdown"] {
+			files = append(files, types.GeneratedFile{Path: p.name + ".md", Content: p.markdown()})
+		}
+		if formats["html"] {
+			files = append(files, types.GeneratedFile{Path: p.name + ".html", Content: p.html()})
+		}
+	}
+
+	return &types.GeneratorResponse{Files: files, Diagnostics: d.diagnostics}, nil
+}
+
+// docs writes the pages of a model
+type docs struct {
+	diagnostics types.Diagnostics
+
+	components types.Components
+	anchors    map[*types.Component]string // the anchors of the defined components on the schemas page
+	defined    types.Components
+	visiting   map[*types.Component]bool
+}
+
+// newDocs returns the docs of the provided components, with an anchor for every defined component
+func newDocs(components types.Components) *docs {
+	d := &docs{
+		diagnostics: make(types.Diagnostics, 0),
+		components:  components,
+		anchors:     make(map[*types.Component]string),
+		visiting:    make(map[*types.Component]bool),
+	}
+
+	taken := make(map[string]bool)
+	for _, comp := range generators.ModelComponents(components) {
+		if comp.Source == types.SourceComponent {
+			d.anchors[comp] = unique(taken, slug(comp.Name))
+			d.defined = append(d.defined, comp)
+		}
+	}
+
+	return d
+}
+
+// sections are the sections of a root page, in order
+var sections = []struct {
+	title string
+	match func(res *types.Resource) bool
+}{
+	{"Resources", func(res *types.Resource) bool { return res.Latest && !res.Deprecated }},
+	{"Deprecated", func(res *types.Resource) bool { return res.Latest && res.Deprecated }},
+	{"Older versions", func(res *types.Resource) bool { return !res.Latest }},
+}
+
+// pages returns the index, a page per root and the schemas page
+func (d *docs) pages(all types.Resources, title string) []*page {
+	resources := make(types.Resources, 0, len(all))
+	for _, res := range all {
+		if nil != res && res.ResourceType != types.FOLDER {
+			resources = append(resources, res)
+		}
+	}
+
+	if len(title) <= 0 {
+		for _, res := range resources {
+			if len(res.Owner) > 0 {
+				title = res.Owner
+				break
+			}
+		}
+	}
+	if len(title) <= 0 {
+		title = "API"
+	}
+	title += " reference"
+
+	hierarchy := resources.GetResourcesByHierarchy()
+	roots := make([]string, 0, len(hierarchy))
+	for root := range hierarchy {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	index := &page{name: indexPage, title: title}
+	index.heading(1, "", plain(title))
+	if url := generators.BaseURL(resources, generators.BasePath(resources)); len(url) > 0 {
+		index.paragraph(plain("Base url: "), code(url))
+	}
+
+	pages := []*page{index}
+	taken := map[string]bool{indexPage: true, schemasPage: true}
+	items := make([]text, 0, len(roots)+1)
+
+	for _, root := range roots {
+		name := strings.Trim(root, "/")
+		if len(name) <= 0 {
+			name = "/"
+		}
+
+		p := &page{name: unique(taken, slug(name)), title: name + " - " + title}
+		p.heading(1, "", plain(name))
+		d.root(p, *hierarchy[root])
+		pages = append(pages, p)
+
+		count := len(*hierarchy[root])
+		noun := " resources"
+		if count == 1 {
+			noun = " resource"
+		}
+		items = append(items, text{{text: name, page: p.name}, plain(" - " + strconv.Itoa(count) + noun)})
+	}
+
+	if len(d.defined) > 0 {
+		items = append(items, text{{text: "Schemas", page: schemasPage}, plain(" - the defined components")})
+		pages = append(pages, d.schemas(title))
+	}
+
+	index.list(items)
+
+	return pages
+}
+
+// root writes the resources of a root to its page, in a section per state of the resources
+func (d *docs) root(p *page, resources types.Resources) {
+	sorted := append(types.Resources{}, resources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch {
+		case a.Path != b.Path:
+			return a.Path < b.Path
+		case a.Method != b.Method:
+			return a.Method < b.Method
+		default:
+			return a.Version > b.Version
+		}
+	})
+
+	anchors := make(map[string]bool)
+	for _, section := range sections {
+		matched := make(types.Resources, 0, len(sorted))
+		for _, res := range sorted {
+			if section.match(res) {
+				matched = append(matched, res)
+			}
+		}
+		if len(matched) <= 0 {
+			continue
+		}
+
+		p.heading(2, "", plain(section.title))
+		for _, res := range matched {
+			d.resource(p, res, anchors)
+		}
+	}
+}
+
+// resource writes the documentation of a resource
+func (d *docs) resource(p *page, res *types.Resource, anchors map[string]bool) {
+	method := strings.ToUpper(res.Method)
+	anchor := slug(method + " " + res.Path)
+	if !res.Latest && len(res.Version) > 0 {
+		anchor += "-v" + slug(res.Version)
+	}
+
+	p.heading(3, unique(anchors, anchor), code(method+" "+res.Path))
+
+	if summary := strings.TrimSpace(res.Summary); len(summary) > 0 {
+		p.paragraph(span{text: summary, strong: true})
+	}
+	if description := strings.TrimSpace(res.Description); len(description) > 0 {
+		p.paragraph(raw(description))
+	}
+
+	details := text{}
+	if res.Deprecated {
+		details = append(details, span{text: "Deprecated.", strong: true}, plain(" "))
+	}
+	if len(res.Name) > 0 {
+		details = append(details, plain("Name: "), code(res.Name), plain(". "))
+	}
+	if len(res.Version) > 0 {
+		details = append(details, plain("Version: "), code(res.Version), plain(". "))
+	}
+	if len(res.Owner) > 0 {
+		details = append(details, plain("Source: "+res.Owner+"."))
+	}
+	if len(details) > 0 {
+		p.paragraph(details...)
+	}
+
+	d.parameters(p, res.Parameters)
+	d.requests(p, res.Requests)
+	d.responses(p, res.Responses)
+}
+
+// parameters writes the table of the parameters of a resource
+func (d *docs) parameters(p *page, params types.Parameters) {
+	rows := make([][]text, 0, len(params))
+	for _, param := range params {
+		if nil == param {
+			continue
+		}
+
+		typ := d.typeOf(param.Type, param.Format, nil, nil, nil, nil)
+		if len(param.Components) > 0 && nil != param.Components[0] {
+			typ = d.component(param.Components[0])
+		}
+
+		rows = append(rows, []text{{code(param.Name)}, {plain(string(param.In))}, typ, {plain(yesNo(param.Required))}, {raw(param.Description)}})
+	}
+
+	if len(rows) > 0 {
+		p.heading(4, "", plain("Parameters"))
+		p.table([]string{"Name", "In", "Type", "Required", "Description"}, rows)
+	}
+}
+
+// requests writes the request bodies of a resource
+func (d *docs) requests(p *page, requests types.Requests) {
+	written := false
+	for _, r := range requests {
+		if nil == r {
+			continue
+		}
+
+		if !written {
+			p.heading(4, "", plain("Request body"))
+			written = true
+		}
+
+		line := text{code(contentType(r.ContentType))}
+		if r.Required {
+			line = append(line, plain(" (required)"))
+		}
+		d.body(p, line, r.Schema)
+	}
+}
+
+// responses writes the responses of a resource with the schemas and examples of their bodies
+func (d *docs) responses(p *page, responses types.Responses) {
+	sorted := generators.SortedResponses(responses)
+	if len(sorted) <= 0 {
+		return
+	}
+
+	p.heading(4, "", plain("Responses"))
+	for _, r := range sorted {
+		line := text{span{text: r.Status, code: true, strong: true}}
+		if description := strings.TrimSpace(r.Description); len(description) > 0 {
+			line = append(line, plain(" "), raw(description))
+		}
+
+		if len(r.ResponseBodies) <= 0 {
+			p.paragraph(line...)
+			continue
+		}
+
+		for i, body := range r.ResponseBodies {
+			if nil == body {
+				continue
+			}
+
+			if i > 0 {
+				line = text{span{text: r.Status, code: true, strong: true}}
+			}
+			line = append(line, plain(" - "), code(contentType(body.MediaType)))
+			d.body(p, line, body.Schema)
+
+			if example := strings.TrimSpace(body.Example); len(example) > 0 {
+				lang, source := exampleOf(example, body.MediaType)
+				p.paragraph(plain("Example:"))
+				p.code(lang, source)
+			}
+		}
+	}
+}
+
+// body writes the line introducing a request or response body followed by its schema: a link to the schemas page for a
+// defined component, a table of the properties of any other object and its type otherwise
+func (d *docs) body(p *page, line text, schema *types.Component) {
+	if nil == schema {
+		p.paragraph(line...)
+		return
+	}
+
+	comp := generators.Resolve(d.components, schema)
+	if nil == comp {
+		comp = schema
+	}
+
+	rows := d.rows(comp)
+	if _, defined := d.anchors[comp]; defined || len(rows) <= 0 {
+		p.paragraph(append(append(line, plain(": ")), d.component(comp)...)...)
+		return
+	}
+
+	p.paragraph(line...)
+	p.table(propertyHeader, rows)
+}
+
+// schemas returns the schemas page, documenting every defined component
+func (d *docs) schemas(title string) *page {
+	p := &page{name: schemasPage, title: "Schemas - " + title}
+	p.heading(1, "", plain("Schemas"))
+
+	count := make(map[string]int, len(d.defined))
+	for _, comp := range d.defined {
+		count[comp.Name]++
+	}
+
+	for _, comp := range d.defined {
+		// components of the same name from different sources or versions tell which one they are
+		title := text{plain(comp.Name)}
+		if count[comp.Name] > 1 && len(comp.Version) > 0 {
+			title = append(title, plain(" (version "+comp.Version+")"))
+		}
+
+		p.heading(2, d.anchors[comp], title...)
+		if description := strings.TrimSpace(comp.Description); len(description) > 0 && !generators.RepeatsName(description, comp.Name) {
+			p.paragraph(raw(description))
+		}
+
+		if rows := d.rows(comp); len(rows) > 0 {
+			p.table(propertyHeader, rows)
+			continue
+		}
+
+		ref := comp.Ref
+		if generators.Resolve(d.components, ref) == comp {
+			ref = nil
+		}
+		p.paragraph(append(text{plain("Type: ")}, d.typeOf(comp.Type, comp.Format, comp.Enums, comp.Null, ref, comp.Properties)...)...)
+	}
+
+	return p
+}
+
+// exampleOf returns the language and source of a code block showing an example, json is indented
+func exampleOf(example, mediaType string) (string, string) {
+	var b bytes.Buffer
+	if err := json.Indent(&b, []byte(example), "", "  "); nil == err {
+		return "json", b.String()
+	}
+
+	switch {
+	case strings.Contains(mediaType, "xml"):
+		return "xml", example
+	case strings.Contains(mediaType, "html"):
+		return "html", example
+	default:
+		return "", example
+	}
+}
+
+// contentType returns the content type of a body, */* when it has none
+func contentType(ct string) string {
+	if len(ct) <= 0 {
+		return "*/*"
+	}
+
+	return ct
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// nonSlug matches what an anchor or page name does not hold
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug returns the lower case anchor or page name of a text, e.g. GET /pets/{petId} is get-pets-petid
+func slug(s string) string {
+	s = strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) <= 0 {
+		return "root"
+	}
+
+	return s
+}
+
+// unique returns name, or name with a number appended when it is taken already, and takes it
+func unique(taken map[string]bool, name string) string {
+	n := name
+	for i := 2; taken[n]; i++ {
+		n = name + "-" + strconv.Itoa(i)
+	}
+	taken[n] = true
+
+	return n
+}
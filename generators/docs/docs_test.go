@@ -0,0 +1,167 @@
+package docs
+
+import (
+	"github.com/spirefy/go-codegen/generators/generatortest"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	files, diagnostics := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionTitle: "Petstore"})
+	if len(diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", diagnostics)
+	}
+
+	for _, page := range []string{"index", "root", "schemas"} {
+		t.Run(page, func(t *testing.T) {
+			want, err := os.ReadFile(filepath.Join("testdata", page+".golden.md"))
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			if got := files[page+".md"]; got != string(want) {
+				t.Errorf("%s.md is not testdata/%s.golden.md:\n%s", page, page, got)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"", []string{"index.md", "root.md", "schemas.md"}},
+		{"markdown", []string{"index.md", "root.md", "schemas.md"}},
+		{"HTML", []string{"index.html", "root.html", "schemas.html"}},
+		{"all", []string{"index.html", "index.md", "root.html", "root.md", "schemas.html", "schemas.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			options := map[string]string{}
+			if len(tt.format) > 0 {
+				options[OptionFormat] = tt.format
+			}
+			files, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), options)
+
+			paths := make([]string, 0, len(files))
+			for path := range files {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("files = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	model := generatortest.Petstore()
+	model.Resources[0].Description = "Lists <every> pet\nof the store"
+
+	files, _ := generatortest.Generate(t, Generator{}, model, map[string]string{OptionFormat: "html", OptionTitle: "Pets & co"})
+
+	for _, want := range []string{
+		"<title>/ - Pets &amp; co reference</title>",
+		`<nav><a href="index.html">Index</a></nav>`,
+		`<h3 id="get-pets"><code>GET /pets</code></h3>`,
+		"<p>Lists &lt;every&gt; pet<br>\nof the store</p>",
+		`<p><strong><code>200</code></strong> - <code>application/json</code>: array of <a href="schemas.html#pet"><code>Pet</code></a></p>`,
+		"<tr><td><code>petId</code></td><td>path</td><td><code>string</code></td><td>yes</td><td>The id of the pet</td></tr>",
+	} {
+		if !strings.Contains(files["root.html"], want) {
+			t.Errorf("root.html does not have %q", want)
+		}
+	}
+
+	if index := files["index.html"]; !strings.Contains(index, `<li><a href="root.html">/</a> - 4 resources</li>`) || strings.Contains(index, "<nav>") {
+		t.Errorf("index.html does not link to the root page alone:\n%s", index)
+	}
+}
+
+func TestExample(t *testing.T) {
+	model := generatortest.Petstore()
+	model.Resources[2].Responses[0].ResponseBodies[0].Example = `{"id":1,"name":"Rex"}`
+
+	files, _ := generatortest.Generate(t, Generator{}, model, nil)
+
+	want := "Example:\n\n```json\n{\n  \"id\": 1,\n  \"name\": \"Rex\"\n}\n```\n"
+	if !strings.Contains(files["root.md"], want) {
+		t.Errorf("root.md does not have the indented example:\n%s", files["root.md"])
+	}
+}
+
+func TestSchemaDescriptions(t *testing.T) {
+	tests := []struct {
+		description string
+		written     bool
+	}{
+		{"Pet", false},
+		{"pet ", false},
+		{"PET", false},
+		{"A pet of the store", true},
+		{"Pets", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			d := newDocs(types.Components{{Id: 1, Name: "Pet", Type: "string", Description: tt.description, Source: types.SourceComponent}})
+			page := string(d.schemas("API").markdown())
+
+			if got := strings.Contains(page, "\n"+strings.TrimSpace(tt.description)+"\n"); got != tt.written {
+				t.Errorf("the description is written: %t, want %t\n%s", got, tt.written, page)
+			}
+		})
+	}
+}
+
+func TestMarkdownText(t *testing.T) {
+	tests := []struct {
+		name string
+		text text
+		want string
+	}{
+		{"plain text is escaped", text{plain("a *b* <c> [d]")}, `a \*b\* \<c\> \[d\]`},
+		{"raw text is not", text{raw("a *b*")}, "a *b*"},
+		{"code with a backtick", text{code("a`b")}, "``a`b``"},
+		{"code starting with a backtick", text{code("`a")}, "`` `a ``"},
+		{"a link", text{{text: "Pet", code: true, page: schemasPage, anchor: "pet"}}, "[`Pet`](schemas.md#pet)"},
+		{"line breaks", text{raw("a\nb")}, "a b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownText(tt.text, true); got != tt.want {
+				t.Errorf("markdownText() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"GET /pets/{petId}", "get-pets-petid"},
+		{"NewPet", "newpet"},
+		{"/", "root"},
+		{"v1.2", "v1-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := slug(tt.s); got != tt.want {
+				t.Errorf("slug(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
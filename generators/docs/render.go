@@ -0,0 +1,264 @@
+package docs
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// span is a piece of inline text. It links to the anchor of a page when anchor is set, the page being empty for the page
+// the span is on.
+type span struct {
+	text   string
+	code   bool
+	strong bool
+	raw    bool // text from the model (e.g. a Description), which is markdown already
+	page   string
+	anchor string
+}
+
+// text is a run of inline spans
+type text []span
+
+func plain(s string) span { return span{text: s} }
+func code(s string) span  { return span{text: s, code: true} }
+func raw(s string) span   { return span{text: s, raw: true} }
+
+// The kinds of blocks of a page
+const (
+	blockHeading = iota
+	blockParagraph
+	blockTable
+	blockCode
+	blockList
+)
+
+// block is a heading, paragraph, table, code block or list of a page
+type block struct {
+	kind   int
+	level  int    // of a heading
+	anchor string // of a heading
+	text   text   // of a heading or paragraph
+	header []string
+	rows   [][]text
+	items  []text
+	lang   string // of a code block
+	code   string
+}
+
+// page is a page of the docs, written as <name>.md and/or <name>.html
+type page struct {
+	name   string
+	title  string
+	blocks []*block
+}
+
+func (p *page) heading(level int, anchor string, t ...span) {
+	p.blocks = append(p.blocks, &block{kind: blockHeading, level: level, anchor: anchor, text: append(text{}, t...)})
+}
+
+func (p *page) paragraph(t ...span) {
+	p.blocks = append(p.blocks, &block{kind: blockParagraph, text: append(text{}, t...)})
+}
+
+func (p *page) table(header []string, rows [][]text) {
+	p.blocks = append(p.blocks, &block{kind: blockTable, header: header, rows: rows})
+}
+
+func (p *page) code(lang, source string) {
+	p.blocks = append(p.blocks, &block{kind: blockCode, lang: lang, code: source})
+}
+
+func (p *page) list(items []text) {
+	p.blocks = append(p.blocks, &block{kind: blockList, items: items})
+}
+
+// markdownSpecial matches the characters of plain text markdown would read as formatting
+var markdownSpecial = regexp.MustCompile("([\\\\`*_\\[\\]<>])")
+
+// whitespace matches the line breaks and runs of spaces inline text is written without
+var whitespace = regexp.MustCompile(`\s+`)
+
+// markdown returns a page as GitHub flavored markdown. Headings get an explicit anchor so links do not depend on how a
+// renderer turns heading text in to ids.
+func (p *page) markdown() []byte {
+	var b strings.Builder
+	b.WriteString(Header + "\n")
+
+	for _, bl := range p.blocks {
+		b.WriteString("\n")
+
+		switch bl.kind {
+		case blockHeading:
+			if len(bl.anchor) > 0 {
+				fmt.Fprintf(&b, "<a id=\"%s\"></a>\n\n", bl.anchor)
+			}
+			fmt.Fprintf(&b, "%s %s\n", strings.Repeat("#", bl.level), markdownText(bl.text, true))
+		case blockParagraph:
+			b.WriteString(markdownText(bl.text, false) + "\n")
+		case blockTable:
+			b.WriteString("| " + strings.Join(bl.header, " | ") + " |\n")
+			b.WriteString("|" + strings.Repeat(" --- |", len(bl.header)) + "\n")
+			for _, row := range bl.rows {
+				cells := make([]string, len(row))
+				for i, cell := range row {
+					cells[i] = strings.ReplaceAll(markdownText(cell, true), "|", "\\|")
+				}
+				b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+			}
+		case blockCode:
+			fence := "```"
+			for strings.Contains(bl.code, fence) {
+				fence += "`"
+			}
+			fmt.Fprintf(&b, "%s%s\n%s\n%s\n", fence, bl.lang, strings.TrimRight(bl.code, "\n"), fence)
+		case blockList:
+			for _, item := range bl.items {
+				b.WriteString("- " + markdownText(item, true) + "\n")
+			}
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// markdownText returns inline text as markdown, on a single line when inline is true
+func markdownText(t text, inline bool) string {
+	var b strings.Builder
+
+	for _, s := range t {
+		var out string
+		switch {
+		case s.code:
+			fence := "`"
+			for strings.Contains(s.text, fence) {
+				fence += "`"
+			}
+			if strings.HasPrefix(s.text, "`") || strings.HasSuffix(s.text, "`") {
+				out = fence + " " + s.text + " " + fence
+			} else {
+				out = fence + s.text + fence
+			}
+		case s.raw:
+			out = strings.TrimSpace(strings.ReplaceAll(s.text, "\r\n", "\n"))
+		default:
+			out = markdownSpecial.ReplaceAllString(s.text, "\\$1")
+		}
+
+		if inline {
+			out = whitespace.ReplaceAllString(out, " ")
+		}
+		if s.strong {
+			out = "**" + out + "**"
+		}
+		if len(s.page) > 0 || len(s.anchor) > 0 {
+			out = "[" + out + "](" + href(s.page, s.anchor, ".md") + ")"
+		}
+
+		b.WriteString(out)
+	}
+
+	return b.String()
+}
+
+// style is the style sheet of the html pages
+const style = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;line-height:1.5;max-width:60rem;margin:2rem auto;padding:0 1rem;color:#1f2328}
+code,pre{font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:.9em;background:#f6f8fa;border-radius:4px}
+code{padding:.1em .3em}pre{padding:1em;overflow:auto}pre code{padding:0;background:none}
+table{border-collapse:collapse;margin:1em 0}th,td{border:1px solid #d0d7de;padding:.3em .6em;text-align:left;vertical-align:top}
+h2{border-bottom:1px solid #d0d7de;padding-bottom:.3em}a{color:#0969da}nav{margin-bottom:1em}`
+
+// html returns a page as a static html document. The index is linked from every other page.
+func (p *page) html() []byte {
+	var b strings.Builder
+
+	b.WriteString("<!DOCTYPE html>\n" + Header + "\n")
+	b.WriteString("<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", html.EscapeString(p.title), style)
+
+	if p.name != indexPage {
+		fmt.Fprintf(&b, "<nav><a href=\"%s.html\">Index</a></nav>\n", indexPage)
+	}
+
+	for _, bl := range p.blocks {
+		switch bl.kind {
+		case blockHeading:
+			id := ""
+			if len(bl.anchor) > 0 {
+				id = fmt.Sprintf(" id=\"%s\"", bl.anchor)
+			}
+			fmt.Fprintf(&b, "<h%d%s>%s</h%d>\n", bl.level, id, htmlText(bl.text), bl.level)
+		case blockParagraph:
+			fmt.Fprintf(&b, "<p>%s</p>\n", htmlText(bl.text))
+		case blockTable:
+			b.WriteString("<table>\n<thead><tr>")
+			for _, h := range bl.header {
+				fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(h))
+			}
+			b.WriteString("</tr></thead>\n<tbody>\n")
+			for _, row := range bl.rows {
+				b.WriteString("<tr>")
+				for _, cell := range row {
+					fmt.Fprintf(&b, "<td>%s</td>", htmlText(cell))
+				}
+				b.WriteString("</tr>\n")
+			}
+			b.WriteString("</tbody>\n</table>\n")
+		case blockCode:
+			class := ""
+			if len(bl.lang) > 0 {
+				class = fmt.Sprintf(" class=\"language-%s\"", bl.lang)
+			}
+			fmt.Fprintf(&b, "<pre><code%s>%s</code></pre>\n", class, html.EscapeString(strings.TrimRight(bl.code, "\n")))
+		case blockList:
+			b.WriteString("<ul>\n")
+			for _, item := range bl.items {
+				fmt.Fprintf(&b, "<li>%s</li>\n", htmlText(item))
+			}
+			b.WriteString("</ul>\n")
+		}
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return []byte(b.String())
+}
+
+// htmlText returns inline text as html. Text from the model keeps its line breaks, the markdown in it is left as is.
+func htmlText(t text) string {
+	var b strings.Builder
+
+	for _, s := range t {
+		out := html.EscapeString(s.text)
+		switch {
+		case s.code:
+			out = "<code>" + out + "</code>"
+		case s.raw:
+			out = strings.ReplaceAll(html.EscapeString(strings.TrimSpace(strings.ReplaceAll(s.text, "\r\n", "\n"))), "\n", "<br>\n")
+		}
+
+		if s.strong {
+			out = "<strong>" + out + "</strong>"
+		}
+		if len(s.page) > 0 || len(s.anchor) > 0 {
+			out = "<a href=\"" + html.EscapeString(href(s.page, s.anchor, ".html")) + "\">" + out + "</a>"
+		}
+
+		b.WriteString(out)
+	}
+
+	return b.String()
+}
+
+// href returns the link to an anchor of a page, a page being written with the provided extension
+func href(page, anchor, ext string) string {
+	if len(page) <= 0 {
+		return "#" + anchor
+	}
+	if len(anchor) <= 0 {
+		return page + ext
+	}
+
+	return page + ext + "#" + anchor
+}
@@ -0,0 +1,171 @@
+package docs
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+)
+
+// propertyHeader is the header of a table of properties
+var propertyHeader = []string{"Name", "Type", "Required", "Description"}
+
+// maxDepth is how deep the properties of inline objects in inline objects are documented
+const maxDepth = 8
+
+// component returns the type of a component where it is used: a link to the schemas page for a defined component, the
+// type of the component itself for any other
+func (d *docs) component(comp *types.Component) text {
+	// bodies and parameters can hold a copy of the component, the one of the model is the one that has an anchor
+	if resolved := generators.Resolve(d.components, comp); nil != resolved {
+		comp = resolved
+	}
+
+	if anchor, ok := d.anchors[comp]; ok {
+		return text{{text: comp.Name, code: true, page: schemasPage, anchor: anchor}}
+	}
+
+	// an inline component that contains itself is only an object where it is used again
+	if d.visiting[comp] {
+		return text{code(primitiveName(comp.Type, comp.Format))}
+	}
+	d.visiting[comp] = true
+	defer delete(d.visiting, comp)
+
+	ref := comp.Ref
+	if generators.Resolve(d.components, ref) == comp {
+		ref = nil
+	}
+
+	return d.typeOf(comp.Type, comp.Format, comp.Enums, comp.Null, ref, comp.Properties)
+}
+
+// typeOf returns the type of a value with the provided type, format, enums, ref and properties, as found on a Component,
+// Property or Parameter, e.g. array of Pet, string (date-time) or string, one of a, b
+func (d *docs) typeOf(typ, format string, enums []string, null *bool, ref any, props types.Properties) text {
+	var t text
+
+	switch {
+	case typ == "array":
+		t = append(text{plain("array of ")}, d.items(ref)...)
+	case typ == "object" && format == "map":
+		t = append(text{plain("map of ")}, d.items(ref)...)
+	case nil != generators.Resolve(d.components, ref):
+		t = d.component(generators.Resolve(d.components, ref))
+	default:
+		t = text{code(primitiveName(typ, format))}
+		for i, v := range enums {
+			if i == 0 {
+				t = append(t, plain(", one of "))
+			} else {
+				t = append(t, plain(", "))
+			}
+			t = append(t, code(v))
+		}
+	}
+
+	if nil != null && *null {
+		t = append(t, plain(" or null"))
+	}
+
+	return t
+}
+
+// items returns the type of the items of an array or the values of a map
+func (d *docs) items(ref any) text {
+	if s, ok := ref.(string); ok && generators.Primitive(s) {
+		switch s {
+		case "string", "boolean", "object", "array":
+			return text{code(s)}
+		default:
+			return text{code(primitiveName("number", s))}
+		}
+	}
+
+	if nil == ref {
+		return text{code("any")}
+	}
+
+	if comp := generators.Resolve(d.components, ref); nil != comp {
+		return d.component(comp)
+	}
+
+	return text{code(fmt.Sprint(ref))}
+}
+
+// rows returns the rows of the table of the properties of a component, or of its items when it is an array (or map) of
+// inline objects. There are none for any other component.
+func (d *docs) rows(comp *types.Component) [][]text {
+	switch {
+	case comp.Type == "object" && comp.Format != "map":
+		return d.propertyRows("", comp.Properties, 0)
+	case comp.Type == "array" && nil == generators.Resolve(d.components, comp.Ref):
+		return d.propertyRows("[].", comp.Properties, 0)
+	case comp.Type == "object" && nil == generators.Resolve(d.components, comp.Ref):
+		return d.propertyRows("{}.", comp.Properties, 0)
+	default:
+		return nil
+	}
+}
+
+// propertyRows returns a row per property. The properties of an inline object (or of the inline object items of an array or
+// map) follow the property, named with its name as prefix: owner.name, tags[].name or labels{}.x.
+func (d *docs) propertyRows(prefix string, props types.Properties, depth int) [][]text {
+	rows := make([][]text, 0, len(props))
+
+	for _, p := range props {
+		if nil == p {
+			continue
+		}
+
+		name := p.RawName
+		if len(name) <= 0 {
+			name = p.Name
+		}
+		name = prefix + name
+
+		required := nil != p.Required && *p.Required
+		rows = append(rows, []text{{code(name)}, d.typeOf(p.Type, p.Format, p.Enums, p.Null, p.Ref, p.Properties), {plain(yesNo(required))}, {raw(p.Description)}})
+
+		if len(p.Properties) <= 0 || depth >= maxDepth || nil != generators.Resolve(d.components, p.Ref) {
+			continue
+		}
+
+		switch {
+		case p.Type == "array":
+			rows = append(rows, d.propertyRows(name+"[].", p.Properties, depth+1)...)
+		case p.Type == "object" && p.Format == "map":
+			rows = append(rows, d.propertyRows(name+"{}.", p.Properties, depth+1)...)
+		case p.Type == "object":
+			rows = append(rows, d.propertyRows(name+".", p.Properties, depth+1)...)
+		}
+	}
+
+	return rows
+}
+
+// primitiveName returns the name of a primitive type with its format, numbers being an integer or number (see
+// loaders.SchemaConverter.TypeOf)
+func primitiveName(typ, format string) string {
+	switch typ {
+	case "number", "integer":
+		switch format {
+		case "int":
+			return "integer"
+		case "int32", "int64":
+			return "integer (" + format + ")"
+		case "float32":
+			return "number (float)"
+		case "float64", "":
+			return typ
+		default:
+			return typ + " (" + format + ")"
+		}
+	case "":
+		return "any"
+	default:
+		if len(format) > 0 {
+			return typ + " (" + format + ")"
+		}
+		return typ
+	}
+}
@@ -0,0 +1,260 @@
+// Package generators holds what the generators built in to this repository have in common, such as reading options,
+// naming identifiers and picking the components of the model that get a type of their own. Each generator lives in its
+// own package below this one and implements pipeline.Generator.
+package generators
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Option returns the value of an option of the request, or fallback when it is not set
+func Option(request *types.GeneratorRequest, key, fallback string) string {
+	if nil != request && nil != request.Options {
+		if value, ok := request.Options[key]; ok && len(strings.TrimSpace(value)) > 0 {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return fallback
+}
+
+// BoolOption returns the value of a true/false option of the request, or fallback when it is not set
+func BoolOption(request *types.GeneratorRequest, key string, fallback bool) bool {
+	switch strings.ToLower(Option(request, key, "")) {
+	case "true", "yes", "1":
+		return true
+	case "false", "no", "0":
+		return false
+	default:
+		return fallback
+	}
+}
+
+// Words
+//
+// This function splits a name in to its words. Anything that is not a letter or digit separates words, as does a change
+// of case, so pet_id, pet-id, petId and PetID all become pet and id (in their original case).
+func Words(name string) []string {
+	words := make([]string, 0)
+	runes := []rune(name)
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// Pascal returns a name as PascalCase, e.g. pet_id is PetId
+func Pascal(name string) string {
+	var b strings.Builder
+
+	for _, w := range Words(name) {
+		runes := []rune(strings.ToLower(w))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	return b.String()
+}
+
+// Camel returns a name as camelCase, e.g. pet_id is petId
+func Camel(name string) string {
+	pascal := []rune(Pascal(name))
+	if len(pascal) > 0 {
+		pascal[0] = unicode.ToLower(pascal[0])
+	}
+
+	return string(pascal)
+}
+
+// Snake returns a name as snake_case, e.g. petId is pet_id
+func Snake(name string) string {
+	words := Words(name)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+
+	return strings.Join(words, "_")
+}
+
+// Comment
+//
+// This function returns text as comment lines, each line starting with the provided prefix (e.g. "// " or "# ") and
+// ending with a new line. Blank lines are kept as an empty comment, an empty text returns an empty string.
+func Comment(prefix, text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if len(text) <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if len(line) <= 0 {
+			b.WriteString(strings.TrimRightFunc(prefix, unicode.IsSpace))
+		} else {
+			b.WriteString(prefix)
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// RepeatsName returns true when a description only repeats the name it describes (ignoring case, spaces and separators),
+// e.g. the title a loader uses as the description of a schema that has none
+func RepeatsName(description, name string) bool {
+	return len(strings.TrimSpace(description)) > 0 && Pascal(description) == Pascal(name)
+}
+
+// ModelComponents
+//
+// This function returns the components of a model that get a type of their own in generated code: the defined, inlined
+// (request and response bodies) and parameter components. They are sorted by name, then source document and version so
+// generators name and write them in the same order on every run.
+func ModelComponents(components types.Components) types.Components {
+	selected := make(types.Components, 0, len(components))
+
+	for _, comp := range components {
+		switch comp.Source {
+		case types.SourceComponent, types.SourceInline, types.SourceRequestBodyInline, types.SourceResponseBodyInline, types.SourceParameter:
+			selected = append(selected, comp)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := selected[i], selected[j]
+		switch {
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		case a.SourceDoc != b.SourceDoc:
+			return a.SourceDoc < b.SourceDoc
+		case a.Version != b.Version:
+			return a.Version < b.Version
+		default:
+			return a.Source < b.Source
+		}
+	})
+
+	return selected
+}
+
+// Primitive returns true for the types an array or map Ref holds for primitive items
+func Primitive(ref string) bool {
+	switch ref {
+	case "string", "number", "integer", "boolean", "object", "array", "int", "int32", "int64", "float32", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+// Resolve returns the component of the provided components a Ref points to, or nil when it is the primitive type of array
+// items or can not be found
+func Resolve(components types.Components, ref any) *types.Component {
+	if s, ok := ref.(string); ok && Primitive(s) {
+		return nil
+	}
+
+	return components.ResolveRef(ref)
+}
+
+// RefType
+//
+// This function returns the type of the component a Ref points to. The items of arrays (and values of maps) that are
+// arrays or maps themselves are SourceProperty components, they have no type of their own and are written in place by
+// inPlace. Any other component is referred to by the name of its type.
+func RefType(comp *types.Component, name func(*types.Component) string, inPlace func(*types.Component) string) string {
+	if comp.Source == types.SourceProperty {
+		return inPlace(comp)
+	}
+
+	return name(comp)
+}
+
+// Unique returns name, or name with a number appended if it is already taken, and marks what it returns as taken. An
+// empty name is Model.
+func Unique(taken map[string]bool, name string) string {
+	if len(name) <= 0 {
+		name = "Model"
+	}
+
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+
+	taken[unique] = true
+	return unique
+}
+
+// Unresolved adds the warning of a generator (e.g. go-models) that the type with the provided name references something
+// that is not in the model, and is generated as the provided fallback type instead
+func Unresolved(diagnostics *types.Diagnostics, generator, name string, ref any, fallback string) {
+	refName := fmt.Sprint(ref)
+	if r, ok := ref.(map[string]any); ok {
+		refName = fmt.Sprint(r["Name"])
+	}
+
+	diagnostics.Warn(generator+"-unresolved", "", "%s references %s which is not in the model, it is generated as %s", name, refName, fallback)
+}
+
+// HTTPResources
+//
+// This function returns the resources a generator of HTTP clients or servers has a use for: the Latest version of every
+// HTTP resource, sorted by path and method. Folders and resources of other types (gRPC, async, GraphQL, SOAP, ...) are
+// left out, the number of them is returned as well so generators can report it.
+func HTTPResources(resources types.Resources) (types.Resources, int) {
+	selected := make(types.Resources, 0, len(resources))
+	skipped := 0
+
+	for _, res := range resources {
+		switch {
+		case nil == res || res.ResourceType == types.FOLDER:
+		case res.ResourceType != types.HTTP && res.ResourceType != 0:
+			skipped++
+		case res.Latest:
+			selected = append(selected, res)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Path != selected[j].Path {
+			return selected[i].Path < selected[j].Path
+		}
+		return selected[i].Method < selected[j].Method
+	})
+
+	return selected, skipped
+}
@@ -0,0 +1,126 @@
+// Package generatortest holds what the tests of the built in generators have in common: a model of a pet store with the
+// cases the generators handle, running a generator on a model and testing the Go code a generator writes with the go
+// command.
+package generatortest
+
+import (
+	"github.com/spirefy/go-codegen/pipeline"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// Petstore
+//
+// This function returns a model of a pet store: a list, create, show and delete resource with path, query and header
+// parameters, a JSON request body, 2xx statuses with and without a body and error statuses (a specific one and the
+// default) with an Error body. Every call returns a new model, generators may change the one they are given.
+func Petstore() *types.LoadedResponse {
+	pet := &types.Component{Id: 1, Name: "Pet", Type: "object", Description: "A pet of the store", Source: types.SourceComponent, Properties: types.Properties{
+		{Name: "id", Type: "integer", Format: "int64", Required: boolPtr(true)},
+		{Name: "name", Type: "string", Required: boolPtr(true), Description: "The name of the pet"},
+		{Name: "tag", Type: "string"},
+		{Name: "bornAt", RawName: "born_at", Type: "string", Format: "date-time", Null: boolPtr(true)},
+		{Name: "status", Type: "string", Enums: []string{"available", "sold"}},
+	}}
+	newPet := &types.Component{Id: 2, Name: "NewPet", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+		{Name: "name", Type: "string", Required: boolPtr(true)},
+		{Name: "tag", Type: "string"},
+	}}
+	apiError := &types.Component{Id: 3, Name: "Error", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+		{Name: "code", Type: "integer", Format: "int32", Required: boolPtr(true)},
+		{Name: "message", Type: "string", Required: boolPtr(true)},
+	}}
+	pets := &types.Component{Id: 4, Name: "ListPets200Response", Type: "array", Ref: pet, Source: types.SourceResponseBodyInline}
+
+	body := func(schema *types.Component) types.ResponseBodies {
+		return types.ResponseBodies{{MediaType: "application/json", Schema: schema}}
+	}
+	petId := &types.Parameter{Name: "petId", In: types.PATH, Required: true, Type: "string", Description: "The id of the pet"}
+
+	model := types.NewLoadedResponse()
+	model.Components = types.Components{pet, newPet, apiError, pets}
+	model.Resources = types.Resources{
+		{Id: 1, Name: "listPets", Summary: "List all pets", Path: "/pets", Method: "get", ResourceType: types.HTTP, Latest: true,
+			Parameters: []*types.Parameter{
+				{Name: "limit", In: types.QUERY, Type: "integer", Format: "int32", Description: "How many pets to return"},
+				{Name: "X-Trace", In: types.HEADER, Type: "string"},
+			},
+			Responses: types.Responses{{Status: "200", ResponseBodies: body(pets)}, {Status: "default", ResponseBodies: body(apiError)}},
+		},
+		{Id: 2, Name: "createPet", Path: "/pets", Method: "post", ResourceType: types.HTTP, Latest: true,
+			Requests:  types.Requests{{Required: true, ContentType: "application/json", Type: "JSON", Schema: newPet}},
+			Responses: types.Responses{{Status: "201", ResponseBodies: body(pet)}, {Status: "default", ResponseBodies: body(apiError)}},
+		},
+		{Id: 3, Name: "showPetById", Path: "/pets/{petId}", Method: "get", ResourceType: types.HTTP, Latest: true,
+			Parameters: []*types.Parameter{petId},
+			Responses:  types.Responses{{Status: "200", ResponseBodies: body(pet)}, {Status: "404", ResponseBodies: body(apiError)}},
+		},
+		{Id: 4, Name: "deletePet", Path: "/pets/{petId}", Method: "delete", ResourceType: types.HTTP, Latest: true, Deprecated: true,
+			Parameters: []*types.Parameter{petId},
+			Responses:  types.Responses{{Status: "204"}},
+		},
+	}
+
+	return model
+}
+
+// Generate returns the files a generator writes for a model with the provided options, by path. The test fails right
+// away when the generator does.
+func Generate(t testing.TB, generator pipeline.Generator, model *types.LoadedResponse, options map[string]string) (map[string]string, types.Diagnostics) {
+	t.Helper()
+
+	resp, err := generator.Generate(&types.GeneratorRequest{Options: options, Model: model})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string, len(resp.Files))
+	for _, f := range resp.Files {
+		files[f.Path] = string(f.Content)
+	}
+
+	return files, resp.Diagnostics
+}
+
+// GoTest
+//
+// This function writes the files (generated files along with tests of them) in to a module of their own and runs their
+// tests with the go command, with the race detector. The test is skipped with -short or when the go command is not
+// installed, since the generated code only uses the standard library nothing needs to be downloaded.
+func GoTest(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if nil != err {
+		t.Skip("the go command is not installed")
+	}
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/generated\n\ngo 1.22\n"
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); nil != err {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	test := exec.Command(goBin, "test", "-race", "./...")
+	test.Dir = dir
+	if out, err := test.CombinedOutput(); nil != err {
+		t.Errorf("go test: %v\n%s", err, out)
+	}
+}
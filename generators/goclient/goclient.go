@@ -0,0 +1,662 @@
+// Package goclient is the built in generator of the go-client target. It writes a Go client with a method per HTTP
+// Resource of the model, named with types.MakeResourceName:
+//
+//   - path parameters are arguments of the method, in the order of the path template, query, header and cookie
+//     parameters are fields of a <Method>Params struct (pointers when not Required)
+//   - the request body is an argument as well, encoded according to Request.ContentType (json, xml, form, multipart,
+//     text or binary)
+//   - a 2xx response is returned as a <Method>Response with a Status<code> field per declared status, decoded in to the
+//     type of its ResponseBody.Schema
+//   - any other status is returned as a *ResponseError, whose Value holds the body decoded in to the type declared for the
+//     status (or the default response)
+//
+// The types of the components are written next to the client with the go-models generator, unless the models option is
+// false in which case the go-models target must be generated in to the same package.
+package goclient
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/generators/gomodels"
+	"github.com/spirefy/go-codegen/types"
+	"go/format"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target is the name of the target this generator writes
+const Target = "go-client"
+
+// Options of the go-client target
+const (
+	OptionPackage    = "package"    // The name of the Go package, client by default
+	OptionFile       = "file"       // The path of the client file relative to the output directory, <package>/client.go by default
+	OptionModels     = "models"     // Whether the models are written next to the client, true by default
+	OptionModelsFile = "modelsFile" // The path of the models file relative to the output directory, <package>/models.go by default
+	OptionBaseURL    = "baseUrl"    // The DefaultBaseURL of the client, taken from the host and basePath of the resources by default
+)
+
+// Generator implements pipeline.Generator for the go-client target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	pkg := gomodels.PackageName(generators.Option(request, OptionPackage, "client"))
+	resources, skipped := generators.HTTPResources(request.Model.Resources)
+
+	g := New(gomodels.New(request.Model.Components))
+
+	if skipped > 0 {
+		g.models.Diagnostics.Info("go-client-skipped", "", "%d resources are not HTTP resources and have no client method", skipped)
+	}
+
+	client, err := g.Source(pkg, resources, generators.Option(request, OptionBaseURL, ""))
+	if nil != err {
+		return nil, err
+	}
+
+	files := []types.GeneratedFile{{Path: generators.Option(request, OptionFile, path.Join(pkg, "client.go")), Content: client}}
+
+	if generators.BoolOption(request, OptionModels, true) {
+		models, err := g.models.Source(pkg)
+		if nil != err {
+			return nil, err
+		}
+
+		files = append(files, types.GeneratedFile{Path: generators.Option(request, OptionModelsFile, path.Join(pkg, "models.go")), Content: models})
+	}
+
+	return &types.GeneratorResponse{Files: files, Diagnostics: g.models.Diagnostics}, nil
+}
+
+// Client
+//
+// This writes the client of the resources of a model. It is exported for generators writing code that calls the client
+// from the same package (e.g. go-workflows), which look up the Method of a resource once Source has been called.
+type Client struct {
+	models    *gomodels.Models
+	methods   map[string]bool
+	resources map[int]*Method // by the Id of the resource, as a copy of it (e.g. the Resource of a Step) has the same one
+
+	// names of the types and functions of the client itself, reserved so no model can take them
+	clientType, errorType, newClient, baseURL string
+
+	// encodings used by at least one method, their helpers are only written when used
+	form, multipart bool
+
+	b strings.Builder
+}
+
+// Arg is a path argument of a method, or a field of its Params struct
+type Arg struct {
+	Name     string
+	Param    *types.Parameter
+	Type     string
+	Optional bool // the field is a pointer (see gomodels.Models.Optional) as the parameter is not Required
+}
+
+// Method is the method of the client calling a resource
+type Method struct {
+	Name     string
+	PathArgs []*Arg // in the order of the path template
+	Params   string // the name of the Params struct, empty when the method has none
+	Fields   []*Arg // of the Params struct
+	Body     string // the Go type of the body argument, empty when the method has none
+	Response string // the name of the Response struct
+}
+
+// New returns a Client writing the types it declares to the provided models
+func New(models *gomodels.Models) *Client {
+	// the fields of the client type can not be the name of a method either
+	methods := map[string]bool{"BaseURL": true, "HTTPClient": true, "Header": true}
+
+	return &Client{models: models, methods: methods, resources: make(map[int]*Method)}
+}
+
+// Type returns the name of the client type, set once Source has been called
+func (g *Client) Type() string {
+	return g.clientType
+}
+
+// ErrorType returns the name of the error type of responses with a status other than 2xx, set once Source has been called
+func (g *Client) ErrorType() string {
+	return g.errorType
+}
+
+// Reserve returns a name for another method of the client type, numbered the way the names of the methods calling
+// resources are when the client already has a method or field with the name
+func (g *Client) Reserve(name string) string {
+	unique := name
+	for i := 2; g.methods[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.methods[unique] = true
+
+	return unique
+}
+
+// Method returns the method calling a resource, or nil when Source was not called with the resource
+func (g *Client) Method(res *types.Resource) *Method {
+	if nil == res {
+		return nil
+	}
+
+	return g.resources[res.Id]
+}
+
+// Source returns the formatted source of the client file
+func (g *Client) Source(pkg string, resources types.Resources, baseURL string) ([]byte, error) {
+	g.clientType = g.models.Reserve("Client")
+	g.errorType = g.models.Reserve("ResponseError")
+	g.newClient = g.models.Reserve("New" + g.clientType)
+	g.baseURL = g.models.Reserve("DefaultBaseURL")
+
+	basePath := generators.BasePath(resources)
+	if len(baseURL) <= 0 {
+		baseURL = generators.BaseURL(resources, basePath)
+	}
+
+	var methods strings.Builder
+	for _, res := range resources {
+		prefix := ""
+		if strings.TrimSuffix(res.Variables["basePath"], "/") != basePath {
+			prefix = strings.TrimSuffix(res.Variables["basePath"], "/")
+		}
+
+		g.method(&methods, res, prefix)
+	}
+
+	g.b.WriteString(gomodels.Header + "\n\n")
+	g.b.WriteString("package " + pkg + "\n\n")
+	g.imports()
+	g.core(baseURL)
+	g.b.WriteString(methods.String())
+	g.helpers()
+
+	source, err := format.Source([]byte(g.b.String()))
+	if nil != err {
+		return nil, fmt.Errorf("problem formatting the generated client: %w", err)
+	}
+
+	return source, nil
+}
+
+func (g *Client) imports() {
+	imports := []string{"bytes", "context", "encoding/json", "encoding/xml", "fmt", "io", "net/http", "net/url", "strings"}
+	if g.multipart {
+		imports = append(imports, "mime/multipart", "reflect")
+	}
+	sort.Strings(imports)
+
+	g.b.WriteString("import (\n")
+	for _, imp := range imports {
+		g.b.WriteString(strconv.Quote(imp) + "\n")
+	}
+	g.b.WriteString(")\n\n")
+}
+
+// core writes the client type, its constructor, the error type and the method sending the requests
+func (g *Client) core(baseURL string) {
+	fmt.Fprintf(&g.b, `// %[2]s is the base url used by %[3]s when none is provided
+const %[2]s = %[4]s
+
+// %[1]s
+//
+// This calls the resources of the API. BaseURL is the scheme, host and base path the paths of the resources are
+// appended to, HTTPClient sends the requests (http.DefaultClient when nil) and Header holds the headers sent with every
+// request, e.g. an Authorization header.
+type %[1]s struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Header     http.Header
+}
+
+// %[3]s returns a client for the provided base url, or %[2]s when it is empty
+func %[3]s(baseURL string) *%[1]s {
+	if baseURL == "" {
+		baseURL = %[2]s
+	}
+
+	return &%[1]s{BaseURL: baseURL, Header: http.Header{}}
+}
+
+// %[5]s
+//
+// This is the error returned for a response with a status other than 2xx. Value holds a pointer to the body decoded in
+// to the type the resource declares for the status, or nil when it declares none (or the body could not be decoded).
+type %[5]s struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Value      any
+}
+
+func (e *%[5]s) Error() string {
+	return fmt.Sprintf("%%s %%s returned status %%d", e.Method, e.Path, e.StatusCode)
+}
+
+// do sends a request and returns the response along with its body, which is read and closed
+func (c *%[1]s) do(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader) (*http.Response, []byte, error) {
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for k, v := range c.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, data, nil
+}
+
+`, g.clientType, g.baseURL, g.newClient, strconv.Quote(baseURL), g.errorType)
+}
+
+// method writes the method of a resource along with its Params and Response types
+func (g *Client) method(b *strings.Builder, res *types.Resource, prefix string) {
+	name := gomodels.GoName(types.MakeResourceName(res.Name, res.Method, res.Path, ""))
+	if len(name) <= 0 {
+		name = "Call"
+	}
+	name = g.Reserve(name)
+
+	method := strings.ToUpper(res.Method)
+	taken := map[string]bool{"c": true, "ctx": true, "params": true, "body": true, "path": true, "query": true, "header": true,
+		"reader": true, "resp": true, "data": true, "err": true, "result": true, "value": true, "raw": true, "form": true,
+		"contentType": true}
+
+	// path parameters are arguments in the order of the template, placeholders without a parameter are strings
+	pathArgs := make([]*Arg, 0)
+	for _, placeholder := range generators.PathParams(res.Path) {
+		p := generators.FindParam(res.Parameters, types.PATH, placeholder)
+		if nil == p {
+			p = &types.Parameter{Name: placeholder, In: types.PATH, Required: true, Type: "string"}
+		}
+
+		argName := gomodels.LocalName(p.Name)
+		if len(argName) <= 0 {
+			argName = "param"
+		}
+		for i := 2; taken[argName]; i++ {
+			argName = gomodels.LocalName(p.Name) + strconv.Itoa(i)
+		}
+		taken[argName] = true
+
+		pathArgs = append(pathArgs, &Arg{Name: argName, Param: p, Type: g.models.ParamType(name, p)})
+	}
+
+	// query, header and cookie parameters are fields of the params struct
+	fields := make([]*Arg, 0)
+	fieldNames := make(map[string]bool)
+	for _, p := range res.Parameters {
+		if nil == p || (p.In != types.QUERY && p.In != types.HEADER && p.In != types.COOKIE) {
+			continue
+		}
+
+		fieldName := gomodels.GoName(p.Name)
+		if len(fieldName) <= 0 {
+			fieldName = "Param"
+		}
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = gomodels.GoName(p.Name) + strconv.Itoa(i)
+		}
+		fieldNames[fieldName] = true
+
+		fields = append(fields, &Arg{Name: fieldName, Param: p, Type: g.models.ParamType(name, p), Optional: !p.Required})
+	}
+
+	request := generators.PickRequest(res.Requests)
+	bodyType, kind := "", ""
+	if nil != request {
+		kind = types.ContentKind(request.ContentType)
+		bodyType = g.models.BodyType(name+"Request", request.Schema, kind, true)
+		g.form = g.form || kind == "FORM"
+		g.multipart = g.multipart || kind == "MULTIPART"
+	}
+
+	// 2xx statuses are fields of the response, the bodies of the others are the Value of the error
+	success, failure := make([]*gomodels.Status, 0), make([]*gomodels.Status, 0)
+	for _, s := range g.models.Statuses(name, res.Responses) {
+		switch {
+		case generators.Success(s.Status):
+			success = append(success, s)
+		case len(s.Type) > 0:
+			failure = append(failure, s)
+		}
+	}
+
+	paramsType := ""
+	if len(fields) > 0 {
+		paramsType = g.models.Reserve(name + "Params")
+		fmt.Fprintf(b, "// %s holds the query, header and cookie parameters of %s\ntype %s struct {\n", paramsType, name, paramsType)
+		for _, f := range fields {
+			b.WriteString(generators.Comment("// ", f.Param.Description))
+
+			typ := f.Type
+			if f.Optional {
+				typ = g.models.Optional(typ)
+			}
+			fmt.Fprintf(b, "%s %s\n", f.Name, typ)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	responseType := g.models.Reserve(name + "Response")
+	g.resources[res.Id] = &Method{Name: name, PathArgs: pathArgs, Params: paramsType, Fields: fields, Body: bodyType, Response: responseType}
+
+	fmt.Fprintf(b, "// %s is the response of %s, the field of the status it returned is set\ntype %s struct {\n", responseType, name, responseType)
+	b.WriteString("StatusCode int\nHeader http.Header\nBody []byte\n")
+	for _, s := range success {
+		if len(s.Type) > 0 {
+			fmt.Fprintf(b, "%s %s\n", s.Field, g.models.Optional(s.Type))
+		}
+	}
+	b.WriteString("}\n\n")
+
+	// the method itself
+	fmt.Fprintf(b, "// %s calls %s %s\n", name, method, res.Path)
+	if doc := strings.TrimSpace(strings.Join([]string{res.Summary, res.Description}, "\n\n")); len(doc) > 0 {
+		b.WriteString("//\n" + generators.Comment("// ", doc))
+	}
+	if res.Deprecated {
+		b.WriteString("//\n// Deprecated: the resource is deprecated.\n")
+	}
+
+	signature := []string{"ctx context.Context"}
+	for _, a := range pathArgs {
+		signature = append(signature, a.Name+" "+a.Type)
+	}
+	if len(paramsType) > 0 {
+		signature = append(signature, "params *"+paramsType)
+	}
+	if len(bodyType) > 0 {
+		signature = append(signature, "body "+bodyType)
+	}
+	fmt.Fprintf(b, "func (c *%s) %s(%s) (*%s, error) {\n", g.clientType, name, strings.Join(signature, ", "), responseType)
+
+	// the path, with every placeholder replaced by its escaped argument
+	segments := generators.PathSegments(res.Path)
+	path := strconv.Quote(prefix + segments[0])
+	for i, a := range pathArgs {
+		path += " + url.PathEscape(strings.Join(paramValues(" + a.Name + "), \",\"))"
+		if len(segments[i+1]) > 0 {
+			path += " + " + strconv.Quote(segments[i+1])
+		}
+	}
+	fmt.Fprintf(b, "path := %s\nquery := url.Values{}\nheader := http.Header{}\n\n", path)
+
+	if len(fields) > 0 {
+		b.WriteString("if params != nil {\n")
+		for _, f := range fields {
+			set := ""
+			switch f.Param.In {
+			case types.QUERY:
+				set = fmt.Sprintf("query[%[1]s] = append(query[%[1]s], paramValues(params.%[2]s)...)", strconv.Quote(f.Param.Name), f.Name)
+			case types.HEADER:
+				set = fmt.Sprintf("header.Set(%s, strings.Join(paramValues(params.%s), \",\"))", strconv.Quote(f.Param.Name), f.Name)
+			case types.COOKIE:
+				set = fmt.Sprintf("header.Add(\"Cookie\", (&http.Cookie{Name: %s, Value: strings.Join(paramValues(params.%s), \",\")}).String())", strconv.Quote(f.Param.Name), f.Name)
+			}
+
+			// optional fields are pointers, slices or maps
+			if f.Optional {
+				fmt.Fprintf(b, "if params.%s != nil {\n%s\n}\n", f.Name, set)
+			} else {
+				b.WriteString(set + "\n")
+			}
+		}
+		b.WriteString("}\n")
+	}
+	b.WriteString("\n")
+
+	g.encode(b, request, kind)
+
+	fmt.Fprintf(b, "\nresp, data, err := c.do(ctx, %s, path, query, header, reader)\nif err != nil {\nreturn nil, err\n}\n\n", strconv.Quote(method))
+	fmt.Fprintf(b, "result := &%s{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}\nswitch {\n", responseType)
+	for _, s := range success {
+		fmt.Fprintf(b, "case %s:\n", s.Condition("resp.StatusCode"))
+		switch {
+		case len(s.Type) <= 0:
+			b.WriteString("return result, nil\n")
+		case g.models.Pointer(s.Type):
+			fmt.Fprintf(b, "result.%s = new(%s)\nreturn result, decode(resp.Header, data, result.%s)\n", s.Field, s.Type, s.Field)
+		default:
+			fmt.Fprintf(b, "return result, decode(resp.Header, data, &result.%s)\n", s.Field)
+		}
+	}
+	b.WriteString("case resp.StatusCode >= 200 && resp.StatusCode <= 299:\nreturn result, nil\n}\n\n")
+
+	value := "nil"
+	if len(failure) == 1 && len(failure[0].Condition("")) <= 0 {
+		value = "value"
+		fmt.Fprintf(b, "var value any = new(%s)\n\n", failure[0].Type)
+	} else if len(failure) > 0 {
+		value = "value"
+		b.WriteString("var value any\nswitch {\n")
+		for _, s := range failure {
+			if condition := s.Condition("resp.StatusCode"); len(condition) <= 0 {
+				b.WriteString("default:\n")
+			} else {
+				fmt.Fprintf(b, "case %s:\n", condition)
+			}
+			fmt.Fprintf(b, "value = new(%s)\n", s.Type)
+		}
+		b.WriteString("}\n\n")
+	}
+	fmt.Fprintf(b, "return nil, newResponseError(%s, path, resp, data, %s)\n}\n\n", strconv.Quote(method), value)
+}
+
+// encode writes the statements setting reader to the encoded request body
+func (g *Client) encode(b *strings.Builder, request *types.Request, kind string) {
+	if nil == request {
+		b.WriteString("var reader io.Reader\n")
+		return
+	}
+
+	contentType := strconv.Quote(request.ContentType)
+	switch kind {
+	case "JSON":
+		fmt.Fprintf(b, "reader, err := encodeJSON(body)\nif err != nil {\nreturn nil, err\n}\nheader.Set(\"Content-Type\", %s)\n", contentType)
+	case "XML":
+		fmt.Fprintf(b, "raw, err := xml.Marshal(body)\nif err != nil {\nreturn nil, err\n}\nreader := bytes.NewReader(raw)\nheader.Set(\"Content-Type\", %s)\n", contentType)
+	case "FORM":
+		fmt.Fprintf(b, "form, err := formValues(body)\nif err != nil {\nreturn nil, err\n}\nreader := strings.NewReader(form.Encode())\nheader.Set(\"Content-Type\", %s)\n", contentType)
+	case "MULTIPART":
+		b.WriteString("reader, contentType, err := encodeMultipart(body)\nif err != nil {\nreturn nil, err\n}\nheader.Set(\"Content-Type\", contentType)\n")
+	case "TEXT":
+		fmt.Fprintf(b, "reader := strings.NewReader(body)\nheader.Set(\"Content-Type\", %s)\n", contentType)
+	default:
+		fmt.Fprintf(b, "reader := body\nheader.Set(\"Content-Type\", %s)\n", contentType)
+	}
+}
+
+// helpers writes the functions the methods use to encode and decode values
+func (g *Client) helpers() {
+	g.b.WriteString(fmt.Sprintf(`// newResponseError returns the error of a response with a status other than 2xx, value is the body decoded in to the
+// type declared for the status
+func newResponseError(method, path string, resp *http.Response, data []byte, value any) *%[1]s {
+	if value != nil && decode(resp.Header, data, value) != nil {
+		value = nil
+	}
+
+	return &%[1]s{Method: method, Path: path, StatusCode: resp.StatusCode, Header: resp.Header, Body: data, Value: value}
+}
+
+`, g.errorType))
+
+	g.b.WriteString(`// paramValues returns the string values of a parameter, one for every item of a slice. Values are written the way they
+// are in json, without the quotes of strings.
+func paramValues(v any) []string {
+	raw, err := json.Marshal(v)
+	if err != nil || string(raw) == "null" {
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		items = []json.RawMessage{raw}
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			values = append(values, s)
+		} else {
+			values = append(values, string(item))
+		}
+	}
+
+	return values
+}
+
+// encodeJSON returns a reader of the json encoding of v
+func encodeJSON(v any) (io.Reader, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(raw), nil
+}
+
+// decode decodes the body of a response in to v, as xml when the response says it is and as json otherwise
+func decode(header http.Header, data []byte, v any) error {
+	switch t := v.(type) {
+	case *string:
+		*t = string(data)
+		return nil
+	case *[]byte:
+		*t = data
+		return nil
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	if strings.Contains(header.Get("Content-Type"), "xml") {
+		return xml.Unmarshal(data, v)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+`)
+
+	if g.form || g.multipart {
+		g.b.WriteString(`// formValues returns the fields of v as form values, v is encoded as json first so the json names are used
+func formValues(v any) (url.Values, error) {
+	if values, ok := v.(url.Values); ok {
+		return values, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	for k, field := range fields {
+		values[k] = paramValues(field)
+	}
+
+	return values, nil
+}
+
+`)
+	}
+
+	if g.multipart {
+		g.b.WriteString(`// encodeMultipart returns the fields of v as multipart/form-data along with its content type. []byte fields are sent as
+// files, all others as form values.
+func encodeMultipart(v any) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() == reflect.Struct {
+		for i := 0; i < rv.NumField(); i++ {
+			name, _, _ := strings.Cut(rv.Type().Field(i).Tag.Get("json"), ",")
+			field := rv.Field(i)
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+
+			if file, ok := field.Interface().([]byte); ok {
+				part, err := w.CreateFormFile(name, name)
+				if err != nil {
+					return nil, "", err
+				}
+				if _, err := part.Write(file); err != nil {
+					return nil, "", err
+				}
+				continue
+			}
+
+			for _, value := range paramValues(field.Interface()) {
+				if err := w.WriteField(name, value); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+	} else {
+		values, err := formValues(v)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for k, vs := range values {
+			for _, value := range vs {
+				if err := w.WriteField(k, value); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return &buf, w.FormDataContentType(), nil
+}
+
+`)
+	}
+}
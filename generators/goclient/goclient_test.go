@@ -0,0 +1,191 @@
+package goclient
+
+import (
+	"github.com/spirefy/go-codegen/generators/generatortest"
+	"strings"
+	"testing"
+)
+
+// clientTest calls the client of the pet store with a server replying what each test wants
+const clientTest = `package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serve returns a client of a server replying with the status and body, after handing the request to check
+func serve(t *testing.T, status int, body string, check func(r *http.Request, body []byte)) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if check != nil {
+			check(r, data)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClient(server.URL)
+}
+
+// responseError returns the *ResponseError of err, failing the test when it is not one
+func responseError(t *testing.T, err error, status int) *ResponseError {
+	t.Helper()
+
+	var re *ResponseError
+	if !errors.As(err, &re) {
+		t.Fatalf("the error is %v, want a *ResponseError", err)
+	}
+	if re.StatusCode != status {
+		t.Errorf("StatusCode = %d, want %d", re.StatusCode, status)
+	}
+
+	return re
+}
+
+func TestListPets(t *testing.T) {
+	limit, trace := int32(5), "abc"
+	c := serve(t, 200, ` + "`" + `[{"id": 1, "name": "Rex", "born_at": "2020-01-02T03:04:05Z"}, {"id": 2, "name": "Tom", "status": "sold"}]` + "`" + `, func(r *http.Request, _ []byte) {
+		if r.Method != "GET" || r.URL.Path != "/pets" || r.URL.Query().Get("limit") != "5" || r.Header.Get("X-Trace") != "abc" {
+			t.Errorf("the request is %s %s with X-Trace %q", r.Method, r.URL, r.Header.Get("X-Trace"))
+		}
+	})
+
+	resp, err := c.ListPets(context.Background(), &ListPetsParams{Limit: &limit, XTrace: &trace})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Status200) != 2 || resp.Status200[0].ID != 1 || resp.Status200[1].Status == nil || *resp.Status200[1].Status != PetStatusSold {
+		t.Fatalf("Status200 = %+v", resp.Status200)
+	}
+	if born := resp.Status200[0].BornAt; born == nil || born.Year() != 2020 {
+		t.Errorf("BornAt = %v, want it in 2020", born)
+	}
+}
+
+func TestListPetsDefault(t *testing.T) {
+	c := serve(t, 500, ` + "`" + `{"code": 7, "message": "broken"}` + "`" + `, nil)
+
+	_, err := c.ListPets(context.Background(), nil)
+	re := responseError(t, err, 500)
+	if value, ok := re.Value.(*Error); !ok || value.Code != 7 || value.Message != "broken" {
+		t.Errorf("Value = %#v, want the Error of the default response", re.Value)
+	}
+}
+
+func TestShowPetByID(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		found  bool
+		value  bool // the error has an *Error Value
+		failed bool
+	}{
+		{"a declared 2xx status", 200, ` + "`" + `{"id": 3, "name": "Rex"}` + "`" + `, true, false, false},
+		{"a 2xx status that is not declared", 202, "", false, false, false},
+		{"a declared error status", 404, ` + "`" + `{"code": 404, "message": "no such pet"}` + "`" + `, false, true, true},
+		{"an error status that is not declared", 500, "oops", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := serve(t, tt.status, tt.body, func(r *http.Request, _ []byte) {
+				if r.URL.EscapedPath() != "/pets/a%2Fb" {
+					t.Errorf("the path is %s, want the pet id escaped", r.URL.EscapedPath())
+				}
+			})
+
+			resp, err := c.ShowPetByID(context.Background(), "a/b")
+			if tt.failed {
+				re := responseError(t, err, tt.status)
+				if _, ok := re.Value.(*Error); ok != tt.value {
+					t.Errorf("Value = %#v", re.Value)
+				}
+				if string(re.Body) != tt.body {
+					t.Errorf("Body = %q, want %q", re.Body, tt.body)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status || (resp.Status200 != nil) != tt.found {
+				t.Errorf("the response is %d with %+v", resp.StatusCode, resp.Status200)
+			}
+		})
+	}
+}
+
+func TestCreatePet(t *testing.T) {
+	tag := "dog"
+	c := serve(t, 201, ` + "`" + `{"id": 9, "name": "Rex", "tag": "dog"}` + "`" + `, func(r *http.Request, body []byte) {
+		var pet map[string]any
+		if err := json.Unmarshal(body, &pet); err != nil || pet["name"] != "Rex" || pet["tag"] != "dog" {
+			t.Errorf("the body is %s", body)
+		}
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("the request is %s with content type %s", r.Method, r.Header.Get("Content-Type"))
+		}
+	})
+
+	resp, err := c.CreatePet(context.Background(), NewPet{Name: "Rex", Tag: &tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status201 == nil || resp.Status201.ID != 9 {
+		t.Errorf("Status201 = %+v", resp.Status201)
+	}
+}
+
+func TestDeletePet(t *testing.T) {
+	c := serve(t, 204, "", nil)
+
+	resp, err := c.DeletePet(context.Background(), "3")
+	if err != nil || resp.StatusCode != 204 {
+		t.Errorf("DeletePet() = %+v, %v", resp, err)
+	}
+}
+`
+
+func TestClient(t *testing.T) {
+	files, diagnostics := generatortest.Generate(t, Generator{}, generatortest.Petstore(), nil)
+	if len(diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", diagnostics)
+	}
+
+	client := files["client/client.go"]
+	for _, want := range []string{
+		"func (c *Client) ListPets(ctx context.Context, params *ListPetsParams) (*ListPetsResponse, error)",
+		"func (c *Client) ShowPetByID(ctx context.Context, petID string) (*ShowPetByIDResponse, error)",
+		"func (c *Client) CreatePet(ctx context.Context, body NewPet) (*CreatePetResponse, error)",
+		"// Deprecated: the resource is deprecated.",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("the client does not have %q", want)
+		}
+	}
+
+	files["client/client_test.go"] = clientTest
+	generatortest.GoTest(t, files)
+}
+
+func TestClientWithoutModels(t *testing.T) {
+	files, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionModels: "false", OptionPackage: "petstore"})
+
+	if _, ok := files["petstore/client.go"]; !ok || len(files) != 1 {
+		t.Errorf("%d files, want only petstore/client.go", len(files))
+	}
+}
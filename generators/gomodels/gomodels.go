@@ -0,0 +1,574 @@
+// Package gomodels is the built in generator of the go-models target. It writes a Go type for every defined, inlined and
+// parameter Component of the model, in a single gofmt formatted file:
+//
+//   - an object becomes a struct with a json tagged field per Property, and inline object properties (or the items of an
+//     array of inline objects) become structs of their own named after the parent and property
+//   - a property that is not Required or can be Null is a pointer (slices and maps excepted) and optional ones are
+//     omitempty
+//   - Format picks the Go type of numbers (int, int32, int64, float32, float64) and strings (date-time is a time.Time,
+//     byte and binary are a []byte)
+//   - Enums become a named type with a constant per value
+//   - a Ref to another component uses the type of that component, arrays and maps use the type their Ref points to
+//
+// Types are written in order of name so the output only changes when the model does. The Models type is exported so the
+// other Go generators can use the same types for their parameters and bodies.
+package gomodels
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"go/format"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target is the name of the target this generator writes
+const Target = "go-models"
+
+// Options of the go-models target
+const (
+	OptionPackage = "package" // The name of the Go package, models by default
+	OptionFile    = "file"    // The path of the generated file relative to the output directory, <package>/models.go by default
+)
+
+// Header is the first line of every generated Go file, it marks the file as generated for go vet and linters
+const Header = "// Code generated by codegen. DO NOT EDIT."
+
+// Generator implements pipeline.Generator for the go-models target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	pkg := PackageName(generators.Option(request, OptionPackage, "models"))
+	models := New(request.Model.Components)
+
+	source, err := models.Source(pkg)
+	if nil != err {
+		return nil, err
+	}
+
+	return &types.GeneratorResponse{
+		Files:       []types.GeneratedFile{{Path: generators.Option(request, OptionFile, path.Join(pkg, "models.go")), Content: source}},
+		Diagnostics: models.Diagnostics,
+	}, nil
+}
+
+// The kinds of declarations
+const (
+	declStruct  = iota // type Name struct {...}
+	declDefined        // type Name <typ>
+	declEnum           // type Name <typ> along with a constant per enum value
+)
+
+type decl struct {
+	kind   int
+	name   string
+	doc    string
+	typ    string   // the underlying type of defined and enum types
+	fields []*field // the fields of a struct
+	consts []*constant
+}
+
+type field struct {
+	name     string
+	jsonName string
+	typ      string
+	doc      string
+	optional bool
+	null     bool
+}
+
+type constant struct {
+	name  string
+	value string
+}
+
+// Models
+//
+// This names and declares the Go types of the components of a model. Every component returned by
+// generators.ModelComponents has a type from the start, components only referenced by them and the types of inline
+// objects and enums get one as they are found. Diagnostics holds a warning for every Ref that could not be resolved.
+type Models struct {
+	Diagnostics types.Diagnostics
+
+	components types.Components
+	names      map[*types.Component]string
+	taken      map[string]bool
+	decls      map[string]*decl
+}
+
+// New returns the Models of the provided components, with a type declared for each of them
+func New(components types.Components) *Models {
+	m := &Models{
+		Diagnostics: make(types.Diagnostics, 0),
+		components:  components,
+		names:       make(map[*types.Component]string),
+		taken:       make(map[string]bool),
+		decls:       make(map[string]*decl),
+	}
+
+	// name every component before declaring any, so the types of inline objects can not take the name of one
+	selected := generators.ModelComponents(components)
+	for _, comp := range selected {
+		m.names[comp] = generators.Unique(m.taken, GoName(comp.Name))
+	}
+
+	for _, comp := range selected {
+		m.declare(comp)
+	}
+
+	return m
+}
+
+// Name returns the name of the Go type of a component, declaring it if the component did not have one yet
+func (m *Models) Name(comp *types.Component) string {
+	if name, ok := m.names[comp]; ok {
+		return name
+	}
+
+	m.names[comp] = generators.Unique(m.taken, GoName(comp.Name))
+	m.declare(comp)
+	return m.names[comp]
+}
+
+// Reserve
+//
+// This method returns a name for a type or function declared next to the models, e.g. by a client in the same package.
+// The name is returned as is unless a model (or anything reserved before) has it, then a number is appended.
+func (m *Models) Reserve(name string) string {
+	return generators.Unique(m.taken, name)
+}
+
+// Resolve returns the component a Ref points to, or nil when it is the primitive type of array items or can not be found
+func (m *Models) Resolve(ref any) *types.Component {
+	return generators.Resolve(m.components, ref)
+}
+
+// TypeOf
+//
+// This method returns the Go type of a value with the provided type, format, enums, ref and properties, as found on a
+// Component, Property or Parameter. The name is used for the type declared for it when it needs one of its own, i.e. an
+// inline object or an enum.
+func (m *Models) TypeOf(name, typ, format string, enums []string, ref any, props types.Properties) string {
+	switch {
+	case typ == "array":
+		return "[]" + m.itemType(name+"Item", ref, props)
+	case typ == "object" && format == "map":
+		return "map[string]" + m.itemType(name+"Value", ref, props)
+	}
+
+	if nil != ref {
+		if comp := m.Resolve(ref); nil != comp {
+			return generators.RefType(comp, m.Name, func(item *types.Component) string {
+				return m.TypeOf(name, item.Type, item.Format, item.Enums, item.Ref, item.Properties)
+			})
+		}
+
+		if s, ok := ref.(string); !ok || !generators.Primitive(s) {
+			generators.Unresolved(&m.Diagnostics, "go-models", name, ref, "any")
+		}
+	}
+
+	switch typ {
+	case "object":
+		if len(props) <= 0 {
+			return "map[string]any"
+		}
+
+		name = generators.Unique(m.taken, name)
+		m.decls[name] = &decl{kind: declStruct, name: name, fields: m.fields(name, props)}
+		return name
+	case "string", "number", "integer", "boolean":
+		base := primitiveType(typ, format)
+		if len(enums) <= 0 || typ == "boolean" {
+			return base
+		}
+
+		name = generators.Unique(m.taken, name)
+		m.enum(name, base, enums, "")
+		return name
+	default:
+		return "any"
+	}
+}
+
+// Pointer returns true when an optional or nullable value of the provided Go type should be a pointer, which is every type
+// other than slices, maps and interfaces
+func (m *Models) Pointer(typ string) bool {
+	for i := 0; i < 8; i++ {
+		d, ok := m.decls[typ]
+		if !ok || d.kind == declStruct {
+			break
+		}
+		typ = d.typ
+	}
+
+	switch typ {
+	case "any", "json.RawMessage", "url.Values", "io.Reader":
+		return false
+	default:
+		return !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[")
+	}
+}
+
+// Source returns the gofmt formatted source of a file of the provided package declaring every type in order of name
+func (m *Models) Source(pkg string) ([]byte, error) {
+	var b strings.Builder
+
+	b.WriteString(Header + "\n\n")
+	b.WriteString("package " + pkg + "\n\n")
+
+	names := make([]string, 0, len(m.decls))
+	usesTime := false
+	for name, d := range m.decls {
+		names = append(names, name)
+		usesTime = usesTime || strings.Contains(d.typ, "time.Time")
+		for _, f := range d.fields {
+			usesTime = usesTime || strings.Contains(f.typ, "time.Time")
+		}
+	}
+	sort.Strings(names)
+
+	if usesTime {
+		b.WriteString("import \"time\"\n\n")
+	}
+
+	for _, name := range names {
+		m.write(&b, m.decls[name])
+	}
+
+	source, err := format.Source([]byte(b.String()))
+	if nil != err {
+		return nil, fmt.Errorf("problem formatting the generated models: %w", err)
+	}
+
+	return source, nil
+}
+
+// declare adds the declaration of the type of a component
+func (m *Models) declare(comp *types.Component) {
+	name := m.names[comp]
+	doc := comp.Description
+	if generators.RepeatsName(doc, comp.Name) {
+		doc = ""
+	}
+
+	target := m.Resolve(comp.Ref)
+	if target == comp {
+		target = nil
+	}
+
+	switch {
+	case comp.Type == "object" && comp.Format != "map" && len(comp.Properties) > 0:
+		m.decls[name] = &decl{kind: declStruct, name: name, doc: doc}
+		m.decls[name].fields = m.fields(name, comp.Properties)
+	case comp.Type == "object" && comp.Format != "map" && nil != target:
+		m.decls[name] = &decl{kind: declDefined, name: name, doc: doc}
+		m.decls[name].typ = m.Name(target)
+	case len(comp.Enums) > 0 && (comp.Type == "string" || comp.Type == "number" || comp.Type == "integer"):
+		m.enum(name, primitiveType(comp.Type, comp.Format), comp.Enums, doc)
+	default:
+		m.decls[name] = &decl{kind: declDefined, name: name, doc: doc}
+
+		// a component can not be an inline object of its own, an array of them is an array of its item type
+		ref := comp.Ref
+		if comp.Type != "array" && comp.Format != "map" {
+			if nil != ref && nil == target && comp.Type == "object" {
+				generators.Unresolved(&m.Diagnostics, "go-models", name, ref, "any")
+			}
+			ref = nil
+		}
+		m.decls[name].typ = m.TypeOf(name, comp.Type, comp.Format, nil, ref, comp.Properties)
+	}
+}
+
+// fields returns the fields of the struct with the provided name for a list of properties
+func (m *Models) fields(name string, props types.Properties) []*field {
+	fields := make([]*field, 0, len(props))
+	taken := make(map[string]bool, len(props))
+
+	for _, p := range props {
+		if nil == p {
+			continue
+		}
+
+		fieldName := GoName(p.Name)
+		if len(fieldName) <= 0 {
+			fieldName = "Field"
+		}
+		for i := 2; taken[fieldName]; i++ {
+			fieldName = GoName(p.Name) + strconv.Itoa(i)
+		}
+		taken[fieldName] = true
+
+		jsonName := p.RawName
+		if len(jsonName) <= 0 {
+			jsonName = p.Name
+		}
+
+		fields = append(fields, &field{
+			name:     fieldName,
+			jsonName: jsonName,
+			typ:      m.TypeOf(name+fieldName, p.Type, p.Format, p.Enums, p.Ref, p.Properties),
+			doc:      p.Description,
+			optional: nil == p.Required || !*p.Required,
+			null:     nil != p.Null && *p.Null,
+		})
+	}
+
+	return fields
+}
+
+// itemType returns the Go type of the items of an array or the values of a map, ref being the component or primitive type
+// of the items and props the properties of inline object items
+func (m *Models) itemType(name string, ref any, props types.Properties) string {
+	if s, ok := ref.(string); ok && generators.Primitive(s) {
+		switch s {
+		case "object":
+			return m.TypeOf(name, "object", "", nil, nil, props)
+		case "array":
+			return "[]any"
+		case "int", "int32", "int64", "float32", "float64":
+			return s
+		default:
+			return m.TypeOf(name, s, s, nil, nil, nil)
+		}
+	}
+
+	if nil == ref {
+		if len(props) > 0 {
+			return m.TypeOf(name, "object", "", nil, nil, props)
+		}
+		return "any"
+	}
+
+	if comp := m.Resolve(ref); nil != comp {
+		return generators.RefType(comp, m.Name, func(item *types.Component) string {
+			return m.TypeOf(name, item.Type, item.Format, item.Enums, item.Ref, item.Properties)
+		})
+	}
+
+	generators.Unresolved(&m.Diagnostics, "go-models", name, ref, "any")
+	return "any"
+}
+
+// enum declares a named type with a constant per value. Values that are not valid for the type (e.g. a number enum value
+// that is not a number) are left out.
+func (m *Models) enum(name, typ string, values []string, doc string) {
+	d := &decl{kind: declEnum, name: name, typ: typ, doc: doc}
+	seen := make(map[string]bool, len(values))
+
+	for i, v := range values {
+		value := strconv.Quote(v)
+		if typ != "string" {
+			bits := 64
+			if strings.HasSuffix(typ, "32") {
+				bits = 32
+			}
+
+			if strings.HasPrefix(typ, "int") {
+				if _, err := strconv.ParseInt(v, 10, bits); nil != err {
+					continue
+				}
+			} else if _, err := strconv.ParseFloat(v, bits); nil != err {
+				continue
+			}
+			value = v
+		}
+
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+
+		suffix := words(v)
+		if len(suffix) <= 0 {
+			suffix = "Value" + strconv.Itoa(i+1)
+		}
+
+		d.consts = append(d.consts, &constant{name: generators.Unique(m.taken, name+suffix), value: value})
+	}
+
+	m.decls[name] = d
+}
+
+// write writes the declaration of a type, and its constants for an enum
+func (m *Models) write(b *strings.Builder, d *decl) {
+	if len(strings.TrimSpace(d.doc)) > 0 {
+		b.WriteString("// " + d.name + "\n//\n")
+		b.WriteString(generators.Comment("// ", d.doc))
+	}
+
+	switch d.kind {
+	case declStruct:
+		b.WriteString("type " + d.name + " struct {\n")
+		for _, f := range d.fields {
+			b.WriteString(generators.Comment("// ", f.doc))
+
+			typ := f.typ
+			if (f.optional || f.null || m.contains(typ, d.name, make(map[string]bool))) && m.Pointer(typ) {
+				typ = "*" + typ
+			}
+
+			tag := f.jsonName
+			if f.optional {
+				tag += ",omitempty"
+			}
+
+			b.WriteString(f.name + " " + typ + " `json:" + strconv.Quote(tag) + "`\n")
+		}
+		b.WriteString("}\n\n")
+	default:
+		b.WriteString("type " + d.name + " " + d.typ + "\n\n")
+	}
+
+	if len(d.consts) > 0 {
+		b.WriteString("// " + d.name + " values\nconst (\n")
+		for _, c := range d.consts {
+			b.WriteString(c.name + " " + d.name + " = " + c.value + "\n")
+		}
+		b.WriteString(")\n\n")
+	}
+}
+
+// contains returns true when a value of the type typ holds a value of the struct named name, through the fields of
+// structs that are not pointers. A required field of such a type must be a pointer or the struct would contain itself.
+func (m *Models) contains(typ, name string, seen map[string]bool) bool {
+	if typ == name {
+		return true
+	}
+
+	d, ok := m.decls[typ]
+	if !ok || seen[typ] {
+		return false
+	}
+	seen[typ] = true
+
+	if d.kind == declDefined {
+		return m.contains(d.typ, name, seen)
+	}
+
+	for _, f := range d.fields {
+		if !f.optional && !f.null && m.contains(f.typ, name, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// primitiveType returns the Go type of a string, number, integer or boolean with the provided format
+func primitiveType(typ, format string) string {
+	switch typ {
+	case "string":
+		switch strings.ToLower(format) {
+		case "date-time":
+			return "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		default:
+			return "string"
+		}
+	case "number", "integer":
+		switch format {
+		case "int", "int32", "int64", "float32", "float64":
+			return format
+		case "float":
+			return "float32"
+		case "double":
+			return "float64"
+		}
+
+		if typ == "integer" {
+			return "int"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	default:
+		return typ
+	}
+}
+
+// initialisms are the words Go names write in upper case
+var initialisms = map[string]bool{
+	"acl": true, "api": true, "ascii": true, "cpu": true, "css": true, "dns": true, "eof": true, "guid": true, "html": true,
+	"http": true, "https": true, "id": true, "ip": true, "json": true, "rpc": true, "sla": true, "smtp": true, "sql": true,
+	"ssh": true, "tcp": true, "tls": true, "ttl": true, "udp": true, "ui": true, "uid": true, "uri": true, "url": true,
+	"utf8": true, "uuid": true, "vm": true, "xml": true, "xsrf": true, "xss": true,
+}
+
+// GoName
+//
+// This function returns the exported Go identifier of a name, e.g. pet_id is PetID. Words are capitalized and the
+// common initialisms (ID, URL, HTTP, ...) are written in upper case. A name that would start with a digit gets an X in
+// front of it, one without any letter or digit returns an empty string.
+func GoName(name string) string {
+	n := words(name)
+	if len(n) > 0 && n[0] >= '0' && n[0] <= '9' {
+		n = "X" + n
+	}
+
+	return n
+}
+
+// words returns the words of a name joined as a Go identifier would, without making sure it does not start with a digit
+func words(name string) string {
+	var b strings.Builder
+
+	for _, w := range generators.Words(name) {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+		} else {
+			b.WriteString(generators.Pascal(w))
+		}
+	}
+
+	return b.String()
+}
+
+// keywords are the Go keywords and predeclared identifiers a local name can not be
+var keywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true, "select": true, "struct": true,
+	"switch": true, "type": true, "var": true, "any": true, "bool": true, "byte": true, "error": true, "string": true,
+	"int": true, "len": true, "new": true, "nil": true, "true": true, "false": true,
+}
+
+// LocalName
+//
+// This function returns the unexported Go identifier of a name, for arguments and variables, e.g. pet_id is petID and
+// type is typ. Names that would be a Go keyword or start with a digit get an underscore appended or prepended.
+func LocalName(name string) string {
+	parts := generators.Words(name)
+	if len(parts) <= 0 {
+		return ""
+	}
+
+	n := strings.ToLower(parts[0]) + words(strings.Join(parts[1:], " "))
+	switch {
+	case keywords[n]:
+		return n + "_"
+	case n[0] >= '0' && n[0] <= '9':
+		return "_" + n
+	default:
+		return n
+	}
+}
+
+// PackageName returns a valid Go package name for the provided name, e.g. pet-store is petstore
+func PackageName(name string) string {
+	pkg := strings.ToLower(strings.Join(generators.Words(name), ""))
+	if len(pkg) <= 0 {
+		return "models"
+	}
+
+	if pkg[0] >= '0' && pkg[0] <= '9' {
+		pkg = "x" + pkg
+	}
+
+	return pkg
+}
@@ -0,0 +1,126 @@
+package gomodels
+
+import (
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// pets is a model with the optional, nullable, enum and format cases of the generator
+func pets() types.Components {
+	owner := &types.Component{Id: 1, Name: "Owner", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+		{Name: "name", Type: "string", Required: boolPtr(true)},
+	}}
+
+	return types.Components{
+		owner,
+		{Id: 2, Name: "Pet", Type: "object", Description: "A pet of the store", Source: types.SourceComponent, Properties: types.Properties{
+			{Name: "id", Type: "integer", Format: "int64", Required: boolPtr(true)},
+			{Name: "name", Type: "string", Required: boolPtr(true), Description: "The name of the pet"},
+			{Name: "tag", Type: "string"},
+			{Name: "nickname", Type: "string", Required: boolPtr(true), Null: boolPtr(true)},
+			{Name: "bornAt", RawName: "born_at", Type: "string", Format: "date-time", Required: boolPtr(true)},
+			{Name: "weight", Type: "number", Format: "float"},
+			{Name: "legs", Type: "integer", Format: "int32"},
+			{Name: "photo", Type: "string", Format: "byte"},
+			{Name: "tags", Type: "array", Ref: "string"},
+			{Name: "labels", Type: "object", Format: "map", Ref: "string"},
+			{Name: "owner", Type: "object", Ref: owner},
+			{Name: "status", Type: "string", Enums: []string{"available", "sold"}, Required: boolPtr(true)},
+		}},
+		{Id: 3, Name: "Size", Type: "integer", Format: "int32", Enums: []string{"1", "2", "x"}, Source: types.SourceComponent},
+	}
+}
+
+func TestSource(t *testing.T) {
+	m := New(pets())
+	got, err := m.Source("models")
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if len(m.Diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", m.Diagnostics)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "pets.golden"))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("the source is not testdata/pets.golden:\n%s", got)
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	tests := []struct {
+		typ    string
+		format string
+		ref    any
+		want   string
+	}{
+		{"string", "", nil, "string"},
+		{"string", "date-time", nil, "time.Time"},
+		{"string", "date", nil, "string"},
+		{"string", "binary", nil, "[]byte"},
+		{"integer", "", nil, "int"},
+		{"integer", "int32", nil, "int32"},
+		{"integer", "int64", nil, "int64"},
+		{"number", "", nil, "float64"},
+		{"number", "int", nil, "int"},
+		{"number", "float", nil, "float32"},
+		{"number", "double", nil, "float64"},
+		{"boolean", "", nil, "bool"},
+		{"array", "", "int64", "[]int64"},
+		{"array", "", nil, "[]any"},
+		{"object", "map", "string", "map[string]string"},
+		{"object", "", nil, "map[string]any"},
+		{"", "", nil, "any"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ+" "+tt.format, func(t *testing.T) {
+			if got := New(nil).TypeOf("Value", tt.typ, tt.format, nil, tt.ref, nil); got != tt.want {
+				t.Errorf("TypeOf() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeOfUnresolved(t *testing.T) {
+	m := New(nil)
+	if got := m.TypeOf("Pet", "object", "", nil, "Missing", nil); got != "map[string]any" {
+		t.Errorf("TypeOf() = %s, want map[string]any", got)
+	}
+
+	if len(m.Diagnostics) != 1 || m.Diagnostics[0].Code != "go-models-unresolved" {
+		t.Errorf("diagnostics = %v, want a go-models-unresolved warning", m.Diagnostics)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"pet_id", "PetID"},
+		{"petId", "PetID"},
+		{"api-url", "APIURL"},
+		{"2fa", "X2fa"},
+		{"---", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GoName(tt.name); got != tt.want {
+				t.Errorf("GoName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package gomodels
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"strings"
+)
+
+// Status is a declared response status of a resource, along with the field and Go type generated clients and servers use
+// for its body
+type Status struct {
+	Status      string // The Response.Status, e.g. 200, 4XX or default
+	Field       string // The name of the field holding the body, e.g. Status200, Status4XX or StatusDefault
+	Type        string // The Go type of the body, empty when the response has none
+	ContentType string // The media type of the body
+}
+
+// Condition returns the Go expression that is true when the status code held by variable matches the status, or an empty
+// string for the default response
+func (s *Status) Condition(variable string) string {
+	switch low, high := generators.StatusRange(s.Status); {
+	case low == 0:
+		return ""
+	case low == high:
+		return fmt.Sprintf("%s == %d", variable, low)
+	default:
+		return fmt.Sprintf("%s >= %d && %s <= %d", variable, low, variable, high)
+	}
+}
+
+// Statuses returns the responses of the resource with the provided method name as Status values, ordered the way
+// generators.SortedResponses orders them
+func (m *Models) Statuses(method string, responses types.Responses) []*Status {
+	statuses := make([]*Status, 0, len(responses))
+
+	for _, r := range generators.SortedResponses(responses) {
+		code := strings.Join(generators.Words(strings.ToUpper(r.Status)), "")
+		if strings.EqualFold(code, "default") {
+			code = "Default"
+		}
+
+		s := &Status{Status: r.Status, Field: "Status" + code}
+		if body := generators.PickBody(r.ResponseBodies); nil != body {
+			s.ContentType = body.MediaType
+			s.Type = m.BodyType(method+code+"Response", body.Schema, types.ContentKind(body.MediaType), false)
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	return statuses
+}
+
+// ParamType returns the Go type of a parameter of the resource with the provided method name, the type of its component
+// when it has one
+func (m *Models) ParamType(method string, p *types.Parameter) string {
+	if len(p.Components) > 0 {
+		if comp := m.Resolve(p.Components[0]); nil != comp {
+			return m.Name(comp)
+		}
+	}
+
+	return m.TypeOf(method+GoName(p.Name)+"Param", p.Type, p.Format, nil, nil, nil)
+}
+
+// BodyType
+//
+// This method returns the Go type of a request (when request is true) or response body of the provided content kind (see
+// types.ContentKind). Text is always a string and binary content an io.Reader for requests and a []byte for responses,
+// other content is the type of its schema. Without a schema json is a json.RawMessage and form values a url.Values.
+func (m *Models) BodyType(name string, schema *types.Component, kind string, request bool) string {
+	switch kind {
+	case "TEXT":
+		return "string"
+	case "BINARY":
+		if request {
+			return "io.Reader"
+		}
+		return "[]byte"
+	}
+
+	if nil != schema {
+		if comp := m.Resolve(schema); nil != comp {
+			return m.Name(comp)
+		}
+	}
+
+	switch kind {
+	case "FORM":
+		return "url.Values"
+	case "JSON":
+		return "json.RawMessage"
+	default:
+		return "any"
+	}
+}
+
+// Optional returns the type of an optional field of the provided type, a pointer to it unless it is a slice, map or
+// interface
+func (m *Models) Optional(typ string) string {
+	if m.Pointer(typ) {
+		return "*" + typ
+	}
+
+	return typ
+}
@@ -0,0 +1,509 @@
+// Package goserver is the built in generator of the go-server target. It writes the Go glue between net/http and a
+// server implementing the HTTP Resources of the model:
+//
+//   - a Server interface with a method per resource, named with types.MakeResourceName. It embeds an interface per Root
+//     of the resources (see types.Resources.GetResourcesByHierarchy), each of them written to a file of its own
+//   - every method takes a <Method>Params struct holding the path, query, header and cookie parameters and the request
+//     body, decoded according to Request.ContentType, and returns a <Method>Response with a Status<code> field per
+//     declared status
+//   - a Router, an http.Handler mapping the Method and Path template of every resource to its method with the patterns of
+//     http.ServeMux (which needs Go 1.22 or later)
+//
+// The types of the components are written next to the server with the go-models generator, unless the models option is
+// false in which case the go-models target must be generated in to the same package.
+package goserver
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/generators/gomodels"
+	"github.com/spirefy/go-codegen/types"
+	"go/format"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target is the name of the target this generator writes
+const Target = "go-server"
+
+// Options of the go-server target
+const (
+	OptionPackage    = "package"    // The name of the Go package, server by default
+	OptionFile       = "file"       // The path of the file holding the Server interface and Router, <package>/server.go by default
+	OptionModels     = "models"     // Whether the models are written next to the server, true by default
+	OptionModelsFile = "modelsFile" // The path of the models file relative to the output directory, <package>/models.go by default
+)
+
+// Generator implements pipeline.Generator for the go-server target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	pkg := gomodels.PackageName(generators.Option(request, OptionPackage, "server"))
+	resources, skipped := generators.HTTPResources(request.Model.Resources)
+
+	g := &generator{
+		models:  gomodels.New(request.Model.Components),
+		methods: make(map[string]bool, len(resources)),
+		files:   make(map[string]bool),
+	}
+
+	if skipped > 0 {
+		g.models.Diagnostics.Info("go-server-skipped", "", "%d resources are not HTTP resources and have no server method", skipped)
+	}
+
+	files, err := g.server(pkg, generators.Option(request, OptionFile, path.Join(pkg, "server.go")), resources)
+	if nil != err {
+		return nil, err
+	}
+
+	if generators.BoolOption(request, OptionModels, true) {
+		models, err := g.models.Source(pkg)
+		if nil != err {
+			return nil, err
+		}
+
+		files = append(files, types.GeneratedFile{Path: generators.Option(request, OptionModelsFile, path.Join(pkg, "models.go")), Content: models})
+	}
+
+	return &types.GeneratorResponse{Files: files, Diagnostics: g.models.Diagnostics}, nil
+}
+
+type generator struct {
+	models  *gomodels.Models
+	methods map[string]bool
+	files   map[string]bool
+	dir     string // the directory of the generated files
+
+	// names of the types and functions of the server itself, reserved so no model can take them
+	serverType, routerType, newRouter, errorType string
+
+	// the interface of every root along with the routes of the router
+	roots  []string
+	routes []*route
+
+	// whether a form or multipart body is decoded by at least one handler, its helper is only written when used
+	form bool
+}
+
+// route is a pattern of the router along with the handler serving it
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  string
+}
+
+// field is a field of a Params struct
+type field struct {
+	name     string
+	param    *types.Parameter
+	wildcard string
+	typ      string
+	optional bool
+}
+
+// packages maps the packages the types of the models can refer to, to their import path
+var packages = map[string]string{"io": "io", "json": "encoding/json", "time": "time", "url": "net/url"}
+
+// identifier matches the names http.ServeMux accepts for a wildcard
+var identifier = regexp.MustCompile(`^[\pL_][\pL\pN_]*$`)
+
+// qualified matches the package qualified identifiers of a type
+var qualified = regexp.MustCompile(`\b(io|json|time|url)\.`)
+
+// server returns the server file and the file of every root, which are written in the directory of the server file
+func (g *generator) server(pkg, file string, resources types.Resources) ([]types.GeneratedFile, error) {
+	g.dir = path.Dir(file)
+	g.serverType = g.models.Reserve("Server")
+	g.routerType = g.models.Reserve("Router")
+	g.newRouter = g.models.Reserve("New" + g.routerType)
+	g.errorType = g.models.Reserve("RequestError")
+	g.files[file] = true
+
+	hierarchy := resources.GetResourcesByHierarchy()
+	roots := make([]string, 0, len(hierarchy))
+	for root := range hierarchy {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	files := make([]types.GeneratedFile, 0, len(roots)+2)
+	for _, root := range roots {
+		f, err := g.root(pkg, root, *hierarchy[root])
+		if nil != err {
+			return nil, err
+		}
+
+		if nil != f {
+			files = append(files, *f)
+		}
+	}
+
+	source, err := g.router(pkg)
+	if nil != err {
+		return nil, err
+	}
+
+	return append([]types.GeneratedFile{{Path: file, Content: source}}, files...), nil
+}
+
+// root returns the file of the resources under root, nil when none of them can be served
+func (g *generator) root(pkg, root string, resources types.Resources) (*types.GeneratedFile, error) {
+	name := gomodels.GoName(root)
+	if len(name) <= 0 {
+		name = "Root"
+	}
+
+	var decls, methods, handlers strings.Builder
+	typeNames := make([]string, 0)
+	iface := ""
+
+	for _, res := range resources {
+		// the resources are served below their base path
+		rt, wildcards, err := g.route(res.Method, strings.TrimSuffix(res.Variables["basePath"], "/")+res.Path)
+		if nil != err {
+			g.models.Diagnostics.Warn("go-server-route", res.SourceDoc, "%s %s is not served: %s", strings.ToUpper(res.Method), res.Path, err)
+			continue
+		}
+
+		if len(iface) <= 0 {
+			iface = g.models.Reserve(name + "Server")
+		}
+
+		typeNames = append(typeNames, g.resource(&decls, &methods, &handlers, res, iface, rt, wildcards)...)
+	}
+
+	if len(iface) <= 0 {
+		return nil, nil
+	}
+	g.roots = append(g.roots, iface)
+
+	imports := map[string]bool{"context": true, "net/http": true}
+	for _, typ := range typeNames {
+		for _, match := range qualified.FindAllStringSubmatch(typ, -1) {
+			imports[packages[match[1]]] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(gomodels.Header + "\n\n")
+	b.WriteString("package " + pkg + "\n\n")
+	writeImports(&b, imports)
+	b.WriteString(decls.String())
+	fmt.Fprintf(&b, "// %s serves the resources under %s\ntype %s interface {\n%s}\n\n", iface, root, iface, methods.String())
+	b.WriteString(handlers.String())
+
+	source, err := format.Source([]byte(b.String()))
+	if nil != err {
+		return nil, fmt.Errorf("problem formatting the generated server of %s: %w", root, err)
+	}
+
+	file := generators.Snake(root)
+	if len(file) <= 0 {
+		file = "root"
+	}
+	unique := path.Join(g.dir, file+"_server.go")
+	for i := 2; g.files[unique]; i++ {
+		unique = path.Join(g.dir, file+"_"+strconv.Itoa(i)+"_server.go")
+	}
+	g.files[unique] = true
+
+	return &types.GeneratedFile{Path: unique, Content: source}, nil
+}
+
+// route adds the route of a resource to the router, it returns the route and the wildcard name of every path parameter or
+// an error when the path can not be a http.ServeMux pattern or conflicts with a route that was added before
+func (g *generator) route(method, path string) (*route, []string, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	segments := generators.PathSegments(path)
+	placeholders := generators.PathParams(path)
+	wildcards := make([]string, 0, len(placeholders))
+	taken := make(map[string]bool, len(placeholders))
+
+	pattern := segments[0]
+	for i, placeholder := range placeholders {
+		if !strings.HasSuffix(segments[i], "/") || (len(segments[i+1]) > 0 && !strings.HasPrefix(segments[i+1], "/")) {
+			return nil, nil, fmt.Errorf("the parameter %s is only part of a path segment", placeholder)
+		}
+
+		base := generators.Camel(placeholder)
+		if !identifier.MatchString(base) {
+			base = "p" + strconv.Itoa(i+1)
+		}
+		wildcard := base
+		for n := 2; taken[wildcard]; n++ {
+			wildcard = base + strconv.Itoa(n)
+		}
+		taken[wildcard] = true
+		wildcards = append(wildcards, wildcard)
+
+		pattern += "{" + wildcard + "}" + segments[i+1]
+	}
+
+	// a path ending in / only matches itself, not every path below it
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+
+	r := &route{method: strings.ToUpper(method), pattern: pattern, segments: strings.Split(strings.TrimSuffix(pattern, "{$}"), "/")}
+	for i := range r.segments {
+		if strings.HasPrefix(r.segments[i], "{") {
+			r.segments[i] = "{}"
+		}
+	}
+
+	for _, other := range g.routes {
+		if other.method == r.method && conflicts(other.segments, r.segments) {
+			return nil, nil, fmt.Errorf("it conflicts with %s %s", other.method, other.pattern)
+		}
+	}
+
+	g.routes = append(g.routes, r)
+
+	return r, wildcards, nil
+}
+
+// conflicts returns true when two routes match the same paths without one of them being more specific than the other,
+// which http.ServeMux does not accept
+func conflicts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	aGeneral, bGeneral := false, false
+	for i := range a {
+		switch {
+		case a[i] == b[i]:
+		case a[i] == "{}":
+			aGeneral = true
+		case b[i] == "{}":
+			bGeneral = true
+		default:
+			return false
+		}
+	}
+
+	return aGeneral == bGeneral
+}
+
+// resource writes the Params and Response types, the interface method and the handler of a resource, it returns the types
+// they use
+func (g *generator) resource(decls, methods, handlers *strings.Builder, res *types.Resource, iface string, rt *route, wildcards []string) []string {
+	name := gomodels.GoName(types.MakeResourceName(res.Name, res.Method, res.Path, ""))
+	if len(name) <= 0 {
+		name = "Serve"
+	}
+	unique := name
+	for i := 2; g.methods[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	name = unique
+	g.methods[name] = true
+
+	rt.handler = "handle" + name
+	typeNames := make([]string, 0)
+
+	// path parameters come first, in the order of the template, placeholders without a parameter are strings
+	fields := make([]*field, 0)
+	fieldNames := make(map[string]bool)
+	add := func(p *types.Parameter, wildcard string) {
+		fieldName := gomodels.GoName(p.Name)
+		if len(fieldName) <= 0 {
+			fieldName = "Param"
+		}
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = gomodels.GoName(p.Name) + strconv.Itoa(i)
+		}
+		fieldNames[fieldName] = true
+
+		f := &field{name: fieldName, param: p, wildcard: wildcard, typ: g.models.ParamType(name, p), optional: !p.Required && p.In != types.PATH}
+		if f.optional {
+			f.typ = g.models.Optional(f.typ)
+		}
+
+		typeNames = append(typeNames, f.typ)
+		fields = append(fields, f)
+	}
+
+	for i, placeholder := range generators.PathParams(res.Path) {
+		p := generators.FindParam(res.Parameters, types.PATH, placeholder)
+		if nil == p {
+			p = &types.Parameter{Name: placeholder, In: types.PATH, Required: true, Type: "string"}
+		}
+
+		add(p, wildcards[i])
+	}
+
+	for _, p := range res.Parameters {
+		if nil != p && (p.In == types.QUERY || p.In == types.HEADER || p.In == types.COOKIE) {
+			add(p, "")
+		}
+	}
+
+	request := generators.PickRequest(res.Requests)
+	bodyType, kind := "", ""
+	if nil != request {
+		kind = types.ContentKind(request.ContentType)
+		bodyType = g.models.BodyType(name+"Request", request.Schema, kind, true)
+		typeNames = append(typeNames, bodyType)
+		g.form = g.form || kind == "FORM" || kind == "MULTIPART"
+	}
+
+	statuses := g.models.Statuses(name, res.Responses)
+
+	paramsType := ""
+	if len(fields) > 0 {
+		paramsType = g.models.Reserve(name + "Params")
+		fmt.Fprintf(decls, "// %s holds the path, query, header and cookie parameters of %s\ntype %s struct {\n", paramsType, name, paramsType)
+		for _, f := range fields {
+			decls.WriteString(generators.Comment("// ", f.param.Description))
+			fmt.Fprintf(decls, "%s %s\n", f.name, f.typ)
+		}
+		decls.WriteString("}\n\n")
+	}
+
+	responseType := g.models.Reserve(name + "Response")
+	fmt.Fprintf(decls, "// %s is the response of %s, the field of its StatusCode is written as the body.\n", responseType, name)
+	fmt.Fprintf(decls, "// A StatusCode of 0 is the first 2xx status declared for the resource (or 200).\n")
+	fmt.Fprintf(decls, "type %s struct {\nStatusCode int\nHeader http.Header\n", responseType)
+	for _, s := range statuses {
+		if len(s.Type) > 0 {
+			typ := g.models.Optional(s.Type)
+			typeNames = append(typeNames, typ)
+			fmt.Fprintf(decls, "%s %s\n", s.Field, typ)
+		}
+	}
+	decls.WriteString("}\n\n")
+
+	// the method of the interface
+	if methods.Len() > 0 {
+		methods.WriteString("\n")
+	}
+	fmt.Fprintf(methods, "// %s serves %s %s\n", name, strings.ToUpper(res.Method), res.Path)
+	if doc := strings.TrimSpace(strings.Join([]string{res.Summary, res.Description}, "\n\n")); len(doc) > 0 {
+		methods.WriteString("//\n" + generators.Comment("// ", doc))
+	}
+	if res.Deprecated {
+		methods.WriteString("//\n// Deprecated: the resource is deprecated.\n")
+	}
+
+	signature, args := []string{"ctx context.Context"}, []string{"r.Context()"}
+	if len(paramsType) > 0 {
+		signature, args = append(signature, "params "+paramsType), append(args, "params")
+	}
+	if len(bodyType) > 0 {
+		signature, args = append(signature, "body "+bodyType), append(args, "body")
+	}
+	fmt.Fprintf(methods, "%s(%s) (%s, error)\n", name, strings.Join(signature, ", "), responseType)
+
+	// the handler decoding the request, calling the method and encoding its response
+	fmt.Fprintf(handlers, "// %s serves %s %s with %s.%s\n", rt.handler, rt.method, rt.pattern, iface, name)
+	fmt.Fprintf(handlers, "func (rt *%s) %s(w http.ResponseWriter, r *http.Request) {\n", g.routerType, rt.handler)
+	if len(paramsType) > 0 {
+		fmt.Fprintf(handlers, "var params %s\n", paramsType)
+		for _, p := range res.Parameters {
+			if nil != p && p.In == types.QUERY {
+				handlers.WriteString("query := r.URL.Query()\n")
+				break
+			}
+		}
+
+		for _, f := range fields {
+			values := ""
+			switch f.param.In {
+			case types.PATH:
+				values = fmt.Sprintf("splitValues(r.PathValue(%s))", strconv.Quote(f.wildcard))
+			case types.QUERY:
+				values = fmt.Sprintf("query[%s]", strconv.Quote(f.param.Name))
+			case types.HEADER:
+				values = fmt.Sprintf("splitValues(r.Header.Values(%s)...)", strconv.Quote(f.param.Name))
+			case types.COOKIE:
+				values = fmt.Sprintf("splitValues(cookieValue(r, %s))", strconv.Quote(f.param.Name))
+			}
+
+			fmt.Fprintf(handlers, "if err := decodeParam(%s, %s, %s, %t, &params.%s); err != nil {\nrt.fail(w, r, err)\nreturn\n}\n",
+				strconv.Quote(string(f.param.In)), strconv.Quote(f.param.Name), values, f.param.Required || f.param.In == types.PATH, f.name)
+		}
+		handlers.WriteString("\n")
+	}
+
+	if nil != request {
+		switch kind {
+		case "BINARY":
+			handlers.WriteString("var body io.Reader = r.Body\n\n")
+		case "FORM", "MULTIPART":
+			fmt.Fprintf(handlers, "var body %s\nif err := decodeForm(r, %t, &body); err != nil {\nrt.fail(w, r, err)\nreturn\n}\n\n", bodyType, request.Required)
+		default:
+			fmt.Fprintf(handlers, "var body %s\nif err := decodeBody(r, %s, %t, &body); err != nil {\nrt.fail(w, r, err)\nreturn\n}\n\n", bodyType, strconv.Quote(kind), request.Required)
+		}
+	}
+
+	fmt.Fprintf(handlers, "resp, err := rt.server.%s(%s)\nif err != nil {\nrt.fail(w, r, err)\nreturn\n}\n\n", name, strings.Join(args, ", "))
+
+	// without a StatusCode the first 2xx status is written
+	code := 200
+	for _, s := range statuses {
+		if low, _ := generators.StatusRange(s.Status); generators.Success(s.Status) {
+			code = low
+			break
+		}
+	}
+	fmt.Fprintf(handlers, "code := resp.StatusCode\nif code == 0 {\ncode = %d\n}\n\n", code)
+
+	// statuses without a body, and a StatusCode that is not declared, are written without one
+	cases := make([]*gomodels.Status, 0, len(statuses))
+	for _, s := range statuses {
+		if len(s.Type) > 0 {
+			cases = append(cases, s)
+		}
+	}
+
+	if len(cases) > 0 {
+		hasDefault := false
+		handlers.WriteString("switch {\n")
+		for _, s := range cases {
+			if condition := s.Condition("code"); len(condition) <= 0 {
+				hasDefault = true
+				handlers.WriteString("default:\n")
+			} else {
+				fmt.Fprintf(handlers, "case %s:\n", condition)
+			}
+
+			value := "resp." + s.Field
+			if g.models.Pointer(s.Type) {
+				value = "bodyOf(" + value + ")"
+			}
+			fmt.Fprintf(handlers, "err = writeResponse(w, code, resp.Header, %s, %s, %s)\n", strconv.Quote(s.ContentType), strconv.Quote(types.ContentKind(s.ContentType)), value)
+		}
+		if !hasDefault {
+			handlers.WriteString("default:\nerr = writeResponse(w, code, resp.Header, \"\", \"\", nil)\n")
+		}
+		handlers.WriteString("}\n")
+	} else {
+		handlers.WriteString("err = writeResponse(w, code, resp.Header, \"\", \"\", nil)\n")
+	}
+	handlers.WriteString("if err != nil {\nrt.fail(w, r, err)\n}\n}\n\n")
+
+	return typeNames
+}
+
+// writeImports writes the import block of a file
+func writeImports(b *strings.Builder, imports map[string]bool) {
+	sorted := make([]string, 0, len(imports))
+	for imp := range imports {
+		sorted = append(sorted, imp)
+	}
+	sort.Strings(sorted)
+
+	b.WriteString("import (\n")
+	for _, imp := range sorted {
+		b.WriteString(strconv.Quote(imp) + "\n")
+	}
+	b.WriteString(")\n\n")
+}
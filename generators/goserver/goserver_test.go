@@ -0,0 +1,236 @@
+package goserver
+
+import (
+	"github.com/spirefy/go-codegen/generators/generatortest"
+	"strings"
+	"testing"
+)
+
+// serverTest routes requests to a server of the pet store recording what each method is called with
+const serverTest = `package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pets serves the pet store, recording the method that was called with its parameters or body
+type pets struct {
+	called string
+	list   ListPetsParams
+	body   NewPet
+	petID  string
+	status int
+	err    error
+}
+
+func (p *pets) ListPets(ctx context.Context, params ListPetsParams) (ListPetsResponse, error) {
+	p.called, p.list = "ListPets", params
+	return ListPetsResponse{Status200: ListPets200Response{{ID: 1, Name: "Rex"}}}, p.err
+}
+
+func (p *pets) CreatePet(ctx context.Context, body NewPet) (CreatePetResponse, error) {
+	p.called, p.body = "CreatePet", body
+	return CreatePetResponse{Status201: &Pet{ID: 2, Name: body.Name, Tag: body.Tag}}, p.err
+}
+
+func (p *pets) DeletePet(ctx context.Context, params DeletePetParams) (DeletePetResponse, error) {
+	p.called, p.petID = "DeletePet", params.PetID
+	return DeletePetResponse{}, p.err
+}
+
+func (p *pets) ShowPetByID(ctx context.Context, params ShowPetByIDParams) (ShowPetByIDResponse, error) {
+	p.called, p.petID = "ShowPetByID", params.PetID
+	if p.status == 404 {
+		return ShowPetByIDResponse{StatusCode: 404, Status404: &Error{Code: 404, Message: "no such pet"}}, p.err
+	}
+	return ShowPetByIDResponse{StatusCode: p.status, Status200: &Pet{ID: 3, Name: "Tom"}}, p.err
+}
+
+// serve sends a request to a router of p and returns the response
+func serve(p *pets, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range header {
+		r.Header[k] = v
+	}
+
+	w := httptest.NewRecorder()
+	NewRouter(p).ServeHTTP(w, r)
+	return w
+}
+
+func TestRouting(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		body   string
+		code   int
+		called string
+	}{
+		{"GET", "/pets", "", 200, "ListPets"},
+		{"POST", "/pets", ` + "`" + `{"name": "Rex"}` + "`" + `, 201, "CreatePet"},
+		{"GET", "/pets/3", "", 200, "ShowPetByID"},
+		{"DELETE", "/pets/3", "", 204, "DeletePet"},
+		{"PUT", "/pets", "", 405, ""},
+		{"GET", "/owners", "", 404, ""},
+		{"GET", "/pets/3/photos", "", 404, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			p := &pets{}
+			w := serve(p, tt.method, tt.target, tt.body, nil)
+
+			if w.Code != tt.code || p.called != tt.called {
+				t.Errorf("the response is %d after calling %q, want %d after calling %q", w.Code, p.called, tt.code, tt.called)
+			}
+		})
+	}
+}
+
+func TestListPetsParams(t *testing.T) {
+	p := &pets{}
+	w := serve(p, "GET", "/pets?limit=5", "", http.Header{"X-Trace": {"abc"}})
+
+	if w.Code != 200 || p.list.Limit == nil || *p.list.Limit != 5 || p.list.XTrace == nil || *p.list.XTrace != "abc" {
+		t.Fatalf("the response is %d with the parameters %+v", w.Code, p.list)
+	}
+	if w.Header().Get("Content-Type") != "application/json" || !strings.Contains(w.Body.String(), ` + "`" + `"name":"Rex"` + "`" + `) {
+		t.Errorf("the body is %s %s", w.Header().Get("Content-Type"), w.Body)
+	}
+
+	p = &pets{}
+	if w := serve(p, "GET", "/pets", "", nil); w.Code != 200 || p.list.Limit != nil || p.list.XTrace != nil {
+		t.Errorf("the response is %d with the parameters %+v, want no parameters", w.Code, p.list)
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   string
+	}{
+		{"a query parameter of the wrong type", "GET", "/pets?limit=many", "", "invalid query parameter limit"},
+		{"a missing required body", "POST", "/pets", "", "invalid request body: a value is required"},
+		{"a body that is not json", "POST", "/pets", "{", "invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &pets{}
+			w := serve(p, tt.method, tt.target, tt.body, nil)
+
+			if w.Code != 400 || p.called != "" || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("the response is %d %q after calling %q, want a 400 with %q", w.Code, w.Body, p.called, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	p := &pets{}
+	if w := serve(p, "GET", "/pets/a%20b", "", nil); w.Code != 200 || p.petID != "a b" {
+		t.Errorf("the response is %d with the pet id %q, want a b", w.Code, p.petID)
+	}
+}
+
+func TestCreatePetBody(t *testing.T) {
+	p := &pets{}
+	w := serve(p, "POST", "/pets", ` + "`" + `{"name": "Rex", "tag": "dog"}` + "`" + `, nil)
+
+	if p.body.Name != "Rex" || p.body.Tag == nil || *p.body.Tag != "dog" {
+		t.Fatalf("the body is %+v", p.body)
+	}
+
+	var pet Pet
+	if err := json.Unmarshal(w.Body.Bytes(), &pet); err != nil || w.Code != 201 || pet.ID != 2 {
+		t.Errorf("the response is %d %s", w.Code, w.Body)
+	}
+}
+
+func TestResponseStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		code   int
+		body   string
+	}{
+		{0, 200, ` + "`" + `"name":"Tom"` + "`" + `},
+		{404, 404, ` + "`" + `"message":"no such pet"` + "`" + `},
+		{202, 202, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.code), func(t *testing.T) {
+			w := serve(&pets{status: tt.status}, "GET", "/pets/3", "", nil)
+
+			body, _ := io.ReadAll(w.Body)
+			if w.Code != tt.code || !strings.Contains(string(body), tt.body) || (tt.body == "") != (len(body) == 0) {
+				t.Errorf("the response is %d %s, want %d with %s", w.Code, body, tt.code, tt.body)
+			}
+		})
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	p := &pets{err: errors.New("broken")}
+	if w := serve(p, "DELETE", "/pets/3", "", nil); w.Code != 500 {
+		t.Errorf("the response is %d, want 500 for an error of the server", w.Code)
+	}
+
+	rt := NewRouter(&pets{})
+	rt.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		var re *RequestError
+		if errors.As(err, &re) && re.In == "query" && re.Name == "limit" {
+			w.WriteHeader(422)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("GET", "/pets?limit=x", nil))
+	if w.Code != 422 {
+		t.Errorf("the response is %d, want the 422 of the ErrorHandler", w.Code)
+	}
+}
+`
+
+func TestServer(t *testing.T) {
+	files, diagnostics := generatortest.Generate(t, Generator{}, generatortest.Petstore(), nil)
+	if len(diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", diagnostics)
+	}
+
+	root := files["server/root_server.go"]
+	for _, want := range []string{
+		"ListPets(ctx context.Context, params ListPetsParams) (ListPetsResponse, error)",
+		"ShowPetByID(ctx context.Context, params ShowPetByIDParams) (ShowPetByIDResponse, error)",
+		"CreatePet(ctx context.Context, body NewPet) (CreatePetResponse, error)",
+		"// Deprecated: the resource is deprecated.",
+	} {
+		if !strings.Contains(root, want) {
+			t.Errorf("the server does not have %q", want)
+		}
+	}
+
+	files["server/server_test.go"] = serverTest
+	generatortest.GoTest(t, files)
+}
+
+func TestServerWithoutModels(t *testing.T) {
+	files, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionModels: "false", OptionPackage: "petstore"})
+
+	if _, ok := files["petstore/models.go"]; ok {
+		t.Error("the models are written, want only the server")
+	}
+	if _, ok := files["petstore/server.go"]; !ok {
+		t.Error("petstore/server.go is not written")
+	}
+}
@@ -0,0 +1,319 @@
+package goserver
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators/gomodels"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// router returns the formatted source of the file holding the Server interface, the Router and the helpers of the handlers
+func (g *generator) router(pkg string) ([]byte, error) {
+	var b strings.Builder
+
+	b.WriteString(gomodels.Header + "\n\n")
+	b.WriteString("package " + pkg + "\n\n")
+
+	imports := map[string]bool{"bytes": true, "encoding/json": true, "encoding/xml": true, "errors": true, "fmt": true,
+		"io": true, "net/http": true, "strings": true}
+	if g.form {
+		imports["net/url"] = true
+		imports["reflect"] = true
+	}
+	writeImports(&b, imports)
+
+	fmt.Fprintf(&b, "// %s is implemented to serve the resources of the API, see %s\ntype %s interface {\n", g.serverType, g.newRouter, g.serverType)
+	for _, root := range g.roots {
+		b.WriteString(root + "\n")
+	}
+	b.WriteString("}\n\n")
+
+	var routes strings.Builder
+	for _, r := range g.routes {
+		fmt.Fprintf(&routes, "rt.mux.HandleFunc(%s, rt.%s)\n", strconv.Quote(r.method+" "+r.pattern), r.handler)
+	}
+
+	fmt.Fprintf(&b, `// %[1]s
+//
+// This is the http.Handler serving the resources of the API with a %[2]s. It decodes the parameters and body of a
+// request, calls the method of the resource and encodes the response it returns. ErrorHandler writes the response of a
+// request that failed, by default a *%[4]s is answered with a 400 and any other error with a 500.
+type %[1]s struct {
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+	server %[2]s
+	mux    *http.ServeMux
+}
+
+// %[3]s returns the router serving the resources of the API with server
+func %[3]s(server %[2]s) *%[1]s {
+	rt := &%[1]s{server: server, mux: http.NewServeMux()}
+%[5]s
+	return rt
+}
+
+func (rt *%[1]s) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(w, r)
+}
+
+// fail writes the response of a request that failed with the ErrorHandler
+func (rt *%[1]s) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if rt.ErrorHandler != nil {
+		rt.ErrorHandler(w, r, err)
+		return
+	}
+
+	var requestError *%[4]s
+	if errors.As(err, &requestError) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// %[4]s is the error of a request whose parameters or body could not be decoded. In is where the value was
+// expected (path, query, header, cookie or body) and Name the name of the parameter.
+type %[4]s struct {
+	In   string
+	Name string
+	Err  error
+}
+
+func (e *%[4]s) Error() string {
+	if e.In == "body" {
+		return fmt.Sprintf("invalid request body: %%v", e.Err)
+	}
+
+	return fmt.Sprintf("invalid %%s parameter %%s: %%v", e.In, e.Name, e.Err)
+}
+
+func (e *%[4]s) Unwrap() error {
+	return e.Err
+}
+
+// errRequired is the error of a required parameter or body the request does not have
+var errRequired = errors.New("a value is required")
+
+// splitValues returns the comma separated values of a path, header or cookie parameter
+func splitValues(values ...string) []string {
+	split := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			split = append(split, strings.Split(value, ",")...)
+		}
+	}
+
+	return split
+}
+
+// cookieValue returns the value of a cookie of the request, or an empty string when it has none
+func cookieValue(r *http.Request, name string) string {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
+// decodeParam
+//
+// This decodes the values of a parameter in to v. The values are decoded as json, first as a single string, then as a
+// single value, then as an array of strings and an array of values, the first one that fits the type of v is kept.
+func decodeParam(in, name string, values []string, required bool, v any) error {
+	if len(values) == 0 {
+		if required {
+			return &%[4]s{In: in, Name: name, Err: errRequired}
+		}
+		return nil
+	}
+
+	joined := strings.Join(values, ",")
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote(value)
+	}
+
+	for _, candidate := range []string{quote(joined), joined, "[" + strings.Join(quoted, ",") + "]", "[" + joined + "]"} {
+		if json.Unmarshal([]byte(candidate), v) == nil {
+			return nil
+		}
+	}
+
+	return &%[4]s{In: in, Name: name, Err: fmt.Errorf("%%q is not a valid value", joined)}
+}
+
+// quote returns a value as a json string
+func quote(value string) string {
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+// decodeBody decodes the body of a request in to v, as xml or text when kind says so and as json otherwise
+func decodeBody(r *http.Request, kind string, required bool, v any) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return &%[4]s{In: "body", Err: err}
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if required {
+			return &%[4]s{In: "body", Err: errRequired}
+		}
+		return nil
+	}
+
+	switch kind {
+	case "TEXT":
+		if s, ok := v.(*string); ok {
+			*s = string(data)
+			return nil
+		}
+	case "XML":
+		err = xml.Unmarshal(data, v)
+	default:
+		err = json.Unmarshal(data, v)
+	}
+
+	if err != nil {
+		return &%[4]s{In: "body", Err: err}
+	}
+
+	return nil
+}
+
+// bodyOf returns the body of a response field, nil when it is not set
+func bodyOf[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+
+	return v
+}
+
+// writeResponse
+//
+// This writes a response with the provided status code and headers. The value is encoded according to kind, as xml, text
+// or binary when it says so and as json otherwise, a nil value is written as a response without a body. The value is
+// encoded before anything is written, so an error can still be answered with another response.
+func writeResponse(w http.ResponseWriter, code int, header http.Header, contentType, kind string, value any) error {
+	var data []byte
+	var err error
+
+	switch {
+	case value == nil:
+	case kind == "TEXT" || kind == "BINARY":
+		switch v := value.(type) {
+		case *string:
+			data = []byte(*v)
+		case []byte:
+			data = v
+		default:
+			data = []byte(fmt.Sprint(v))
+		}
+	case kind == "XML":
+		data, err = xml.Marshal(value)
+	default:
+		data, err = json.Marshal(value)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for k, v := range header {
+		w.Header()[k] = v
+	}
+	if value != nil && contentType != "" && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+
+	return nil
+}
+
+`, g.routerType, g.serverType, g.newRouter, g.errorType, routes.String())
+
+	if g.form {
+		fmt.Fprintf(&b, `// decodeForm
+//
+// This decodes a form or multipart/form-data body in to v, a url.Values or a struct whose fields are matched on their
+// json name. The files of a multipart body are read in to []byte fields.
+func decodeForm(r *http.Request, required bool, v any) error {
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		err = r.ParseMultipartForm(32 << 20)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return &%[1]s{In: "body", Err: err}
+	}
+
+	if required && len(r.PostForm) == 0 && (r.MultipartForm == nil || len(r.MultipartForm.File) == 0) {
+		return &%[1]s{In: "body", Err: errRequired}
+	}
+
+	if values, ok := v.(*url.Values); ok {
+		*values = r.PostForm
+		return nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		raw, err := json.Marshal(r.PostForm)
+		if err == nil {
+			err = json.Unmarshal(raw, v)
+		}
+		if err != nil {
+			return &%[1]s{In: "body", Err: err}
+		}
+		return nil
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+		name, _, _ := strings.Cut(rv.Type().Field(i).Tag.Get("json"), ",")
+		field := rv.Field(i)
+
+		if r.MultipartForm != nil && len(r.MultipartForm.File[name]) > 0 {
+			file, err := r.MultipartForm.File[name][0].Open()
+			if err != nil {
+				return &%[1]s{In: "body", Err: err}
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return &%[1]s{In: "body", Err: err}
+			}
+
+			switch field.Interface().(type) {
+			case []byte:
+				field.Set(reflect.ValueOf(data))
+			case *[]byte:
+				field.Set(reflect.ValueOf(&data))
+			}
+			continue
+		}
+
+		if err := decodeParam("body", name, r.PostForm[name], false, field.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+`, g.errorType)
+	}
+
+	source, err := format.Source([]byte(b.String()))
+	if nil != err {
+		return nil, fmt.Errorf("problem formatting the generated server: %w", err)
+	}
+
+	return source, nil
+}
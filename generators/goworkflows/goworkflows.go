@@ -0,0 +1,465 @@
+// Package goworkflows is the built in generator of the go-workflows target. It writes a Go function, a method of the
+// client the go-client generator writes, for every Workflow of the model:
+//
+//   - the Inputs component is the argument of the function, and the Outputs are the fields of the <Workflow>Outputs
+//     struct it returns, typed after what their expressions read (e.g. the body of the response of a step)
+//   - every Step calls the client method of its Resource, with the Parameters of the step read from the runtime
+//     expressions of Arazzo ($inputs.<name>, $steps.<id>.outputs.<name>, ...)
+//   - steps run as soon as the steps they DependsOn succeeded, so the ones that do not depend on each other run
+//     concurrently
+//   - a step succeeded when its SuccessCriteria are met (a 2xx status when it has none), then the first of its OnSuccess
+//     (or otherwise OnFailure) actions whose criteria are met is taken: end the workflow, retry the step or goto another
+//     step. Going forward skips the steps in between, going back runs the step again along with the steps that ran
+//     before and depend on it.
+//   - a step that failed without an action for it fails the workflow with a *WorkflowError
+//
+// The workflows declare types next to the models and call the client, so the client and models are written along with
+// them and the go-client target should not be generated in to the same package.
+package goworkflows
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/generators/goclient"
+	"github.com/spirefy/go-codegen/generators/gomodels"
+	"github.com/spirefy/go-codegen/types"
+	"go/format"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target is the name of the target this generator writes
+const Target = "go-workflows"
+
+// Options of the go-workflows target
+const (
+	OptionPackage    = "package"    // The name of the Go package, workflows by default
+	OptionFile       = "file"       // The path of the workflows file relative to the output directory, <package>/workflows.go by default
+	OptionClientFile = "clientFile" // The path of the client file relative to the output directory, <package>/client.go by default
+	OptionModelsFile = "modelsFile" // The path of the models file relative to the output directory, <package>/models.go by default
+	OptionBaseURL    = "baseUrl"    // The DefaultBaseURL of the client, taken from the host and basePath of the resources by default
+)
+
+// Generator implements pipeline.Generator for the go-workflows target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	pkg := gomodels.PackageName(generators.Option(request, OptionPackage, "workflows"))
+	resources, _ := generators.HTTPResources(request.Model.Resources)
+
+	g := &generator{models: gomodels.New(request.Model.Components)}
+	g.client = goclient.New(g.models)
+
+	client, err := g.client.Source(pkg, resources, generators.Option(request, OptionBaseURL, ""))
+	if nil != err {
+		return nil, err
+	}
+
+	workflows, err := g.source(pkg, request.Model.Workflows)
+	if nil != err {
+		return nil, err
+	}
+
+	// the models go last, the workflows declare types of their own
+	models, err := g.models.Source(pkg)
+	if nil != err {
+		return nil, err
+	}
+
+	files := []types.GeneratedFile{
+		{Path: generators.Option(request, OptionFile, path.Join(pkg, "workflows.go")), Content: workflows},
+		{Path: generators.Option(request, OptionClientFile, path.Join(pkg, "client.go")), Content: client},
+		{Path: generators.Option(request, OptionModelsFile, path.Join(pkg, "models.go")), Content: models},
+	}
+
+	return &types.GeneratorResponse{Files: files, Diagnostics: g.models.Diagnostics}, nil
+}
+
+type generator struct {
+	models *gomodels.Models
+	client *goclient.Client
+
+	// the name of the error type of failed steps, reserved so no model can take it
+	errorType string
+}
+
+// source returns the formatted source of the workflows file, the shared types and functions are only written when there
+// is a workflow using them
+func (g *generator) source(pkg string, workflows types.Workflows) ([]byte, error) {
+	g.errorType = g.models.Reserve("WorkflowError")
+
+	var functions strings.Builder
+	written := 0
+	for _, wf := range workflows {
+		if nil != wf && g.workflow(&functions, wf) {
+			written++
+		}
+	}
+
+	if written <= 0 {
+		g.models.Diagnostics.Info("go-workflows-empty", "", "the model has no workflows that can be generated")
+	}
+
+	var b strings.Builder
+	b.WriteString(gomodels.Header + "\n\n")
+	b.WriteString("package " + pkg + "\n\n")
+
+	if written > 0 {
+		b.WriteString("import (\n")
+		for _, imp := range []string{"bytes", "context", "encoding/json", "errors", "fmt", "io", "net/http", "net/url", "regexp", "sort", "strconv", "strings", "sync", "time"} {
+			b.WriteString(strconv.Quote(imp) + "\n")
+		}
+		b.WriteString(")\n\n")
+
+		b.WriteString(functions.String())
+		b.WriteString(strings.NewReplacer("WorkflowError", g.errorType, "ResponseError", g.client.ErrorType()).Replace(runtime))
+	}
+
+	source, err := format.Source([]byte(b.String()))
+	if nil != err {
+		return nil, fmt.Errorf("problem formatting the generated workflows: %w", err)
+	}
+
+	return source, nil
+}
+
+// workflow writes the function of a workflow along with its Outputs type. It returns false, writing nothing, when a step
+// has no client method to call or the steps depend on each other in a cycle.
+func (g *generator) workflow(b *strings.Builder, wf *types.Workflow) bool {
+	ids := make(map[string]bool, len(wf.Steps))
+	for _, step := range wf.Steps {
+		if nil == step {
+			continue
+		}
+
+		if nil == g.client.Method(step.Resource) {
+			g.models.Diagnostics.Warn("go-workflows-step", "", "workflow %s is not generated, step %s has no HTTP resource of the model to call", wf.Id, step.Id)
+			return false
+		}
+		ids[step.Id] = true
+	}
+
+	if id := cycle(wf.Steps); len(id) > 0 {
+		g.models.Diagnostics.Warn("go-workflows-cycle", "", "workflow %s is not generated, step %s depends on itself through the steps it depends on", wf.Id, id)
+		return false
+	}
+
+	name := gomodels.GoName(wf.Id)
+	if len(name) <= 0 {
+		name = "Workflow"
+	}
+	name = g.client.Reserve(name)
+
+	inputs, inputsArg := "nil", ""
+	if len(wf.Inputs) > 0 && nil != wf.Inputs[0] {
+		comp := g.models.Resolve(wf.Inputs[0])
+		if nil == comp {
+			comp = wf.Inputs[0]
+		}
+		inputs, inputsArg = "inputs", ", inputs "+g.models.Optional(g.models.Name(comp))
+	}
+
+	// the outputs, in order of their names
+	outputsType := g.models.Reserve(name + "Outputs")
+	outputs := make([]string, 0, len(wf.Outputs))
+	for id, output := range wf.Outputs {
+		if nil != output {
+			outputs = append(outputs, id)
+		}
+	}
+	sort.Strings(outputs)
+
+	fields := make(map[string]string, len(outputs))
+	taken := make(map[string]bool, len(outputs))
+	fmt.Fprintf(b, "// %s holds the outputs of %s, an output the workflow ended without is left empty\ntype %s struct {\n", outputsType, name, outputsType)
+	for _, id := range outputs {
+		field := gomodels.GoName(id)
+		if len(field) <= 0 {
+			field = "Output"
+		}
+		for i := 2; taken[field]; i++ {
+			field = gomodels.GoName(id) + strconv.Itoa(i)
+		}
+		taken[field] = true
+		fields[id] = field
+
+		typ := g.goType(name+gomodels.GoName(id)+"Output", g.shapeOf(wf, nil, wf.Outputs[id].Expression.Text, 0))
+		fmt.Fprintf(b, "%s %s `json:%s`\n", field, typ, strconv.Quote(id))
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "// %s runs the %s workflow\n", name, wf.Id)
+	if len(strings.TrimSpace(wf.Description)) > 0 {
+		b.WriteString("//\n" + generators.Comment("// ", wf.Description))
+	}
+	fmt.Fprintf(b, "func (c *%s) %s(ctx context.Context%s) (*%s, error) {\n", g.client.Type(), name, inputsArg, outputsType)
+	fmt.Fprintf(b, "w, err := newWorkflowRun(%s, %s, []*workflowStep{\n", strconv.Quote(wf.Id), inputs)
+	for _, step := range wf.Steps {
+		if nil != step {
+			g.step(b, wf, step, ids)
+		}
+	}
+	b.WriteString("})\nif err != nil {\nreturn nil, err\n}\n\n")
+	b.WriteString("if err := w.run(ctx); err != nil {\nreturn nil, err\n}\n\n")
+
+	fmt.Fprintf(b, "outputs := &%s{}\n", outputsType)
+	for _, id := range outputs {
+		fmt.Fprintf(b, "if err := workflowValue(w, %s, false, &outputs.%s); err != nil {\nreturn nil, err\n}\n", strconv.Quote(wf.Outputs[id].Expression.Text), fields[id])
+	}
+	b.WriteString("\nreturn outputs, nil\n}\n\n")
+
+	return true
+}
+
+// step writes the workflowStep of a step, its call sets the arguments of the client method from the parameters of the step
+func (g *generator) step(b *strings.Builder, wf *types.Workflow, step *types.Step, ids map[string]bool) {
+	method := g.client.Method(step.Resource)
+
+	fmt.Fprintf(b, "{\nid: %s,\n", strconv.Quote(step.Id))
+
+	dependsOn := make([]string, 0, len(step.DependsOn))
+	for _, dep := range step.DependsOn {
+		if ids[dep.Id] && dep.Id != step.Id {
+			dependsOn = append(dependsOn, strconv.Quote(dep.Id))
+		}
+	}
+	if len(dependsOn) > 0 {
+		fmt.Fprintf(b, "dependsOn: []string{%s},\n", strings.Join(dependsOn, ", "))
+	}
+
+	b.WriteString("call: func(ctx context.Context, w *workflowRun) (*workflowResponse, error) {\n")
+
+	used := make(map[string]bool, len(step.Parameters))
+	names := make([]string, 0, len(step.Parameters))
+	for key := range step.Parameters {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	// find returns the key of the parameter of the step setting a parameter of the resource
+	find := func(p *types.Parameter) string {
+		for _, key := range names {
+			wp := step.Parameters[key]
+			if !used[key] && (wp.In == string(p.In) || len(wp.In) <= 0) && (wp.Name == p.Name || (p.In == types.HEADER && strings.EqualFold(wp.Name, p.Name))) {
+				used[key] = true
+				return key
+			}
+		}
+		return ""
+	}
+
+	args := []string{"ctx"}
+	for _, a := range method.PathArgs {
+		local := a.Name
+		if local == "w" {
+			local = "wArg"
+		}
+		args = append(args, local)
+
+		fmt.Fprintf(b, "var %s %s\n", local, a.Type)
+		if key := find(a.Param); len(key) > 0 {
+			fmt.Fprintf(b, "if err := workflowValue(w, %s, true, &%s); err != nil {\nreturn nil, err\n}\n", strconv.Quote(step.Parameters[key].Value), local)
+		} else {
+			g.models.Diagnostics.Warn("go-workflows-parameter", "", "step %s of workflow %s does not set path parameter %s, it is empty", step.Id, wf.Id, a.Param.Name)
+		}
+	}
+
+	if len(method.Params) > 0 {
+		args = append(args, "params")
+		fmt.Fprintf(b, "params := &%s{}\n", method.Params)
+		for _, f := range method.Fields {
+			key := find(f.Param)
+			switch {
+			case len(key) <= 0:
+			case f.Optional && g.models.Pointer(f.Type):
+				fmt.Fprintf(b, "if err := workflowOptional(w, %s, &params.%s); err != nil {\nreturn nil, err\n}\n", strconv.Quote(step.Parameters[key].Value), f.Name)
+			default:
+				fmt.Fprintf(b, "if err := workflowValue(w, %s, %t, &params.%s); err != nil {\nreturn nil, err\n}\n", strconv.Quote(step.Parameters[key].Value), f.Param.Required, f.Name)
+			}
+		}
+	}
+
+	// the payload of the request body and its replacements, keyed by their json pointer
+	payload, replacements := "", make([]string, 0)
+	hasBody := false
+	for _, key := range names {
+		wp := step.Parameters[key]
+		if used[key] || wp.In != "body" {
+			continue
+		}
+
+		used[key] = true
+		hasBody = true
+		if len(wp.Target) > 0 {
+			replacements = append(replacements, strconv.Quote(wp.Target)+": "+strconv.Quote(wp.Value))
+		} else {
+			payload = wp.Value
+		}
+	}
+
+	if len(method.Body) > 0 {
+		args = append(args, "body")
+		if hasBody {
+			values := "nil"
+			if len(replacements) > 0 {
+				values = "map[string]string{" + strings.Join(replacements, ", ") + "}"
+			}
+			fmt.Fprintf(b, "body, err := workflowBody[%s](w, %s, %s)\nif err != nil {\nreturn nil, err\n}\n", method.Body, strconv.Quote(payload), values)
+		} else {
+			fmt.Fprintf(b, "var body %s\n", method.Body)
+		}
+	} else if hasBody {
+		g.models.Diagnostics.Warn("go-workflows-parameter", "", "step %s of workflow %s has a request body, %s %s has none and it is left out", step.Id, wf.Id, strings.ToUpper(step.Resource.Method), step.Resource.Path)
+	}
+
+	for _, key := range names {
+		if !used[key] {
+			g.models.Diagnostics.Warn("go-workflows-parameter", "", "step %s of workflow %s sets parameter %s, %s %s has no such parameter and it is left out", step.Id, wf.Id, step.Parameters[key].Name, strings.ToUpper(step.Resource.Method), step.Resource.Path)
+		}
+	}
+
+	fmt.Fprintf(b, "\nresp, err := c.%s(%s)\nif err != nil {\nreturn workflowResult(err)\n}\n\n", method.Name, strings.Join(args, ", "))
+	b.WriteString("return &workflowResponse{statusCode: resp.StatusCode, header: resp.Header, body: resp.Body}, nil\n},\n")
+
+	// without success criteria a step succeeded when its status is 2xx
+	criteria := step.SuccessCriteria
+	if len(criteria) <= 0 {
+		criteria = []types.Expression{{Text: "$statusCode >= 200 && $statusCode <= 299"}}
+	}
+	fmt.Fprintf(b, "criteria: %s,\n", g.criteria(wf, criteria))
+
+	if len(step.OnSuccess) > 0 {
+		fmt.Fprintf(b, "onSuccess: %s,\n", g.actions(wf, step, step.OnSuccess, ids))
+	}
+	if len(step.OnFailure) > 0 {
+		fmt.Fprintf(b, "onFailure: %s,\n", g.actions(wf, step, step.OnFailure, ids))
+	}
+
+	if len(step.Outputs) > 0 {
+		outputs := make([]string, 0, len(step.Outputs))
+		for name := range step.Outputs {
+			outputs = append(outputs, name)
+		}
+		sort.Strings(outputs)
+
+		b.WriteString("outputs: map[string]string{\n")
+		for _, name := range outputs {
+			fmt.Fprintf(b, "%s: %s,\n", strconv.Quote(name), strconv.Quote(step.Outputs[name].Expression.Text))
+		}
+		b.WriteString("},\n")
+	}
+
+	b.WriteString("},\n")
+}
+
+// criteria returns the []workflowCriterion literal of the provided criteria
+func (g *generator) criteria(wf *types.Workflow, criteria []types.Expression) string {
+	values := make([]string, 0, len(criteria))
+
+	for _, c := range criteria {
+		kind := strings.ToLower(c.Type)
+		if len(kind) <= 0 {
+			kind = "simple"
+		}
+
+		switch kind {
+		case "simple", "regex", "jsonpath":
+		default:
+			g.models.Diagnostics.Warn("go-workflows-criterion", "", "criterion %s of workflow %s is a %s criterion, which is not supported and fails when evaluated", c.Text, wf.Id, kind)
+		}
+
+		value := "kind: " + strconv.Quote(kind)
+		if len(c.Context) > 0 {
+			value += ", context: " + strconv.Quote(c.Context)
+		}
+		values = append(values, "{"+value+", condition: "+strconv.Quote(c.Text)+"}")
+	}
+
+	return "[]workflowCriterion{" + strings.Join(values, ", ") + "}"
+}
+
+// actions returns the []workflowAction literal of the actions of a step
+func (g *generator) actions(wf *types.Workflow, step *types.Step, actions []types.Action, ids map[string]bool) string {
+	values := make([]string, 0, len(actions))
+
+	for _, a := range actions {
+		value := "kind: " + strconv.Quote(a.Type)
+
+		switch a.Type {
+		case "end":
+		case "goto", "retry":
+			switch {
+			case len(a.WorkflowId) > 0:
+				g.models.Diagnostics.Warn("go-workflows-action", "", "step %s of workflow %s goes to workflow %s, which is not supported and fails when taken", step.Id, wf.Id, a.WorkflowId)
+				value += ", workflowID: " + strconv.Quote(a.WorkflowId)
+			case len(a.StepId) > 0 && !ids[a.StepId]:
+				g.models.Diagnostics.Warn("go-workflows-action", "", "step %s of workflow %s goes to step %s which does not exist", step.Id, wf.Id, a.StepId)
+				value += ", stepID: " + strconv.Quote(a.StepId)
+			case len(a.StepId) > 0:
+				value += ", stepID: " + strconv.Quote(a.StepId)
+			}
+
+			if a.Type == "retry" {
+				// a single retry is attempted when the action has no limit
+				limit := a.RetryLimit
+				if limit <= 0 {
+					limit = 1
+				}
+				value += fmt.Sprintf(", retryAfter: %d * time.Millisecond, retryLimit: %d", int64(a.RetryAfter*1000), limit)
+			}
+		default:
+			g.models.Diagnostics.Warn("go-workflows-action", "", "step %s of workflow %s has a %s action, which is not supported and fails when taken", step.Id, wf.Id, a.Type)
+		}
+
+		if len(a.Criteria) > 0 {
+			value += ", criteria: " + g.criteria(wf, a.Criteria)
+		}
+		values = append(values, "{"+value+"}")
+	}
+
+	return "[]workflowAction{" + strings.Join(values, ", ") + "}"
+}
+
+// cycle returns the id of a step that depends on itself through the steps it depends on, an empty string when there is
+// none
+func cycle(steps types.Steps) string {
+	dependsOn := make(map[string][]string, len(steps))
+	for _, step := range steps {
+		if nil != step {
+			for _, dep := range step.DependsOn {
+				dependsOn[step.Id] = append(dependsOn[step.Id], dep.Id)
+			}
+		}
+	}
+
+	// 1 while the steps a step depends on are visited, 2 once they all were
+	state := make(map[string]int, len(steps))
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		switch state[id] {
+		case 1:
+			return true
+		case 2:
+			return false
+		}
+
+		state[id] = 1
+		for _, dep := range dependsOn[id] {
+			if dep != id && visit(dep) {
+				return true
+			}
+		}
+		state[id] = 2
+		return false
+	}
+
+	for _, step := range steps {
+		if nil != step && visit(step.Id) {
+			return step.Id
+		}
+	}
+
+	return ""
+}
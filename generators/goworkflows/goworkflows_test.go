@@ -0,0 +1,138 @@
+package goworkflows
+
+import (
+	"github.com/spirefy/go-codegen/generators/generatortest"
+	"github.com/spirefy/go-codegen/types"
+	"testing"
+)
+
+// listPets returns a model with a workflow of a single step, enough for the runtime to be written
+func listPets() *types.LoadedResponse {
+	res := &types.Resource{Id: 1, Name: "listPets", Path: "/pets", Method: "get", ResourceType: types.HTTP, Latest: true}
+
+	model := types.NewLoadedResponse()
+	model.Resources = types.Resources{res}
+	model.Workflows = types.Workflows{{Id: "listPets", Steps: types.Steps{{Id: "list", Resource: res}}}}
+	return model
+}
+
+// runtimeTest runs workflows of steps that only count how often they are called, going to other steps with goto actions
+const runtimeTest = `package workflows
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// counted returns steps with the provided ids that count their calls, each returns the statuses in order (the last
+// one once they ran out)
+func counted(calls map[string]int, mu *sync.Mutex, statuses map[string][]int, ids ...string) []*workflowStep {
+	steps := make([]*workflowStep, 0, len(ids))
+	for _, id := range ids {
+		id := id
+		steps = append(steps, &workflowStep{id: id, call: func(ctx context.Context, w *workflowRun) (*workflowResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls[id]++
+			status := 200
+			if s := statuses[id]; len(s) > 0 {
+				status = s[len(s)-1]
+				if calls[id] <= len(s) {
+					status = s[calls[id]-1]
+				}
+			}
+			return &workflowResponse{statusCode: status}, nil
+		}, criteria: []workflowCriterion{{kind: "simple", condition: "$statusCode < 300"}}})
+	}
+
+	return steps
+}
+
+func TestGoTo(t *testing.T) {
+	goTo := func(step string, status int) workflowAction {
+		return workflowAction{kind: "goto", stepID: step, criteria: []workflowCriterion{{kind: "simple", condition: "$statusCode == " + strconv.Itoa(status)}}}
+	}
+
+	tests := []struct {
+		name     string
+		ids      []string
+		statuses map[string][]int
+		setup    func(steps map[string]*workflowStep)
+		want     map[string]int
+	}{
+		{
+			// b and c wait for a, which goes to c: b is skipped and c and d run once
+			name: "forward",
+			ids:  []string{"a", "b", "c", "d"},
+			setup: func(steps map[string]*workflowStep) {
+				steps["b"].dependsOn = []string{"a"}
+				steps["c"].dependsOn = []string{"a"}
+				steps["d"].dependsOn = []string{"c"}
+				steps["a"].onSuccess = []workflowAction{goTo("c", 200)}
+			},
+			want: map[string]int{"a": 1, "c": 1, "d": 1},
+		},
+		{
+			// c goes back to b the first time, b and c run again while a does not
+			name:     "backward",
+			ids:      []string{"a", "b", "c"},
+			statuses: map[string][]int{"c": {201, 200}},
+			setup: func(steps map[string]*workflowStep) {
+				steps["b"].dependsOn = []string{"a"}
+				steps["c"].dependsOn = []string{"b"}
+				steps["c"].onSuccess = []workflowAction{goTo("b", 201)}
+			},
+			want: map[string]int{"a": 1, "b": 2, "c": 2},
+		},
+		{
+			// a step that already ran when another goes forward to it does not run again
+			name: "forward to a step that ran",
+			ids:  []string{"a", "b"},
+			setup: func(steps map[string]*workflowStep) {
+				steps["a"].dependsOn = []string{"b"}
+				steps["a"].onSuccess = []workflowAction{goTo("b", 200)}
+			},
+			want: map[string]int{"a": 1, "b": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := map[string]int{}
+
+			steps := counted(calls, &mu, tt.statuses, tt.ids...)
+			byID := make(map[string]*workflowStep, len(steps))
+			for _, s := range steps {
+				byID[s.id] = s
+			}
+			tt.setup(byID)
+
+			w, err := newWorkflowRun("test", nil, steps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(calls, tt.want) {
+				t.Errorf("calls = %v, want %v", calls, tt.want)
+			}
+		})
+	}
+}
+`
+
+// TestRuntimeGoTo runs the runtime the workflows are written with, in a module of its own, with goto actions going
+// forward and back
+func TestRuntimeGoTo(t *testing.T) {
+	files, _ := generatortest.Generate(t, Generator{}, listPets(), nil)
+
+	files["workflows/runtime_test.go"] = runtimeTest
+	generatortest.GoTest(t, files)
+}
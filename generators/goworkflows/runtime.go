@@ -0,0 +1,1177 @@
+package goworkflows
+
+// runtime is the source of the types and functions the workflow functions share, written once after them. It runs the
+// steps, evaluates the runtime expressions and criteria of Arazzo and takes the actions of the steps. WorkflowError and
+// ResponseError are replaced with the names the error types have in the package.
+const runtime = `// WorkflowError is the error of a step of a workflow that failed, either because calling its resource failed (Err is
+// set) or because its success criteria were not met and none of its failure actions applied
+type WorkflowError struct {
+	Workflow   string
+	Step       string
+	StatusCode int
+	Body       []byte
+	Err        error
+}
+
+func (e *WorkflowError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("step %s of workflow %s failed: %v", e.Step, e.Workflow, e.Err)
+	}
+
+	return fmt.Sprintf("step %s of workflow %s failed with status %d", e.Step, e.Workflow, e.StatusCode)
+}
+
+func (e *WorkflowError) Unwrap() error {
+	return e.Err
+}
+
+// workflowMaxJumps is how many times a run goes to another step (or retries one) before it fails, so steps that keep
+// going to each other end
+const workflowMaxJumps = 1000
+
+// workflowResponse is the response of a step, what the expressions of the step read
+type workflowResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// workflowCriterion is a success criterion of a step or a criterion of an action. The kind is simple, regex or jsonpath,
+// the context the runtime expression the condition of a regex or jsonpath criterion applies to.
+type workflowCriterion struct {
+	kind      string
+	context   string
+	condition string
+}
+
+// workflowAction is what a run does once a step succeeded or failed: end the workflow, go to another step or retry the
+// step. It is taken when all of its criteria are met.
+type workflowAction struct {
+	kind       string
+	stepID     string
+	workflowID string
+	retryAfter time.Duration
+	retryLimit int
+	criteria   []workflowCriterion
+}
+
+// workflowStep is a step of a workflow, call calls its resource with the parameters of the step
+type workflowStep struct {
+	id        string
+	dependsOn []string
+	call      func(ctx context.Context, w *workflowRun) (*workflowResponse, error)
+	criteria  []workflowCriterion
+	onSuccess []workflowAction
+	onFailure []workflowAction
+	outputs   map[string]string
+}
+
+// workflowRun is a run of a workflow, it holds the inputs of the workflow and the outputs of the steps that succeeded
+type workflowRun struct {
+	name   string
+	inputs any
+	steps  []*workflowStep
+
+	mu      sync.Mutex
+	outputs  map[string]map[string]any
+	executed map[string]bool
+	skipped  map[string]bool
+	ended    bool
+	err      error
+	jumps    int
+}
+
+// newWorkflowRun returns a run of the workflow with the provided name, inputs and steps
+func newWorkflowRun(name string, inputs any, steps []*workflowStep) (*workflowRun, error) {
+	w := &workflowRun{name: name, steps: steps, outputs: map[string]map[string]any{}, executed: map[string]bool{}, skipped: map[string]bool{}}
+
+	raw, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("problem encoding the inputs of workflow %s: %w", name, err)
+	}
+	w.inputs = workflowJSON(raw)
+
+	return w, nil
+}
+
+// run runs every step as soon as the steps it depends on succeeded, so steps that do not depend on each other run
+// concurrently. A step a goto skipped or already executed is not run again. It returns once every step ran, the workflow
+// ended or a step failed.
+func (w *workflowRun) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(map[string]chan struct{}, len(w.steps))
+	for _, s := range w.steps {
+		done[s.id] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range w.steps {
+		wg.Add(1)
+		go func(s *workflowStep) {
+			defer wg.Done()
+			defer close(done[s.id])
+
+			for _, dep := range s.dependsOn {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			// a step the step depends on failed or ended the workflow
+			if w.stopped() || !w.claim(s.id) {
+				return
+			}
+
+			if err := w.execute(ctx, s); err != nil {
+				w.fail(err)
+			}
+			if w.stopped() {
+				cancel()
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// stopped returns true once a step failed or ended the workflow
+func (w *workflowRun) stopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ended || w.err != nil
+}
+
+// fail records the error of a step, unless another step failed or ended the workflow first
+func (w *workflowRun) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.ended && w.err == nil {
+		w.err = err
+	}
+}
+
+// execute calls the resource of a step until its success criteria are met, taking the actions of the step
+func (w *workflowRun) execute(ctx context.Context, s *workflowStep) error {
+	w.mu.Lock()
+	w.executed[s.id] = true
+	w.mu.Unlock()
+
+	for retries := 0; ; retries++ {
+		resp, err := s.call(ctx, w)
+		if err != nil {
+			return &WorkflowError{Workflow: w.name, Step: s.id, Err: err}
+		}
+
+		failed := func(err error) error {
+			return &WorkflowError{Workflow: w.name, Step: s.id, StatusCode: resp.statusCode, Body: resp.body, Err: err}
+		}
+
+		ok, err := w.check(s.criteria, resp)
+		if err != nil {
+			return failed(err)
+		}
+
+		actions := s.onFailure
+		if ok {
+			outputs := make(map[string]any, len(s.outputs))
+			for name, expr := range s.outputs {
+				if outputs[name], err = w.eval(expr, resp); err != nil {
+					return failed(fmt.Errorf("output %s: %w", name, err))
+				}
+			}
+
+			w.mu.Lock()
+			w.outputs[s.id] = outputs
+			w.mu.Unlock()
+
+			actions = s.onSuccess
+		}
+
+		action, err := w.action(actions, resp)
+		if err != nil {
+			return failed(err)
+		}
+
+		switch {
+		case action == nil && ok:
+			return nil
+		case action == nil:
+			return failed(nil)
+		case action.kind == "end":
+			w.mu.Lock()
+			w.ended = true
+			w.mu.Unlock()
+			return nil
+		case action.kind == "goto":
+			if err := w.jump(); err != nil {
+				return failed(err)
+			}
+			return w.goTo(ctx, s, action)
+		case action.kind == "retry" && retries < action.retryLimit:
+			if err := w.jump(); err != nil {
+				return failed(err)
+			}
+
+			select {
+			case <-time.After(action.retryAfter):
+			case <-ctx.Done():
+				return failed(ctx.Err())
+			}
+
+			if action.stepID != "" && action.stepID != s.id {
+				return w.goTo(ctx, s, action)
+			}
+		case action.kind == "retry" && ok:
+			return nil
+		case action.kind == "retry":
+			return failed(fmt.Errorf("retried %d times", retries))
+		default:
+			return failed(fmt.Errorf("action %s is not supported", action.kind))
+		}
+	}
+}
+
+// jump counts going to another step or retrying one, it fails once the run did so workflowMaxJumps times
+func (w *workflowRun) jump() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.jumps++
+	if w.jumps > workflowMaxJumps {
+		return fmt.Errorf("workflow %s went to another step more than %d times", w.name, workflowMaxJumps)
+	}
+
+	return nil
+}
+
+// goTo takes a goto action of the step from. Going forward skips the steps in between that did not start yet, the step
+// goes on to is then run once the steps it depends on are done (unless it already started). Going back executes the step
+// the action goes to, then again the steps that ran before and depend on it (directly or not), so the workflow goes on
+// from the step the way it did the first time.
+func (w *workflowRun) goTo(ctx context.Context, from *workflowStep, action *workflowAction) error {
+	if action.workflowID != "" {
+		return fmt.Errorf("going to workflow %s is not supported", action.workflowID)
+	}
+
+	var target *workflowStep
+	fromIndex, targetIndex := 0, 0
+	for i, s := range w.steps {
+		if s == from {
+			fromIndex = i
+		}
+		if s.id == action.stepID {
+			target, targetIndex = s, i
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("workflow %s has no step %s", w.name, action.stepID)
+	}
+
+	if targetIndex > fromIndex {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		for _, s := range w.steps[fromIndex+1 : targetIndex] {
+			if !w.executed[s.id] {
+				w.skipped[s.id] = true
+			}
+		}
+		delete(w.skipped, target.id)
+		return nil
+	}
+
+	if err := w.execute(ctx, target); err != nil || w.stopped() {
+		return err
+	}
+
+	affected := map[string]bool{target.id: true}
+	for changed := true; changed; {
+		changed = false
+		for _, s := range w.steps {
+			for _, dep := range s.dependsOn {
+				if affected[dep] && !affected[s.id] {
+					affected[s.id], changed = true, true
+				}
+			}
+		}
+	}
+
+	for _, s := range w.steps {
+		if s == target || !affected[s.id] || !w.started(s.id) {
+			continue
+		}
+		if err := w.execute(ctx, s); err != nil || w.stopped() {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claim marks the step with the provided id as executed, it returns false when a goto skipped the step or it already was
+func (w *workflowRun) claim(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.skipped[id] || w.executed[id] {
+		return false
+	}
+
+	w.executed[id] = true
+	return true
+}
+
+// started returns true once the step with the provided id was executed
+func (w *workflowRun) started(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.executed[id]
+}
+
+// action returns the first of the actions all criteria of which are met, nil when there is none
+func (w *workflowRun) action(actions []workflowAction, resp *workflowResponse) (*workflowAction, error) {
+	for i := range actions {
+		ok, err := w.check(actions[i].criteria, resp)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &actions[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// check returns true when all the criteria are met by the response
+func (w *workflowRun) check(criteria []workflowCriterion, resp *workflowResponse) (bool, error) {
+	for _, c := range criteria {
+		ok, err := w.criterion(c, resp)
+		if err != nil {
+			return false, fmt.Errorf("criterion %s: %w", c.condition, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// criterion returns true when a criterion is met by the response
+func (w *workflowRun) criterion(c workflowCriterion, resp *workflowResponse) (bool, error) {
+	switch c.kind {
+	case "", "simple":
+		return workflowCondition(c.condition, func(operand string) (any, error) {
+			return w.eval(operand, resp)
+		})
+	case "regex":
+		value, err := w.eval(c.context, resp)
+		if err != nil {
+			return false, err
+		}
+
+		re, err := regexp.Compile(c.condition)
+		if err != nil {
+			return false, err
+		}
+
+		return re.MatchString(workflowString(value)), nil
+	case "jsonpath":
+		value, err := w.eval(c.context, resp)
+		if err != nil {
+			return false, err
+		}
+
+		nodes, err := workflowSelect(c.condition, value, value)
+		return len(nodes) > 0, err
+	default:
+		return false, fmt.Errorf("%s criteria are not supported", c.kind)
+	}
+}
+
+// workflowTemplate matches the runtime expressions embedded in a string, e.g. Bearer {$inputs.token}
+var workflowTemplate = regexp.MustCompile("\\{(\\$[^{}]+)\\}")
+
+// eval
+//
+// This method returns the value of a runtime expression: $statusCode, $response.header.<name>, $response.body, $inputs
+// and $inputs.<name>, or $steps.<id>.outputs.<name>. The last three may be followed by a json pointer, e.g.
+// $response.body#/0/id. Anything else is a literal, in which embedded expressions are replaced with their values. The
+// value of what is not there is nil.
+func (w *workflowRun) eval(expr string, resp *workflowResponse) (any, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return w.interpolate(expr, resp)
+	}
+
+	source, pointer, _ := strings.Cut(expr, "#")
+	if strings.HasPrefix(source, "$statusCode") || strings.HasPrefix(source, "$response.") {
+		if resp == nil {
+			return nil, fmt.Errorf("%s is only available to the criteria and outputs of a step", expr)
+		}
+	}
+
+	var value any
+	switch {
+	case source == "$statusCode":
+		return resp.statusCode, nil
+	case strings.HasPrefix(source, "$response.header."):
+		return resp.header.Get(strings.TrimPrefix(source, "$response.header.")), nil
+	case source == "$response.body":
+		value = workflowJSON(resp.body)
+	case source == "$inputs" || strings.HasPrefix(source, "$inputs."):
+		value = workflowField(w.inputs, strings.TrimPrefix(strings.TrimPrefix(source, "$inputs"), "."))
+	case strings.HasPrefix(source, "$steps."):
+		id, name, ok := strings.Cut(strings.TrimPrefix(source, "$steps."), ".outputs.")
+		if !ok {
+			return nil, fmt.Errorf("runtime expression %s is not supported", expr)
+		}
+
+		w.mu.Lock()
+		outputs := w.outputs[id]
+		w.mu.Unlock()
+
+		value = workflowField(outputs, name)
+	default:
+		return nil, fmt.Errorf("runtime expression %s is not supported", expr)
+	}
+
+	return workflowPointer(value, pointer), nil
+}
+
+// interpolate returns a string with the runtime expressions embedded in it replaced with their values
+func (w *workflowRun) interpolate(s string, resp *workflowResponse) (any, error) {
+	if !strings.Contains(s, "{$") {
+		return s, nil
+	}
+
+	var err error
+	result := workflowTemplate.ReplaceAllStringFunc(s, func(match string) string {
+		value, e := w.eval(match[1:len(match)-1], resp)
+		if e != nil && err == nil {
+			err = e
+		}
+		return workflowString(value)
+	})
+
+	return result, err
+}
+
+// resolve returns a value decoded from json with the strings in it that are (or embed) runtime expressions replaced with
+// their values
+func (w *workflowRun) resolve(value any) (any, error) {
+	var err error
+
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "$") {
+			return w.eval(v, nil)
+		}
+		return w.interpolate(v, nil)
+	case map[string]any:
+		for k, item := range v {
+			if v[k], err = w.resolve(item); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if v[i], err = w.resolve(item); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return value, nil
+}
+
+// workflowValue sets target to the value of a runtime expression converted to its type. It is left as is when the
+// expression has no value, which is an error when required is true.
+func workflowValue[T any](w *workflowRun, expr string, required bool, target *T) error {
+	value, err := w.eval(expr, nil)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		if required {
+			return fmt.Errorf("%s has no value", expr)
+		}
+		return nil
+	}
+
+	converted, err := workflowConvert[T](value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", expr, err)
+	}
+
+	*target = converted
+	return nil
+}
+
+// workflowOptional sets target to a pointer to the value of a runtime expression converted to its type, it is left as is
+// when the expression has no value
+func workflowOptional[T any](w *workflowRun, expr string, target **T) error {
+	value, err := w.eval(expr, nil)
+	if err != nil || value == nil {
+		return err
+	}
+
+	converted, err := workflowConvert[T](value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", expr, err)
+	}
+
+	*target = &converted
+	return nil
+}
+
+// workflowBody returns the request body of a step: the payload (json with runtime expressions in it, a runtime expression
+// or a string embedding them) with the value of every replacement set at its json pointer, converted to the body type
+func workflowBody[T any](w *workflowRun, payload string, replacements map[string]string) (T, error) {
+	var body T
+	var value any
+	var err error
+
+	switch trimmed := strings.TrimSpace(payload); {
+	case trimmed == "":
+	case strings.HasPrefix(trimmed, "$"):
+		value, err = w.eval(trimmed, nil)
+	default:
+		if decoded := workflowJSON([]byte(trimmed)); decoded != nil {
+			if _, ok := decoded.(string); !ok {
+				value, err = w.resolve(decoded)
+				break
+			}
+		}
+		value, err = w.interpolate(payload, nil)
+	}
+	if err != nil {
+		return body, fmt.Errorf("request body: %w", err)
+	}
+
+	targets := make([]string, 0, len(replacements))
+	for target := range replacements {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	for _, target := range targets {
+		replacement, err := w.eval(replacements[target], nil)
+		if err != nil {
+			return body, fmt.Errorf("request body %s: %w", target, err)
+		}
+		if value, err = workflowSet(value, workflowTokens(target), replacement); err != nil {
+			return body, fmt.Errorf("request body %s: %w", target, err)
+		}
+	}
+
+	if value == nil {
+		return body, nil
+	}
+
+	return workflowConvert[T](value)
+}
+
+// workflowConvert returns a value decoded from json (or read by a runtime expression) as a T. Strings are decoded as json
+// as well, so "5" is an int, and numbers and booleans are their text when T is a string type.
+func workflowConvert[T any](value any) (T, error) {
+	var v T
+
+	switch t := any(&v).(type) {
+	case *any:
+		*t = value
+		return v, nil
+	case *string:
+		*t = workflowString(value)
+		return v, nil
+	case *[]byte:
+		*t = []byte(workflowString(value))
+		return v, nil
+	case *io.Reader:
+		*t = strings.NewReader(workflowString(value))
+		return v, nil
+	case *url.Values:
+		if s, ok := value.(string); ok {
+			values, err := url.ParseQuery(s)
+			*t = values
+			return v, err
+		}
+
+		values := url.Values{}
+		if fields, ok := value.(map[string]any); ok {
+			for k, field := range fields {
+				values[k] = paramValues(field)
+			}
+		}
+		*t = values
+		return v, nil
+	}
+
+	candidates := make([][]byte, 0, 2)
+	if raw, err := json.Marshal(value); err == nil {
+		candidates = append(candidates, raw)
+	}
+	if s, ok := value.(string); ok {
+		candidates = append(candidates, []byte(s))
+	} else if raw, err := json.Marshal(workflowString(value)); err == nil {
+		candidates = append(candidates, raw)
+	}
+
+	var err error
+	for _, raw := range candidates {
+		var candidate T
+		if err = json.Unmarshal(raw, &candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return v, fmt.Errorf("%s can not be converted: %v", workflowString(value), err)
+}
+
+// workflowResult returns the response of a step whose call returned an error. A response with a status other than 2xx is
+// a response as well, as the success criteria of the step decide whether the step failed.
+func workflowResult(err error) (*workflowResponse, error) {
+	var re *ResponseError
+	if errors.As(err, &re) {
+		return &workflowResponse{statusCode: re.StatusCode, header: re.Header, body: re.Body}, nil
+	}
+
+	return nil, err
+}
+
+// workflowJSON returns data decoded from json, numbers as a json.Number so they keep their precision, or data as a
+// string when it is not json. Empty data is nil.
+func workflowJSON(data []byte) any {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return string(data)
+	}
+
+	return value
+}
+
+// workflowString returns a value as text: strings as they are, nil as an empty string and anything else as json
+func workflowString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(raw)
+}
+
+// workflowField returns the field of a value at a path of names separated by dots, nil when it has none
+func workflowField(current any, path string) any {
+	if path == "" {
+		return current
+	}
+
+	for _, name := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			current = v[name]
+		default:
+			return nil
+		}
+	}
+
+	return current
+}
+
+// workflowTokens returns the unescaped tokens of a json pointer
+func workflowTokens(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return nil
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens
+}
+
+// workflowPointer returns the value a json pointer points to, nil when there is none
+func workflowPointer(value any, pointer string) any {
+	for _, token := range workflowTokens(pointer) {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+
+	return value
+}
+
+// workflowSet returns a value with the value at the json pointer of the provided tokens set, objects are added for the
+// tokens that are not there yet
+func workflowSet(node any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	token := tokens[0]
+	switch n := node.(type) {
+	case nil:
+		child, err := workflowSet(nil, tokens[1:], value)
+		return map[string]any{token: child}, err
+	case map[string]any:
+		child, err := workflowSet(n[token], tokens[1:], value)
+		n[token] = child
+		return n, err
+	case []any:
+		if token == "-" {
+			child, err := workflowSet(nil, tokens[1:], value)
+			return append(n, child), err
+		}
+
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i >= len(n) {
+			return nil, fmt.Errorf("%s is not an index of the array", token)
+		}
+
+		n[i], err = workflowSet(n[i], tokens[1:], value)
+		return n, err
+	default:
+		return nil, fmt.Errorf("%s can not be set on %s", token, workflowString(node))
+	}
+}
+
+// workflowChildren returns the value of the field (or item) with the provided name, or every one of them for *. Fields
+// are in order of their names.
+func workflowChildren(node any, name string) []any {
+	switch n := node.(type) {
+	case map[string]any:
+		if name != "*" {
+			if child, ok := n[name]; ok {
+				return []any{child}
+			}
+			return nil
+		}
+
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		children := make([]any, 0, len(keys))
+		for _, k := range keys {
+			children = append(children, n[k])
+		}
+		return children
+	case []any:
+		if name == "*" {
+			return n
+		}
+		if i, err := strconv.Atoi(name); err == nil {
+			if i < 0 {
+				i += len(n)
+			}
+			if i >= 0 && i < len(n) {
+				return []any{n[i]}
+			}
+		}
+	}
+
+	return nil
+}
+
+// workflowSelect
+//
+// This function returns what a JSONPath selects of a value, current being what @ is. Names, indexes, wildcards and
+// filters are supported, e.g. $.pets[0].name, $.pets[*] or $.pets[?(@.status == 'sold')], the conditions of filters being
+// the ones of simple criteria.
+func workflowSelect(path string, root, current any) ([]any, error) {
+	path = strings.TrimSpace(path)
+
+	var nodes []any
+	switch {
+	case strings.HasPrefix(path, "$"):
+		nodes = []any{root}
+	case strings.HasPrefix(path, "@"):
+		nodes = []any{current}
+	default:
+		return nil, fmt.Errorf("JSONPath %s does not start with $ or @", path)
+	}
+
+	rest := path[1:]
+	for rest != "" {
+		var next []any
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return nil, fmt.Errorf("descendants of JSONPath %s are not supported", path)
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			name := rest[1 : end+1]
+			rest = rest[end+1:]
+			for _, node := range nodes {
+				next = append(next, workflowChildren(node, name)...)
+			}
+		case rest[0] == '[':
+			end := workflowBracket(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %s has a [ without ]", path)
+			}
+
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case strings.HasPrefix(selector, "?"):
+				filter := strings.TrimSpace(selector[1:])
+				if strings.HasPrefix(filter, "(") && strings.HasSuffix(filter, ")") {
+					filter = filter[1 : len(filter)-1]
+				}
+
+				for _, node := range nodes {
+					for _, child := range workflowChildren(node, "*") {
+						ok, err := workflowCondition(filter, func(operand string) (any, error) {
+							selected, err := workflowSelect(operand, root, child)
+							if err != nil || len(selected) == 0 {
+								return nil, err
+							}
+							return selected[0], nil
+						})
+						if err != nil {
+							return nil, err
+						}
+						if ok {
+							next = append(next, child)
+						}
+					}
+				}
+			default:
+				name := selector
+				if len(name) >= 2 && (name[0] == '\'' || name[0] == '"') && name[len(name)-1] == name[0] {
+					name = name[1 : len(name)-1]
+				}
+
+				for _, node := range nodes {
+					next = append(next, workflowChildren(node, name)...)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %s can not be read at %s", path, rest)
+		}
+
+		nodes = next
+	}
+
+	return nodes, nil
+}
+
+// workflowBracket returns the index of the ] closing the [ a string starts with, -1 when there is none
+func workflowBracket(s string) int {
+	depth := 0
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// workflowCondition
+//
+// This function returns whether the condition of a simple criterion is true, e.g. $statusCode == 200 &&
+// $response.body#/status != 'sold'. Operands are runtime expressions (read with operand), numbers, 'strings', true,
+// false and null, compared with ==, !=, <, <=, > and >=, and conditions are combined with &&, || and ! and grouped with
+// parentheses. An operand on its own is true unless it is false, null, 0 or an empty string.
+func workflowCondition(condition string, operand func(string) (any, error)) (bool, error) {
+	p := &workflowParser{tokens: workflowLex(condition), operand: operand}
+
+	value, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, fmt.Errorf("unexpected %s in %s", p.tokens[p.pos], condition)
+	}
+
+	return workflowTruthy(value), nil
+}
+
+// workflowLex returns the tokens of a condition: operators, parentheses, quoted strings and words
+func workflowLex(condition string) []string {
+	tokens := make([]string, 0)
+
+	for i := 0; i < len(condition); {
+		c := condition[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'' || c == '"':
+			j := len(condition)
+			if end := strings.IndexByte(condition[i+1:], c); end >= 0 {
+				j = i + end + 2
+			}
+			tokens = append(tokens, condition[i:j])
+			i = j
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			j := i + 1
+			for j < len(condition) && j < i+2 && strings.ContainsRune("=&|", rune(condition[j])) {
+				j++
+			}
+			tokens = append(tokens, condition[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(condition) && !strings.ContainsRune(" \t\n\r()=!<>&|", rune(condition[j])) {
+				if condition[j] == '[' {
+					if end := workflowBracket(condition[j:]); end > 0 {
+						j += end
+					}
+				}
+				j++
+			}
+			tokens = append(tokens, condition[i:j])
+			i = j
+		}
+	}
+
+	return tokens
+}
+
+// workflowParser parses and evaluates the tokens of a condition
+type workflowParser struct {
+	tokens  []string
+	pos     int
+	operand func(string) (any, error)
+}
+
+func (p *workflowParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *workflowParser) or() (any, error) {
+	left, err := p.and()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+
+		var right any
+		right, err = p.and()
+		left = workflowTruthy(left) || workflowTruthy(right)
+	}
+
+	return left, err
+}
+
+func (p *workflowParser) and() (any, error) {
+	left, err := p.not()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+
+		var right any
+		right, err = p.not()
+		left = workflowTruthy(left) && workflowTruthy(right)
+	}
+
+	return left, err
+}
+
+func (p *workflowParser) not() (any, error) {
+	if p.peek() == "!" {
+		p.pos++
+		value, err := p.not()
+		return !workflowTruthy(value), err
+	}
+
+	return p.comparison()
+}
+
+func (p *workflowParser) comparison() (any, error) {
+	left, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op := p.peek(); op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.pos++
+
+		right, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		return workflowCompare(left, right, op), nil
+	default:
+		return left, nil
+	}
+}
+
+func (p *workflowParser) value() (any, error) {
+	token := p.peek()
+	p.pos++
+
+	switch {
+	case token == "":
+		return nil, errors.New("the condition ends early")
+	case token == "(":
+		value, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("the condition has a ( without )")
+		}
+		p.pos++
+		return value, nil
+	case token[0] == '\'' || token[0] == '"':
+		return strings.TrimSuffix(token[1:], token[:1]), nil
+	case token[0] == '$' || token[0] == '@':
+		return p.operand(token)
+	case token == "true" || token == "false":
+		return token == "true", nil
+	case token == "null":
+		return nil, nil
+	}
+
+	if _, err := strconv.ParseFloat(token, 64); err == nil {
+		return json.Number(token), nil
+	}
+
+	return token, nil
+}
+
+// workflowNumber returns a value as a number, when it is one or a string holding one
+func workflowNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// workflowCompare compares two operands, as numbers when both are and as text otherwise
+func workflowCompare(left, right any, op string) bool {
+	if left == nil || right == nil {
+		switch op {
+		case "==":
+			return left == nil && right == nil
+		case "!=":
+			return (left == nil) != (right == nil)
+		default:
+			return false
+		}
+	}
+
+	var cmp int
+	l, lok := workflowNumber(left)
+	r, rok := workflowNumber(right)
+	switch {
+	case lok && rok && l < r:
+		cmp = -1
+	case lok && rok && l > r:
+		cmp = 1
+	case lok && rok:
+		cmp = 0
+	default:
+		cmp = strings.Compare(workflowString(left), workflowString(right))
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// workflowTruthy returns false for false, nil, 0 and empty strings, and true for anything else
+func workflowTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case json.Number, int, float64:
+		n, _ := workflowNumber(v)
+		return n != 0
+	}
+
+	return true
+}
+`
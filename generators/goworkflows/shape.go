@@ -0,0 +1,187 @@
+package goworkflows
+
+import (
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"strconv"
+	"strings"
+)
+
+// maxDepth is how many outputs of steps reading outputs of other steps (and how many json pointer tokens) are followed
+// to find the type of an output
+const maxDepth = 32
+
+// shape is what is known of the value of a runtime expression: the type, format, enums, ref and properties of the
+// component, property or parameter it reads. A nil shape is a value of any type.
+type shape struct {
+	typ, format string
+	enums       []string
+	ref         any
+	props       types.Properties
+}
+
+// goType returns the Go type of the values of a shape, name being the name of the type declared for it when it needs one
+func (g *generator) goType(name string, s *shape) string {
+	if nil == s {
+		return "any"
+	}
+
+	return g.models.TypeOf(name, s.typ, s.format, s.enums, s.ref, s.props)
+}
+
+// shapeOf
+//
+// This method returns the shape of the value of a runtime expression of a step (nil for the outputs of the workflow):
+// $statusCode is an int, a header a string, $response.body the body of the first 2xx response of the resource of the step
+// with a body, $inputs the Inputs component of the workflow and the output of a step the shape of the expression of the
+// output. A json pointer (or the names following $inputs) is followed through the properties and items of the shape.
+func (g *generator) shapeOf(wf *types.Workflow, step *types.Step, expr string, depth int) *shape {
+	source, pointer, _ := strings.Cut(strings.TrimSpace(expr), "#")
+	tokens := tokensOf(pointer)
+
+	switch {
+	case depth > maxDepth:
+		return nil
+	case source == "$statusCode":
+		return &shape{typ: "number", format: "int"}
+	case strings.HasPrefix(source, "$response.header."):
+		return &shape{typ: "string"}
+	case source == "$response.body":
+		if nil == step || nil == step.Resource {
+			return nil
+		}
+
+		for _, r := range generators.SortedResponses(step.Resource.Responses) {
+			if body := generators.PickBody(r.ResponseBodies); generators.Success(r.Status) && nil != body && nil != body.Schema {
+				return g.walk(&shape{ref: body.Schema}, tokens)
+			}
+		}
+		return nil
+	case source == "$inputs" || strings.HasPrefix(source, "$inputs."):
+		if len(wf.Inputs) <= 0 || nil == wf.Inputs[0] {
+			return nil
+		}
+
+		names := strings.Split(strings.TrimPrefix(source, "$inputs."), ".")
+		if source == "$inputs" {
+			names = nil
+		}
+		return g.walk(&shape{ref: wf.Inputs[0]}, append(names, tokens...))
+	case strings.HasPrefix(source, "$steps."):
+		id, name, ok := strings.Cut(strings.TrimPrefix(source, "$steps."), ".outputs.")
+		if !ok {
+			return nil
+		}
+
+		names := strings.Split(name, ".")
+		for _, s := range wf.Steps {
+			if nil == s || s.Id != id {
+				continue
+			}
+
+			output, ok := s.Outputs[names[0]]
+			if !ok {
+				return nil
+			}
+			return g.walk(g.shapeOf(wf, s, output.Expression.Text, depth+1), append(names[1:], tokens...))
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// walk returns the shape of what the provided tokens (names of properties and indexes of items) point to in a shape
+func (g *generator) walk(s *shape, tokens []string) *shape {
+	for i := 0; nil != s && i < maxDepth; i++ {
+		isArray, isMap := s.typ == "array", s.typ == "object" && s.format == "map"
+
+		// a ref to a component is kept when nothing is left to walk, so the value has the type of the component
+		if comp := g.models.Resolve(s.ref); nil != comp && !isArray && !isMap {
+			if len(tokens) <= 0 {
+				return s
+			}
+
+			ref := comp.Ref
+			if g.models.Resolve(ref) == comp {
+				ref = nil
+			}
+			s = &shape{typ: comp.Type, format: comp.Format, enums: comp.Enums, ref: ref, props: comp.Properties}
+			continue
+		}
+
+		if len(tokens) <= 0 {
+			return s
+		}
+
+		token := tokens[0]
+		tokens = tokens[1:]
+
+		switch {
+		case isArray:
+			if _, err := strconv.Atoi(token); nil != err {
+				return nil
+			}
+			s = items(s.ref, s.props)
+		case isMap:
+			s = items(s.ref, s.props)
+		case s.typ == "object":
+			s = property(s.props, token)
+		default:
+			return nil
+		}
+	}
+
+	return s
+}
+
+// items returns the shape of the items of an array or the values of a map, ref being the component or primitive type of
+// the items and props the properties of inline object items
+func items(ref any, props types.Properties) *shape {
+	if s, ok := ref.(string); ok {
+		switch s {
+		case "string", "boolean", "array", "number":
+			return &shape{typ: s}
+		case "integer":
+			return &shape{typ: "number", format: "int"}
+		case "object":
+			return &shape{typ: "object", props: props}
+		case "int", "int32", "int64", "float32", "float64":
+			return &shape{typ: "number", format: s}
+		}
+	}
+
+	if nil == ref {
+		if len(props) > 0 {
+			return &shape{typ: "object", props: props}
+		}
+		return nil
+	}
+
+	return &shape{ref: ref}
+}
+
+// property returns the shape of the property of an object with the provided name, nil when the object has none
+func property(props types.Properties, name string) *shape {
+	for _, p := range props {
+		if nil != p && (p.RawName == name || p.Name == name) {
+			return &shape{typ: p.Type, format: p.Format, enums: p.Enums, ref: p.Ref, props: p.Properties}
+		}
+	}
+
+	return nil
+}
+
+// tokensOf returns the unescaped tokens of a json pointer
+func tokensOf(pointer string) []string {
+	if len(strings.Trim(pointer, "/")) <= 0 {
+		return nil
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens
+}
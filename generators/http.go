@@ -0,0 +1,164 @@
+package generators
+
+import (
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathParam matches the {name} placeholders of a path template
+var pathParam = regexp.MustCompile(`{([^{}]+)}`)
+
+// PathParams returns the names of the {name} placeholders of a path template, in order
+func PathParams(path string) []string {
+	names := make([]string, 0)
+
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+
+	return names
+}
+
+// PathSegments returns the literal text of a path template around its placeholders, there is always one more of them than
+// there are PathParams
+func PathSegments(path string) []string {
+	return pathParam.Split(path, -1)
+}
+
+// FindParam returns the parameter of a resource with the provided name and location, or nil if there is none
+func FindParam(params types.Parameters, in types.QueryIn, name string) *types.Parameter {
+	for _, p := range params {
+		if nil != p && p.In == in && p.Name == name {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// PickRequest
+//
+// This function returns the request body a generated method sends when a resource accepts more than one: the Default
+// one, else the first json one, else the first one. It returns nil when the resource has no request body.
+func PickRequest(requests types.Requests) *types.Request {
+	var picked *types.Request
+
+	for _, r := range requests {
+		switch {
+		case nil == r:
+		case r.Default:
+			return r
+		case nil == picked || (types.ContentKind(r.ContentType) == "JSON" && types.ContentKind(picked.ContentType) != "JSON"):
+			picked = r
+		}
+	}
+
+	return picked
+}
+
+// PickBody returns the response body a generated method reads when a response has more than one: the first json one, else
+// the first one
+func PickBody(bodies types.ResponseBodies) *types.ResponseBody {
+	var picked *types.ResponseBody
+
+	for _, body := range bodies {
+		if nil != body && (nil == picked || (types.ContentKind(body.MediaType) == "JSON" && types.ContentKind(picked.MediaType) != "JSON")) {
+			picked = body
+		}
+	}
+
+	return picked
+}
+
+// StatusRange
+//
+// This function returns the range of status codes a Response.Status covers: a single code for 200, 400 through 499 for
+// 4XX and 0, 0 for default or anything else that is not a status code.
+func StatusRange(status string) (low, high int) {
+	code := strings.ToUpper(strings.TrimSpace(status))
+
+	if len(code) == 3 && strings.HasSuffix(code, "XX") && code[0] >= '1' && code[0] <= '5' {
+		low = int(code[0]-'0') * 100
+		return low, low + 99
+	}
+
+	if n, err := strconv.Atoi(code); nil == err && n >= 100 && n <= 599 {
+		return n, n
+	}
+
+	return 0, 0
+}
+
+// SortedResponses returns the responses of a resource in the order generated code should match them: exact status codes
+// first, then ranges (2XX) and the default response last
+func SortedResponses(responses types.Responses) types.Responses {
+	sorted := make(types.Responses, 0, len(responses))
+	for _, r := range responses {
+		if nil != r {
+			sorted = append(sorted, r)
+		}
+	}
+
+	rank := func(r *types.Response) int {
+		switch low, high := StatusRange(r.Status); {
+		case low == 0:
+			return 2
+		case low != high:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+
+	return sorted
+}
+
+// Success returns true when a Response.Status is a 2xx status code or range
+func Success(status string) bool {
+	low, _ := StatusRange(status)
+	return low >= 200 && low <= 299
+}
+
+// BasePath returns the basePath variable of the resources when they all have the same one, an empty string otherwise
+func BasePath(resources types.Resources) string {
+	basePath := ""
+
+	for i, res := range resources {
+		if i == 0 {
+			basePath = res.Variables["basePath"]
+		} else if res.Variables["basePath"] != basePath {
+			return ""
+		}
+	}
+
+	return strings.TrimSuffix(basePath, "/")
+}
+
+// BaseURL
+//
+// This function returns the url generated clients call by default: the scheme and host of the first resource that has a
+// host, followed by the provided base path. Hosts that are still a variable (e.g. {{baseUrl}}) are left out.
+func BaseURL(resources types.Resources, basePath string) string {
+	for _, res := range resources {
+		host := res.Variables["host"]
+		if len(host) <= 0 || strings.Contains(host, "{{") {
+			continue
+		}
+
+		scheme := "https"
+		if schemes := strings.Split(res.Variables["schemes"], ","); len(strings.TrimSpace(schemes[0])) > 0 {
+			scheme = strings.TrimSpace(schemes[0])
+		}
+
+		return scheme + "://" + host + basePath
+	}
+
+	return basePath
+}
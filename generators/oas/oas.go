@@ -0,0 +1,387 @@
+// Package oas is the built in generator of the openapi target. It writes the model back out as a single OpenAPI 3.1
+// document, e.g. to publish what several sources (OpenAPI, Postman, HAR, ...) were merged in to:
+//
+//   - every HTTP Resource (the Latest version of it) is an operation of the path item of its Path, below the basePath of
+//     the resources, tagged with its Root
+//   - defined Components are the schemas of components/schemas, referenced with a $ref, and every other Component (request
+//     and response bodies, parameters) is a schema written in place
+//   - Parameters, Requests and Responses are the parameters, requestBody and responses of the operation
+//
+// Paths, operations, schemas and everything else keyed by name are written in order of their keys, so the document only
+// changes when the model does and diffs cleanly.
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target is the name of the target this generator writes
+const Target = "openapi"
+
+// Options of the openapi target
+const (
+	OptionFile    = "file"    // The path of the document relative to the output directory, openapi.json by default. A .yaml or .yml file is written as yaml
+	OptionTitle   = "title"   // The info.title of the document, the Owner of the resources by default
+	OptionVersion = "version" // The info.version of the document, the Version of the resources by default
+)
+
+// Version is the OpenAPI version of the written documents
+const Version = "3.1.0"
+
+// Generator implements pipeline.Generator for the openapi target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	e := newEmitter(request.Model.Components)
+
+	resources, skipped := generators.HTTPResources(request.Model.Resources)
+	if skipped > 0 {
+		e.diagnostics.Info("openapi-skipped", "", "%d resources are not HTTP resources and have no operation", skipped)
+	}
+
+	doc := e.document(resources, generators.Option(request, OptionTitle, ""), generators.Option(request, OptionVersion, ""))
+
+	file := generators.Option(request, OptionFile, "openapi.json")
+	content, err := encode(doc, strings.HasSuffix(file, ".yaml") || strings.HasSuffix(file, ".yml"))
+	if nil != err {
+		return nil, err
+	}
+
+	return &types.GeneratorResponse{Files: []types.GeneratedFile{{Path: file, Content: content}}, Diagnostics: e.diagnostics}, nil
+}
+
+// The parts of an OpenAPI document the emitter writes. Fields are in the order the specification lists them, maps are
+// written in order of their keys.
+type (
+	document struct {
+		OpenAPI    string                           `json:"openapi"`
+		Info       info                             `json:"info"`
+		Servers    []server                         `json:"servers,omitempty"`
+		Tags       []tag                            `json:"tags,omitempty"`
+		Paths      map[string]map[string]*operation `json:"paths"`
+		Components *components                      `json:"components,omitempty"`
+	}
+
+	info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+
+	server struct {
+		URL string `json:"url"`
+	}
+
+	tag struct {
+		Name string `json:"name"`
+	}
+
+	components struct {
+		Schemas map[string]schema `json:"schemas"`
+	}
+
+	operation struct {
+		Tags        []string             `json:"tags,omitempty"`
+		Summary     string               `json:"summary,omitempty"`
+		Description string               `json:"description,omitempty"`
+		OperationID string               `json:"operationId"`
+		Parameters  []*parameter         `json:"parameters,omitempty"`
+		RequestBody *requestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*response `json:"responses"`
+		Deprecated  bool                 `json:"deprecated,omitempty"`
+	}
+
+	parameter struct {
+		Name        string `json:"name"`
+		In          string `json:"in"`
+		Description string `json:"description,omitempty"`
+		Required    bool   `json:"required,omitempty"`
+		Schema      schema `json:"schema"`
+		Example     string `json:"example,omitempty"`
+	}
+
+	requestBody struct {
+		Content  map[string]*mediaType `json:"content"`
+		Required bool                  `json:"required,omitempty"`
+	}
+
+	response struct {
+		Description string                `json:"description"`
+		Content     map[string]*mediaType `json:"content,omitempty"`
+	}
+
+	mediaType struct {
+		Schema  schema `json:"schema,omitempty"`
+		Example any    `json:"example,omitempty"`
+	}
+)
+
+// methods are the methods a path item has an operation for
+var methods = map[string]bool{"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true,
+	"patch": true, "trace": true}
+
+// inOrder is the order parameters are written in
+var inOrder = map[types.QueryIn]int{types.PATH: 0, types.QUERY: 1, types.HEADER: 2, types.COOKIE: 3}
+
+// document returns the document of the provided resources
+func (e *emitter) document(resources types.Resources, title, version string) *document {
+	for _, res := range resources {
+		if len(title) <= 0 {
+			title = res.Owner
+		}
+		if len(version) <= 0 {
+			version = res.Version
+		}
+	}
+	if len(title) <= 0 {
+		title = "API"
+	}
+	if len(version) <= 0 {
+		version = "1.0.0"
+	}
+
+	doc := &document{OpenAPI: Version, Info: info{Title: title, Version: version}, Paths: make(map[string]map[string]*operation)}
+
+	basePath := generators.BasePath(resources)
+	if url := generators.BaseURL(resources, basePath); len(url) > 0 {
+		doc.Servers = []server{{URL: url}}
+	}
+
+	tags := make(map[string]bool)
+	operationIds := make(map[string]bool, len(resources))
+
+	for _, res := range resources {
+		method := strings.ToLower(res.Method)
+		if !methods[method] {
+			e.diagnostics.Warn("openapi-method", res.SourceDoc, "%s %s has a method OpenAPI has no operation for and is left out", res.Method, res.Path)
+			continue
+		}
+
+		// resources that do not share the basePath of the others keep theirs in their path
+		path := res.Path
+		if prefix := strings.TrimSuffix(res.Variables["basePath"], "/"); prefix != basePath {
+			path = prefix + path
+		}
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(map[string]*operation)
+			doc.Paths[path] = item
+		}
+		if _, ok := item[method]; ok {
+			e.diagnostics.Warn("openapi-duplicate", res.SourceDoc, "%s %s is provided by more than one source, only the first one is written", res.Method, path)
+			continue
+		}
+
+		op := e.operation(res, path)
+
+		id := op.OperationID
+		for i := 2; operationIds[op.OperationID]; i++ {
+			op.OperationID = id + strconv.Itoa(i)
+		}
+		operationIds[op.OperationID] = true
+
+		if name := strings.Trim(res.Root, "/"); len(name) > 0 {
+			op.Tags = []string{name}
+			tags[name] = true
+		}
+
+		item[method] = op
+	}
+
+	for name := range tags {
+		doc.Tags = append(doc.Tags, tag{Name: name})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
+	})
+
+	if schemas := e.schemas(); len(schemas) > 0 {
+		doc.Components = &components{Schemas: schemas}
+	}
+
+	return doc
+}
+
+// operation returns the operation of a resource
+func (e *emitter) operation(res *types.Resource, path string) *operation {
+	op := &operation{
+		Summary:     res.Summary,
+		Description: res.Description,
+		OperationID: res.Name,
+		Deprecated:  res.Deprecated,
+		Responses:   make(map[string]*response),
+	}
+	if len(op.OperationID) <= 0 {
+		op.OperationID = generators.Camel(types.MakeResourceName(res.Name, res.Method, res.Path, ""))
+	}
+
+	// every placeholder of the path needs a parameter, even when the source did not declare one
+	for _, name := range generators.PathParams(path) {
+		if nil == generators.FindParam(res.Parameters, types.PATH, name) {
+			op.Parameters = append(op.Parameters, &parameter{Name: name, In: string(types.PATH), Required: true, Schema: schema{"type": "string"}})
+		}
+	}
+
+	for _, p := range res.Parameters {
+		if nil == p {
+			continue
+		}
+		if _, ok := inOrder[p.In]; !ok {
+			continue
+		}
+
+		param := &parameter{Name: p.Name, In: string(p.In), Description: p.Description, Required: p.Required || p.In == types.PATH}
+		if !strings.Contains(p.Value, "{{") {
+			// a value that is still a variable (e.g. {{token}}) is no example
+			param.Example = p.Value
+		}
+		if len(p.Components) > 0 && nil != p.Components[0] {
+			param.Schema = e.component(p.Components[0])
+		} else {
+			param.Schema = e.primitive(p.Type, p.Format, nil)
+		}
+
+		op.Parameters = append(op.Parameters, param)
+	}
+
+	sort.SliceStable(op.Parameters, func(i, j int) bool {
+		a, b := op.Parameters[i], op.Parameters[j]
+		if a.In != b.In {
+			return inOrder[types.QueryIn(a.In)] < inOrder[types.QueryIn(b.In)]
+		}
+		return a.Name < b.Name
+	})
+
+	for _, r := range res.Requests {
+		if nil == r || len(r.ContentType) <= 0 {
+			continue
+		}
+
+		if nil == op.RequestBody {
+			op.RequestBody = &requestBody{Content: make(map[string]*mediaType)}
+		}
+		if _, ok := op.RequestBody.Content[r.ContentType]; !ok {
+			op.RequestBody.Content[r.ContentType] = &mediaType{Schema: e.body(r.Schema)}
+		}
+		op.RequestBody.Required = op.RequestBody.Required || r.Required
+	}
+
+	for _, r := range res.Responses {
+		if nil == r {
+			continue
+		}
+
+		status := strings.ToUpper(r.Status)
+		if status == "DEFAULT" || len(status) <= 0 {
+			status = "default"
+		}
+		if _, ok := op.Responses[status]; ok {
+			continue
+		}
+
+		resp := &response{Description: r.Description}
+		if len(resp.Description) <= 0 {
+			resp.Description = description(status)
+		}
+
+		for _, body := range r.ResponseBodies {
+			if nil == body || len(body.MediaType) <= 0 {
+				continue
+			}
+
+			if nil == resp.Content {
+				resp.Content = make(map[string]*mediaType)
+			}
+			if _, ok := resp.Content[body.MediaType]; !ok {
+				resp.Content[body.MediaType] = &mediaType{Schema: e.body(body.Schema), Example: example(body.Example)}
+			}
+		}
+
+		op.Responses[status] = resp
+	}
+
+	// an operation must declare at least one response
+	if len(op.Responses) <= 0 {
+		op.Responses["default"] = &response{Description: description("default")}
+	}
+
+	return op
+}
+
+// description returns the description of a response that has none of its own, the status text of its status code
+func description(status string) string {
+	if code, err := strconv.Atoi(status); nil == err && len(http.StatusText(code)) > 0 {
+		return http.StatusText(code)
+	}
+
+	return "Response " + status
+}
+
+// example returns the example of a response body, parsed when it is json so it is written as json rather than a string
+func example(value string) any {
+	if len(strings.TrimSpace(value)) <= 0 {
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); nil == err {
+		return v
+	}
+
+	return value
+}
+
+// encode returns a document as indented json, or yaml when asYaml is true. Both keep the order of the fields of the
+// document and write maps in order of their keys.
+func encode(doc *document, asYaml bool) ([]byte, error) {
+	var b bytes.Buffer
+
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); nil != err {
+		return nil, err
+	}
+
+	if !asYaml {
+		return b.Bytes(), nil
+	}
+
+	// json is yaml, so the json document is read as yaml nodes (that keep its order) and written again in block style
+	var node yaml.Node
+	if err := yaml.Unmarshal(b.Bytes(), &node); nil != err {
+		return nil, err
+	}
+	blockStyle(&node)
+
+	var out bytes.Buffer
+	yamlEncoder := yaml.NewEncoder(&out)
+	yamlEncoder.SetIndent(2)
+	if err := yamlEncoder.Encode(&node); nil != err {
+		return nil, err
+	}
+	if err := yamlEncoder.Close(); nil != err {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// blockStyle clears the (flow and quoting) style of a node and its children, so they are written in the default style
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
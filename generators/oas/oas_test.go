@@ -0,0 +1,137 @@
+package oas
+
+import (
+	"encoding/json"
+	"github.com/spirefy/go-codegen/generators/generatortest"
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/loaders/openapi"
+	"github.com/spirefy/go-codegen/types"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestDocument(t *testing.T) {
+	files, diagnostics := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionTitle: "Petstore", OptionVersion: "2.0.0"})
+	if len(diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", diagnostics)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "petstore.golden.json"))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if got := files["openapi.json"]; got != string(want) {
+		t.Errorf("the document is not testdata/petstore.golden.json:\n%s", got)
+	}
+}
+
+func TestYaml(t *testing.T) {
+	jsonFiles, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), nil)
+	yamlFiles, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionFile: "api/openapi.yaml"})
+
+	var fromJson, fromYaml any
+	if err := yaml.Unmarshal([]byte(jsonFiles["openapi.json"]), &fromJson); nil != err {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(yamlFiles["api/openapi.yaml"]), &fromYaml); nil != err {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(fromJson, fromYaml) {
+		t.Errorf("the yaml document is not the json one:\n%s", yamlFiles["api/openapi.yaml"])
+	}
+}
+
+// TestLoadDocument loads the document written for the pet store with the openapi loader, which has to find the resources
+// and components it was written from
+func TestLoadDocument(t *testing.T) {
+	files, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), nil)
+
+	model, err := (openapi.Loader{}).Load("openapi.json", []byte(files["openapi.json"]))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	resources := make([]string, 0, len(model.Resources))
+	for _, res := range model.Resources {
+		resources = append(resources, res.Method+" "+res.Path)
+	}
+	sort.Strings(resources)
+	if want := []string{"delete /pets/{petId}", "get /pets", "get /pets/{petId}", "post /pets"}; !reflect.DeepEqual(resources, want) {
+		t.Errorf("resources = %v, want %v", resources, want)
+	}
+
+	var pet *types.Component
+	for _, comp := range model.Components {
+		if comp.Name == "Pet" {
+			pet = comp
+		}
+	}
+	if nil == pet {
+		t.Fatalf("components = %v, want a Pet", loadertest.Names(model.Components))
+	}
+
+	if id := loadertest.Property(pet, "id"); nil == id || nil == id.Required || !*id.Required {
+		t.Errorf("the id of the pet is %+v, want it required", id)
+	}
+	if born := loadertest.Property(pet, "born_at"); nil == born || nil == born.Null || !*born.Null {
+		t.Errorf("born_at is %+v, want it nullable", born)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	pet := &types.Component{Id: 1, Name: "Pet", Type: "object", Source: types.SourceComponent}
+	inline := &types.Component{Id: 2, Name: "Size", Type: "string", Enums: []string{"S", "M"}, Source: types.SourceProperty}
+
+	tests := []struct {
+		name  string
+		typ   string
+		enums []string
+		null  *bool
+		ref   any
+		want  string
+	}{
+		{"a component", "object", nil, nil, pet, `{"$ref":"#/components/schemas/Pet"}`},
+		{"a nullable component", "object", nil, boolPtr(true), pet, `{"anyOf":[{"$ref":"#/components/schemas/Pet"},{"type":"null"}]}`},
+		{"an inline component", "string", nil, nil, inline, `{"enum":["S","M"],"type":"string"}`},
+		{"a nullable string", "string", nil, boolPtr(true), nil, `{"type":["string","null"]}`},
+		{"a nullable enum", "string", []string{"a", "b"}, boolPtr(true), nil, `{"enum":["a","b",null],"type":["string","null"]}`},
+		{"an integer enum", "integer", []string{"1", "x", "2"}, nil, nil, `{"enum":[1,2],"type":"integer"}`},
+		{"an array of components", "array", nil, nil, pet, `{"items":{"$ref":"#/components/schemas/Pet"},"type":"array"}`},
+		{"an array of int64", "array", nil, nil, "int64", `{"items":{"format":"int64","type":"integer"},"type":"array"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEmitter(types.Components{pet, inline})
+
+			got, err := json.Marshal(e.schema(tt.typ, "", "", tt.enums, tt.null, tt.ref, nil))
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("schema = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaUnresolved(t *testing.T) {
+	e := newEmitter(types.Components{})
+	e.schema("object", "", "", nil, nil, "Missing", nil)
+	e.schema("array", "", "", nil, nil, "Missing", nil)
+
+	if !reflect.DeepEqual(loadertest.Codes(e.diagnostics), []string{"openapi-unresolved"}) {
+		t.Errorf("diagnostics = %v, want a single openapi-unresolved warning", e.diagnostics)
+	}
+}
@@ -0,0 +1,310 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// schema is a JSON Schema, in the 2020-12 dialect OpenAPI 3.1 uses. Being a map its keywords are written in order.
+type schema map[string]any
+
+// emitter converts the components of a model in to schemas. Defined components are named and written to
+// components/schemas, every other component is written in place wherever it is used.
+type emitter struct {
+	diagnostics types.Diagnostics
+
+	components types.Components
+	names      map[*types.Component]string
+	taken      map[string]bool
+	defined    []*types.Component // the components of components/schemas, in the order they were named
+	visiting   map[*types.Component]bool
+	warned     map[string]bool
+}
+
+// schemaName matches what the name of a schema of components/schemas can not hold
+var schemaName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// newEmitter returns the emitter of the provided components, with every defined component named
+func newEmitter(components types.Components) *emitter {
+	e := &emitter{
+		diagnostics: make(types.Diagnostics, 0),
+		components:  components,
+		names:       make(map[*types.Component]string),
+		taken:       make(map[string]bool),
+		visiting:    make(map[*types.Component]bool),
+		warned:      make(map[string]bool),
+	}
+
+	for _, comp := range generators.ModelComponents(components) {
+		if comp.Source == types.SourceComponent {
+			e.define(comp)
+		}
+	}
+
+	return e
+}
+
+// define names a component of components/schemas, numbering names that are already taken
+func (e *emitter) define(comp *types.Component) {
+	name := schemaName.ReplaceAllString(comp.Name, "_")
+	if len(name) <= 0 {
+		name = "Schema"
+	}
+
+	unique := name
+	for i := 2; e.taken[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+
+	e.taken[unique] = true
+	e.names[comp] = unique
+	e.defined = append(e.defined, comp)
+}
+
+// schemas
+//
+// This method returns the schemas of components/schemas. Writing a schema can define another one (an inline component
+// that contains itself), so this is called once all operations are written and keeps going until every defined component
+// has its schema.
+func (e *emitter) schemas() map[string]schema {
+	schemas := make(map[string]schema, len(e.defined))
+	for i := 0; i < len(e.defined); i++ {
+		schemas[e.names[e.defined[i]]] = e.schemaOf(e.defined[i])
+	}
+
+	return schemas
+}
+
+// component
+//
+// This method returns the schema where a component is used: a $ref for a defined component, the schema of the component
+// itself for any other. An inline component that (through its properties) contains itself can not be written in place,
+// so it is defined as well.
+func (e *emitter) component(comp *types.Component) schema {
+	// bodies and parameters can hold a copy of the component, the one of the model is the one that is named
+	if resolved := e.components.ResolveRef(comp); nil != resolved {
+		comp = resolved
+	}
+
+	if _, ok := e.names[comp]; !ok && e.visiting[comp] {
+		e.define(comp)
+	}
+
+	if name, ok := e.names[comp]; ok {
+		return schema{"$ref": "#/components/schemas/" + name}
+	}
+
+	e.visiting[comp] = true
+	defer delete(e.visiting, comp)
+
+	return e.schemaOf(comp)
+}
+
+// body returns the schema of a request or response body, nil when it has none
+func (e *emitter) body(comp *types.Component) schema {
+	if nil == comp {
+		return nil
+	}
+
+	return e.component(comp)
+}
+
+// schemaOf returns the schema of a component
+func (e *emitter) schemaOf(comp *types.Component) schema {
+	ref := comp.Ref
+	if generators.Resolve(e.components, ref) == comp {
+		ref = nil
+	}
+
+	return e.schema(comp.Type, comp.Format, comp.Description, comp.Enums, comp.Null, ref, comp.Properties)
+}
+
+// schema returns the schema of a value with the provided type, format, enums, ref and properties, as found on a Component
+// or Property
+func (e *emitter) schema(typ, format, description string, enums []string, null *bool, ref any, props types.Properties) schema {
+	var s schema
+
+	switch {
+	case typ == "array":
+		s = schema{"type": "array", "items": e.items(ref, props)}
+	case typ == "object" && format == "map":
+		s = schema{"type": "object", "additionalProperties": e.items(ref, props)}
+	case nil != generators.Resolve(e.components, ref):
+		s = e.component(generators.Resolve(e.components, ref))
+	default:
+		if nil != ref {
+			e.unresolved(ref)
+		}
+
+		if typ == "object" {
+			s = e.object(props)
+		} else {
+			s = e.primitive(typ, format, enums)
+		}
+	}
+
+	if len(description) > 0 {
+		s["description"] = description
+	}
+
+	if nil != null && *null {
+		return nullable(s)
+	}
+
+	return s
+}
+
+// object returns the schema of an object with the provided properties
+func (e *emitter) object(props types.Properties) schema {
+	s := schema{"type": "object"}
+	if len(props) <= 0 {
+		return s
+	}
+
+	properties := make(map[string]schema, len(props))
+	required := make([]string, 0, len(props))
+
+	for _, p := range props {
+		if nil == p {
+			continue
+		}
+
+		name := p.RawName
+		if len(name) <= 0 {
+			name = p.Name
+		}
+
+		properties[name] = e.schema(p.Type, p.Format, p.Description, p.Enums, p.Null, p.Ref, p.Properties)
+		if nil != p.Required && *p.Required {
+			required = append(required, name)
+		}
+	}
+
+	s["properties"] = properties
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+
+	return s
+}
+
+// items returns the schema of the items of an array or the values of a map, ref being the component or primitive type of
+// the items and props the properties of inline object items
+func (e *emitter) items(ref any, props types.Properties) schema {
+	if s, ok := ref.(string); ok && generators.Primitive(s) {
+		switch s {
+		case "object":
+			return e.object(props)
+		case "array":
+			return schema{"type": "array"}
+		case "string", "boolean", "number", "integer":
+			return e.primitive(s, "", nil)
+		default:
+			return e.primitive("number", s, nil)
+		}
+	}
+
+	if nil == ref {
+		if len(props) > 0 {
+			return e.object(props)
+		}
+		return schema{}
+	}
+
+	if comp := generators.Resolve(e.components, ref); nil != comp {
+		return e.component(comp)
+	}
+
+	e.unresolved(ref)
+	return schema{}
+}
+
+// primitive
+//
+// This method returns the schema of a string, number or boolean. Numbers are a number in the model with the Go name of
+// their type as format (see loaders.SchemaConverter.TypeOf), which is turned back in to an integer or number with the
+// OpenAPI format. Enum values are written as the type they are.
+func (e *emitter) primitive(typ, format string, enums []string) schema {
+	s := schema{}
+
+	switch typ {
+	case "number", "integer":
+		s["type"] = "number"
+		switch format {
+		case "int":
+			s["type"] = "integer"
+		case "int32", "int64":
+			s["type"], s["format"] = "integer", format
+		case "float32":
+			s["format"] = "float"
+		case "float64", "":
+			if typ == "integer" {
+				s["type"] = "integer"
+			}
+		default:
+			s["format"] = format
+		}
+	case "string", "boolean", "null":
+		s["type"] = typ
+		if len(format) > 0 {
+			s["format"] = format
+		}
+	case "object":
+		s["type"] = "object"
+	}
+
+	if len(enums) > 0 {
+		values := make([]any, 0, len(enums))
+		for _, v := range enums {
+			switch s["type"] {
+			case "number", "integer":
+				if _, err := strconv.ParseFloat(v, 64); nil == err {
+					values = append(values, json.Number(v))
+				}
+			case "boolean":
+				if b, err := strconv.ParseBool(v); nil == err {
+					values = append(values, b)
+				}
+			default:
+				values = append(values, v)
+			}
+		}
+		s["enum"] = values
+	}
+
+	return s
+}
+
+// nullable returns a schema that allows null as well as what the provided schema does
+func nullable(s schema) schema {
+	typ, ok := s["type"].(string)
+	if !ok {
+		return schema{"anyOf": []schema{s, {"type": "null"}}}
+	}
+
+	s["type"] = []string{typ, "null"}
+	if values, ok := s["enum"].([]any); ok {
+		s["enum"] = append(values, nil)
+	}
+
+	return s
+}
+
+// unresolved reports a ref that is not a component of the model, once
+func (e *emitter) unresolved(ref any) {
+	name := fmt.Sprint(ref)
+	if c, ok := ref.(*types.Component); ok && nil != c {
+		name = c.Name
+	}
+
+	if !e.warned[name] {
+		e.warned[name] = true
+		e.diagnostics.Warn("openapi-unresolved", "", "%s is referenced but not a component of the model, it is written as an empty schema", name)
+	}
+}
@@ -0,0 +1,485 @@
+package python
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"strconv"
+	"strings"
+)
+
+// client writes the client module, a function per resource
+type client struct {
+	models    *models
+	functions map[string]bool
+
+	b strings.Builder
+}
+
+// reserved are the names of the module a function can not take and of the locals of a function an argument can not take
+var reserved = map[string]bool{"client": true, "body": true, "path": true, "query": true, "headers": true, "data": true,
+	"models": true, "response": true}
+
+// arg is an argument of a function
+type arg struct {
+	name  string
+	param *types.Parameter
+	typ   string
+}
+
+// source returns the client module
+func (c *client) source(resources types.Resources, baseURL string) []byte {
+	basePath := generators.BasePath(resources)
+	if len(baseURL) <= 0 {
+		baseURL = generators.BaseURL(resources, basePath)
+	}
+
+	var functions strings.Builder
+	for _, res := range resources {
+		prefix := ""
+		if strings.TrimSuffix(res.Variables["basePath"], "/") != basePath {
+			prefix = strings.TrimSuffix(res.Variables["basePath"], "/")
+		}
+
+		c.function(&functions, res, prefix)
+	}
+
+	c.b.WriteString(Header + "\n")
+	fmt.Fprintf(&c.b, `"""Functions calling the resources of the API, one per resource."""
+
+from __future__ import annotations
+
+import datetime
+import decimal
+import enum
+import functools
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+import uuid
+from dataclasses import dataclass, field
+from typing import Any, Dict, Generic, List, Literal, Optional, Tuple, TypeVar, Union
+
+from pydantic import AnyUrl, Base64Bytes, BaseModel, TypeAdapter
+
+from . import models
+
+DEFAULT_BASE_URL = %s
+
+T = TypeVar("T")
+
+
+@dataclass
+class Client:
+    """What every call needs: the url the paths of the resources are appended to, the headers sent with every request
+    (e.g. an Authorization header) and the timeout of a request in seconds.
+    """
+
+    base_url: str = DEFAULT_BASE_URL
+    headers: Dict[str, str] = field(default_factory=dict)
+    timeout: Optional[float] = None
+
+
+@dataclass
+class Response(Generic[T]):
+    """A response with a 2xx status, body holds the body decoded in to the type declared for the status."""
+
+    status: int
+    headers: Dict[str, str]
+    body: T
+
+
+class ApiError(Exception):
+    """The error raised for a response with a status other than 2xx. value holds the body decoded in to the type declared
+    for the status, or None when it declares none (or the body could not be decoded).
+    """
+
+    def __init__(self, method: str, path: str, status: int, headers: Dict[str, str], body: bytes, value: Any) -> None:
+        super().__init__(f"{method} {path} returned status {status}")
+        self.method = method
+        self.path = path
+        self.status = status
+        self.headers = headers
+        self.body = body
+        self.value = value
+
+
+# A declared status: the range of status codes it covers (0, 0 for the default response), the type of its body and how
+# the body is decoded (json, text, binary or none)
+_Status = Tuple[int, int, Any, str]
+
+
+def _send(
+    client: Client,
+    method: str,
+    path: str,
+    query: List[Tuple[str, str]],
+    headers: Dict[str, str],
+    data: Optional[bytes],
+    statuses: List[_Status],
+) -> Response[Any]:
+    """Sends a request and returns its response, the body is decoded according to the first declared status it matches."""
+    url = client.base_url.rstrip("/") + path
+    if query:
+        url += "?" + urllib.parse.urlencode(query)
+
+    request = urllib.request.Request(url, data=data, method=method)
+    for name, value in {**client.headers, **headers}.items():
+        request.add_header(name, value)
+
+    try:
+        with urllib.request.urlopen(request, timeout=client.timeout) as response:
+            status, response_headers, body = response.status, dict(response.headers.items()), response.read()
+    except urllib.error.HTTPError as error:
+        status, response_headers, body = error.code, dict(error.headers.items()), error.read()
+
+    value = None
+    for low, high, typ, kind in statuses:
+        if low == 0 or low <= status <= high:
+            try:
+                value = _decode(body, typ, kind)
+            except ValueError:
+                if 200 <= status <= 299:
+                    raise
+            break
+
+    if 200 <= status <= 299:
+        return Response(status, response_headers, value)
+
+    raise ApiError(method, path, status, response_headers, body, value)
+
+
+@functools.lru_cache(maxsize=None)
+def _adapter(typ: Any) -> TypeAdapter[Any]:
+    """Returns the pydantic TypeAdapter validating and serializing values of a type."""
+    return TypeAdapter(typ)
+
+
+def _decode(body: bytes, typ: Any, kind: str) -> Any:
+    """Returns a response body decoded according to kind, json bodies are validated against typ."""
+    if kind == "none":
+        return None
+    if kind == "binary":
+        return body
+    if kind == "text":
+        return body.decode("utf-8")
+    if not body.strip():
+        return None
+    return _adapter(typ).validate_json(body)
+
+
+def _values(value: Any) -> List[str]:
+    """Returns the string values of a parameter, one for every item of a list."""
+    if value is None:
+        return []
+    if isinstance(value, (list, tuple)):
+        return [v for item in value for v in _values(item)]
+    if isinstance(value, enum.Enum):
+        value = value.value
+    if isinstance(value, bool):
+        return ["true" if value else "false"]
+    if isinstance(value, (datetime.date, datetime.time)):
+        return [value.isoformat()]
+    if isinstance(value, BaseModel):
+        return [value.model_dump_json(by_alias=True, exclude_unset=True)]
+    if isinstance(value, dict):
+        return [json.dumps(value)]
+    return [str(value)]
+
+
+def _path(value: Any) -> str:
+    """Returns the escaped value of a path parameter."""
+    return urllib.parse.quote(",".join(_values(value)), safe="")
+
+
+def _query(query: List[Tuple[str, str]], name: str, value: Any) -> None:
+    """Adds the values of a parameter to the query string."""
+    query.extend((name, v) for v in _values(value))
+
+
+def _header(headers: Dict[str, str], name: str, value: Any) -> None:
+    """Sets a header to the comma separated values of a parameter."""
+    values = _values(value)
+    if values:
+        headers[name] = ",".join(values)
+
+
+def _cookie(headers: Dict[str, str], name: str, value: Any) -> None:
+    """Adds a parameter to the Cookie header."""
+    values = _values(value)
+    if values:
+        cookie = name + "=" + urllib.parse.quote(",".join(values))
+        headers["Cookie"] = headers["Cookie"] + "; " + cookie if "Cookie" in headers else cookie
+
+
+def _fields(body: Any) -> Dict[str, Any]:
+    """Returns the fields of a form or multipart body by their name in json."""
+    if isinstance(body, BaseModel):
+        return body.model_dump(by_alias=True, exclude_unset=True)
+    return dict(body)
+
+
+def _encode(body: Any, typ: Any, kind: str, content_type: str, headers: Dict[str, str]) -> Optional[bytes]:
+    """Returns a request body encoded according to kind and sets its Content-Type header, None when there is no body."""
+    if body is None:
+        return None
+
+    if kind == "json":
+        data = _adapter(typ).dump_json(body, by_alias=True, exclude_unset=True)
+    elif kind == "form":
+        fields = [(name, v) for name, value in _fields(body).items() for v in _values(value)]
+        data = urllib.parse.urlencode(fields).encode("utf-8")
+    elif kind == "multipart":
+        boundary = uuid.uuid4().hex
+        parts = []
+        for name, value in _fields(body).items():
+            if isinstance(value, bytes):
+                parts.append((f'form-data; name="{name}"; filename="{name}"', value))
+            else:
+                parts.extend((f'form-data; name="{name}"', v.encode("utf-8")) for v in _values(value))
+
+        data = b"".join(
+            f"--{boundary}\r\nContent-Disposition: {disposition}\r\n\r\n".encode("utf-8") + content + b"\r\n"
+            for disposition, content in parts
+        )
+        data += f"--{boundary}--\r\n".encode("utf-8")
+        content_type = "multipart/form-data; boundary=" + boundary
+    elif isinstance(body, str):
+        data = body.encode("utf-8")
+    else:
+        data = bytes(body)
+
+    headers["Content-Type"] = content_type
+    return data
+`, Quote(baseURL))
+
+	c.b.WriteString(functions.String())
+
+	return []byte(c.b.String())
+}
+
+// function writes the function of a resource
+func (c *client) function(b *strings.Builder, res *types.Resource, prefix string) {
+	name := FieldName(types.MakeResourceName(res.Name, res.Method, res.Path, ""))
+	if reserved[name] {
+		name += "_"
+	}
+	unique := name
+	for i := 2; c.functions[unique]; i++ {
+		unique = name + "_" + strconv.Itoa(i)
+	}
+	name = unique
+	c.functions[name] = true
+
+	method := strings.ToUpper(res.Method)
+	taken := make(map[string]bool, len(reserved))
+	for k := range reserved {
+		taken[k] = true
+	}
+	newArg := func(p *types.Parameter) *arg {
+		argName := FieldName(p.Name)
+		for i := 2; taken[argName]; i++ {
+			argName = FieldName(p.Name) + "_" + strconv.Itoa(i)
+		}
+		taken[argName] = true
+
+		return &arg{name: argName, param: p, typ: c.paramType(ClassName(name), p)}
+	}
+
+	// path parameters are positional arguments in the order of the template, the others are keyword only arguments
+	pathArgs := make([]*arg, 0)
+	for _, placeholder := range generators.PathParams(res.Path) {
+		p := generators.FindParam(res.Parameters, types.PATH, placeholder)
+		if nil == p {
+			p = &types.Parameter{Name: placeholder, In: types.PATH, Required: true, Type: "string"}
+		}
+		pathArgs = append(pathArgs, newArg(p))
+	}
+
+	keywordArgs := make([]*arg, 0)
+	for _, p := range res.Parameters {
+		if nil != p && (p.In == types.QUERY || p.In == types.HEADER || p.In == types.COOKIE) {
+			keywordArgs = append(keywordArgs, newArg(p))
+		}
+	}
+
+	request := generators.PickRequest(res.Requests)
+	bodyType, kind := "", ""
+	if nil != request {
+		kind = types.ContentKind(request.ContentType)
+		bodyType = c.bodyType(ClassName(name)+"Request", request.Schema, kind, true)
+	}
+
+	// the declared statuses, the type of the body of a 2xx response is the type of the body of the returned Response
+	statuses := make([]string, 0, len(res.Responses))
+	results := make([]string, 0)
+	for _, r := range generators.SortedResponses(res.Responses) {
+		typ, decode := "None", "none"
+		if body := generators.PickBody(r.ResponseBodies); nil != body {
+			contentKind := types.ContentKind(body.MediaType)
+			typ = c.bodyType(ClassName(name)+ClassName(r.Status)+"Response", body.Schema, contentKind, false)
+			decode = map[string]string{"JSON": "json", "BINARY": "binary"}[contentKind]
+			if len(decode) <= 0 {
+				decode = "text"
+			}
+		}
+
+		low, high := generators.StatusRange(r.Status)
+		statuses = append(statuses, fmt.Sprintf("(%d, %d, %s, %s)", low, high, typ, Quote(decode)))
+
+		if generators.Success(r.Status) && !contains(results, typ) {
+			results = append(results, typ)
+		}
+	}
+
+	result := "None"
+	switch {
+	case len(results) == 1:
+		result = results[0]
+	case len(results) > 1:
+		result = "Union[" + strings.Join(results, ", ") + "]"
+	}
+
+	// the signature
+	signature := []string{"client: Client"}
+	for _, a := range pathArgs {
+		signature = append(signature, a.name+": "+a.typ)
+	}
+	if nil != request {
+		if request.Required {
+			signature = append(signature, "body: "+bodyType)
+		} else {
+			signature = append(signature, "body: Optional["+bodyType+"] = None")
+		}
+	}
+	if len(keywordArgs) > 0 {
+		signature = append(signature, "*")
+		for _, a := range keywordArgs {
+			if a.param.Required {
+				signature = append(signature, a.name+": "+a.typ)
+			} else {
+				signature = append(signature, a.name+": Optional["+a.typ+"] = None")
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "\n\ndef %s(\n", name)
+	for _, s := range signature {
+		fmt.Fprintf(b, "    %s,\n", s)
+	}
+	fmt.Fprintf(b, ") -> Response[%s]:\n", result)
+
+	// the docstring, the Summary and Description of the resource followed by its arguments
+	doc := strings.TrimSpace(res.Summary)
+	if len(doc) <= 0 {
+		doc = "Calls " + method + " " + res.Path + "."
+	} else {
+		doc += "\n\n" + method + " " + res.Path
+	}
+	if description := strings.TrimSpace(res.Description); len(description) > 0 {
+		doc += "\n\n" + description
+	}
+	if res.Deprecated {
+		doc += "\n\nDeprecated: the resource is deprecated."
+	}
+
+	var documented strings.Builder
+	for _, a := range append(pathArgs, keywordArgs...) {
+		if description := strings.Join(strings.Fields(a.param.Description), " "); len(description) > 0 {
+			fmt.Fprintf(&documented, "    %s: %s\n", a.name, description)
+		}
+	}
+	if documented.Len() > 0 {
+		doc += "\n\nArgs:\n" + strings.TrimRight(documented.String(), "\n")
+	}
+	b.WriteString(Docstring("    ", doc))
+
+	// the body of the function
+	segments := generators.PathSegments(res.Path)
+	path := Quote(prefix + segments[0])
+	for i, a := range pathArgs {
+		path += " + _path(" + a.name + ")"
+		if len(segments[i+1]) > 0 {
+			path += " + " + Quote(segments[i+1])
+		}
+	}
+	fmt.Fprintf(b, "    path = %s\n    query: List[Tuple[str, str]] = []\n    headers: Dict[str, str] = {}\n", path)
+
+	for _, a := range keywordArgs {
+		switch a.param.In {
+		case types.QUERY:
+			fmt.Fprintf(b, "    _query(query, %s, %s)\n", Quote(a.param.Name), a.name)
+		case types.HEADER:
+			fmt.Fprintf(b, "    _header(headers, %s, %s)\n", Quote(a.param.Name), a.name)
+		case types.COOKIE:
+			fmt.Fprintf(b, "    _cookie(headers, %s, %s)\n", Quote(a.param.Name), a.name)
+		}
+	}
+
+	data := "None"
+	if nil != request {
+		encode := map[string]string{"JSON": "json", "FORM": "form", "MULTIPART": "multipart"}[kind]
+		if len(encode) <= 0 {
+			encode = "raw"
+		}
+
+		data = "data"
+		fmt.Fprintf(b, "    data = _encode(body, %s, %s, %s, headers)\n", bodyType, Quote(encode), Quote(request.ContentType))
+	}
+
+	fmt.Fprintf(b, "\n    return _send(\n        client,\n        %s,\n        path,\n        query,\n        headers,\n        %s,\n        [\n", Quote(method), data)
+	for _, s := range statuses {
+		fmt.Fprintf(b, "            %s,\n", s)
+	}
+	b.WriteString("        ],\n    )\n")
+}
+
+// paramType returns the type of a parameter, the type of its component when it has one
+func (c *client) paramType(function string, p *types.Parameter) string {
+	if len(p.Components) > 0 {
+		if comp := generators.Resolve(c.models.components, p.Components[0]); nil != comp {
+			return "models." + c.models.name(comp)
+		}
+	}
+
+	return c.models.typeOf(function+ClassName(p.Name)+"Param", p.Type, p.Format, nil, nil, nil, "models.")
+}
+
+// bodyType
+//
+// This method returns the type of a request (when request is true) or response body of the provided content kind (see
+// types.ContentKind). Text and xml are a str and binary content bytes, json is the type of its schema. Form and
+// multipart request bodies are the type of their schema as well, a Dict without one.
+func (c *client) bodyType(name string, schema *types.Component, kind string, request bool) string {
+	switch {
+	case kind == "TEXT" || kind == "XML":
+		return "str"
+	case kind == "BINARY":
+		return "bytes"
+	case kind != "JSON" && !request:
+		return "str"
+	}
+
+	if nil != schema {
+		if comp := generators.Resolve(c.models.components, schema); nil != comp {
+			return "models." + c.models.name(comp)
+		}
+	}
+
+	if kind == "JSON" {
+		return "Any"
+	}
+	return "Dict[str, Any]"
+}
+
+// contains returns true when values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,544 @@
+package python
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The kinds of declarations
+const (
+	declClass = iota // class Name(BaseModel)
+	declAlias        // Name = <typ>
+	declEnum         // class Name(<typ>, enum.Enum)
+)
+
+type decl struct {
+	kind    int
+	name    string
+	doc     string
+	typ     string   // the aliased type of an alias, the value type of an enum
+	fields  []*field // the fields of a class
+	members []*member
+}
+
+type field struct {
+	name     string
+	alias    string // the name of the property in json, when it is not the name of the field
+	typ      string
+	doc      string
+	optional bool
+	null     bool
+}
+
+type member struct {
+	name  string
+	value string
+}
+
+// models names and declares the pydantic models of the components of a model, the same way gomodels.Models does for Go.
+// Objects are classes, enums are enum.Enum classes and every other component is a type alias.
+type models struct {
+	diagnostics types.Diagnostics
+
+	components types.Components
+	names      map[*types.Component]string
+	taken      map[string]bool
+	decls      map[string]*decl
+}
+
+// newModels returns the models of the provided components, with a type declared for each of them
+func newModels(components types.Components) *models {
+	m := &models{
+		diagnostics: make(types.Diagnostics, 0),
+		components:  components,
+		names:       make(map[*types.Component]string),
+		taken:       make(map[string]bool),
+		decls:       make(map[string]*decl),
+	}
+
+	// classes are not named after what the module imports
+	for name := range imported {
+		m.taken[name] = true
+	}
+
+	selected := generators.ModelComponents(components)
+	for _, comp := range selected {
+		m.names[comp] = generators.Unique(m.taken, ClassName(comp.Name))
+	}
+
+	for _, comp := range selected {
+		m.declare(comp)
+	}
+
+	return m
+}
+
+// name returns the name of the type of a component, declaring it if the component did not have one yet
+func (m *models) name(comp *types.Component) string {
+	if name, ok := m.names[comp]; ok {
+		return name
+	}
+
+	m.names[comp] = generators.Unique(m.taken, ClassName(comp.Name))
+	m.declare(comp)
+	return m.names[comp]
+}
+
+// typeOf
+//
+// This method returns the Python type of a value with the provided type, format, enums, ref and properties, as found on a
+// Component, Property or Parameter. The name is used for the class declared for an inline object. Declared types are
+// prefixed with qualifier, e.g. "models." for the client module.
+func (m *models) typeOf(name, typ, format string, enums []string, ref any, props types.Properties, qualifier string) string {
+	switch {
+	case typ == "array":
+		return "List[" + m.itemType(name+"Item", ref, props, qualifier) + "]"
+	case typ == "object" && format == "map":
+		return "Dict[str, " + m.itemType(name+"Value", ref, props, qualifier) + "]"
+	}
+
+	if nil != ref {
+		if comp := generators.Resolve(m.components, ref); nil != comp {
+			return generators.RefType(comp, func(named *types.Component) string {
+				return qualifier + m.name(named)
+			}, func(item *types.Component) string {
+				return m.typeOf(name, item.Type, item.Format, item.Enums, item.Ref, item.Properties, qualifier)
+			})
+		}
+
+		if s, ok := ref.(string); !ok || !generators.Primitive(s) {
+			generators.Unresolved(&m.diagnostics, "python", name, ref, "Any")
+		}
+	}
+
+	switch typ {
+	case "object":
+		if len(props) <= 0 {
+			return "Dict[str, Any]"
+		}
+
+		name = generators.Unique(m.taken, name)
+		m.decls[name] = &decl{kind: declClass, name: name}
+		m.decls[name].fields = m.fields(name, props)
+		return qualifier + name
+	case "string", "number", "integer":
+		if len(enums) <= 0 {
+			return primitiveType(typ, format)
+		}
+		return literal(typ, format, enums)
+	case "boolean":
+		return "bool"
+	default:
+		return "Any"
+	}
+}
+
+// fields returns the fields of the class with the provided name for a list of properties
+func (m *models) fields(name string, props types.Properties) []*field {
+	fields := make([]*field, 0, len(props))
+	taken := make(map[string]bool, len(props))
+
+	for _, p := range props {
+		if nil == p {
+			continue
+		}
+
+		jsonName := p.RawName
+		if len(jsonName) <= 0 {
+			jsonName = p.Name
+		}
+
+		fieldName := FieldName(jsonName)
+		for i := 2; taken[fieldName]; i++ {
+			fieldName = FieldName(jsonName) + "_" + strconv.Itoa(i)
+		}
+		taken[fieldName] = true
+
+		alias := ""
+		if fieldName != jsonName {
+			alias = jsonName
+		}
+
+		fields = append(fields, &field{
+			name:     fieldName,
+			alias:    alias,
+			typ:      m.typeOf(name+generators.Pascal(p.Name), p.Type, p.Format, p.Enums, p.Ref, p.Properties, ""),
+			doc:      p.Description,
+			optional: nil == p.Required || !*p.Required,
+			null:     nil != p.Null && *p.Null,
+		})
+	}
+
+	return fields
+}
+
+// itemType returns the type of the items of an array or the values of a map, ref being the component or primitive type
+// of the items and props the properties of inline object items
+func (m *models) itemType(name string, ref any, props types.Properties, qualifier string) string {
+	if s, ok := ref.(string); ok && generators.Primitive(s) {
+		switch s {
+		case "object":
+			return m.typeOf(name, "object", "", nil, nil, props, qualifier)
+		case "array":
+			return "List[Any]"
+		default:
+			return primitiveType(s, s)
+		}
+	}
+
+	if nil == ref {
+		if len(props) > 0 {
+			return m.typeOf(name, "object", "", nil, nil, props, qualifier)
+		}
+		return "Any"
+	}
+
+	if comp := generators.Resolve(m.components, ref); nil != comp {
+		return generators.RefType(comp, func(named *types.Component) string {
+			return qualifier + m.name(named)
+		}, func(item *types.Component) string {
+			return m.typeOf(name, item.Type, item.Format, item.Enums, item.Ref, item.Properties, qualifier)
+		})
+	}
+
+	generators.Unresolved(&m.diagnostics, "python", name, ref, "Any")
+	return "Any"
+}
+
+// declare adds the declaration of the type of a component
+func (m *models) declare(comp *types.Component) {
+	name := m.names[comp]
+	doc := comp.Description
+
+	target := generators.Resolve(m.components, comp.Ref)
+	if target == comp {
+		target = nil
+	}
+
+	switch {
+	case comp.Type == "object" && comp.Format != "map" && len(comp.Properties) > 0:
+		m.decls[name] = &decl{kind: declClass, name: name, doc: doc}
+		m.decls[name].fields = m.fields(name, comp.Properties)
+	case comp.Type == "object" && comp.Format != "map" && nil != target:
+		m.decls[name] = &decl{kind: declAlias, name: name, doc: doc}
+		m.decls[name].typ = m.name(target)
+	case len(comp.Enums) > 0 && (comp.Type == "string" || comp.Type == "number" || comp.Type == "integer"):
+		m.enum(name, comp.Type, comp.Format, comp.Enums, doc)
+	default:
+		m.decls[name] = &decl{kind: declAlias, name: name, doc: doc}
+
+		// a component can not be an inline object of its own, an array of them is an array of its item type
+		ref := comp.Ref
+		if comp.Type != "array" && comp.Format != "map" {
+			if nil != ref && nil == target && comp.Type == "object" {
+				generators.Unresolved(&m.diagnostics, "python", name, ref, "Any")
+			}
+			ref = nil
+		}
+
+		typ := m.typeOf(name, comp.Type, comp.Format, nil, ref, comp.Properties, "")
+		if nil != comp.Null && *comp.Null {
+			typ = "Optional[" + typ + "]"
+		}
+		m.decls[name].typ = typ
+	}
+}
+
+// enum declares an enum.Enum class with a member per value. Values that are not valid for the type (e.g. a number enum
+// value that is not a number) are left out.
+func (m *models) enum(name, typ, format string, values []string, doc string) {
+	d := &decl{kind: declEnum, name: name, typ: primitiveType(typ, format), doc: doc}
+	seen := make(map[string]bool, len(values))
+	members := make(map[string]bool, len(values))
+
+	for i, v := range values {
+		value := Quote(v)
+		if typ != "string" {
+			n, err := strconv.ParseFloat(v, 64)
+			if nil != err {
+				continue
+			}
+			value = strconv.FormatFloat(n, 'f', -1, 64)
+			if d.typ == "int" && n != float64(int64(n)) {
+				continue
+			}
+		}
+
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+
+		memberName := strings.ToUpper(generators.Snake(v))
+		switch {
+		case len(memberName) <= 0:
+			memberName = "VALUE_" + strconv.Itoa(i+1)
+		case memberName[0] >= '0' && memberName[0] <= '9':
+			memberName = "VALUE_" + memberName
+		}
+		unique := memberName
+		for n := 2; members[unique]; n++ {
+			unique = memberName + "_" + strconv.Itoa(n)
+		}
+		members[unique] = true
+
+		d.members = append(d.members, &member{name: unique, value: value})
+	}
+
+	m.decls[name] = d
+}
+
+// source returns the models module. Enums come first, then the classes in order of name and the aliases, each after the
+// aliases it refers to, and finally every class is rebuilt so the annotations that refer to types declared after it
+// are resolved.
+func (m *models) source() []byte {
+	var b strings.Builder
+
+	b.WriteString(Header + "\n")
+	b.WriteString("\nfrom __future__ import annotations\n")
+
+	names := make([]string, 0, len(m.decls))
+	for name := range m.decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m.imports(&b)
+
+	classes := make([]string, 0, len(names))
+	for _, kind := range []int{declEnum, declClass} {
+		for _, name := range names {
+			if d := m.decls[name]; d.kind == kind {
+				m.write(&b, d)
+				if kind == declClass {
+					classes = append(classes, name)
+				}
+			}
+		}
+	}
+
+	written := make(map[string]bool, len(names))
+	for _, name := range names {
+		m.alias(&b, name, written, make(map[string]bool))
+	}
+
+	if len(classes) > 0 {
+		b.WriteString("\n")
+		for _, name := range classes {
+			b.WriteString("\n" + name + ".model_rebuild()")
+		}
+		b.WriteString("\n")
+	}
+
+	return []byte(b.String())
+}
+
+// imports writes the imports of the names the declarations use
+func (m *models) imports(b *strings.Builder) {
+	used := make(map[string]bool)
+	for _, d := range m.decls {
+		exprs := []string{d.typ}
+		switch d.kind {
+		case declClass:
+			used["BaseModel"] = true
+		case declEnum:
+			used["enum"] = true
+		}
+
+		for _, f := range d.fields {
+			exprs = append(exprs, f.typ)
+			used["Optional"] = used["Optional"] || f.optional || f.null
+			used["ConfigDict"] = used["ConfigDict"] || len(f.alias) > 0
+			used["Field"] = used["Field"] || len(f.alias) > 0
+		}
+
+		for _, typ := range exprs {
+			for _, name := range pythonName.FindAllString(typ, -1) {
+				used[name] = true
+			}
+		}
+	}
+
+	selected := func(names ...string) []string {
+		selected := make([]string, 0, len(names))
+		for _, name := range names {
+			if used[name] {
+				selected = append(selected, name)
+			}
+		}
+		return selected
+	}
+
+	modules := selected("datetime", "decimal", "enum", "uuid")
+	typing := selected("Any", "Dict", "List", "Literal", "Optional")
+	pydantic := selected("AnyUrl", "Base64Bytes", "BaseModel", "ConfigDict", "Field")
+
+	if len(modules) > 0 || len(typing) > 0 {
+		b.WriteString("\n")
+	}
+	for _, module := range modules {
+		b.WriteString("import " + module + "\n")
+	}
+	if len(typing) > 0 {
+		b.WriteString("from typing import " + strings.Join(typing, ", ") + "\n")
+	}
+
+	if len(pydantic) > 0 {
+		b.WriteString("\nfrom pydantic import " + strings.Join(pydantic, ", ") + "\n")
+	}
+}
+
+// alias writes the declaration of an alias after the aliases it refers to
+func (m *models) alias(b *strings.Builder, name string, written, visiting map[string]bool) {
+	d, ok := m.decls[name]
+	if !ok || d.kind != declAlias || written[name] || visiting[name] {
+		return
+	}
+	visiting[name] = true
+
+	for _, ref := range pythonName.FindAllString(d.typ, -1) {
+		m.alias(b, ref, written, visiting)
+	}
+
+	written[name] = true
+	m.write(b, d)
+}
+
+// write writes the declaration of a type
+func (m *models) write(b *strings.Builder, d *decl) {
+	switch d.kind {
+	case declClass:
+		b.WriteString("\n\nclass " + d.name + "(BaseModel):\n")
+		b.WriteString(Docstring("    ", d.doc))
+
+		aliased := false
+		for _, f := range d.fields {
+			aliased = aliased || len(f.alias) > 0
+		}
+		if len(strings.TrimSpace(d.doc)) > 0 || aliased {
+			b.WriteString("\n")
+		}
+		if aliased {
+			b.WriteString("    model_config = ConfigDict(populate_by_name=True)\n\n")
+		}
+
+		for _, f := range d.fields {
+			b.WriteString(generators.Comment("    # ", f.doc))
+
+			typ := f.typ
+			if f.optional || f.null {
+				typ = "Optional[" + typ + "]"
+			}
+
+			args := make([]string, 0, 2)
+			if f.optional {
+				args = append(args, "default=None")
+			}
+			if len(f.alias) > 0 {
+				args = append(args, "alias="+Quote(f.alias))
+			}
+
+			switch {
+			case len(f.alias) > 0:
+				fmt.Fprintf(b, "    %s: %s = Field(%s)\n", f.name, typ, strings.Join(args, ", "))
+			case f.optional:
+				fmt.Fprintf(b, "    %s: %s = None\n", f.name, typ)
+			default:
+				fmt.Fprintf(b, "    %s: %s\n", f.name, typ)
+			}
+		}
+		if len(d.fields) <= 0 && len(strings.TrimSpace(d.doc)) <= 0 {
+			b.WriteString("    pass\n")
+		}
+	case declEnum:
+		b.WriteString("\n\nclass " + d.name + "(" + d.typ + ", enum.Enum):\n")
+		b.WriteString(Docstring("    ", d.doc))
+		if len(strings.TrimSpace(d.doc)) > 0 && len(d.members) > 0 {
+			b.WriteString("\n")
+		}
+		for _, c := range d.members {
+			fmt.Fprintf(b, "    %s = %s\n", c.name, c.value)
+		}
+		if len(d.members) <= 0 && len(strings.TrimSpace(d.doc)) <= 0 {
+			b.WriteString("    pass\n")
+		}
+	default:
+		b.WriteString("\n\n")
+		b.WriteString(generators.Comment("# ", d.doc))
+		fmt.Fprintf(b, "%s = %s\n", d.name, d.typ)
+	}
+}
+
+// literal returns the Literal type of the values of an enum, values that are not valid for the type are left out
+func literal(typ, format string, enums []string) string {
+	values := make([]string, 0, len(enums))
+	seen := make(map[string]bool, len(enums))
+
+	for _, v := range enums {
+		value := Quote(v)
+		if typ != "string" {
+			n, err := strconv.ParseFloat(v, 64)
+			if nil != err {
+				continue
+			}
+			value = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	if len(values) <= 0 {
+		return primitiveType(typ, format)
+	}
+
+	return "Literal[" + strings.Join(values, ", ") + "]"
+}
+
+// primitiveType returns the Python type of a string, number or integer with the provided format
+func primitiveType(typ, format string) string {
+	switch typ {
+	case "string":
+		switch strings.ToLower(format) {
+		case "date-time":
+			return "datetime.datetime"
+		case "date":
+			return "datetime.date"
+		case "time":
+			return "datetime.time"
+		case "uuid":
+			return "uuid.UUID"
+		case "uri", "url":
+			return "AnyUrl"
+		case "byte":
+			return "Base64Bytes"
+		case "binary":
+			return "bytes"
+		case "decimal":
+			return "decimal.Decimal"
+		default:
+			return "str"
+		}
+	case "number", "integer", "int", "int32", "int64", "float32", "float64":
+		switch strings.ToLower(format) {
+		case "decimal":
+			return "decimal.Decimal"
+		case "int", "int32", "int64", "integer":
+			return "int"
+		}
+		if strings.HasPrefix(typ, "int") {
+			return "int"
+		}
+		return "float"
+	case "boolean":
+		return "bool"
+	default:
+		return "Any"
+	}
+}
@@ -0,0 +1,152 @@
+// Package python is the built in generator of the python target. It writes a Python package with two modules:
+//
+//   - models.py with a pydantic (v2) model for every defined, inlined and parameter Component. Objects are BaseModel
+//     classes whose fields are Optional when they are not Required or can be Null, Format picks the Python type of
+//     strings and numbers (datetime, date, UUID, AnyUrl, Decimal, ...) and Enums become enum.Enum classes (or a
+//     Literal for the enum of a property)
+//   - client.py with a function per HTTP Resource, named with types.MakeResourceName and documented with its Summary
+//     and Description. It only uses urllib from the standard library to send requests, the bodies are validated with
+//     the models.
+package python
+
+import (
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Target is the name of the target this generator writes
+const Target = "python"
+
+// Options of the python target
+const (
+	OptionPackage = "package" // The directory of the Python package relative to the output directory, api by default
+	OptionClient  = "client"  // Whether the client module is written next to the models, true by default
+	OptionBaseURL = "baseUrl" // The DEFAULT_BASE_URL of the client, taken from the host and basePath of the resources by default
+)
+
+// Header is the first line of every generated module
+const Header = "# Code generated by codegen. DO NOT EDIT."
+
+// Generator implements pipeline.Generator for the python target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	m := newModels(request.Model.Components)
+
+	pkg := strings.Trim(generators.Option(request, OptionPackage, "api"), "/")
+	file := func(name string) string {
+		if len(pkg) <= 0 || pkg == "." {
+			return name
+		}
+		return pkg + "/" + name
+	}
+
+	files := []types.GeneratedFile{{Path: file("__init__.py"), Content: []byte(Header + "\n")}}
+
+	if generators.BoolOption(request, OptionClient, true) {
+		resources, skipped := generators.HTTPResources(request.Model.Resources)
+		if skipped > 0 {
+			m.diagnostics.Info("python-skipped", "", "%d resources are not HTTP resources and have no client function", skipped)
+		}
+
+		c := &client{models: m, functions: make(map[string]bool, len(resources))}
+		files = append(files, types.GeneratedFile{Path: file("client.py"), Content: c.source(resources, generators.Option(request, OptionBaseURL, ""))})
+	}
+
+	// the client declares the types it uses while it is written, so the models come last
+	files = append(files, types.GeneratedFile{Path: file("models.py"), Content: m.source()})
+
+	return &types.GeneratorResponse{Files: files, Diagnostics: m.diagnostics}, nil
+}
+
+// pythonName matches the identifiers of a Python type expression
+var pythonName = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// keywords are the Python keywords and the names of the attributes of pydantic.BaseModel a field can not be named after
+var keywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true, "await": true,
+	"break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true, "except": true,
+	"finally": true, "for": true, "from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true, "return": true, "try": true,
+	"while": true, "with": true, "yield": true, "copy": true, "dict": true, "json": true, "schema": true,
+	"schema_json": true, "construct": true, "validate": true, "model_config": true, "model_fields": true,
+}
+
+// imported are the names the generated modules import, along with the builtin types their annotations use. A class,
+// field, function or argument named after one of them would hide it from the annotations that follow.
+var imported = map[string]bool{
+	"annotations": true, "dataclass": true, "datetime": true, "decimal": true, "enum": true, "field": true,
+	"functools": true, "json": true, "models": true, "urllib": true, "uuid": true,
+	"Any": true, "Dict": true, "Generic": true, "List": true, "Literal": true, "Optional": true, "Tuple": true,
+	"TypeVar": true, "Union": true, "AnyUrl": true, "Base64Bytes": true, "BaseModel": true, "ConfigDict": true,
+	"Field": true, "TypeAdapter": true,
+	"bool": true, "bytes": true, "float": true, "int": true, "str": true,
+}
+
+// ClassName returns the PascalCase Python name of a class, names that would start with a digit get Model in front of them
+func ClassName(name string) string {
+	n := generators.Pascal(name)
+	if len(n) > 0 && n[0] >= '0' && n[0] <= '9' {
+		n = "Model" + n
+	}
+
+	return n
+}
+
+// FieldName returns the snake_case Python name of a field, argument or function. Keywords and imported names get an
+// underscore appended and names that would start with a digit get field_ in front of them.
+func FieldName(name string) string {
+	n := generators.Snake(name)
+	switch {
+	case len(n) <= 0:
+		return "field"
+	case n[0] >= '0' && n[0] <= '9':
+		return "field_" + n
+	case keywords[n] || imported[n]:
+		return n + "_"
+	default:
+		return n
+	}
+}
+
+// Quote returns a Python string literal of s
+func Quote(s string) string {
+	return strconv.Quote(s)
+}
+
+// Docstring returns text as a docstring indented by indent, or an empty string when there is no text
+func Docstring(indent, text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if len(text) <= 0 {
+		return ""
+	}
+
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\\", "\\\\"), `"""`, `\"\"\"`)
+	if strings.HasSuffix(text, `"`) {
+		text += " "
+	}
+
+	if !strings.Contains(text, "\n") {
+		return indent + `"""` + text + `"""` + "\n"
+	}
+
+	var b strings.Builder
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		switch {
+		case i == 0:
+			b.WriteString(indent + `"""` + line + "\n")
+		case len(line) <= 0:
+			b.WriteString("\n")
+		default:
+			b.WriteString(indent + line + "\n")
+		}
+	}
+	b.WriteString(indent + `"""` + "\n")
+
+	return b.String()
+}
@@ -0,0 +1,239 @@
+package python
+
+import (
+	"github.com/spirefy/go-codegen/generators/generatortest"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// generate returns the files the python target writes for the provided components, by path
+func generate(t *testing.T, components types.Components, resources types.Resources) map[string]string {
+	t.Helper()
+
+	model := types.NewLoadedResponse()
+	model.Components = components
+	model.Resources = resources
+
+	files, _ := generatortest.Generate(t, Generator{}, model, nil)
+	return files
+}
+
+func TestModelsReserveImportedNames(t *testing.T) {
+	components := types.Components{
+		{Id: 1, Name: "Field", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+			{Name: "datetime", Type: "string", Format: "date-time"},
+			{Name: "uuid", Type: "string", Format: "uuid"},
+			{Name: "str", Type: "string"},
+		}},
+		{Id: 2, Name: "Optional", Type: "string", Source: types.SourceComponent},
+	}
+
+	models := generate(t, components, nil)["api/models.py"]
+
+	for _, want := range []string{
+		"class Field2(BaseModel):",
+		"datetime_: Optional[datetime.datetime] = Field(default=None, alias=\"datetime\")",
+		"uuid_: Optional[uuid.UUID] = Field(default=None, alias=\"uuid\")",
+		"str_: Optional[str] = Field(default=None, alias=\"str\")",
+		"Optional2 = str",
+	} {
+		if !strings.Contains(models, want) {
+			t.Errorf("models.py does not have %q:\n%s", want, models)
+		}
+	}
+}
+
+func TestModelsImportWhatTheyUse(t *testing.T) {
+	tests := []struct {
+		name       string
+		components types.Components
+		imports    []string
+	}{
+		{
+			name:       "no models",
+			components: types.Components{},
+			imports:    []string{"from __future__ import annotations"},
+		},
+		{
+			name: "a class of required strings",
+			components: types.Components{{Id: 1, Name: "Pet", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+				{Name: "name", Type: "string", Required: boolPtr(true)},
+			}}},
+			imports: []string{"from __future__ import annotations", "from pydantic import BaseModel"},
+		},
+		{
+			name: "an enum and a list of strings",
+			components: types.Components{
+				{Id: 1, Name: "Kind", Type: "string", Enums: []string{"a", "b"}, Source: types.SourceComponent},
+				{Id: 2, Name: "Dates", Type: "array", Ref: "string", Source: types.SourceComponent},
+			},
+			imports: []string{"from __future__ import annotations", "import enum", "from typing import List"},
+		},
+		{
+			name: "a class with an optional aliased field",
+			components: types.Components{{Id: 1, Name: "Pet", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+				{Name: "bornAt", Type: "string", Format: "date-time"},
+			}}},
+			imports: []string{"from __future__ import annotations", "import datetime", "from typing import Optional", "from pydantic import BaseModel, ConfigDict, Field"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models := generate(t, tt.components, nil)["api/models.py"]
+
+			imports := make([]string, 0)
+			for _, line := range strings.Split(models, "\n") {
+				if strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "from ") {
+					imports = append(imports, line)
+				}
+			}
+
+			if !reflect.DeepEqual(imports, tt.imports) {
+				t.Errorf("imports = %q, want %q", imports, tt.imports)
+			}
+		})
+	}
+}
+
+func TestModelsOptional(t *testing.T) {
+	components := types.Components{{Id: 1, Name: "pet", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+		{Name: "name", Type: "string", Required: boolPtr(true)},
+		{Name: "tag", Type: "string"},
+		{Name: "nickname", Type: "string", Required: boolPtr(true), Null: boolPtr(true)},
+		{Name: "bornAt", RawName: "born_at", Type: "string", Format: "date-time", Null: boolPtr(true)},
+		{Name: "status", Type: "string", Enums: []string{"available", "sold"}, Required: boolPtr(true)},
+		{Name: "tags", Type: "array", Ref: "string"},
+	}}}
+
+	models := generate(t, components, nil)["api/models.py"]
+
+	for _, want := range []string{
+		"class Pet(BaseModel):",
+		"    name: str\n",
+		"    tag: Optional[str] = None\n",
+		"    nickname: Optional[str]\n",
+		"    born_at: Optional[datetime.datetime] = None\n",
+		"    status: Literal[\"available\", \"sold\"]\n",
+		"    tags: Optional[List[str]] = None\n",
+	} {
+		if !strings.Contains(models, want) {
+			t.Errorf("models.py does not have %q:\n%s", want, models)
+		}
+	}
+}
+
+func TestClassName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"pet", "Pet"},
+		{"list_pets_200_response", "ListPets200Response"},
+		{"api-key", "ApiKey"},
+		{"2fa", "Model2fa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassName(tt.name); got != tt.want {
+				t.Errorf("ClassName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"petId", "pet_id"},
+		{"X-Trace", "x_trace"},
+		{"2fa", "field_2fa"},
+		{"---", "field"},
+		{"class", "class_"},
+		{"schema", "schema_"},
+		{"models", "models_"},
+		{"int", "int_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FieldName(tt.name); got != tt.want {
+				t.Errorf("FieldName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	files, diagnostics := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionBaseURL: "https://pets.example.com/v1"})
+	if len(diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", diagnostics)
+	}
+
+	client := files["api/client.py"]
+	for _, want := range []string{
+		"from . import models",
+		`DEFAULT_BASE_URL = "https://pets.example.com/v1"`,
+		"def list_pets(\n    client: Client,\n    *,\n    limit: Optional[int] = None,\n    x_trace: Optional[str] = None,\n) -> Response[models.ListPets200Response]:",
+		`    _query(query, "limit", limit)`,
+		`    _header(headers, "X-Trace", x_trace)`,
+		`    data = _encode(body, models.NewPet, "json", "application/json", headers)`,
+		`    path = "/pets/" + _path(pet_id)`,
+		"            (200, 200, models.Pet, \"json\"),\n            (404, 404, models.Error, \"json\"),",
+		"            (0, 0, models.Error, \"json\"),",
+		"            (204, 204, None, \"none\"),",
+		"    Deprecated: the resource is deprecated.",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client.py does not have %q", want)
+		}
+	}
+
+	if models := files["api/models.py"]; !strings.Contains(models, "ListPets200Response = List[Pet]") {
+		t.Errorf("models.py does not declare the inline response:\n%s", models)
+	}
+}
+
+// TestCompile compiles the modules written for the pet store with python3, the modules are not run since they need
+// pydantic
+func TestCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs python3")
+	}
+
+	python, err := exec.LookPath("python3")
+	if nil != err {
+		t.Skip("python3 is not installed")
+	}
+
+	files, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), nil)
+
+	dir := t.TempDir()
+	args := []string{"-m", "py_compile"}
+	for path, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); nil != err {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); nil != err {
+			t.Fatal(err)
+		}
+		args = append(args, file)
+	}
+
+	if out, err := exec.Command(python, args...).CombinedOutput(); nil != err {
+		t.Errorf("py_compile: %v\n%s", err, out)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
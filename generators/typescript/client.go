@@ -0,0 +1,548 @@
+package typescript
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"strconv"
+	"strings"
+)
+
+// client writes the client module, a function per resource along with its Params and Responses types
+type client struct {
+	models    *models
+	functions map[string]bool
+
+	// helpers used by at least one function, the others are not written so the module passes noUnusedLocals
+	used map[string]bool
+
+	b strings.Builder
+}
+
+// keywords are the reserved words of TypeScript a function can not be named after
+var keywords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true, "import": true, "in": true, "instanceof": true, "new": true,
+	"null": true, "return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true, "try": true,
+	"typeof": true, "var": true, "void": true, "while": true, "with": true, "let": true, "static": true, "yield": true,
+	"await": true, "implements": true, "interface": true, "package": true, "private": true, "protected": true,
+	"public": true,
+}
+
+// helpers are the names of the functions of the client module a resource function can not take
+var helpers = map[string]bool{"send": true, "matches": true, "decode": true, "encodeBody": true, "paramValues": true,
+	"addQuery": true, "addHeader": true, "addCookie": true, "models": true, "fetch": true}
+
+// groups are the locations parameters are grouped by, in the order they are written
+var groups = []types.QueryIn{types.PATH, types.QUERY, types.HEADER, types.COOKIE}
+
+// source returns the client module
+func (c *client) source(resources types.Resources, models, baseURL string) []byte {
+	basePath := generators.BasePath(resources)
+	if len(baseURL) <= 0 {
+		baseURL = generators.BaseURL(resources, basePath)
+	}
+
+	var functions strings.Builder
+	for _, res := range resources {
+		prefix := ""
+		if strings.TrimSuffix(res.Variables["basePath"], "/") != basePath {
+			prefix = strings.TrimSuffix(res.Variables["basePath"], "/")
+		}
+
+		c.function(&functions, res, prefix)
+	}
+
+	c.b.WriteString(Header + "\n\n")
+	fmt.Fprintf(&c.b, "import type * as models from %s;\n\n", Quote(models))
+	c.core(baseURL)
+	c.helpers()
+	c.b.WriteString(functions.String())
+
+	return []byte(c.b.String())
+}
+
+// core writes the types shared by every function and the function sending the requests
+func (c *client) core(baseURL string) {
+	fmt.Fprintf(&c.b, `/** The base url of the requests when the options of a call have none */
+export const DEFAULT_BASE_URL = %s;
+
+/** The options of a call */
+export interface RequestOptions {
+  /** The scheme, host and base path the paths of the resources are appended to, DEFAULT_BASE_URL by default */
+  baseUrl?: string;
+  /** Headers sent with the request, e.g. an Authorization header */
+  headers?: Record<string, string>;
+  /** The fetch function sending the request, the global fetch by default */
+  fetch?: typeof fetch;
+  /** Aborts the request */
+  signal?: AbortSignal;
+}
+
+/**
+ * The response of a call, one for every status declared in R. The status is the declared status the response matched
+ * (e.g. "200", "4XX" or "default") and narrows the type of the body, statusCode is the actual status code.
+ */
+export type ApiResponse<R> = {
+  [S in keyof R]: { status: S; statusCode: number; headers: Headers; body: R[S] };
+}[keyof R];
+
+/** The error thrown for a response with a status the resource does not declare */
+export class ApiError extends Error {
+  method: string;
+  path: string;
+  statusCode: number;
+  headers: Headers;
+  body: string;
+
+  constructor(method: string, path: string, statusCode: number, headers: Headers, body: string) {
+    super(method + " " + path + " returned status " + statusCode);
+    this.name = "ApiError";
+    this.method = method;
+    this.path = path;
+    this.statusCode = statusCode;
+    this.headers = headers;
+    this.body = body;
+  }
+}
+
+/** How a body is encoded or decoded */
+type Kind = "json" | "form" | "multipart" | "text" | "binary" | "none";
+
+/** send sends a request and returns the response decoded according to the first declared status it matches */
+async function send<R>(
+  method: string,
+  path: string,
+  query: URLSearchParams,
+  headers: Headers,
+  body: BodyInit | undefined,
+  statuses: Array<[string, Kind]>,
+  options: RequestOptions = {},
+): Promise<ApiResponse<R>> {
+  const search = query.toString();
+  const url = (options.baseUrl ?? DEFAULT_BASE_URL).replace(/\/+$/, "") + path + (search ? "?" + search : "");
+
+  const all = new Headers(options.headers);
+  headers.forEach((value, key) => all.set(key, value));
+
+  const response = await (options.fetch ?? fetch)(url, { method, headers: all, body, signal: options.signal });
+  const match = statuses.find(([status]) => matches(status, response.status));
+  if (!match) {
+    throw new ApiError(method, path, response.status, response.headers, await response.text());
+  }
+
+  const result: unknown = {
+    status: match[0],
+    statusCode: response.status,
+    headers: response.headers,
+    body: await decode(response, match[1]),
+  };
+  return result as ApiResponse<R>;
+}
+
+/** matches returns true when a status code is the declared status, in its range (e.g. 4XX) or the status is default */
+function matches(status: string, code: number): boolean {
+  if (status === "default" || status === String(code)) {
+    return true;
+  }
+
+  return /^[1-5]XX$/i.test(status) && Math.floor(code / 100) === Number(status[0]);
+}
+
+/** decode returns the body of a response decoded according to kind */
+async function decode(response: Response, kind: Kind): Promise<unknown> {
+  switch (kind) {
+    case "none":
+      return undefined;
+    case "binary":
+      return response.blob();
+    case "json": {
+      const text = await response.text();
+      return text ? JSON.parse(text) : undefined;
+    }
+    default:
+      return response.text();
+  }
+}
+`, Quote(baseURL))
+}
+
+// helpers writes the functions encoding parameters and bodies that are used by at least one function
+func (c *client) helpers() {
+	if c.used["encodeBody"] {
+		c.b.WriteString(`
+/** encodeBody returns a request body encoded according to kind */
+function encodeBody(body: unknown, kind: Kind): BodyInit {
+  switch (kind) {
+    case "json":
+      return JSON.stringify(body);
+    case "form": {
+      if (body instanceof URLSearchParams) {
+        return body;
+      }
+
+      const form = new URLSearchParams();
+      for (const [key, value] of Object.entries(body as object)) {
+        paramValues(value).forEach((v) => form.append(key, v));
+      }
+      return form;
+    }
+    case "multipart": {
+      if (body instanceof FormData) {
+        return body;
+      }
+
+      const data = new FormData();
+      for (const [key, value] of Object.entries(body as object)) {
+        if (value instanceof Blob) {
+          data.append(key, value);
+        } else {
+          paramValues(value).forEach((v) => data.append(key, v));
+        }
+      }
+      return data;
+    }
+    default:
+      return body as BodyInit;
+  }
+}
+`)
+	}
+	if c.used["paramValues"] {
+		c.b.WriteString(`
+/** paramValues returns the string values of a parameter, one for every item of an array */
+function paramValues(value: unknown): string[] {
+  if (value === undefined || value === null) {
+    return [];
+  }
+
+  const items: unknown[] = Array.isArray(value) ? value : [value];
+  return items.map((item) => {
+    if (item instanceof Date) {
+      return item.toISOString();
+    }
+    return typeof item === "object" ? JSON.stringify(item) : String(item);
+  });
+}
+`)
+	}
+	if c.used["addQuery"] {
+		c.b.WriteString(`
+/** addQuery adds the values of a parameter to the query string */
+function addQuery(query: URLSearchParams, name: string, value: unknown): void {
+  paramValues(value).forEach((v) => query.append(name, v));
+}
+`)
+	}
+	if c.used["addHeader"] {
+		c.b.WriteString(`
+/** addHeader sets a header to the comma separated values of a parameter */
+function addHeader(headers: Headers, name: string, value: unknown): void {
+  const values = paramValues(value);
+  if (values.length > 0) {
+    headers.set(name, values.join(","));
+  }
+}
+`)
+	}
+	if c.used["addCookie"] {
+		c.b.WriteString(`
+/** addCookie adds a parameter to the Cookie header, browsers do not send a Cookie header set by a script */
+function addCookie(headers: Headers, name: string, value: unknown): void {
+  const values = paramValues(value);
+  if (values.length > 0) {
+    const cookie = name + "=" + encodeURIComponent(values.join(","));
+    const existing = headers.get("Cookie");
+    headers.set("Cookie", existing ? existing + "; " + cookie : cookie);
+  }
+}
+`)
+	}
+}
+
+// function writes the function of a resource along with its Params and Responses types
+func (c *client) function(b *strings.Builder, res *types.Resource, prefix string) {
+	name := generators.Camel(types.MakeResourceName(res.Name, res.Method, res.Path, ""))
+	if len(name) <= 0 {
+		name = "call"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	if keywords[name] || helpers[name] {
+		name += "_"
+	}
+	unique := name
+	for i := 2; c.functions[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	name = unique
+	c.functions[name] = true
+
+	typeName := TypeName(name)
+	method := strings.ToUpper(res.Method)
+
+	// the parameters grouped by where they are sent, placeholders without a parameter are strings
+	params := make(map[types.QueryIn]types.Parameters, len(groups))
+	seen := make(map[string]bool)
+	for _, placeholder := range generators.PathParams(res.Path) {
+		p := generators.FindParam(res.Parameters, types.PATH, placeholder)
+		if nil == p {
+			p = &types.Parameter{Name: placeholder, In: types.PATH, Required: true, Type: "string"}
+		}
+
+		if !seen[string(types.PATH)+p.Name] {
+			seen[string(types.PATH)+p.Name] = true
+			params[types.PATH] = append(params[types.PATH], p)
+		}
+	}
+	for _, p := range res.Parameters {
+		if nil != p && p.In != types.PATH && !seen[string(p.In)+p.Name] {
+			seen[string(p.In)+p.Name] = true
+			params[p.In] = append(params[p.In], p)
+		}
+	}
+
+	paramsType, paramsRequired := "", false
+	var decl strings.Builder
+	for _, in := range groups {
+		if len(params[in]) <= 0 {
+			continue
+		}
+
+		required := paramGroupRequired(params[in])
+		var fields strings.Builder
+		for _, p := range params[in] {
+			optional := "?"
+			if p.Required || in == types.PATH {
+				optional = ""
+			}
+
+			fields.WriteString(JSDoc("    ", p.Description))
+			fmt.Fprintf(&fields, "    %s%s: %s;\n", PropertyName(p.Name), optional, c.paramType(name, p))
+		}
+		paramsRequired = paramsRequired || required
+
+		optional := "?"
+		if required {
+			optional = ""
+		}
+		fmt.Fprintf(&decl, "  %s%s: {\n%s  };\n", in, optional, fields.String())
+	}
+	if decl.Len() > 0 {
+		paramsType = typeName + "Params"
+		fmt.Fprintf(b, "/** The parameters of %s, by where they are sent */\nexport interface %s {\n%s}\n\n", name, paramsType, decl.String())
+	}
+
+	request := generators.PickRequest(res.Requests)
+	bodyType, kind := "", ""
+	if nil != request {
+		kind = types.ContentKind(request.ContentType)
+		bodyType = c.bodyType(request.Schema, kind, true)
+	}
+
+	// the responses keyed by their status, a resource that declares none returns the body of any response as text
+	statuses := make([][2]string, 0, len(res.Responses))
+	declared := make(map[string]bool, len(res.Responses))
+	responsesType := typeName + "Responses"
+	fmt.Fprintf(b, "/** The responses of %s, by status */\nexport interface %s {\n", name, responsesType)
+	for _, r := range generators.SortedResponses(res.Responses) {
+		typ, decode := "undefined", "none"
+		if body := generators.PickBody(r.ResponseBodies); nil != body {
+			typ = c.bodyType(body.Schema, types.ContentKind(body.MediaType), false)
+			decode = decodeKind(types.ContentKind(body.MediaType))
+		}
+
+		status := r.Status
+		if _, high := generators.StatusRange(status); high <= 0 {
+			status = "default"
+		}
+		if declared[status] {
+			continue
+		}
+		declared[status] = true
+
+		b.WriteString(JSDoc("  ", r.Description))
+		fmt.Fprintf(b, "  %s: %s;\n", Quote(status), typ)
+		statuses = append(statuses, [2]string{status, decode})
+	}
+	if len(statuses) <= 0 {
+		b.WriteString("  \"default\": string;\n")
+		statuses = append(statuses, [2]string{"default", "text"})
+	}
+	b.WriteString("}\n\n")
+
+	// the function itself
+	doc := strings.TrimSpace(strings.Join([]string{res.Summary, res.Description}, "\n\n"))
+	doc = strings.TrimSpace(method + " " + res.Path + "\n\n" + doc)
+	if res.Deprecated {
+		doc += "\n\n@deprecated the resource is deprecated"
+	}
+	b.WriteString("/**\n" + generators.Comment(" * ", doc) + " */\n")
+
+	args := make([]string, 0, 3)
+	if len(paramsType) > 0 {
+		if paramsRequired {
+			args = append(args, "params: "+paramsType)
+		} else {
+			args = append(args, "params: "+paramsType+" = {}")
+		}
+	}
+	if len(bodyType) > 0 {
+		if request.Required {
+			args = append(args, "body: "+bodyType)
+		} else {
+			args = append(args, "body?: "+bodyType)
+		}
+	}
+	args = append(args, "options?: RequestOptions")
+	fmt.Fprintf(b, "export async function %s(%s): Promise<ApiResponse<%s>> {\n", name, strings.Join(args, ", "), responsesType)
+
+	// the path, with every placeholder replaced by its escaped value
+	segments := generators.PathSegments(res.Path)
+	path := Quote(prefix + segments[0])
+	for i, placeholder := range generators.PathParams(res.Path) {
+		c.used["paramValues"] = true
+		path += " + encodeURIComponent(paramValues(params.path" + access(placeholder) + ").join(\",\"))"
+		if len(segments[i+1]) > 0 {
+			path += " + " + Quote(segments[i+1])
+		}
+	}
+	fmt.Fprintf(b, "  const path = %s;\n  const query = new URLSearchParams();\n  const headers = new Headers();\n", path)
+
+	for _, in := range groups[1:] {
+		add := map[types.QueryIn]string{types.QUERY: "addQuery", types.HEADER: "addHeader", types.COOKIE: "addCookie"}[in]
+		for _, p := range params[in] {
+			c.used[add], c.used["paramValues"] = true, true
+			target := "headers"
+			if in == types.QUERY {
+				target = "query"
+			}
+			fmt.Fprintf(b, "  %s(%s, %s, params.%s%s);\n", add, target, Quote(p.Name), in, optionalAccess(p.Name, !paramGroupRequired(params[in])))
+		}
+	}
+
+	body := "undefined"
+	if nil != request {
+		c.used["encodeBody"], c.used["paramValues"] = true, true
+		encode := map[string]string{"JSON": "json", "FORM": "form", "MULTIPART": "multipart"}[kind]
+		if len(encode) <= 0 {
+			encode = "text"
+		}
+
+		// fetch sets the content type of multipart bodies itself, along with the boundary
+		if kind != "MULTIPART" {
+			fmt.Fprintf(b, "  headers.set(\"Content-Type\", %s);\n", Quote(request.ContentType))
+		}
+
+		if request.Required {
+			body = fmt.Sprintf("encodeBody(body, %s)", Quote(encode))
+		} else {
+			body = fmt.Sprintf("body === undefined ? undefined : encodeBody(body, %s)", Quote(encode))
+		}
+	}
+
+	list := make([]string, 0, len(statuses))
+	for _, s := range statuses {
+		list = append(list, "["+Quote(s[0])+", "+Quote(s[1])+"]")
+	}
+	fmt.Fprintf(b, "\n  return send<%s>(%s, path, query, headers, %s, [%s], options);\n}\n\n", responsesType, Quote(method), body, strings.Join(list, ", "))
+}
+
+// paramType returns the type of a parameter, the type of its component when it has one
+func (c *client) paramType(function string, p *types.Parameter) string {
+	if len(p.Components) > 0 {
+		if comp := generators.Resolve(c.models.components, p.Components[0]); nil != comp {
+			return "models." + c.models.name(comp)
+		}
+	}
+
+	return c.models.typeOf(function+"."+p.Name, p.Type, p.Format, nil, nil, nil, "    ")
+}
+
+// bodyType
+//
+// This method returns the type of a request (when request is true) or response body of the provided content kind (see
+// types.ContentKind). Text and xml are strings, binary content is any BodyInit for requests and a Blob for responses,
+// other content is the type of its schema. Form and multipart request bodies can also be a URLSearchParams or FormData.
+func (c *client) bodyType(schema *types.Component, kind string, request bool) string {
+	switch kind {
+	case "TEXT", "XML":
+		return "string"
+	case "BINARY":
+		if request {
+			return "BodyInit"
+		}
+		return "Blob"
+	}
+
+	typ := "unknown"
+	if nil != schema {
+		if comp := generators.Resolve(c.models.components, schema); nil != comp {
+			typ = "models." + c.models.name(comp)
+		}
+	}
+
+	switch {
+	case !request && kind != "JSON":
+		return "string"
+	case request && kind == "FORM":
+		if typ == "unknown" {
+			typ = "{ [key: string]: unknown }"
+		}
+		return "URLSearchParams | " + typ
+	case request && kind == "MULTIPART":
+		if typ == "unknown" {
+			typ = "{ [key: string]: unknown }"
+		}
+		return "FormData | " + typ
+	default:
+		return typ
+	}
+}
+
+// decodeKind returns the Kind a response body of the provided content kind is decoded as
+func decodeKind(kind string) string {
+	switch kind {
+	case "JSON":
+		return "json"
+	case "BINARY":
+		return "binary"
+	default:
+		return "text"
+	}
+}
+
+// paramGroupRequired returns true when the group of parameters is required, i.e. one of them is
+func paramGroupRequired(params types.Parameters) bool {
+	for _, p := range params {
+		if p.Required || p.In == types.PATH {
+			return true
+		}
+	}
+
+	return false
+}
+
+// access returns the property access of a name, .name or ["name"] when it is not an identifier
+func access(name string) string {
+	if identifier.MatchString(name) {
+		return "." + name
+	}
+
+	return "[" + Quote(name) + "]"
+}
+
+// optionalAccess returns the access of a property of a group of parameters that may be undefined
+func optionalAccess(name string, optional bool) string {
+	if !optional {
+		return access(name)
+	}
+
+	if identifier.MatchString(name) {
+		return "?." + name
+	}
+
+	return "?.[" + Quote(name) + "]"
+}
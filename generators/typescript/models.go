@@ -0,0 +1,365 @@
+package typescript
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// models names and declares the TypeScript types of the components of a model, the same way gomodels.Models does for Go.
+// Inline objects do not need a name in TypeScript, they are written as object literal types where they are used.
+type models struct {
+	diagnostics types.Diagnostics
+
+	components types.Components
+	names      map[*types.Component]string
+	taken      map[string]bool
+	decls      map[string]string
+}
+
+// identifier matches the property names that do not need to be quoted
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// newModels returns the models of the provided components, with a type declared for each of them
+func newModels(components types.Components) *models {
+	m := &models{
+		diagnostics: make(types.Diagnostics, 0),
+		components:  components,
+		names:       make(map[*types.Component]string),
+		taken:       make(map[string]bool),
+		decls:       make(map[string]string),
+	}
+
+	selected := generators.ModelComponents(components)
+	for _, comp := range selected {
+		m.names[comp] = generators.Unique(m.taken, TypeName(comp.Name))
+	}
+
+	for _, comp := range selected {
+		m.declare(comp)
+	}
+
+	return m
+}
+
+// name returns the name of the type of a component, declaring it if the component did not have one yet
+func (m *models) name(comp *types.Component) string {
+	if name, ok := m.names[comp]; ok {
+		return name
+	}
+
+	m.names[comp] = generators.Unique(m.taken, TypeName(comp.Name))
+	m.declare(comp)
+	return m.names[comp]
+}
+
+// typeOf
+//
+// This method returns the TypeScript type of a value with the provided type, format, enums, ref and properties, as found
+// on a Component, Property or Parameter. Objects with properties are object literal types, indented by indent. The name
+// is only used to report a Ref that can not be resolved.
+func (m *models) typeOf(name, typ, format string, enums []string, ref any, props types.Properties, indent string) string {
+	switch {
+	case typ == "array":
+		return arrayOf(m.itemType(name, ref, props, indent))
+	case typ == "object" && format == "map":
+		return "{ [key: string]: " + m.itemType(name, ref, props, indent) + " }"
+	}
+
+	if nil != ref {
+		if comp := generators.Resolve(m.components, ref); nil != comp {
+			return generators.RefType(comp, m.name, func(item *types.Component) string {
+				return m.typeOf(name, item.Type, item.Format, item.Enums, item.Ref, item.Properties, indent)
+			})
+		}
+
+		if s, ok := ref.(string); !ok || !generators.Primitive(s) {
+			generators.Unresolved(&m.diagnostics, "typescript", name, ref, "unknown")
+		}
+	}
+
+	switch typ {
+	case "object":
+		if len(props) <= 0 {
+			return "{ [key: string]: unknown }"
+		}
+		return m.object(name, props, indent)
+	case "string", "number", "integer":
+		if len(enums) <= 0 {
+			return primitiveType(typ)
+		}
+		return union(typ, enums)
+	case "boolean":
+		return "boolean"
+	default:
+		return "unknown"
+	}
+}
+
+// object returns the object literal type of a list of properties
+func (m *models) object(name string, props types.Properties, indent string) string {
+	var b strings.Builder
+
+	b.WriteString("{\n")
+	b.WriteString(m.fields(name, props, indent+"  "))
+	b.WriteString(indent + "}")
+
+	return b.String()
+}
+
+// fields returns a line per property, with a ? when it is not Required and | null when it can be Null
+func (m *models) fields(name string, props types.Properties, indent string) string {
+	var b strings.Builder
+
+	for _, p := range props {
+		if nil == p {
+			continue
+		}
+
+		propName := p.RawName
+		if len(propName) <= 0 {
+			propName = p.Name
+		}
+
+		typ := m.typeOf(name+"."+propName, p.Type, p.Format, p.Enums, p.Ref, p.Properties, indent)
+		if nil != p.Null && *p.Null {
+			typ = nullable(typ)
+		}
+
+		optional := ""
+		if nil == p.Required || !*p.Required {
+			optional = "?"
+		}
+
+		b.WriteString(JSDoc(indent, p.Description))
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", indent, PropertyName(propName), optional, typ)
+	}
+
+	return b.String()
+}
+
+// itemType returns the type of the items of an array or the values of a map, ref being the component or primitive type
+// of the items and props the properties of inline object items
+func (m *models) itemType(name string, ref any, props types.Properties, indent string) string {
+	if s, ok := ref.(string); ok && generators.Primitive(s) {
+		switch s {
+		case "object":
+			return m.typeOf(name, "object", "", nil, nil, props, indent)
+		case "array":
+			return "unknown[]"
+		default:
+			return primitiveType(s)
+		}
+	}
+
+	if nil == ref {
+		if len(props) > 0 {
+			return m.object(name, props, indent)
+		}
+		return "unknown"
+	}
+
+	if comp := generators.Resolve(m.components, ref); nil != comp {
+		return generators.RefType(comp, m.name, func(item *types.Component) string {
+			return m.typeOf(name, item.Type, item.Format, item.Enums, item.Ref, item.Properties, indent)
+		})
+	}
+
+	generators.Unresolved(&m.diagnostics, "typescript", name, ref, "unknown")
+	return "unknown"
+}
+
+// declare adds the declaration of the type of a component, an interface for objects and a type alias otherwise
+func (m *models) declare(comp *types.Component) {
+	name := m.names[comp]
+	doc := JSDoc("", comp.Description)
+
+	target := generators.Resolve(m.components, comp.Ref)
+	if target == comp {
+		target = nil
+	}
+
+	switch {
+	case comp.Type == "object" && comp.Format != "map" && len(comp.Properties) > 0:
+		m.decls[name] = doc + "export interface " + name + " {\n" + m.fields(name, comp.Properties, "  ") + "}\n"
+	case comp.Type == "object" && comp.Format != "map" && nil != target:
+		m.decls[name] = doc + "export type " + name + " = " + m.name(target) + ";\n"
+	default:
+		// a component can not be an object literal of its own, an array of them is an array of its item type
+		ref := comp.Ref
+		if comp.Type != "array" && comp.Format != "map" {
+			if nil != ref && nil == target && comp.Type == "object" {
+				generators.Unresolved(&m.diagnostics, "typescript", name, ref, "unknown")
+			}
+			ref = nil
+		}
+
+		typ := m.typeOf(name, comp.Type, comp.Format, comp.Enums, ref, comp.Properties, "")
+		if nil != comp.Null && *comp.Null {
+			typ = nullable(typ)
+		}
+		m.decls[name] = doc + "export type " + name + " = " + typ + ";\n"
+	}
+}
+
+// source returns the module declaring every type in order of name
+func (m *models) source() []byte {
+	var b strings.Builder
+
+	b.WriteString(Header + "\n")
+
+	names := make([]string, 0, len(m.decls))
+	for name := range m.decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString("\n" + m.decls[name])
+	}
+
+	if len(names) <= 0 {
+		b.WriteString("\nexport {};\n")
+	}
+
+	return []byte(b.String())
+}
+
+// union returns the union of the literal types of the values of an enum, values that are not valid for the type (e.g. a
+// number enum value that is not a number) are left out
+func union(typ string, enums []string) string {
+	values := make([]string, 0, len(enums))
+	seen := make(map[string]bool, len(enums))
+
+	for _, v := range enums {
+		value := Quote(v)
+		if typ != "string" {
+			n, err := strconv.ParseFloat(v, 64)
+			if nil != err {
+				continue
+			}
+			value = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	if len(values) <= 0 {
+		return primitiveType(typ)
+	}
+
+	return strings.Join(values, " | ")
+}
+
+// arrayOf returns the array type of an item type, parenthesized when it is a union
+func arrayOf(item string) string {
+	depth, quoted := 0, false
+
+	for i := 0; i < len(item); i++ {
+		switch c := item[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == '}' || c == ')' || c == ']':
+			depth--
+		case c == '|' && depth == 0:
+			return "(" + item + ")[]"
+		}
+	}
+
+	return item + "[]"
+}
+
+// nullable returns typ | null, unless typ already is nullable
+func nullable(typ string) string {
+	if strings.HasSuffix(typ, " | null") {
+		return typ
+	}
+
+	return typ + " | null"
+}
+
+// primitiveType returns the TypeScript type of a string, number (in any format) or boolean
+func primitiveType(typ string) string {
+	switch typ {
+	case "string":
+		return "string"
+	case "number", "integer", "int", "int32", "int64", "float32", "float64":
+		return "number"
+	case "boolean":
+		return "boolean"
+	default:
+		return "unknown"
+	}
+}
+
+// TypeName returns the PascalCase TypeScript name of a type, names that would start with a digit get an underscore in
+// front of them
+func TypeName(name string) string {
+	n := generators.Pascal(name)
+	if len(n) > 0 && n[0] >= '0' && n[0] <= '9' {
+		n = "_" + n
+	}
+
+	return n
+}
+
+// PropertyName returns the name of a property as written in a type, quoted when it is not an identifier
+func PropertyName(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+
+	return Quote(name)
+}
+
+// Quote returns a TypeScript string literal of s
+func Quote(s string) string {
+	var b strings.Builder
+
+	b.WriteString("\"")
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteString("\\" + string(r))
+		case r == '\n':
+			b.WriteString("\\n")
+		case r == '\r':
+			b.WriteString("\\r")
+		case r == '\t':
+			b.WriteString("\\t")
+		case r < 0x20 || r == 0x2028 || r == 0x2029:
+			fmt.Fprintf(&b, "\\u%04x", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString("\"")
+
+	return b.String()
+}
+
+// JSDoc returns text as a JSDoc comment indented by indent, or an empty string when there is no text
+func JSDoc(indent, text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "*/", "*\\/")
+	if len(text) <= 0 {
+		return ""
+	}
+
+	if !strings.Contains(text, "\n") {
+		return indent + "/** " + text + " */\n"
+	}
+
+	return indent + "/**\n" + generators.Comment(indent+" * ", text) + indent + " */\n"
+}
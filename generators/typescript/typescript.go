@@ -0,0 +1,77 @@
+// Package typescript is the built in generator of the typescript target. It writes two ES modules:
+//
+//   - models.ts with a type for every defined, inlined and parameter Component. Objects are interfaces whose properties
+//     are optional (?) when they are not Required and nullable (| null) when they can be Null, Enums are unions of
+//     their values and inline objects are object literal types
+//   - client.ts with an async function per HTTP Resource, named with types.MakeResourceName. The parameters of a
+//     function are grouped by Parameter.In (path, query, header and cookie) and sent where they belong, the request body
+//     is encoded according to Request.ContentType and the response is a union keyed by the declared Response.Status
+//
+// The client only depends on the fetch api, so it runs in browsers, Node.js 18 and later, Deno and Bun.
+package typescript
+
+import (
+	"github.com/spirefy/go-codegen/generators"
+	"github.com/spirefy/go-codegen/types"
+	"path/filepath"
+	"strings"
+)
+
+// Target is the name of the target this generator writes
+const Target = "typescript"
+
+// Options of the typescript target
+const (
+	OptionModelsFile = "modelsFile" // The path of the models module relative to the output directory, models.ts by default
+	OptionClientFile = "clientFile" // The path of the client module relative to the output directory, client.ts by default
+	OptionClient     = "client"     // Whether the client is written next to the models, true by default
+	OptionBaseURL    = "baseUrl"    // The DEFAULT_BASE_URL of the client, taken from the host and basePath of the resources by default
+)
+
+// Header is the first line of every generated module
+const Header = "// Code generated by codegen. DO NOT EDIT."
+
+// Generator implements pipeline.Generator for the typescript target
+type Generator struct{}
+
+func (Generator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	m := newModels(request.Model.Components)
+	modelsFile := generators.Option(request, OptionModelsFile, "models.ts")
+
+	files := make([]types.GeneratedFile, 0, 2)
+
+	if generators.BoolOption(request, OptionClient, true) {
+		resources, skipped := generators.HTTPResources(request.Model.Resources)
+		if skipped > 0 {
+			m.diagnostics.Info("typescript-skipped", "", "%d resources are not HTTP resources and have no client function", skipped)
+		}
+
+		clientFile := generators.Option(request, OptionClientFile, "client.ts")
+		c := &client{models: m, functions: make(map[string]bool, len(resources)), used: make(map[string]bool)}
+		files = append(files, types.GeneratedFile{
+			Path:    clientFile,
+			Content: c.source(resources, importPath(clientFile, modelsFile), generators.Option(request, OptionBaseURL, "")),
+		})
+	}
+
+	// the client declares the types it uses while it is written, so the models come last
+	files = append([]types.GeneratedFile{{Path: modelsFile, Content: m.source()}}, files...)
+
+	return &types.GeneratorResponse{Files: files, Diagnostics: m.diagnostics}, nil
+}
+
+// importPath returns the path the client module imports the models module with, relative to the client and without the
+// .ts extension
+func importPath(clientFile, modelsFile string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(clientFile)), filepath.FromSlash(modelsFile))
+	if nil != err {
+		rel = modelsFile
+	}
+
+	rel = strings.TrimSuffix(filepath.ToSlash(rel), ".ts")
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+
+	return rel
+}
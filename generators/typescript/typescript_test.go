@@ -0,0 +1,174 @@
+package typescript
+
+import (
+	"github.com/spirefy/go-codegen/generators/generatortest"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// pets is a model with the naming, optional, nullable, enum and inline object cases of the models
+func pets() types.Components {
+	owner := &types.Component{Id: 1, Name: "owner", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+		{Name: "name", Type: "string", Required: boolPtr(true)},
+	}}
+
+	return types.Components{
+		owner,
+		{Id: 2, Name: "Pet", Type: "object", Description: "A pet of the store", Source: types.SourceComponent, Properties: types.Properties{
+			{Name: "id", Type: "integer", Format: "int64", Required: boolPtr(true)},
+			{Name: "name", Type: "string", Required: boolPtr(true), Description: "The name of the pet"},
+			{Name: "tag", Type: "string"},
+			{Name: "nickname", Type: "string", Required: boolPtr(true), Null: boolPtr(true)},
+			{Name: "bornAt", RawName: "born_at", Type: "string", Format: "date-time", Null: boolPtr(true)},
+			{Name: "X-Id", Type: "string"},
+			{Name: "status", Type: "string", Enums: []string{"available", "sold", "available"}, Required: boolPtr(true)},
+			{Name: "sizes", Type: "array", Ref: &types.Component{Name: "size", Type: "integer", Enums: []string{"1", "2"}, Source: types.SourceProperty}},
+			{Name: "labels", Type: "object", Format: "map", Ref: "string"},
+			{Name: "owner", Type: "object", Ref: owner},
+			{Name: "vet", Type: "object", Properties: types.Properties{
+				{Name: "name", Type: "string", Required: boolPtr(true)},
+			}},
+		}},
+		{Id: 3, Name: "2fa", Type: "integer", Enums: []string{"6", "x", "8"}, Source: types.SourceComponent},
+		{Id: 4, Name: "pet", Type: "array", Ref: "string", Null: boolPtr(true), Source: types.SourceComponent},
+	}
+}
+
+func TestModels(t *testing.T) {
+	m := newModels(pets())
+	got := m.source()
+
+	if len(m.diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", m.diagnostics)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "pets.golden"))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("the models are not testdata/pets.golden:\n%s", got)
+	}
+}
+
+func TestModelsUnresolved(t *testing.T) {
+	m := newModels(types.Components{{Id: 1, Name: "Pet", Type: "object", Source: types.SourceComponent, Properties: types.Properties{
+		{Name: "owner", Type: "object", Ref: "Missing"},
+	}}})
+
+	if got := string(m.source()); !strings.Contains(got, "owner?: { [key: string]: unknown };") {
+		t.Errorf("the owner is not an object of unknown values:\n%s", got)
+	}
+
+	if len(m.diagnostics) != 1 || m.diagnostics[0].Code != "typescript-unresolved" {
+		t.Errorf("diagnostics = %v, want a typescript-unresolved warning", m.diagnostics)
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"pet", "Pet"},
+		{"list_pets_200_response", "ListPets200Response"},
+		{"api-key", "ApiKey"},
+		{"2fa", "_2fa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TypeName(tt.name); got != tt.want {
+				t.Errorf("TypeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropertyName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"petId", "petId"},
+		{"$ref", "$ref"},
+		{"born_at", "born_at"},
+		{"X-Trace", `"X-Trace"`},
+		{"2fa", `"2fa"`},
+		{`a"b`, `"a\"b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PropertyName(tt.name); got != tt.want {
+				t.Errorf("PropertyName(%q) = %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	files, diagnostics := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionBaseURL: "https://pets.example.com/v1"})
+	if len(diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", diagnostics)
+	}
+
+	client := files["client.ts"]
+	for _, want := range []string{
+		`import type * as models from "./models";`,
+		`export const DEFAULT_BASE_URL = "https://pets.example.com/v1";`,
+		"export async function listPets(params: ListPetsParams = {}, options?: RequestOptions): Promise<ApiResponse<ListPetsResponses>> {",
+		`  addQuery(query, "limit", params.query?.limit);`,
+		`  addHeader(headers, "X-Trace", params.header?.["X-Trace"]);`,
+		"export async function createPet(body: models.NewPet, options?: RequestOptions): Promise<ApiResponse<CreatePetResponses>> {",
+		`  return send<CreatePetResponses>("POST", path, query, headers, encodeBody(body, "json"), [["201", "json"], ["default", "json"]], options);`,
+		`  const path = "/pets/" + encodeURIComponent(paramValues(params.path.petId).join(","));`,
+		"export interface ShowPetByIdResponses {\n  \"200\": models.Pet;\n  \"404\": models.Error;\n}",
+		"export interface DeletePetResponses {\n  \"204\": undefined;\n}",
+		" * @deprecated the resource is deprecated",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client.ts does not have %q", want)
+		}
+	}
+
+	if models := files["models.ts"]; !strings.Contains(models, "export type ListPets200Response = Pet[];") {
+		t.Errorf("models.ts does not declare the inline response:\n%s", models)
+	}
+}
+
+func TestWithoutClient(t *testing.T) {
+	files, _ := generatortest.Generate(t, Generator{}, generatortest.Petstore(), map[string]string{OptionClient: "false", OptionModelsFile: "src/api.ts"})
+
+	if _, ok := files["src/api.ts"]; !ok || len(files) != 1 {
+		t.Errorf("%d files, want only src/api.ts", len(files))
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	tests := []struct {
+		client string
+		models string
+		want   string
+	}{
+		{"client.ts", "models.ts", "./models"},
+		{"api/client.ts", "models.ts", "../models"},
+		{"client.ts", "types/models.ts", "./types/models"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.client+" "+tt.models, func(t *testing.T) {
+			if got := importPath(tt.client, tt.models); got != tt.want {
+				t.Errorf("importPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,4 +4,5 @@ go 1.21.0
 
 require (
 	github.com/extism/go-pdk v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,433 @@
+// Package arazzo is the built in loader for Arazzo 1.0 workflow documents (json or yaml). Every workflow becomes a
+// Workflow whose steps reference the Resources of the operations they call. Those resources come from the source
+// descriptions of the document, which must be loaded as sources of the same run, see types.LoadedResponse.ResolveWorkflows.
+package arazzo
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"strings"
+)
+
+type document struct {
+	Arazzo string `json:"arazzo"`
+	Info   struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	SourceDescriptions []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+		Type string `json:"type"`
+	} `json:"sourceDescriptions"`
+	Workflows  []*workflow `json:"workflows"`
+	Components struct {
+		Inputs         map[string]*loaders.Schema `json:"inputs"`
+		Parameters     map[string]*parameter      `json:"parameters"`
+		SuccessActions map[string]*action         `json:"successActions"`
+		FailureActions map[string]*action         `json:"failureActions"`
+	} `json:"components"`
+}
+
+type workflow struct {
+	WorkflowId  string            `json:"workflowId"`
+	Summary     string            `json:"summary"`
+	Description string            `json:"description"`
+	Inputs      *loaders.Schema   `json:"inputs"`
+	DependsOn   []string          `json:"dependsOn"`
+	Steps       []*step           `json:"steps"`
+	Outputs     map[string]string `json:"outputs"`
+	Parameters  []*parameter      `json:"parameters"`
+}
+
+type step struct {
+	StepId          string            `json:"stepId"`
+	Description     string            `json:"description"`
+	OperationId     string            `json:"operationId"`
+	OperationPath   string            `json:"operationPath"`
+	WorkflowId      string            `json:"workflowId"`
+	Parameters      []*parameter      `json:"parameters"`
+	RequestBody     *requestBody      `json:"requestBody"`
+	SuccessCriteria []*criterion      `json:"successCriteria"`
+	OnSuccess       []*action         `json:"onSuccess"`
+	OnFailure       []*action         `json:"onFailure"`
+	Outputs         map[string]string `json:"outputs"`
+
+	// Not part of Arazzo 1.0, but accepted so a document can order steps that do not otherwise reference each other
+	DependsOn []string `json:"dependsOn"`
+}
+
+// parameter is a parameter, or a reusable parameter reference ($components.parameters.name) with an optional value
+type parameter struct {
+	Reference string `json:"reference"`
+	Name      string `json:"name"`
+	In        string `json:"in"`
+	Value     any    `json:"value"`
+}
+
+type requestBody struct {
+	ContentType  string `json:"contentType"`
+	Payload      any    `json:"payload"`
+	Replacements []struct {
+		Target string `json:"target"`
+		Value  any    `json:"value"`
+	} `json:"replacements"`
+}
+
+type criterion struct {
+	Context   string `json:"context"`
+	Condition string `json:"condition"`
+	Type      any    `json:"type"` // simple, regex, jsonpath, xpath or an expression type object
+}
+
+// action is a success or failure action, or a reusable action reference ($components.successActions.name)
+type action struct {
+	Reference  string       `json:"reference"`
+	Name       string       `json:"name"`
+	Type       string       `json:"type"`
+	StepId     string       `json:"stepId"`
+	WorkflowId string       `json:"workflowId"`
+	RetryAfter float64      `json:"retryAfter"`
+	RetryLimit int          `json:"retryLimit"`
+	Criteria   []*criterion `json:"criteria"`
+}
+
+// stepsExpression finds the steps a runtime expression reads from, e.g. $steps.getPet.outputs.id
+var stepsExpression = regexp.MustCompile(`\$steps\.([A-Za-z0-9_\-]+)`)
+
+// Loader implements pipeline.Loader for Arazzo 1.0
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	version, ok := loaders.HeaderValue(header, "arazzo")
+	switch {
+	case ok && strings.HasPrefix(version, "1."):
+		return types.ConfidenceCertain
+	case ok:
+		return types.ConfidenceMedium
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	doc := &document{}
+	if err := loaders.Decode(data, doc); nil != err {
+		return nil, fmt.Errorf("%s is not a valid Arazzo document: %w", source, err)
+	}
+
+	l := &loader{
+		doc:      doc,
+		source:   source,
+		response: types.NewLoadedResponse(),
+	}
+
+	if !strings.HasPrefix(doc.Arazzo, "1.") {
+		l.response.Diagnostics.Warn("arazzo-version", source, "Arazzo version %q is not supported, loading it as 1.0", doc.Arazzo)
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Version:     doc.Info.Version,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *document
+	source    string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+}
+
+func (l *loader) load() {
+	for _, name := range loaders.SortedKeys(l.doc.Components.Inputs) {
+		if _, err := l.converter.Define(name, l.doc.Components.Inputs[name]); nil != err {
+			l.response.Diagnostics.Warn("arazzo-inputs", l.source, "problem loading inputs %s: %s", name, err.Error()).Location = &types.Location{Path: loaders.Pointer("components", "inputs", name)}
+		}
+	}
+
+	for i, wf := range l.doc.Workflows {
+		if nil == wf {
+			continue
+		}
+
+		if len(wf.WorkflowId) <= 0 {
+			l.response.Diagnostics.Warn("arazzo-workflow", l.source, "workflow has no workflowId and has been ignored").Location = &types.Location{Path: loaders.Pointer("workflows", fmt.Sprint(i))}
+			continue
+		}
+
+		l.response.Workflows.AddWorkflow(l.workflow(i, wf))
+	}
+}
+
+func (l *loader) workflow(index int, wf *workflow) *types.Workflow {
+	description := wf.Description
+	if len(description) <= 0 {
+		description = wf.Summary
+	}
+
+	w := &types.Workflow{
+		Id:          wf.WorkflowId,
+		Description: description,
+		Inputs:      make(types.Components, 0),
+		Steps:       make(types.Steps, 0, len(wf.Steps)),
+		Outputs:     make(map[string]*types.Output, len(wf.Outputs)),
+		DependsOn:   wf.DependsOn,
+	}
+
+	if nil != wf.Inputs {
+		inputs, _, err := l.converter.Inline(wf.WorkflowId+"Inputs", wf.Inputs, types.SourceInline)
+		if nil != err {
+			l.response.Diagnostics.Warn("arazzo-inputs", l.source, "problem loading inputs of workflow %s: %s", wf.WorkflowId, err.Error()).Location = &types.Location{Path: loaders.Pointer("workflows", fmt.Sprint(index), "inputs")}
+		} else if nil != inputs {
+			w.Inputs = append(w.Inputs, inputs)
+		}
+	}
+
+	for _, name := range loaders.SortedKeys(wf.Outputs) {
+		w.Outputs[name] = &types.Output{Id: name, Expression: types.Expression{Id: name, Text: wf.Outputs[name]}}
+	}
+
+	steps := make(map[string]*types.Step, len(wf.Steps))
+	references := make(map[string][]string, len(wf.Steps))
+
+	for i, s := range wf.Steps {
+		if nil == s {
+			continue
+		}
+
+		location := &types.Location{Path: loaders.Pointer("workflows", fmt.Sprint(index), "steps", fmt.Sprint(i))}
+		st, refs := l.step(wf, s, location)
+		w.Steps = append(w.Steps, st)
+		steps[st.Id] = st
+		references[st.Id] = refs
+	}
+
+	// a step depends on every step it reads outputs from, plus any it explicitly lists
+	for _, st := range w.Steps {
+		seen := make(map[string]bool)
+		for _, id := range references[st.Id] {
+			dep, ok := steps[id]
+			if !ok || id == st.Id || seen[id] {
+				if !ok {
+					l.response.Diagnostics.Warn("arazzo-step", l.source, "step %s of workflow %s references step %s which does not exist", st.Id, w.Id, id)
+				}
+				continue
+			}
+
+			seen[id] = true
+			// only the identity of the step is kept, the step itself is in Steps
+			st.DependsOn = append(st.DependsOn, types.Step{Id: dep.Id, Name: dep.Name})
+		}
+	}
+
+	return w
+}
+
+func (l *loader) step(wf *workflow, s *step, location *types.Location) (*types.Step, []string) {
+	st := &types.Step{
+		Id:              s.StepId,
+		Name:            s.StepId,
+		Description:     s.Description,
+		Parameters:      make(types.WorkflowParameters),
+		Outputs:         make(map[string]types.Output, len(s.Outputs)),
+		SuccessCriteria: make([]types.Expression, 0, len(s.SuccessCriteria)),
+	}
+
+	// expressions of this step that may read outputs of other steps
+	expressions := make([]string, 0)
+
+	switch {
+	case len(s.OperationId) > 0:
+		operationId := s.OperationId
+		// $sourceDescriptions.<name>.<operationId> qualifies the operation with the source description it is in
+		if strings.HasPrefix(operationId, "$sourceDescriptions.") {
+			parts := strings.SplitN(strings.TrimPrefix(operationId, "$sourceDescriptions."), ".", 2)
+			if len(parts) == 2 {
+				operationId = parts[1]
+			}
+		}
+
+		st.Resource = &types.Resource{
+			Name:      types.MakeResourceName(operationId, "", "", ""),
+			SourceDoc: l.source,
+		}
+	case len(s.OperationPath) > 0:
+		pth, method, ok := operationPath(s.OperationPath)
+		if !ok {
+			l.response.Diagnostics.Warn("arazzo-step", l.source, "operationPath %s of step %s can not be parsed", s.OperationPath, s.StepId).Location = location
+			break
+		}
+
+		st.Resource = &types.Resource{
+			Path:      pth,
+			Method:    method,
+			SourceDoc: l.source,
+		}
+	case len(s.WorkflowId) > 0:
+		l.response.Diagnostics.Warn("arazzo-unsupported", l.source, "step %s calls workflow %s, steps calling workflows are not supported", s.StepId, s.WorkflowId).Location = location
+	default:
+		l.response.Diagnostics.Warn("arazzo-step", l.source, "step %s has no operationId, operationPath or workflowId", s.StepId).Location = location
+	}
+
+	// workflow parameters apply to every step, the step may override them
+	for _, p := range append(append([]*parameter{}, wf.Parameters...), s.Parameters...) {
+		p = l.resolveParameter(p)
+		if nil == p {
+			continue
+		}
+
+		value := loaders.Example(p.Value)
+		st.Parameters[p.Name] = types.WorkflowParameter{Name: p.Name, In: p.In, Value: value}
+		expressions = append(expressions, value)
+	}
+
+	if nil != s.RequestBody {
+		payload := loaders.Example(s.RequestBody.Payload)
+		st.Parameters["body"] = types.WorkflowParameter{Name: "body", In: "body", Value: payload, Style: s.RequestBody.ContentType}
+		expressions = append(expressions, payload)
+
+		for _, r := range s.RequestBody.Replacements {
+			value := loaders.Example(r.Value)
+			st.Parameters["body"+r.Target] = types.WorkflowParameter{Name: "body", In: "body", Value: value, Target: r.Target}
+			expressions = append(expressions, value)
+		}
+	}
+
+	for i, c := range s.SuccessCriteria {
+		if nil != c {
+			st.SuccessCriteria = append(st.SuccessCriteria, c.expression(fmt.Sprintf("%s.successCriteria.%d", s.StepId, i)))
+			expressions = append(expressions, c.Context, c.Condition)
+		}
+	}
+
+	for _, name := range loaders.SortedKeys(s.Outputs) {
+		st.Outputs[name] = types.Output{Id: name, Expression: types.Expression{Id: name, Text: s.Outputs[name]}}
+	}
+
+	for _, a := range s.OnSuccess {
+		if a = l.resolveAction(a, l.doc.Components.SuccessActions); nil != a {
+			st.OnSuccess = append(st.OnSuccess, a.action())
+		}
+	}
+
+	for _, a := range s.OnFailure {
+		if a = l.resolveAction(a, l.doc.Components.FailureActions); nil != a {
+			st.OnFailure = append(st.OnFailure, a.action())
+		}
+	}
+
+	references := append([]string{}, s.DependsOn...)
+	for _, expr := range expressions {
+		for _, match := range stepsExpression.FindAllStringSubmatch(expr, -1) {
+			references = append(references, match[1])
+		}
+	}
+
+	return st, references
+}
+
+func (c *criterion) expression(id string) types.Expression {
+	typ := "simple"
+	switch t := c.Type.(type) {
+	case string:
+		typ = t
+	case map[string]any:
+		if s, ok := t["type"].(string); ok {
+			typ = s
+		}
+	}
+
+	return types.Expression{Id: id, Text: c.Condition, Type: typ, Context: c.Context}
+}
+
+func (a *action) action() types.Action {
+	act := types.Action{
+		Id:         a.Name,
+		Type:       a.Type,
+		StepId:     a.StepId,
+		WorkflowId: a.WorkflowId,
+		RetryAfter: a.RetryAfter,
+		RetryLimit: a.RetryLimit,
+	}
+
+	for i, c := range a.Criteria {
+		if nil != c {
+			act.Criteria = append(act.Criteria, c.expression(fmt.Sprintf("%s.criteria.%d", a.Name, i)))
+		}
+	}
+
+	return act
+}
+
+// operationPath
+//
+// This function returns the path and method of an operationPath, e.g. {$sourceDescriptions.petstore.url}#/paths/~1pets~1{petId}/get
+// is /pets/{petId} and get.
+func operationPath(operationPath string) (string, string, bool) {
+	indx := strings.Index(operationPath, "#/paths/")
+	if indx < 0 {
+		return "", "", false
+	}
+
+	pointer := operationPath[indx+len("#/paths/"):]
+	slash := strings.LastIndex(pointer, "/")
+	if slash <= 0 {
+		return "", "", false
+	}
+
+	pth := strings.ReplaceAll(strings.ReplaceAll(pointer[:slash], "~1", "/"), "~0", "~")
+	return pth, strings.ToLower(pointer[slash+1:]), true
+}
+
+// resolveSchema resolves a $ref to a reusable inputs schema in this document
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	name := loaders.RefName(ref)
+	if strings.HasPrefix(ref, "#/components/inputs/") {
+		return name, l.doc.Components.Inputs[name]
+	}
+
+	return name, nil
+}
+
+func (l *loader) resolveParameter(p *parameter) *parameter {
+	if nil == p || len(p.Reference) <= 0 {
+		return p
+	}
+
+	found := l.doc.Components.Parameters[strings.TrimPrefix(p.Reference, "$components.parameters.")]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "parameter %s is not defined", p.Reference)
+		return nil
+	}
+
+	// a reference may override the value of the reusable parameter
+	resolved := *found
+	if nil != p.Value {
+		resolved.Value = p.Value
+	}
+
+	return &resolved
+}
+
+func (l *loader) resolveAction(a *action, actions map[string]*action) *action {
+	if nil == a || len(a.Reference) <= 0 {
+		return a
+	}
+
+	name := a.Reference[strings.LastIndex(a.Reference, ".")+1:]
+	found := actions[name]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "action %s is not defined", a.Reference)
+	}
+
+	return found
+}
@@ -0,0 +1,126 @@
+package arazzo
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/loaders/openapi"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"1.x", "arazzo: 1.0.0\ninfo:\n  title: Pet adoption", types.ConfidenceCertain},
+		{"json", `{"arazzo": "1.0.1"}`, types.ConfidenceCertain},
+		{"another version", "arazzo: 2.0.0", types.ConfidenceMedium},
+		{"openapi", "openapi: 3.0.3", types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe("flow.yaml", []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "adopt.arazzo.yaml")
+
+	if len(resp.Resources) > 0 || len(resp.Diagnostics) > 0 {
+		t.Errorf("%d resources and diagnostics %v, want none", len(resp.Resources), resp.Diagnostics)
+	}
+
+	if len(resp.Workflows) != 1 {
+		t.Fatalf("%d workflows, want 1", len(resp.Workflows))
+	}
+
+	wf := resp.Workflows[0]
+	if wf.Id != "adoptPet" || len(wf.Inputs) != 1 || wf.Inputs[0].Name != "AdoptPetInputs" {
+		t.Errorf("the workflow is %s with inputs %v", wf.Id, wf.Inputs)
+	}
+
+	if out := wf.Outputs["pet"]; nil == out || out.Expression.Text != "$steps.getPet.outputs.pet" {
+		t.Errorf("the pet output is %+v", out)
+	}
+
+	if len(wf.Steps) != 2 {
+		t.Fatalf("%d steps, want 2", len(wf.Steps))
+	}
+
+	list, get := wf.Steps[0], wf.Steps[1]
+
+	// an operationId is matched by name, an operationPath by path and method
+	if list.Id != "listPets" || list.Resource.Name != "listPets" {
+		t.Errorf("the first step is %s for the resource %+v", list.Id, list.Resource)
+	}
+	if get.Id != "getPet" || get.Resource.Path != "/pets/{petId}" || get.Resource.Method != "get" {
+		t.Errorf("the second step is %s for the resource %+v", get.Id, get.Resource)
+	}
+
+	if want := (types.WorkflowParameters{"limit": {Name: "limit", In: "query", Value: "$inputs.limit"}}); !reflect.DeepEqual(list.Parameters, want) {
+		t.Errorf("the parameters of listPets are %v, want %v", list.Parameters, want)
+	}
+
+	// a parameter of the components
+	if p := get.Parameters["petId"]; p.In != "path" || p.Value != "$steps.listPets.outputs.petId" {
+		t.Errorf("the petId parameter of getPet is %+v", p)
+	}
+
+	if criteria := get.SuccessCriteria; len(criteria) != 1 || criteria[0].Type != "jsonpath" || criteria[0].Context != "$response.body" {
+		t.Errorf("the success criteria of getPet are %+v", criteria)
+	}
+
+	// a failure action of the components
+	if actions := get.OnFailure; len(actions) != 1 || actions[0].Type != "retry" || actions[0].RetryLimit != 3 {
+		t.Errorf("the failure actions of getPet are %+v", actions)
+	}
+}
+
+func TestResolveSteps(t *testing.T) {
+	tests := []struct {
+		file      string
+		resources []string // name of the resource of every step, after the workflows are resolved
+		codes     []string
+	}{
+		{"adopt.arazzo.yaml", []string{"listPets", "showPetById"}, []string{}},
+		{"unresolved.arazzo.yaml", []string{"listPets", ""}, []string{types.CodeUnresolvedStep}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			model := types.NewLoadedResponse()
+			model.Merge(loadertest.Load(t, openapi.Loader{}, "petstore.yaml"))
+			model.Merge(loadertest.Load(t, Loader{}, tt.file))
+
+			codes := make([]string, 0)
+			for _, d := range model.ResolveWorkflows() {
+				codes = append(codes, d.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("diagnostics = %v, want %v", codes, tt.codes)
+			}
+
+			resources := make([]string, 0)
+			for _, step := range model.Workflows[0].Steps {
+				resources = append(resources, step.Resource.Name)
+			}
+			if !reflect.DeepEqual(resources, tt.resources) {
+				t.Errorf("resources = %v, want %v", resources, tt.resources)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", "workflows: [adopt", "- a list\n- not a document"} {
+		if _, err := (Loader{}).Load("flow.yaml", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
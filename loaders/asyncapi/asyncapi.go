@@ -0,0 +1,731 @@
+// Package asyncapi is the built in loader for AsyncAPI 2.x and 3.x documents (json or yaml). Every operation becomes a
+// Resource whose Path is the channel address and whose Method is the direction of the operation, send or receive, as
+// seen from the application the document describes. Channels only served over ws or wss are WEBSOCKET resources, all
+// others are ASYNC. The messages of an operation become its Requests, with their payloads and headers as Components, and
+// the messages of an AsyncAPI 3 reply become a Response with the status "reply".
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"net/url"
+	"strings"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "asyncapi"
+
+const (
+	// Send is the Method of a Resource for an operation where the application sends messages to the channel
+	Send = "send"
+
+	// Receive is the Method of a Resource for an operation where the application receives messages from the channel
+	Receive = "receive"
+)
+
+type document struct {
+	AsyncAPI string `json:"asyncapi"`
+	Info     struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	} `json:"info"`
+	DefaultContentType string                `json:"defaultContentType"`
+	Servers            map[string]*server    `json:"servers"`
+	Channels           map[string]*channel   `json:"channels"`
+	Operations         map[string]*operation `json:"operations"` // 3.x
+	Components         struct {
+		Schemas    map[string]*loaders.Schema `json:"schemas"`
+		Messages   map[string]*message        `json:"messages"`
+		Parameters map[string]*parameter      `json:"parameters"`
+		Channels   map[string]*channel        `json:"channels"`
+		Operations map[string]*operation      `json:"operations"`
+		Servers    map[string]*server         `json:"servers"`
+	} `json:"components"`
+}
+
+type reference struct {
+	Ref string `json:"$ref"`
+}
+
+type server struct {
+	Ref         string `json:"$ref"`
+	URL         string `json:"url"`      // 2.x
+	Host        string `json:"host"`     // 3.x
+	Pathname    string `json:"pathname"` // 3.x
+	Protocol    string `json:"protocol"`
+	Description string `json:"description"`
+}
+
+type channel struct {
+	Ref         string                `json:"$ref"`
+	Address     *string               `json:"address"` // 3.x, null when the address is not known up front
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Servers     []any                 `json:"servers"` // server names in 2.x, server $refs in 3.x
+	Parameters  map[string]*parameter `json:"parameters"`
+	Messages    map[string]*message   `json:"messages"`  // 3.x
+	Publish     *operation            `json:"publish"`   // 2.x
+	Subscribe   *operation            `json:"subscribe"` // 2.x
+}
+
+type operation struct {
+	Ref         string     `json:"$ref"`
+	Action      string     `json:"action"` // 3.x
+	Channel     *reference `json:"channel"`
+	OperationId string     `json:"operationId"` // 2.x
+	Summary     string     `json:"summary"`
+	Description string     `json:"description"`
+	Message     *message   `json:"message"`  // 2.x, a message or a oneOf of messages
+	Messages    []*message `json:"messages"` // 3.x, references to channel messages
+	Reply       *struct {
+		Ref      string     `json:"$ref"`
+		Channel  *reference `json:"channel"`
+		Messages []*message `json:"messages"`
+	} `json:"reply"`
+}
+
+type message struct {
+	Ref          string          `json:"$ref"`
+	OneOf        []*message      `json:"oneOf"`
+	MessageId    string          `json:"messageId"`
+	Name         string          `json:"name"`
+	Title        string          `json:"title"`
+	Summary      string          `json:"summary"`
+	Description  string          `json:"description"`
+	ContentType  string          `json:"contentType"`
+	SchemaFormat string          `json:"schemaFormat"` // 2.x
+	Headers      *loaders.Schema `json:"headers"`
+	Payload      json.RawMessage `json:"payload"`
+	Examples     []struct {
+		Payload any `json:"payload"`
+	} `json:"examples"`
+}
+
+// messageRef is a resolved message along with the $ref it was resolved from, if any
+type messageRef struct {
+	ref string
+	msg *message
+}
+
+type parameter struct {
+	Ref         string          `json:"$ref"`
+	Description string          `json:"description"`
+	Schema      *loaders.Schema `json:"schema"` // 2.x
+	Enum        []string        `json:"enum"`   // 3.x
+	Default     string          `json:"default"`
+	Examples    []string        `json:"examples"`
+}
+
+// Loader implements pipeline.Loader for AsyncAPI 2.x and 3.x
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	version, ok := loaders.HeaderValue(header, "asyncapi")
+	switch {
+	case ok && (strings.HasPrefix(version, "2.") || strings.HasPrefix(version, "3.")):
+		return types.ConfidenceCertain
+	case ok:
+		return types.ConfidenceMedium
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	doc := &document{}
+	if err := loaders.Decode(data, doc); nil != err {
+		return nil, fmt.Errorf("%s is not a valid AsyncAPI document: %w", source, err)
+	}
+
+	l := &loader{
+		doc:      doc,
+		source:   source,
+		response: types.NewLoadedResponse(),
+		payloads: make(map[*message]*types.Component),
+		headers:  make(map[*message]*types.Component),
+	}
+
+	if !strings.HasPrefix(doc.AsyncAPI, "2.") && !strings.HasPrefix(doc.AsyncAPI, "3.") {
+		l.response.Diagnostics.Warn("asyncapi-version", source, "AsyncAPI version %q is not supported, loading it as 3.0", doc.AsyncAPI)
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Version:     doc.Info.Version,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *document
+	source    string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+
+	// the components of messages used by more than one operation are only created once
+	payloads map[*message]*types.Component
+	headers  map[*message]*types.Component
+}
+
+func (l *loader) load() {
+	for _, name := range loaders.SortedKeys(l.doc.Components.Schemas) {
+		if _, err := l.converter.Define(name, l.doc.Components.Schemas[name]); nil != err {
+			l.response.Diagnostics.Warn("asyncapi-schema", l.source, "problem loading schema %s: %s", name, err.Error()).Location = &types.Location{Path: loaders.Pointer("components", "schemas", name)}
+		}
+	}
+
+	if strings.HasPrefix(l.doc.AsyncAPI, "2.") {
+		for _, name := range loaders.SortedKeys(l.doc.Channels) {
+			ch := l.resolveChannel(l.doc.Channels[name])
+			if nil == ch {
+				continue
+			}
+
+			// publish is what other applications publish to the channel, so what the application receives
+			if op := l.resolveOperation(ch.Publish); nil != op {
+				l.resource(op.OperationId, Receive, name, ch, op, l.messages2(op.Message), loaders.Pointer("channels", name, "publish"))
+			}
+
+			if op := l.resolveOperation(ch.Subscribe); nil != op {
+				l.resource(op.OperationId, Send, name, ch, op, l.messages2(op.Message), loaders.Pointer("channels", name, "subscribe"))
+			}
+		}
+
+		return
+	}
+
+	for _, id := range loaders.SortedKeys(l.doc.Operations) {
+		op := l.resolveOperation(l.doc.Operations[id])
+		location := loaders.Pointer("operations", id)
+		if nil == op {
+			continue
+		}
+
+		if op.Action != Send && op.Action != Receive {
+			l.response.Diagnostics.Warn("asyncapi-operation", l.source, "operation %s has an unknown action %q and has been ignored", id, op.Action).Location = &types.Location{Path: location}
+			continue
+		}
+
+		if nil == op.Channel {
+			l.response.Diagnostics.Warn("asyncapi-operation", l.source, "operation %s has no channel and has been ignored", id).Location = &types.Location{Path: location}
+			continue
+		}
+
+		key, ch := l.channelOf(op.Channel.Ref)
+		if nil == ch {
+			l.response.Diagnostics.Warn("unresolved-ref", l.source, "channel %s of operation %s is not defined", op.Channel.Ref, id).Location = &types.Location{Path: location}
+			continue
+		}
+
+		l.resource(id, op.Action, key, ch, op, l.messages3(ch, op.Messages), location)
+	}
+}
+
+func (l *loader) resource(operationId, action, key string, ch *channel, op *operation, messages []messageRef, location string) {
+	address := key
+	if nil != ch.Address {
+		address = *ch.Address
+	}
+
+	description := op.Description
+	if len(description) <= 0 {
+		description = ch.Description
+	}
+
+	summary := op.Summary
+	if len(summary) <= 0 {
+		summary = ch.Summary
+	}
+
+	// channel addresses are often topic names such as user.signedup rather than paths
+	segments := strings.FieldsFunc(address, func(r rune) bool { return r == '/' || r == '.' })
+	name := types.MakeResourceName(operationId, action, "/"+strings.Join(segments, "/"), "")
+	servers := l.servers(ch)
+
+	resourceType := types.ASYNC
+	if len(servers) > 0 {
+		resourceType = types.WEBSOCKET
+		for _, s := range servers {
+			if p := strings.ToLower(s.Protocol); p != "ws" && p != "wss" {
+				resourceType = types.ASYNC
+			}
+		}
+	}
+
+	res, err := l.response.Resources.NewResource(address, action, name, description, summary, Source, l.doc.Info.Version, l.doc.Info.Title, false, true, resourceType)
+	if nil != err {
+		l.response.Diagnostics.Warn("asyncapi-operation", l.source, "problem loading operation: %s", err.Error()).Location = &types.Location{Path: location}
+		return
+	}
+
+	res.SourceDoc = l.source
+	if len(segments) > 0 {
+		res.Root = segments[0]
+	}
+
+	if len(servers) > 0 {
+		s := servers[0]
+		host, basePath := s.Host, s.Pathname
+		if len(s.URL) > 0 {
+			// a 2.x url may or may not have a scheme, e.g. broker.example.com:9092 or ws://example.com/ws
+			raw := s.URL
+			if !strings.Contains(raw, "://") {
+				raw = "//" + raw
+			}
+
+			if u, err := url.Parse(raw); nil == err {
+				host, basePath = u.Host, u.Path
+			} else {
+				host = s.URL
+			}
+		}
+
+		if len(host) > 0 {
+			res.Variables["host"] = host
+		}
+		if len(basePath) > 0 && basePath != "/" {
+			res.Variables["basePath"] = basePath
+		}
+		if len(s.Protocol) > 0 {
+			res.Variables["schemes"] = s.Protocol
+		}
+	}
+
+	components := make(types.Components, 0)
+	used := func(comp *types.Component) {
+		if nil != comp && nil == components.FindComponentById(comp.Id) {
+			components = append(components, comp)
+		}
+	}
+
+	for _, pname := range loaders.SortedKeys(ch.Parameters) {
+		p := l.resolveParameter(ch.Parameters[pname])
+		if nil == p {
+			continue
+		}
+
+		param := &types.Parameter{
+			Name:        pname,
+			In:          types.PATH,
+			Description: p.Description,
+			Required:    true,
+			Type:        "string",
+			Value:       p.Default,
+			Components:  make(types.Components, 0),
+		}
+
+		if nil != p.Schema {
+			param.Type, param.Format, _ = l.converter.TypeOf(l.converter.Flatten(p.Schema))
+			if len(param.Value) <= 0 {
+				param.Value = loaders.Example(p.Schema.Default)
+			}
+		}
+
+		if len(param.Value) <= 0 && len(p.Examples) > 0 {
+			param.Value = p.Examples[0]
+		}
+
+		res.Parameters = append(res.Parameters, param)
+	}
+
+	for i, m := range messages {
+		payload, headers := l.message(res.Name, m.msg)
+		contentType := l.contentType(m.msg)
+
+		req := &types.Request{
+			Required:    true,
+			ContentType: contentType,
+			Type:        types.ContentKind(contentType),
+			Default:     i == 0,
+			Ref:         m.ref,
+			Schema:      payload,
+		}
+
+		res.Requests = append(res.Requests, req)
+		used(payload)
+		used(headers)
+		l.headerParameters(res, headers)
+	}
+
+	// a reply without messages of its own replies with the messages of its channel
+	if nil != op.Reply {
+		resp := &types.Response{
+			Status:         "reply",
+			Description:    "Reply to " + res.Name,
+			ResponseBodies: make(types.ResponseBodies, 0),
+		}
+
+		replyChannel := ch
+		if nil != op.Reply.Channel {
+			if _, c := l.channelOf(op.Reply.Channel.Ref); nil != c {
+				replyChannel = c
+			}
+		}
+
+		for i, m := range l.messages3(replyChannel, op.Reply.Messages) {
+			payload, headers := l.message(res.Name+"Reply", m.msg)
+			body := &types.ResponseBody{
+				MediaType: l.contentType(m.msg),
+				Ref:       m.ref,
+				Default:   i == 0,
+				Schema:    payload,
+			}
+
+			if len(m.msg.Examples) > 0 {
+				body.Example = loaders.Example(m.msg.Examples[0].Payload)
+			}
+
+			resp.ResponseBodies = append(resp.ResponseBodies, body)
+			used(payload)
+			used(headers)
+		}
+
+		if len(resp.ResponseBodies) > 0 {
+			res.Responses = append(res.Responses, resp)
+		}
+	}
+
+	res.Components = &components
+}
+
+// message returns the payload and headers components of a message, creating them the first time the message is used
+func (l *loader) message(resourceName string, msg *message) (*types.Component, *types.Component) {
+	if payload, ok := l.payloads[msg]; ok {
+		return payload, l.headers[msg]
+	}
+
+	name := msg.Name
+	if len(name) <= 0 {
+		name = msg.MessageId
+	}
+	if len(name) <= 0 {
+		name = resourceName + "Message"
+	}
+
+	var payload, headers *types.Component
+
+	if schema := l.payload(name, msg); nil != schema {
+		comp, _, err := l.converter.Inline(name+"Payload", schema, types.SourceRequestBodyInline)
+		if nil != err {
+			l.response.Diagnostics.Warn("asyncapi-schema", l.source, "problem loading payload of message %s: %s", name, err.Error())
+		}
+		payload = comp
+	}
+
+	if nil != msg.Headers {
+		comp, _, err := l.converter.Inline(name+"Headers", msg.Headers, types.SourceInline)
+		if nil != err {
+			l.response.Diagnostics.Warn("asyncapi-schema", l.source, "problem loading headers of message %s: %s", name, err.Error())
+		}
+		headers = comp
+	}
+
+	l.payloads[msg] = payload
+	l.headers[msg] = headers
+	return payload, headers
+}
+
+// payload returns the schema of the payload of a message. Payloads in a schema format other than JSON Schema (e.g. Avro)
+// are reported and skipped.
+func (l *loader) payload(name string, msg *message) *loaders.Schema {
+	if len(msg.Payload) <= 0 || string(msg.Payload) == "null" {
+		return nil
+	}
+
+	format := msg.SchemaFormat
+	raw := msg.Payload
+
+	// an AsyncAPI 3 multi format schema wraps the schema along with its format
+	multi := struct {
+		SchemaFormat string          `json:"schemaFormat"`
+		Schema       json.RawMessage `json:"schema"`
+	}{}
+	if err := json.Unmarshal(raw, &multi); nil == err && len(multi.SchemaFormat) > 0 && len(multi.Schema) > 0 {
+		format, raw = multi.SchemaFormat, multi.Schema
+	}
+
+	if len(format) > 0 && !strings.Contains(format, "schema+json") && !strings.Contains(format, "schema+yaml") && !strings.Contains(format, "vnd.aai.asyncapi") {
+		l.response.Diagnostics.Warn("asyncapi-unsupported", l.source, "payload of message %s uses the schema format %s which is not supported", name, format)
+		return nil
+	}
+
+	schema := &loaders.Schema{}
+	if err := json.Unmarshal(raw, schema); nil != err {
+		l.response.Diagnostics.Warn("asyncapi-schema", l.source, "payload of message %s is not a valid schema: %s", name, err.Error())
+		return nil
+	}
+
+	return schema
+}
+
+// headerParameters adds a HEADER parameter to the resource for every property of a headers component
+func (l *loader) headerParameters(res *types.Resource, headers *types.Component) {
+	if nil == headers {
+		return
+	}
+
+	for _, prop := range headers.Properties {
+		found := false
+		for _, p := range res.Parameters {
+			if p.Name == prop.Name && p.In == types.HEADER {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		res.Parameters = append(res.Parameters, &types.Parameter{
+			Name:        prop.Name,
+			In:          types.HEADER,
+			Description: prop.Description,
+			Required:    nil != prop.Required && *prop.Required,
+			Type:        prop.Type,
+			Format:      prop.Format,
+			Components:  types.Components{headers},
+		})
+	}
+}
+
+func (l *loader) contentType(msg *message) string {
+	switch {
+	case len(msg.ContentType) > 0:
+		return msg.ContentType
+	case len(l.doc.DefaultContentType) > 0:
+		return l.doc.DefaultContentType
+	default:
+		return "application/json"
+	}
+}
+
+// servers returns the servers a channel is available on, which is every server unless the channel lists them
+func (l *loader) servers(ch *channel) []*server {
+	servers := make([]*server, 0)
+
+	if len(ch.Servers) <= 0 {
+		for _, name := range loaders.SortedKeys(l.doc.Servers) {
+			if s := l.resolveServer(l.doc.Servers[name]); nil != s {
+				servers = append(servers, s)
+			}
+		}
+
+		return servers
+	}
+
+	for _, v := range ch.Servers {
+		name := ""
+		switch t := v.(type) {
+		case string:
+			name = t
+		case map[string]any:
+			name = loaders.RefName(fmt.Sprint(t["$ref"]))
+		}
+
+		if s := l.resolveServer(l.doc.Servers[name]); nil != s {
+			servers = append(servers, s)
+		}
+	}
+
+	return servers
+}
+
+// messages2 returns the messages of an AsyncAPI 2 operation, which is a single message or a oneOf of messages
+func (l *loader) messages2(msg *message) []messageRef {
+	if nil == msg {
+		return nil
+	}
+
+	ref := msg.Ref
+	if msg = l.resolveMessage(msg); nil == msg {
+		return nil
+	}
+
+	if len(msg.OneOf) <= 0 {
+		return []messageRef{{ref: ref, msg: msg}}
+	}
+
+	messages := make([]messageRef, 0, len(msg.OneOf))
+	for _, m := range msg.OneOf {
+		if resolved := l.resolveMessage(m); nil != resolved {
+			messages = append(messages, messageRef{ref: m.Ref, msg: resolved})
+		}
+	}
+
+	return messages
+}
+
+// messages3 returns the messages of an AsyncAPI 3 operation, or all messages of its channel if the operation lists none
+func (l *loader) messages3(ch *channel, refs []*message) []messageRef {
+	messages := make([]messageRef, 0)
+
+	if len(refs) <= 0 {
+		for _, name := range loaders.SortedKeys(ch.Messages) {
+			m := ch.Messages[name]
+			if msg := l.resolveMessage(m); nil != msg {
+				if len(msg.Name) <= 0 {
+					msg.Name = name
+				}
+				messages = append(messages, messageRef{ref: m.Ref, msg: msg})
+			}
+		}
+
+		return messages
+	}
+
+	for _, m := range refs {
+		if msg := l.resolveMessage(m); nil != msg {
+			messages = append(messages, messageRef{ref: m.Ref, msg: msg})
+		}
+	}
+
+	return messages
+}
+
+// tokens returns the unescaped tokens of the json pointer of a local $ref, e.g. #/channels/user~1signup is channels and
+// user/signup
+func tokens(ref string) []string {
+	indx := strings.Index(ref, "#/")
+	if indx < 0 {
+		return nil
+	}
+
+	parts := strings.Split(ref[indx+2:], "/")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+	}
+
+	return parts
+}
+
+// channelOf returns the key and channel a channel $ref points to
+func (l *loader) channelOf(ref string) (string, *channel) {
+	t := tokens(ref)
+	switch {
+	case len(t) == 2 && t[0] == "channels":
+		return t[1], l.resolveChannel(l.doc.Channels[t[1]])
+	case len(t) == 3 && t[0] == "components" && t[1] == "channels":
+		return t[2], l.resolveChannel(l.doc.Components.Channels[t[2]])
+	default:
+		return "", nil
+	}
+}
+
+func (l *loader) resolveChannel(ch *channel) *channel {
+	if nil == ch || len(ch.Ref) <= 0 {
+		return ch
+	}
+
+	_, found := l.channelOf(ch.Ref)
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "channel %s is not defined", ch.Ref)
+	}
+
+	return found
+}
+
+func (l *loader) resolveOperation(op *operation) *operation {
+	if nil == op || len(op.Ref) <= 0 {
+		return op
+	}
+
+	t := tokens(op.Ref)
+	var found *operation
+	switch {
+	case len(t) == 2 && t[0] == "operations":
+		found = l.doc.Operations[t[1]]
+	case len(t) == 3 && t[0] == "components" && t[1] == "operations":
+		found = l.doc.Components.Operations[t[2]]
+	}
+
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "operation %s is not defined", op.Ref)
+	}
+
+	return found
+}
+
+// resolveMessage returns the message a $ref points to, either a component message or (in 3.x) a message of a channel.
+// A message without a name is named after the key it is defined with.
+func (l *loader) resolveMessage(msg *message) *message {
+	for depth := 0; nil != msg && len(msg.Ref) > 0 && depth < 8; depth++ {
+		ref := msg.Ref
+		t := tokens(ref)
+
+		var found *message
+		switch {
+		case len(t) == 3 && t[0] == "components" && t[1] == "messages":
+			found = l.doc.Components.Messages[t[2]]
+		case len(t) == 4 && t[0] == "channels" && t[2] == "messages":
+			if ch := l.resolveChannel(l.doc.Channels[t[1]]); nil != ch {
+				found = ch.Messages[t[3]]
+			}
+		}
+
+		if nil == found {
+			l.response.Diagnostics.Warn("unresolved-ref", l.source, "message %s is not defined", ref)
+			return nil
+		}
+
+		if len(found.Ref) <= 0 {
+			// the same message object for every reference, so its components are only created once
+			if len(found.Name) <= 0 {
+				found.Name = t[len(t)-1]
+			}
+			return found
+		}
+
+		msg = found
+	}
+
+	return msg
+}
+
+func (l *loader) resolveParameter(p *parameter) *parameter {
+	if nil == p || len(p.Ref) <= 0 {
+		return p
+	}
+
+	found := l.doc.Components.Parameters[loaders.RefName(p.Ref)]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "parameter %s is not defined", p.Ref)
+	}
+
+	return found
+}
+
+func (l *loader) resolveServer(s *server) *server {
+	if nil == s || len(s.Ref) <= 0 {
+		return s
+	}
+
+	t := tokens(s.Ref)
+	var found *server
+	switch {
+	case len(t) == 2 && t[0] == "servers":
+		found = l.doc.Servers[t[1]]
+	case len(t) == 3 && t[0] == "components" && t[1] == "servers":
+		found = l.doc.Components.Servers[t[2]]
+	}
+
+	return found
+}
+
+// resolveSchema resolves a $ref to a schema in components/schemas
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	name := loaders.RefName(ref)
+	if t := tokens(ref); len(t) == 3 && t[0] == "components" && t[1] == "schemas" {
+		return name, l.doc.Components.Schemas[name]
+	}
+
+	return name, nil
+}
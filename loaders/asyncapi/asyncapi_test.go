@@ -0,0 +1,123 @@
+package asyncapi
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"2.x", "asyncapi: 2.6.0\ninfo:\n  title: Account Service", types.ConfidenceCertain},
+		{"3.x", `{"asyncapi": "3.0.0"}`, types.ConfidenceCertain},
+		{"another version", "asyncapi: 1.2.0", types.ConfidenceMedium},
+		{"openapi", "openapi: 3.0.3", types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe("events.yaml", []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	type operation struct {
+		name     string
+		action   string // the method of the resource
+		address  string
+		typ      types.ResourceType
+		messages []string // schema of each request body
+		replies  []string // schema of each body of the reply
+	}
+
+	tests := []struct {
+		file       string
+		operations []operation
+		components []string
+	}{
+		{
+			file: "accounts-v2.yaml",
+			operations: []operation{
+				{"receiveUserUserIdSignedup", "receive", "user/{userId}/signedup", types.ASYNC, []string{"User", "UserDeletedPayload"}, []string{}},
+				{"sendUserSignedUp", "send", "user/{userId}/signedup", types.ASYNC, []string{"User"}, []string{}},
+			},
+			components: []string{"User", "UserDeletedPayload", "UserSignedUpHeaders"},
+		},
+		{
+			file: "chat-v3.yaml",
+			operations: []operation{
+				{"onMessage", "receive", "rooms/{roomId}", types.WEBSOCKET, []string{"AckPayload", "ChatMessagePayload"}, []string{}},
+				{"sendMessage", "send", "rooms/{roomId}", types.WEBSOCKET, []string{"ChatMessagePayload"}, []string{"AckPayload"}},
+			},
+			components: []string{"AckPayload", "ChatMessagePayload"},
+		},
+		{
+			// the reply only names a channel, so it is every message of that channel
+			file: "reply-channel.yaml",
+			operations: []operation{
+				{"sendPing", "send", "ping", types.ASYNC, []string{"PingPayload"}, []string{"PongPayload"}},
+			},
+			components: []string{"PingPayload", "PongPayload"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			resp := loadertest.Load(t, Loader{}, tt.file)
+
+			if len(resp.Diagnostics) > 0 {
+				t.Errorf("diagnostics = %v, want none", resp.Diagnostics)
+			}
+
+			components := loadertest.Names(resp.Components)
+			if !reflect.DeepEqual(components, tt.components) {
+				t.Errorf("components = %v, want %v", components, tt.components)
+			}
+
+			if len(resp.Resources) != len(tt.operations) {
+				t.Fatalf("%d resources, want %d", len(resp.Resources), len(tt.operations))
+			}
+
+			for i, op := range tt.operations {
+				res := resp.Resources[i]
+				if res.Name != op.name || res.Method != op.action || res.Path != op.address || res.ResourceType != op.typ {
+					t.Errorf("resource %d is %s %s %s of type %d, want %s %s %s of type %d", i, res.Name, res.Method, res.Path, res.ResourceType, op.name, op.action, op.address, op.typ)
+				}
+
+				messages := make([]string, 0)
+				for _, body := range res.Requests {
+					messages = append(messages, body.Schema.Name)
+				}
+				if !reflect.DeepEqual(messages, op.messages) {
+					t.Errorf("the messages of %s are %v, want %v", op.name, messages, op.messages)
+				}
+
+				replies := make([]string, 0)
+				for _, r := range res.Responses {
+					for _, body := range r.ResponseBodies {
+						replies = append(replies, body.Schema.Name)
+					}
+				}
+				if !reflect.DeepEqual(replies, op.replies) {
+					t.Errorf("the replies of %s are %v, want %v", op.name, replies, op.replies)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", "channels: [ping", "- a list\n- not a document"} {
+		if _, err := (Loader{}).Load("events.yaml", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
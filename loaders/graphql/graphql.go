@@ -0,0 +1,392 @@
+// Package graphql is the built in loader for GraphQL schemas (SDL). Every field of the query, mutation and subscription
+// types becomes a GRAPHQL Resource with the operation type as Method and /<operation>/<field> as Path, its arguments
+// become Parameters (In argument) and its type the ResponseBody. Object, input, interface, enum and union types become
+// defined Components, a non-null field is Required and any other field can be Null.
+//
+// A schema is often split over several files, types that are not defined in a file are kept as a Ref and linked when
+// the sources are merged.
+package graphql
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "graphql"
+
+// ContentType is the content type of the ResponseBodies of the resources created by this loader
+const ContentType = "application/json"
+
+const (
+	Query        = "query"
+	Mutation     = "mutation"
+	Subscription = "subscription"
+)
+
+// schemaRef is the prefix of the $refs the loader uses between the schemas it builds for types
+const schemaRef = "#/types/"
+
+var rootType = regexp.MustCompile(`(?m)^\s*(?:extend\s+)?(?:type\s+(?:Query|Mutation|Subscription)\b|schema\s*(?:@\w+\s*)*\{)`)
+var typeDefinition = regexp.MustCompile(`(?m)^\s*(?:extend\s+)?(?:type|input|interface|enum)\s+[_A-Za-z]\w*[^{\n]*\{`)
+
+// scalars maps the built in scalars, and scalars commonly defined by schemas, to schemas. Any other custom scalar is a
+// string.
+var scalars = map[string]loaders.Schema{
+	"Int":      {Type: "integer", Format: "int32"},
+	"Float":    {Type: "number", Format: "double"},
+	"String":   {Type: "string"},
+	"Boolean":  {Type: "boolean"},
+	"ID":       {Type: "string"},
+	"DateTime": {Type: "string", Format: "date-time"},
+	"Date":     {Type: "string", Format: "date"},
+	"Time":     {Type: "string", Format: "time"},
+	"UUID":     {Type: "string", Format: "uuid"},
+	"URL":      {Type: "string", Format: "uri"},
+	"URI":      {Type: "string", Format: "uri"},
+	"Long":     {Type: "integer", Format: "int64"},
+	"BigInt":   {Type: "integer", Format: "int64"},
+	"JSON":     {Type: "object"},
+	"Upload":   {Type: "string", Format: "binary"},
+}
+
+// Loader implements pipeline.Loader for GraphQL schemas
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	switch {
+	case rootType.Match(header):
+		return types.ConfidenceCertain
+	case loaders.HasExtension(source, ".graphql", ".graphqls", ".gql"):
+		return types.ConfidenceHigh
+	case typeDefinition.Match(header):
+		return types.ConfidenceLow
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	doc, err := parse(string(data))
+	if nil != err {
+		return nil, fmt.Errorf("%s is not a valid GraphQL schema: %w", source, err)
+	}
+
+	l := &loader{
+		doc:         doc,
+		source:      source,
+		response:    types.NewLoadedResponse(),
+		definitions: make(map[string]*definition),
+		schemas:     make(map[string]*loaders.Schema),
+		names:       make([]string, 0),
+		unresolved:  make(map[string]bool),
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *document
+	source    string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+
+	// the definitions of the document with their extensions merged in, keyed on name
+	definitions map[string]*definition
+	roots       map[string]string
+
+	// the schemas of every type that becomes a component, keyed on type name
+	schemas map[string]*loaders.Schema
+	names   []string
+
+	// the names of the types that are referenced but not defined, these are reported once
+	unresolved map[string]bool
+}
+
+func (l *loader) load() {
+	l.merge()
+
+	l.roots = map[string]string{Query: "Query", Mutation: "Mutation", Subscription: "Subscription"}
+	for op, name := range l.doc.operations {
+		if _, ok := l.roots[op]; !ok {
+			l.response.Diagnostics.Warn("graphql-unsupported", l.source, "%s is not an operation type", op)
+			continue
+		}
+
+		l.roots[op] = name
+		if _, ok := l.definitions[name]; !ok {
+			l.response.Diagnostics.Warn("graphql-schema", l.source, "the %s type %s is not defined in this file", op, name)
+		}
+	}
+
+	// every name has to be known before fields can be resolved against them
+	for _, def := range l.doc.definitions {
+		if l.isRoot(def.name) || def.kind == "scalar" || nil != l.schemas[def.name] || l.definitions[def.name] != def {
+			continue
+		}
+
+		l.schemas[def.name] = &loaders.Schema{}
+		l.names = append(l.names, def.name)
+	}
+
+	for _, name := range l.names {
+		l.typ(l.definitions[name])
+	}
+
+	for _, name := range l.names {
+		if _, err := l.converter.Define(name, l.schemas[name]); nil != err {
+			l.response.Diagnostics.Warn("graphql-schema", l.source, "problem loading %s: %s", name, err.Error()).Location = &types.Location{Line: l.definitions[name].line}
+		}
+	}
+
+	for _, op := range []string{Query, Mutation, Subscription} {
+		if def, ok := l.definitions[l.roots[op]]; ok {
+			for _, f := range def.fields {
+				l.resource(op, f)
+			}
+		}
+	}
+}
+
+// merge adds the fields, values and members of type extensions to the type they extend. An extension of a type that
+// is not defined in this file is used as the definition instead.
+func (l *loader) merge() {
+	for _, def := range l.doc.definitions {
+		base, ok := l.definitions[def.name]
+		if !ok {
+			l.definitions[def.name] = def
+			continue
+		}
+
+		if !def.extension {
+			if base.extension {
+				// the extension came first, the definition takes its place
+				def, base = base, def
+				l.definitions[def.name] = base
+			} else {
+				l.response.Diagnostics.Warn("graphql-schema", l.source, "%s is defined more than once", def.name).Location = &types.Location{Line: def.line}
+				continue
+			}
+		}
+
+		base.interfaces = append(base.interfaces, def.interfaces...)
+		base.fields = append(base.fields, def.fields...)
+		base.inputFields = append(base.inputFields, def.inputFields...)
+		base.values = append(base.values, def.values...)
+		base.members = append(base.members, def.members...)
+		base.directives = append(base.directives, def.directives...)
+	}
+}
+
+func (l *loader) isRoot(name string) bool {
+	for _, root := range l.roots {
+		if root == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typ fills in the schema of an object, input, interface, enum or union type
+func (l *loader) typ(def *definition) {
+	schema := l.schemas[def.name]
+	schema.Description = def.description
+
+	switch def.kind {
+	case "type", "interface":
+		schema.Type = "object"
+		if def.kind == "interface" {
+			schema.Format = "interface"
+		}
+
+		schema.Properties = make(map[string]*loaders.Schema, len(def.fields))
+		for _, f := range def.fields {
+			schema.Properties[f.name] = l.field(f.typ, f.description, f.directives, f.line)
+			if f.typ.nonNull {
+				schema.Required = append(schema.Required, f.name)
+			}
+		}
+	case "input":
+		schema.Type = "object"
+		schema.Properties = make(map[string]*loaders.Schema, len(def.inputFields))
+		for _, f := range def.inputFields {
+			schema.Properties[f.name] = l.field(f.typ, f.description, f.directives, f.line)
+			if f.typ.nonNull && !f.hasDefault {
+				schema.Required = append(schema.Required, f.name)
+			}
+		}
+	case "enum":
+		schema.Type = "string"
+		for _, v := range def.values {
+			schema.Enum = append(schema.Enum, v.name)
+		}
+	case "union":
+		// the members are alternatives, Flatten merges their fields in to a single object
+		schema.Type = "object"
+		schema.Format = "union"
+		for _, member := range def.members {
+			schema.OneOf = append(schema.OneOf, l.typeSchema(&typeRef{name: member, nonNull: true}, def.line))
+		}
+	}
+}
+
+// field returns the schema of a field or input value, including its description and whether it is deprecated
+func (l *loader) field(ref *typeRef, description string, directives []*directive, line int) *loaders.Schema {
+	schema := l.typeSchema(ref, line)
+	schema.Description = description
+	schema.Deprecated = deprecated(directives)
+	return schema
+}
+
+// typeSchema returns the schema of a type reference. Named types are $refs to their schemas, types that are not defined
+// in this file are $refs as well and are linked once every source is loaded.
+func (l *loader) typeSchema(ref *typeRef, line int) *loaders.Schema {
+	var schema *loaders.Schema
+
+	switch def, defined := l.definitions[ref.name]; {
+	case nil != ref.list:
+		schema = &loaders.Schema{Type: "array", Items: l.typeSchema(ref.list, line)}
+	case defined && def.kind == "scalar":
+		s, ok := scalars[ref.name]
+		if !ok {
+			s = loaders.Schema{Type: "string", Format: loaders.ComponentName(ref.name)}
+		}
+		schema = &s
+	case defined:
+		schema = &loaders.Schema{Ref: schemaRef + ref.name}
+	default:
+		if s, ok := scalars[ref.name]; ok {
+			schema = &s
+			break
+		}
+
+		if !l.unresolved[ref.name] {
+			l.unresolved[ref.name] = true
+			l.response.Diagnostics.Warn("unresolved-ref", l.source, "%s is not defined in this file", ref.name).Location = &types.Location{Line: line}
+		}
+		schema = &loaders.Schema{Ref: schemaRef + ref.name}
+	}
+
+	schema.Nullable = !ref.nonNull
+	return schema
+}
+
+func (l *loader) resource(op string, f *fieldDefinition) {
+	pth := "/" + op + "/" + f.name
+
+	res, err := l.response.Resources.NewResource(pth, op, types.MakeResourceName(f.name, "", "", ""), f.description, "", Source, "", l.source, deprecated(f.directives), true, types.GRAPHQL)
+	if nil != err {
+		l.response.Diagnostics.Warn("graphql-schema", l.source, "problem loading %s %s: %s", op, f.name, err.Error()).Location = &types.Location{Line: f.line}
+		return
+	}
+
+	res.SourceDoc = l.source
+	res.Variables["operation"] = op
+	res.Variables["field"] = f.name
+	res.Variables["type"] = f.typ.String()
+
+	components := make(types.Components, 0)
+	add := func(comp *types.Component) {
+		if nil != comp && nil == components.FindComponentById(comp.Id) {
+			components = append(components, comp)
+		}
+	}
+
+	for _, arg := range f.arguments {
+		param := l.parameter(res.Name, arg)
+		res.Parameters = append(res.Parameters, param)
+
+		for _, comp := range param.Components {
+			add(comp)
+		}
+	}
+
+	schema := l.typeSchema(f.typ, f.line)
+	body := &types.ResponseBody{
+		MediaType: ContentType,
+		Default:   true,
+	}
+
+	if len(schema.Ref) > 0 {
+		body.Ref = loaders.ComponentName(strings.TrimPrefix(schema.Ref, schemaRef))
+	}
+
+	comp, _, err := l.converter.Inline(res.Name+"Response", schema, types.SourceResponseBodyInline)
+	if nil != err {
+		l.response.Diagnostics.Warn("graphql-schema", l.source, "problem loading the type of %s: %s", f.name, err.Error()).Location = &types.Location{Line: f.line}
+	} else {
+		body.Schema = comp
+		add(comp)
+	}
+
+	res.Responses = append(res.Responses, &types.Response{
+		Status:         "200",
+		Description:    f.typ.String(),
+		ResponseBodies: types.ResponseBodies{body},
+	})
+
+	res.Components = &components
+}
+
+func (l *loader) parameter(resourceName string, arg *inputValue) *types.Parameter {
+	param := &types.Parameter{
+		Name:        arg.name,
+		In:          types.ARGUMENT,
+		Description: arg.description,
+		Required:    arg.typ.nonNull && !arg.hasDefault,
+		Components:  make(types.Components, 0),
+	}
+
+	if arg.hasDefault {
+		param.Value = arg.defaultVal
+		if unquoted, err := strconv.Unquote(arg.defaultVal); nil == err {
+			param.Value = unquoted
+		}
+	}
+
+	schema := l.typeSchema(arg.typ, arg.line)
+	typ, format, _ := l.converter.TypeOf(l.converter.Flatten(schema))
+	param.Type = typ
+	param.Format = format
+
+	if typ == "object" || typ == "array" || len(schema.Ref) > 0 {
+		comp, _, err := l.converter.Inline(resourceName+types.ToCamelCase(arg.name, true)+"Param", schema, types.SourceParameter)
+		if nil != err {
+			l.response.Diagnostics.Warn("graphql-schema", l.source, "problem loading argument %s: %s", arg.name, err.Error()).Location = &types.Location{Line: arg.line}
+		} else if nil != comp {
+			param.Components = append(param.Components, comp)
+		}
+	}
+
+	return param
+}
+
+// resolveSchema resolves the $refs between the schemas of types
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	name := strings.TrimPrefix(ref, schemaRef)
+	return name, l.schemas[name]
+}
+
+// deprecated returns whether the @deprecated directive is one of the directives
+func deprecated(directives []*directive) bool {
+	for _, d := range directives {
+		if d.name == "deprecated" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		header string
+		want   int
+	}{
+		{"a root type", "schema.txt", "type Query {\n  user: User\n}", types.ConfidenceCertain},
+		{"a .graphql file", "items.graphql", "type LineItem { sku: String! }", types.ConfidenceHigh},
+		{"a .gql file", "items.gql", "# empty", types.ConfidenceHigh},
+		{"a type definition", "items.txt", "type LineItem {\n  sku: String!\n}", types.ConfidenceLow},
+		{"anything else", "items.txt", `{"type": "object"}`, types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe(tt.source, []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		file       string
+		resources  []string // method and path
+		components []string
+		codes      []string
+	}{
+		{
+			file: "schema.graphql",
+			resources: []string{
+				"query /query/user", "query /query/users", "query /query/search", "query /query/ping", "query /query/order",
+				"mutation /mutation/createUser", "subscription /subscription/userCreated",
+			},
+			components: []string{"Entity", "Node", "Order", "PingResponse", "Role", "SearchResponse", "SearchResult", "User", "UserInput", "UsersResponse"},
+			codes:      []string{"unresolved-ref"}, // LineItem is in items.graphql
+		},
+		{
+			file:       "items.graphql",
+			resources:  []string{},
+			components: []string{"LineItem"},
+			codes:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			resp := loadertest.Load(t, Loader{}, tt.file)
+
+			resources := make([]string, 0, len(resp.Resources))
+			for _, res := range resp.Resources {
+				resources = append(resources, res.Method+" "+res.Path)
+				if res.ResourceType != types.GRAPHQL {
+					t.Errorf("%s is of type %d", res.Name, res.ResourceType)
+				}
+			}
+			if !reflect.DeepEqual(resources, tt.resources) {
+				t.Errorf("resources = %v, want %v", resources, tt.resources)
+			}
+
+			components := loadertest.Names(resp.Components)
+			if !reflect.DeepEqual(components, tt.components) {
+				t.Errorf("components = %v, want %v", components, tt.components)
+			}
+
+			codes := loadertest.Codes(resp.Diagnostics)
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("diagnostics = %v, want %v", codes, tt.codes)
+			}
+		})
+	}
+}
+
+func TestLoadFields(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "schema.graphql")
+
+	tests := []struct {
+		name       string
+		parameters []string // name, type and whether it is required
+		response   string
+	}{
+		{"user", []string{"id string true"}, "User"},
+		{"users", []string{"role string false", "limit number false", "filter object false"}, "UsersResponse"},
+		{"ping", []string{}, "PingResponse"},
+		{"createUser", []string{"input object true"}, "User"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resp.Resources.FindResourceByName(tt.name)
+			if nil == res {
+				t.Fatalf("%s is not loaded", tt.name)
+			}
+
+			parameters := make([]string, 0)
+			for _, p := range res.Parameters {
+				if p.In != types.ARGUMENT {
+					t.Errorf("%s is in %s", p.Name, p.In)
+				}
+
+				parameters = append(parameters, fmt.Sprintf("%s %s %t", p.Name, p.Type, p.Required))
+			}
+			if !reflect.DeepEqual(parameters, tt.parameters) {
+				t.Errorf("parameters = %v, want %v", parameters, tt.parameters)
+			}
+
+			if len(res.Responses) != 1 || res.Responses[0].ResponseBodies[0].Schema.Name != tt.response {
+				t.Errorf("the response is not a %s", tt.response)
+			}
+		})
+	}
+
+	user := resp.Components.FindComponentByName("User")
+	if want := "A user of the shop.\n  Indented line."; user.Description != want {
+		t.Errorf("the description of User is %q, want %q", user.Description, want)
+	}
+}
+
+func TestLoadAcrossFiles(t *testing.T) {
+	model := types.NewLoadedResponse()
+	model.Merge(loadertest.Load(t, Loader{}, "schema.graphql"))
+	model.Merge(loadertest.Load(t, Loader{}, "items.graphql"))
+
+	items := loadertest.Property(model.Components.FindComponentByName("Order"), "items")
+	if nil == items {
+		t.Fatal("Order has no items field")
+	}
+
+	if items.Ref != model.Components.FindComponentByName("LineItem") {
+		t.Errorf("the items of Order are not the LineItem of items.graphql")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", "type Query {", "<schema/>"} {
+		if _, err := (Loader{}).Load("schema.graphql", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
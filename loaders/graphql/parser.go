@@ -0,0 +1,655 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenNumber
+	tokenString
+	tokenPunctuator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// lex splits a GraphQL document in to tokens, commas are insignificant in GraphQL and skipped like white space
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0, len(src)/4)
+	runes := []rune(src)
+	line := 1
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r) || r == ',' || r == '\uFEFF':
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '"':
+			start := line
+			if i+2 < len(runes) && runes[i+1] == '"' && runes[i+2] == '"' {
+				i += 3
+				begin := i
+				for i+2 < len(runes) && !(runes[i] == '"' && runes[i+1] == '"' && runes[i+2] == '"' && runes[i-1] != '\\') {
+					if runes[i] == '\n' {
+						line++
+					}
+					i++
+				}
+				if i+2 >= len(runes) {
+					return nil, fmt.Errorf("line %d: block string is not closed", start)
+				}
+				tokens = append(tokens, token{kind: tokenString, text: blockString(string(runes[begin:i])), line: start})
+				i += 3
+				continue
+			}
+
+			var b strings.Builder
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\n' {
+					return nil, fmt.Errorf("line %d: string is not closed", line)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						b.WriteRune('\n')
+					case 't':
+						b.WriteRune('\t')
+					default:
+						b.WriteRune(runes[i])
+					}
+				} else {
+					b.WriteRune(runes[i])
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("line %d: string is not closed", line)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), line: start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, text: string(runes[start:i]), line: line})
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE+-", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), line: line})
+		case r == '.' && i+2 < len(runes) && runes[i+1] == '.' && runes[i+2] == '.':
+			tokens = append(tokens, token{kind: tokenPunctuator, text: "...", line: line})
+			i += 3
+		case strings.ContainsRune("!$&()=:@[]{}|", r):
+			tokens = append(tokens, token{kind: tokenPunctuator, text: string(r), line: line})
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, r)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+// blockString removes the common indentation and the leading and trailing blank lines of a block string
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+
+	indent := -1
+	for i, l := range lines {
+		if i == 0 || len(strings.TrimSpace(l)) <= 0 {
+			continue
+		}
+
+		if n := len(l) - len(strings.TrimLeft(l, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	for i, l := range lines {
+		if i > 0 && indent > 0 && len(l) >= indent {
+			lines[i] = l[indent:]
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// typeRef is a reference to a type, e.g. [String!]!
+type typeRef struct {
+	name    string
+	list    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if nil != t.list {
+		s = "[" + t.list.String() + "]"
+	}
+
+	if t.nonNull {
+		s += "!"
+	}
+
+	return s
+}
+
+type directive struct {
+	name      string
+	arguments map[string]string
+}
+
+type inputValue struct {
+	description string
+	name        string
+	typ         *typeRef
+	defaultVal  string
+	hasDefault  bool
+	directives  []*directive
+	line        int
+}
+
+type fieldDefinition struct {
+	description string
+	name        string
+	arguments   []*inputValue
+	typ         *typeRef
+	directives  []*directive
+	line        int
+}
+
+type enumValue struct {
+	description string
+	name        string
+	directives  []*directive
+}
+
+// definition is a type definition (or extension) of any kind: scalar, type, interface, union, enum or input
+type definition struct {
+	kind        string
+	description string
+	name        string
+	line        int
+	interfaces  []string
+	fields      []*fieldDefinition // type and interface
+	inputFields []*inputValue      // input
+	values      []*enumValue       // enum
+	members     []string           // union
+	directives  []*directive
+	extension   bool
+}
+
+type document struct {
+	definitions []*definition
+
+	// the operation types of the schema definition, e.g. query: Query
+	operations map[string]string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses a GraphQL schema document (SDL). Executable definitions (queries and fragments) are not expected in a
+// schema and are reported as errors.
+func parse(src string) (*document, error) {
+	tokens, err := lex(src)
+	if nil != err {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	doc := &document{operations: make(map[string]string)}
+
+	for p.peek().kind != tokenEOF {
+		description := p.description()
+		t := p.next()
+
+		extension := false
+		if t.text == "extend" {
+			extension = true
+			t = p.next()
+		}
+
+		switch t.text {
+		case "schema":
+			if _, err = p.directives(); nil != err {
+				return nil, err
+			}
+			if p.peek().text != "{" {
+				continue
+			}
+			p.next()
+			for p.peek().text != "}" {
+				op := p.next()
+				if op.kind != tokenName {
+					return nil, p.errorf(op, "an operation type")
+				}
+				if err = p.expect(":"); nil != err {
+					return nil, err
+				}
+				doc.operations[op.text] = p.next().text
+			}
+			p.next()
+		case "directive":
+			if err = p.directiveDefinition(); nil != err {
+				return nil, err
+			}
+		case "scalar", "type", "interface", "union", "enum", "input":
+			def, err := p.definition(t, description)
+			if nil != err {
+				return nil, err
+			}
+			def.extension = extension
+			doc.definitions = append(doc.definitions, def)
+		default:
+			return nil, p.errorf(t, "a type system definition")
+		}
+	}
+
+	return doc, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.text != text || t.kind == tokenString {
+		return p.errorf(t, fmt.Sprintf("%q", text))
+	}
+
+	return nil
+}
+
+func (p *parser) errorf(t token, expected string) error {
+	found := t.text
+	if t.kind == tokenEOF {
+		found = "end of file"
+	}
+
+	return fmt.Errorf("line %d: expected %s but found %q", t.line, expected, found)
+}
+
+func (p *parser) name() (token, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return t, p.errorf(t, "a name")
+	}
+
+	return t, nil
+}
+
+// description returns the description string before a definition, if there is one
+func (p *parser) description() string {
+	if p.peek().kind == tokenString {
+		return p.next().text
+	}
+
+	return ""
+}
+
+func (p *parser) definition(start token, description string) (*definition, error) {
+	name, err := p.name()
+	if nil != err {
+		return nil, err
+	}
+
+	def := &definition{kind: start.text, description: description, name: name.text, line: start.line}
+
+	if p.peek().text == "implements" {
+		p.next()
+		if p.peek().text == "&" {
+			p.next()
+		}
+
+		for {
+			iface, err := p.name()
+			if nil != err {
+				return nil, err
+			}
+			def.interfaces = append(def.interfaces, iface.text)
+
+			if p.peek().text != "&" {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if def.directives, err = p.directives(); nil != err {
+		return nil, err
+	}
+
+	switch def.kind {
+	case "type", "interface":
+		if p.peek().text == "{" {
+			def.fields, err = p.fields()
+		}
+	case "input":
+		if p.peek().text == "{" {
+			p.next()
+			for p.peek().text != "}" {
+				value, err := p.inputValue()
+				if nil != err {
+					return nil, err
+				}
+				def.inputFields = append(def.inputFields, value)
+			}
+			p.next()
+		}
+	case "enum":
+		if p.peek().text == "{" {
+			p.next()
+			for p.peek().text != "}" {
+				value := &enumValue{description: p.description()}
+				t, err := p.name()
+				if nil != err {
+					return nil, err
+				}
+				value.name = t.text
+				if value.directives, err = p.directives(); nil != err {
+					return nil, err
+				}
+				def.values = append(def.values, value)
+			}
+			p.next()
+		}
+	case "union":
+		if p.peek().text == "=" {
+			p.next()
+			if p.peek().text == "|" {
+				p.next()
+			}
+
+			for {
+				member, err := p.name()
+				if nil != err {
+					return nil, err
+				}
+				def.members = append(def.members, member.text)
+
+				if p.peek().text != "|" {
+					break
+				}
+				p.next()
+			}
+		}
+	}
+
+	return def, err
+}
+
+func (p *parser) fields() ([]*fieldDefinition, error) {
+	fields := make([]*fieldDefinition, 0)
+	if err := p.expect("{"); nil != err {
+		return nil, err
+	}
+
+	for p.peek().text != "}" {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), `"}"`)
+		}
+
+		f := &fieldDefinition{description: p.description()}
+		name, err := p.name()
+		if nil != err {
+			return nil, err
+		}
+		f.name, f.line = name.text, name.line
+
+		if f.arguments, err = p.arguments(); nil != err {
+			return nil, err
+		}
+
+		if err = p.expect(":"); nil != err {
+			return nil, err
+		}
+
+		if f.typ, err = p.typeRef(); nil != err {
+			return nil, err
+		}
+
+		if f.directives, err = p.directives(); nil != err {
+			return nil, err
+		}
+
+		fields = append(fields, f)
+	}
+
+	p.next()
+	return fields, nil
+}
+
+// arguments parses the argument definitions of a field if there are any
+func (p *parser) arguments() ([]*inputValue, error) {
+	arguments := make([]*inputValue, 0)
+	if p.peek().text != "(" {
+		return arguments, nil
+	}
+
+	p.next()
+	for p.peek().text != ")" {
+		value, err := p.inputValue()
+		if nil != err {
+			return nil, err
+		}
+		arguments = append(arguments, value)
+	}
+
+	p.next()
+	return arguments, nil
+}
+
+func (p *parser) inputValue() (*inputValue, error) {
+	value := &inputValue{description: p.description()}
+
+	name, err := p.name()
+	if nil != err {
+		return nil, err
+	}
+	value.name, value.line = name.text, name.line
+
+	if err = p.expect(":"); nil != err {
+		return nil, err
+	}
+
+	if value.typ, err = p.typeRef(); nil != err {
+		return nil, err
+	}
+
+	if p.peek().text == "=" {
+		p.next()
+		value.hasDefault = true
+		if value.defaultVal, err = p.value(); nil != err {
+			return nil, err
+		}
+	}
+
+	value.directives, err = p.directives()
+	return value, err
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	ref := &typeRef{}
+
+	if p.peek().text == "[" {
+		p.next()
+		list, err := p.typeRef()
+		if nil != err {
+			return nil, err
+		}
+		ref.list = list
+
+		if err = p.expect("]"); nil != err {
+			return nil, err
+		}
+	} else {
+		name, err := p.name()
+		if nil != err {
+			return nil, err
+		}
+		ref.name = name.text
+	}
+
+	if p.peek().text == "!" {
+		p.next()
+		ref.nonNull = true
+	}
+
+	return ref, nil
+}
+
+// value parses a constant value and returns it as GraphQL source text, e.g. 10, "text", RED or [1, 2]
+func (p *parser) value() (string, error) {
+	t := p.next()
+
+	switch {
+	case t.kind == tokenString:
+		return fmt.Sprintf("%q", t.text), nil
+	case t.kind == tokenName || t.kind == tokenNumber:
+		return t.text, nil
+	case t.text == "$":
+		name, err := p.name()
+		return "$" + name.text, err
+	case t.text == "[":
+		values := make([]string, 0)
+		for p.peek().text != "]" {
+			if p.peek().kind == tokenEOF {
+				return "", p.errorf(p.peek(), `"]"`)
+			}
+			v, err := p.value()
+			if nil != err {
+				return "", err
+			}
+			values = append(values, v)
+		}
+		p.next()
+		return "[" + strings.Join(values, ", ") + "]", nil
+	case t.text == "{":
+		fields := make([]string, 0)
+		for p.peek().text != "}" {
+			name, err := p.name()
+			if nil != err {
+				return "", err
+			}
+			if err = p.expect(":"); nil != err {
+				return "", err
+			}
+			v, err := p.value()
+			if nil != err {
+				return "", err
+			}
+			fields = append(fields, name.text+": "+v)
+		}
+		p.next()
+		return "{" + strings.Join(fields, ", ") + "}", nil
+	default:
+		return "", p.errorf(t, "a value")
+	}
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	directives := make([]*directive, 0)
+
+	for p.peek().text == "@" {
+		p.next()
+		name, err := p.name()
+		if nil != err {
+			return nil, err
+		}
+
+		d := &directive{name: name.text, arguments: make(map[string]string)}
+		if p.peek().text == "(" {
+			p.next()
+			for p.peek().text != ")" {
+				arg, err := p.name()
+				if nil != err {
+					return nil, err
+				}
+				if err = p.expect(":"); nil != err {
+					return nil, err
+				}
+				if d.arguments[arg.text], err = p.value(); nil != err {
+					return nil, err
+				}
+			}
+			p.next()
+		}
+
+		directives = append(directives, d)
+	}
+
+	return directives, nil
+}
+
+// directiveDefinition parses (and ignores) a directive definition
+func (p *parser) directiveDefinition() error {
+	if err := p.expect("@"); nil != err {
+		return err
+	}
+
+	if _, err := p.name(); nil != err {
+		return err
+	}
+
+	if _, err := p.arguments(); nil != err {
+		return err
+	}
+
+	if p.peek().text == "repeatable" {
+		p.next()
+	}
+
+	if err := p.expect("on"); nil != err {
+		return err
+	}
+
+	if p.peek().text == "|" {
+		p.next()
+	}
+
+	for {
+		if _, err := p.name(); nil != err {
+			return err
+		}
+
+		if p.peek().text != "|" {
+			return nil
+		}
+		p.next()
+	}
+}
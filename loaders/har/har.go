@@ -0,0 +1,550 @@
+// Package har is the built in loader for HAR 1.2 (HTTP Archive) files, the captures browsers and proxies export. The
+// recorded requests are grouped in to Resources by method, host and templated path: numeric, UUID and object id path
+// segments become path parameters. Query strings and request headers become parameters, and the request and response
+// Components are inferred from the recorded json bodies. The first recorded body of every response is kept as its
+// Example.
+//
+// Captures also hold the pages, scripts, styles, images and fonts a browser loaded, those entries are skipped.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "har"
+
+var (
+	numericSegment  = regexp.MustCompile(`^\d+$`)
+	uuidSegment     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	objectIdSegment = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+)
+
+// ignoredHeaders are the request headers every client sends, which are not parameters of an api
+var ignoredHeaders = map[string]bool{
+	"accept":                    true,
+	"accept-encoding":           true,
+	"accept-language":           true,
+	"cache-control":             true,
+	"connection":                true,
+	"content-length":            true,
+	"content-type":              true,
+	"cookie":                    true,
+	"dnt":                       true,
+	"host":                      true,
+	"origin":                    true,
+	"pragma":                    true,
+	"priority":                  true,
+	"referer":                   true,
+	"te":                        true,
+	"upgrade-insecure-requests": true,
+	"user-agent":                true,
+}
+
+// sensitiveHeaders are kept as parameters, but without the recorded value
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"x-api-key":           true,
+	"x-auth-token":        true,
+	"x-csrf-token":        true,
+}
+
+type document struct {
+	Log struct {
+		Version string `json:"version"`
+		Creator struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"creator"`
+		Entries []*entry `json:"entries"`
+	} `json:"log"`
+}
+
+type entry struct {
+	Request  *request  `json:"request"`
+	Response *response `json:"response"`
+}
+
+type request struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	Headers     []*nameValue `json:"headers"`
+	QueryString []*nameValue `json:"queryString"`
+	PostData    *struct {
+		MimeType string       `json:"mimeType"`
+		Text     string       `json:"text"`
+		Params   []*nameValue `json:"params"`
+	} `json:"postData"`
+}
+
+type response struct {
+	Status     int          `json:"status"`
+	StatusText string       `json:"statusText"`
+	Headers    []*nameValue `json:"headers"`
+	Content    struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+		Encoding string `json:"encoding"`
+	} `json:"content"`
+}
+
+type nameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Loader implements pipeline.Loader for HAR files
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	h := string(header)
+	_, log := loaders.HeaderValue(header, "log")
+
+	switch {
+	case log && strings.Contains(h, `"creator"`) && strings.Contains(h, `"version"`):
+		return types.ConfidenceCertain
+	case loaders.HasExtension(source, ".har"):
+		return types.ConfidenceHigh
+	case log && strings.Contains(h, `"entries"`):
+		return types.ConfidenceMedium
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	doc := &document{}
+	if err := json.Unmarshal(data, doc); nil != err {
+		return nil, fmt.Errorf("%s is not a valid HAR file: %w", source, err)
+	}
+
+	l := &loader{
+		doc:      doc,
+		source:   source,
+		response: types.NewLoadedResponse(),
+		samples:  make(map[*types.Resource]*samples),
+	}
+
+	if v := doc.Log.Version; len(v) > 0 && v != "1.2" && v != "1.1" {
+		l.response.Diagnostics.Warn("har-version", source, "HAR version %s is not supported, loading it as 1.2", v)
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *document
+	source    string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+
+	// the recorded bodies of every request and response body, in the order the resources are created
+	resources []*types.Resource
+	samples   map[*types.Resource]*samples
+}
+
+type samples struct {
+	// the number of entries recorded for the resource, and how many of them had each parameter
+	entries int
+	params  map[*types.Parameter]int
+
+	requests map[*types.Request][]any
+	bodies   map[*types.ResponseBody][]any
+}
+
+func (l *loader) load() {
+	skipped := 0
+
+	for i, e := range l.doc.Log.Entries {
+		if nil == e || nil == e.Request || nil == e.Response {
+			continue
+		}
+
+		if !l.entry(e, loaders.Pointer("log", "entries", strconv.Itoa(i))) {
+			skipped++
+		}
+	}
+
+	if skipped > 0 {
+		l.response.Diagnostics.Info("har-skipped", l.source, "%d entries are not api requests (pages, scripts, styles, images or fonts) and have been skipped", skipped)
+	}
+
+	// now that every entry has been seen, the parameters that are always sent are required and the components are
+	// inferred from all of the recorded bodies
+	for _, res := range l.resources {
+		s := l.samples[res]
+		for _, param := range res.Parameters {
+			if param.In != types.PATH {
+				param.Required = s.params[param] >= s.entries
+			}
+		}
+
+		l.components(res)
+	}
+}
+
+// entry adds a recorded request to its resource, false is returned when the entry is not an api request
+func (l *loader) entry(e *entry, location string) bool {
+	u, err := url.Parse(e.Request.URL)
+	if nil != err || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	if static(e.Response.Content.MimeType) {
+		return false
+	}
+
+	method := strings.ToLower(e.Request.Method)
+	if len(method) <= 0 {
+		method = "get"
+	}
+
+	pth, pathParams := templatePath(u.Path)
+
+	res := l.response.Resources.FindResource(pth, method, &u.Host)
+	if nil == res {
+		res, err = l.response.Resources.NewResource(pth, method, types.MakeResourceName("", method, pth, ""), "", "", Source, "", u.Host, false, true, types.HTTP)
+		if nil != err {
+			l.response.Diagnostics.Warn("har-entry", l.source, "problem loading %s %s: %s", method, e.Request.URL, err.Error()).Location = &types.Location{Path: location}
+			return true
+		}
+
+		res.SourceDoc = l.source
+		res.Variables["host"] = u.Host
+		res.Variables["schemes"] = u.Scheme
+		res.Parameters = append(res.Parameters, pathParams...)
+
+		l.resources = append(l.resources, res)
+		l.samples[res] = &samples{
+			params:   make(map[*types.Parameter]int),
+			requests: make(map[*types.Request][]any),
+			bodies:   make(map[*types.ResponseBody][]any),
+		}
+	}
+
+	s := l.samples[res]
+	s.entries++
+
+	query := e.Request.QueryString
+	if len(query) <= 0 {
+		values := u.Query()
+		for _, name := range loaders.SortedKeys(values) {
+			for _, v := range values[name] {
+				query = append(query, &nameValue{Name: name, Value: v})
+			}
+		}
+	}
+
+	seen := make(map[*types.Parameter]bool)
+	for _, q := range query {
+		if nil != q && len(q.Name) > 0 {
+			seen[l.parameter(res, q.Name, q.Value, types.QUERY)] = true
+		}
+	}
+
+	for _, h := range e.Request.Headers {
+		// http/2 pseudo headers (:method, :path) are part of the request line
+		if nil == h || len(h.Name) <= 0 || strings.HasPrefix(h.Name, ":") || ignoredHeaders[strings.ToLower(h.Name)] || strings.HasPrefix(strings.ToLower(h.Name), "sec-") {
+			continue
+		}
+
+		value := h.Value
+		if sensitiveHeaders[strings.ToLower(h.Name)] {
+			value = ""
+		}
+
+		seen[l.parameter(res, h.Name, value, types.HEADER)] = true
+	}
+
+	for param := range seen {
+		s.params[param]++
+	}
+
+	l.requestBody(res, e.Request)
+	l.responseBody(res, e.Response)
+	return true
+}
+
+// parameter returns the parameter of a resource with the provided name, adding it when it is new
+func (l *loader) parameter(res *types.Resource, name, value string, in types.QueryIn) *types.Parameter {
+	for _, p := range res.Parameters {
+		if p.In == in && strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+
+	param := &types.Parameter{
+		Name:  name,
+		In:    in,
+		Type:  "string",
+		Value: value,
+	}
+
+	res.Parameters = append(res.Parameters, param)
+	return param
+}
+
+func (l *loader) requestBody(res *types.Resource, req *request) {
+	if nil == req.PostData {
+		return
+	}
+
+	contentType := strings.TrimSpace(strings.SplitN(req.PostData.MimeType, ";", 2)[0])
+	var sample any
+
+	if v, ok := loaders.ParseExample(req.PostData.Text); ok {
+		sample = v
+		if len(contentType) <= 0 {
+			contentType = "application/json"
+		}
+	} else if len(req.PostData.Params) > 0 {
+		form := make(map[string]any, len(req.PostData.Params))
+		for _, p := range req.PostData.Params {
+			if nil != p && len(p.Name) > 0 {
+				form[p.Name] = p.Value
+			}
+		}
+		sample = form
+	}
+
+	if len(contentType) <= 0 {
+		if len(req.PostData.Text) <= 0 && nil == sample {
+			return
+		}
+		contentType = "text/plain"
+	}
+
+	var request *types.Request
+	for _, r := range res.Requests {
+		if strings.EqualFold(r.ContentType, contentType) {
+			request = r
+		}
+	}
+
+	if nil == request {
+		request = &types.Request{
+			Required:    true,
+			ContentType: contentType,
+			Type:        types.ContentKind(contentType),
+			Default:     len(res.Requests) <= 0,
+		}
+		res.Requests = append(res.Requests, request)
+	}
+
+	if nil != sample {
+		l.samples[res].requests[request] = append(l.samples[res].requests[request], sample)
+	}
+}
+
+func (l *loader) responseBody(res *types.Resource, r *response) {
+	status := "default"
+	if r.Status > 0 {
+		status = strconv.Itoa(r.Status)
+	}
+
+	var resp *types.Response
+	for _, rs := range res.Responses {
+		if rs.Status == status {
+			resp = rs
+		}
+	}
+
+	if nil == resp {
+		resp = &types.Response{
+			Status:         status,
+			Description:    r.StatusText,
+			ResponseBodies: make(types.ResponseBodies, 0),
+		}
+		res.Responses = append(res.Responses, resp)
+	}
+
+	text := r.Content.Text
+	if r.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(text)
+		if nil != err {
+			return
+		}
+		text = string(decoded)
+	}
+
+	if len(text) <= 0 {
+		return
+	}
+
+	sample, isJson := loaders.ParseExample(text)
+	mediaType := strings.TrimSpace(strings.SplitN(r.Content.MimeType, ";", 2)[0])
+	if len(mediaType) <= 0 && isJson {
+		mediaType = "application/json"
+	}
+	if len(mediaType) <= 0 {
+		mediaType = "text/plain"
+	}
+
+	var rb *types.ResponseBody
+	for _, b := range resp.ResponseBodies {
+		if strings.EqualFold(b.MediaType, mediaType) {
+			rb = b
+		}
+	}
+
+	if nil == rb {
+		rb = &types.ResponseBody{
+			MediaType: mediaType,
+			Default:   len(resp.ResponseBodies) <= 0,
+			Example:   text,
+		}
+		resp.ResponseBodies = append(resp.ResponseBodies, rb)
+	}
+
+	if isJson {
+		l.samples[res].bodies[rb] = append(l.samples[res].bodies[rb], sample)
+	}
+}
+
+// components infers the components of the request and response bodies of a resource from the bodies recorded for them
+func (l *loader) components(res *types.Resource) {
+	components := make(types.Components, 0)
+	s := l.samples[res]
+
+	for i, req := range res.Requests {
+		examples := s.requests[req]
+		if len(examples) <= 0 {
+			continue
+		}
+
+		rawName := res.Name + "Request"
+		if i > 0 {
+			rawName += types.ToCamelCase(strings.ToLower(req.Type), true)
+		}
+
+		req.Schema = l.infer(rawName, examples, types.SourceRequestBodyInline)
+		if nil != req.Schema {
+			components = append(components, req.Schema)
+		}
+	}
+
+	for _, resp := range res.Responses {
+		for i, rb := range resp.ResponseBodies {
+			examples := s.bodies[rb]
+			if len(examples) <= 0 {
+				continue
+			}
+
+			rawName := res.Name + types.ToCamelCase(resp.Status, true) + "Response"
+			if i > 0 {
+				rawName += types.ToCamelCase(strings.ToLower(types.ContentKind(rb.MediaType)), true)
+			}
+
+			rb.Schema = l.infer(rawName, examples, types.SourceResponseBodyInline)
+			if nil != rb.Schema {
+				components = append(components, rb.Schema)
+			}
+		}
+	}
+
+	res.Components = &components
+}
+
+// infer creates the component for the examples of a body, merging all of them in to a single shape
+func (l *loader) infer(rawName string, examples []any, source types.ComponentSource) *types.Component {
+	comp, err := l.response.Components.InferComponent(loaders.ComponentName(rawName), rawName, "", source, examples...)
+	if nil != err {
+		l.response.Diagnostics.Warn("har-schema", l.source, "problem inferring %s: %s", rawName, err.Error())
+		return nil
+	}
+
+	comp.SourceDoc = l.source
+	return comp
+}
+
+// templatePath returns the templated path of a recorded url path along with its path parameters. The last id segment
+// becomes {id}, any before it are named after the segment they follow, e.g. /users/12/orders/7 is
+// /users/{userId}/orders/{id}.
+func templatePath(raw string) (string, types.Parameters) {
+	segments := make([]string, 0)
+	for _, s := range strings.Split(raw, "/") {
+		if len(s) > 0 {
+			segments = append(segments, s)
+		}
+	}
+
+	ids := make([]int, 0)
+	for i, s := range segments {
+		if numericSegment.MatchString(s) || uuidSegment.MatchString(s) || objectIdSegment.MatchString(s) {
+			ids = append(ids, i)
+		}
+	}
+
+	params := make(types.Parameters, 0, len(ids))
+	names := make(map[string]bool, len(ids))
+
+	for n := len(ids) - 1; n >= 0; n-- {
+		i := ids[n]
+		name := "id"
+		if n < len(ids)-1 || names[name] {
+			name = "id" + strconv.Itoa(n+1)
+			if i > 0 && !isId(segments[i-1]) {
+				name = types.ToCamelCase(strings.TrimSuffix(segments[i-1], "s"), false) + "Id"
+			}
+		}
+
+		for names[name] {
+			name += "_"
+		}
+		names[name] = true
+
+		param := &types.Parameter{
+			Name:     name,
+			In:       types.PATH,
+			Required: true,
+			Type:     "string",
+			Value:    segments[i],
+		}
+
+		switch {
+		case numericSegment.MatchString(segments[i]):
+			param.Type, param.Format = "number", "int"
+		case uuidSegment.MatchString(segments[i]):
+			param.Format = "uuid"
+		}
+
+		params = append(types.Parameters{param}, params...)
+		segments[i] = "{" + name + "}"
+	}
+
+	return "/" + strings.Join(segments, "/"), params
+}
+
+func isId(segment string) bool {
+	return strings.HasPrefix(segment, "{") || numericSegment.MatchString(segment) || uuidSegment.MatchString(segment) || objectIdSegment.MatchString(segment)
+}
+
+// static returns true for the media types of the pages, scripts, styles, images and fonts a browser loads
+func static(mimeType string) bool {
+	mt := strings.ToLower(mimeType)
+
+	switch {
+	case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "font/"), strings.HasPrefix(mt, "video/"), strings.HasPrefix(mt, "audio/"):
+		return true
+	case strings.HasPrefix(mt, "text/html"), strings.HasPrefix(mt, "text/css"), strings.Contains(mt, "javascript"):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,131 @@
+package har
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		header string
+		want   int
+	}{
+		{"a log with a creator", "capture.json", `{"log": {"version": "1.2", "creator": {"name": "WebInspector"}}}`, types.ConfidenceCertain},
+		{"a .har file", "capture.har", `{"something": "else"}`, types.ConfidenceHigh},
+		{"a log with entries", "capture.json", `{"log": {"entries": []}}`, types.ConfidenceMedium},
+		{"any other json", "capture.json", `{"entries": []}`, types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe(tt.source, []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "capture.har")
+
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Code != "har-skipped" || resp.Diagnostics[0].Severity != types.SeverityInfo {
+		t.Errorf("diagnostics = %v, want the script that is skipped", resp.Diagnostics)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		parameters []string // name and in
+		requests   []string
+		responses  []string // status and schema
+	}{
+		{
+			// three requests for different ids, one of them a 404
+			name:       "getV1UsersId",
+			method:     "get",
+			path:       "/v1/users/{id}",
+			parameters: []string{"id path", "expand query", "Authorization header", "X-Request-Id header"},
+			requests:   []string{},
+			responses:  []string{"200 GetV1UsersId200Response", "404 GetV1UsersId404Response"},
+		},
+		{
+			name:       "postV1UsersUserIdOrdersIdItems",
+			method:     "post",
+			path:       "/v1/users/{userId}/orders/{id}/items",
+			parameters: []string{"userId path", "id path"},
+			requests:   []string{"PostV1UsersUserIdOrdersIdItemsRequest"},
+			responses:  []string{"201 PostV1UsersUserIdOrdersIdItems201Response"},
+		},
+	}
+
+	if len(resp.Resources) != len(tests) {
+		t.Fatalf("%d resources, want %d", len(resp.Resources), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resp.Resources[i]
+			if res.Name != tt.name || res.Method != tt.method || res.Path != tt.path || res.Owner != "api.example.com" {
+				t.Errorf("the resource is %s %s %s of %s", res.Name, res.Method, res.Path, res.Owner)
+			}
+
+			parameters := make([]string, 0)
+			for _, p := range res.Parameters {
+				parameters = append(parameters, p.Name+" "+string(p.In))
+			}
+			if !reflect.DeepEqual(parameters, tt.parameters) {
+				t.Errorf("parameters = %v, want %v", parameters, tt.parameters)
+			}
+
+			requests := make([]string, 0)
+			for _, body := range res.Requests {
+				requests = append(requests, body.Schema.Name)
+			}
+			if !reflect.DeepEqual(requests, tt.requests) {
+				t.Errorf("requests = %v, want %v", requests, tt.requests)
+			}
+
+			responses := make([]string, 0)
+			for _, r := range res.Responses {
+				for _, body := range r.ResponseBodies {
+					responses = append(responses, r.Status+" "+body.Schema.Name)
+				}
+			}
+			if !reflect.DeepEqual(responses, tt.responses) {
+				t.Errorf("responses = %v, want %v", responses, tt.responses)
+			}
+		})
+	}
+}
+
+func TestLoadInferred(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "capture.har")
+
+	user := resp.Components.FindComponentByName("GetV1UsersId200Response")
+	if nil == user {
+		t.Fatal("the 200 response of getV1UsersId is not inferred")
+	}
+
+	// age is only in the base64 encoded response of the second request, email is only ever null so it is untyped
+	properties := make([]string, 0, len(user.Properties))
+	for _, prop := range user.Properties {
+		properties = append(properties, prop.Name+" "+prop.Type)
+	}
+	want := []string{"age number", "email ", "id number", "name string", "tags array"}
+	if !reflect.DeepEqual(properties, want) {
+		t.Errorf("properties = %v, want %v", properties, want)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", `{"log": [`, "log: {}"} {
+		if _, err := (Loader{}).Load("capture.har", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
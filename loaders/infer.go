@@ -0,0 +1,51 @@
+package loaders
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// templateVariable matches a {{variable}} that is used as a json value on its own, e.g. {"id": {{petId}}}
+var templateVariable = regexp.MustCompile(`([:\[,]\s*)\{\{[^{}]+\}\}`)
+
+// ParseExample
+//
+// This function decodes an example body that is expected to be json. Unquoted template variables, which collections
+// often use in place of numbers or objects, are read as null. Numbers are decoded as a json.Number so their literal text
+// is kept for InferComponent. False is returned if the body is not json.
+func ParseExample(body string) (any, bool) {
+	body = strings.TrimSpace(body)
+	if len(body) <= 0 || (body[0] != '{' && body[0] != '[') {
+		return nil, false
+	}
+
+	if v, err := decodeExample(body); nil == err {
+		return v, true
+	}
+
+	if v, err := decodeExample(templateVariable.ReplaceAllString(body, "${1}null")); nil == err {
+		return v, true
+	}
+
+	return nil, false
+}
+
+// decodeExample decodes a single json value with UseNumber, anything following the value is an error
+func decodeExample(body string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); nil != err {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); io.EOF != err {
+		return nil, errors.New("the example is followed by more data")
+	}
+
+	return v, nil
+}
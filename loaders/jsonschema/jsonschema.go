@@ -0,0 +1,342 @@
+// Package jsonschema is the built in loader for plain JSON Schema (2020-12) documents, e.g. the schemas of event payloads
+// or config files that are not part of an API. It only creates defined Components: one for the document itself (named
+// after its $id or file name), one for every schema in $defs (or definitions) and one for every other subschema that has
+// an $id or $anchor or is the target of a $ref.
+//
+// A $ref to another file (e.g. address.json#/$defs/Street) is returned as LoadedResponse.Imports so the pipeline loads
+// that file as well, the reference is linked when the sources are merged.
+package jsonschema
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Source is the name of the format this loader reads
+const Source = "jsonschema"
+
+// Draft is the JSON Schema dialect this loader follows
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// drafts are the older dialects that load the same way, definitions is read as well as $defs
+var drafts = []string{"2019-09", "draft-07", "draft-06", "draft-04"}
+
+// Loader implements pipeline.Loader for JSON Schema documents
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	dialect, ok := loaders.HeaderValue(header, "$schema")
+	name := strings.ToLower(source)
+
+	for _, key := range []string{"openapi", "swagger", "asyncapi", "arazzo"} {
+		if _, found := loaders.HeaderValue(header, key); found {
+			return types.ConfidenceNone
+		}
+	}
+
+	switch {
+	case ok && strings.Contains(dialect, "json-schema.org"):
+		return types.ConfidenceCertain
+	case strings.HasSuffix(name, ".schema.json") || strings.HasSuffix(name, ".schema.yaml") || strings.HasSuffix(name, ".schema.yml"):
+		return types.ConfidenceHigh
+	default:
+		if _, found := loaders.HeaderValue(header, "$defs"); found {
+			return types.ConfidenceLow
+		}
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	var dialect struct {
+		Schema string `json:"$schema"`
+	}
+
+	root := &loaders.Schema{}
+	if err := loaders.Decode(data, root); nil != err {
+		return nil, fmt.Errorf("%s is not a valid JSON Schema document: %w", source, err)
+	}
+	_ = loaders.Decode(data, &dialect)
+
+	l := &loader{
+		root:     root,
+		source:   source,
+		response: types.NewLoadedResponse(),
+		schemas:  make(map[string]*loaders.Schema),
+		names:    make(map[string]string),
+		ids:      make(map[string]string),
+		anchors:  make(map[string]string),
+	}
+
+	if len(dialect.Schema) > 0 && !strings.Contains(dialect.Schema, "2020-12") {
+		supported := false
+		for _, d := range drafts {
+			supported = supported || strings.Contains(dialect.Schema, d)
+		}
+
+		if !supported {
+			l.response.Diagnostics.Warn("jsonschema-version", source, "%s is not a supported dialect, loading it as 2020-12", dialect.Schema)
+		}
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	root      *loaders.Schema
+	source    string
+	base      *url.URL
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+
+	// every subschema of the document keyed on its json pointer (# for the document itself), along with the name of
+	// the component for the ones that become components
+	schemas map[string]*loaders.Schema
+	names   map[string]string
+
+	// the json pointers of the subschemas with an $id (keyed on the absolute $id) or an $anchor
+	ids     map[string]string
+	anchors map[string]string
+}
+
+func (l *loader) load() {
+	l.base = &url.URL{Path: l.source}
+	if u, err := url.Parse(l.root.Id); nil == err && len(l.root.Id) > 0 {
+		l.base = u
+	}
+
+	// the document itself is a component, unless it is only a collection of $defs
+	if len(l.root.Types()) > 0 || len(l.root.Properties) > 0 || nil != l.root.Items || len(l.root.Ref) > 0 ||
+		len(l.root.AllOf) > 0 || len(l.root.OneOf) > 0 || len(l.root.AnyOf) > 0 || len(l.root.Enum) > 0 {
+		name := loaders.RefName(l.source)
+		if len(l.root.Id) > 0 {
+			name = loaders.RefName(l.root.Id)
+		}
+		l.name("#", name)
+	}
+
+	l.index(l.root, "#", l.base)
+	l.link(l.root, "#", l.base)
+
+	for _, ptr := range loaders.SortedKeys(l.names) {
+		if _, err := l.converter.Define(l.names[ptr], l.schemas[ptr]); nil != err {
+			l.response.Diagnostics.Warn("jsonschema-schema", l.source, "problem loading %s: %s", l.names[ptr], err.Error()).Location = &types.Location{Path: strings.TrimPrefix(ptr, "#")}
+		}
+	}
+}
+
+// index records the json pointer of every subschema, and the $defs, $ids and $anchors that become components
+func (l *loader) index(s *loaders.Schema, ptr string, base *url.URL) {
+	if nil == s {
+		return
+	}
+
+	l.schemas[ptr] = s
+
+	if len(s.Id) > 0 {
+		if u, err := base.Parse(s.Id); nil == err {
+			base = u
+			l.ids[withoutFragment(u)] = ptr
+			if ptr != "#" {
+				l.name(ptr, loaders.RefName(s.Id))
+			}
+		}
+	}
+
+	if len(s.Anchor) > 0 {
+		l.anchors[s.Anchor] = ptr
+		l.name(ptr, s.Anchor)
+	}
+
+	for _, name := range loaders.SortedKeys(s.Defs) {
+		l.name(ptr+loaders.Pointer("$defs", name), name)
+		l.index(s.Defs[name], ptr+loaders.Pointer("$defs", name), base)
+	}
+
+	for _, name := range loaders.SortedKeys(s.Definitions) {
+		l.name(ptr+loaders.Pointer("definitions", name), name)
+		l.index(s.Definitions[name], ptr+loaders.Pointer("definitions", name), base)
+	}
+
+	l.children(s, ptr, func(child *loaders.Schema, childPtr string) {
+		l.index(child, childPtr, base)
+	})
+}
+
+// link rewrites every $ref in to either a local json pointer (#/...) to a named subschema, or a reference to another
+// file which is imported
+func (l *loader) link(s *loaders.Schema, ptr string, base *url.URL) {
+	if nil == s {
+		return
+	}
+
+	if len(s.Id) > 0 {
+		if u, err := base.Parse(s.Id); nil == err {
+			base = u
+		}
+	}
+
+	if len(s.Ref) > 0 {
+		s.Ref = l.rewrite(s.Ref, ptr, base)
+	}
+
+	for _, name := range loaders.SortedKeys(s.Defs) {
+		l.link(s.Defs[name], ptr+loaders.Pointer("$defs", name), base)
+	}
+
+	for _, name := range loaders.SortedKeys(s.Definitions) {
+		l.link(s.Definitions[name], ptr+loaders.Pointer("definitions", name), base)
+	}
+
+	l.children(s, ptr, func(child *loaders.Schema, childPtr string) {
+		l.link(child, childPtr, base)
+	})
+}
+
+// children calls fn for every subschema of s other than its $defs
+func (l *loader) children(s *loaders.Schema, ptr string, fn func(child *loaders.Schema, childPtr string)) {
+	for _, name := range loaders.SortedKeys(s.Properties) {
+		fn(s.Properties[name], ptr+loaders.Pointer("properties", name))
+	}
+
+	if nil != s.Items {
+		fn(s.Items, ptr+loaders.Pointer("items"))
+	}
+
+	if nil != s.AdditionalProperties {
+		fn(s.AdditionalProperties, ptr+loaders.Pointer("additionalProperties"))
+	}
+
+	for i, child := range s.AllOf {
+		fn(child, ptr+loaders.Pointer("allOf", strconv.Itoa(i)))
+	}
+
+	for i, child := range s.OneOf {
+		fn(child, ptr+loaders.Pointer("oneOf", strconv.Itoa(i)))
+	}
+
+	for i, child := range s.AnyOf {
+		fn(child, ptr+loaders.Pointer("anyOf", strconv.Itoa(i)))
+	}
+}
+
+func (l *loader) rewrite(ref, ptr string, base *url.URL) string {
+	target, err := base.Parse(ref)
+	if nil != err {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "%s is not a valid $ref", ref).Location = &types.Location{Path: strings.TrimPrefix(ptr, "#")}
+		return ref
+	}
+
+	doc := withoutFragment(target)
+	scope, local := l.ids[doc]
+	if !local && doc == withoutFragment(l.base) {
+		scope, local = "#", true
+	}
+
+	if !local {
+		l.external(ref, target)
+		return ref
+	}
+
+	fragment := target.Fragment
+	var resolved string
+	switch {
+	case len(fragment) <= 0:
+		resolved = scope
+	case strings.HasPrefix(fragment, "/"):
+		resolved = scope + escape(fragment)
+	default:
+		resolved = l.anchors[fragment]
+	}
+
+	if _, ok := l.schemas[resolved]; !ok || len(resolved) <= 0 {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "%s is not defined in this document", ref).Location = &types.Location{Path: strings.TrimPrefix(ptr, "#")}
+		return ref
+	}
+
+	// any other subschema a $ref points to becomes a component as well
+	if _, ok := l.names[resolved]; !ok {
+		l.name(resolved, loaders.RefName(resolved))
+	}
+
+	return resolved
+}
+
+// external imports the file a $ref to another document points to. A $ref next to the $id of this document is loaded
+// from the directory of this file, other urls as is.
+func (l *loader) external(ref string, target *url.URL) {
+	location := strings.SplitN(ref, "#", 2)[0]
+
+	if target.IsAbs() {
+		location = withoutFragment(target)
+		if l.base.IsAbs() && target.Host == l.base.Host && path.Dir(target.Path) == path.Dir(l.base.Path) {
+			location = path.Base(target.Path)
+		}
+	}
+
+	for _, imp := range l.response.Imports {
+		if imp == location {
+			return
+		}
+	}
+
+	l.response.Imports = append(l.response.Imports, location)
+}
+
+// name sets the component name of the subschema at a json pointer, a name that is taken gets a number appended
+func (l *loader) name(ptr, name string) {
+	if _, ok := l.names[ptr]; ok {
+		return
+	}
+
+	taken := make(map[string]bool, len(l.names))
+	for _, n := range l.names {
+		taken[strings.ToLower(loaders.ComponentName(n))] = true
+	}
+
+	unique := name
+	for i := 2; taken[strings.ToLower(loaders.ComponentName(unique))]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+
+	l.names[ptr] = unique
+}
+
+// resolveSchema resolves a $ref that was rewritten by link, references to other files are linked when merged
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	if name, ok := l.names[ref]; ok {
+		return name, l.schemas[ref]
+	}
+
+	return loaders.RefName(ref), nil
+}
+
+func withoutFragment(u *url.URL) string {
+	copied := *u
+	copied.Fragment = ""
+	copied.RawFragment = ""
+	return copied.String()
+}
+
+// escape returns a json pointer fragment in the escaped form the loader uses, url escapes such as %25 are decoded
+func escape(fragment string) string {
+	tokens := strings.Split(strings.TrimPrefix(fragment, "/"), "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+
+	return loaders.Pointer(tokens...)
+}
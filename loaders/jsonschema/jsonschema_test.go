@@ -0,0 +1,107 @@
+package jsonschema
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		header string
+		want   int
+	}{
+		{"a json-schema.org dialect", "order.json", `{"$schema": "https://json-schema.org/draft/2020-12/schema"}`, types.ConfidenceCertain},
+		{"a .schema.json file", "order.schema.json", `{"type": "object"}`, types.ConfidenceHigh},
+		{"a .schema.yaml file", "order.schema.yaml", "type: object", types.ConfidenceHigh},
+		{"only $defs", "order.json", `{"$defs": {}}`, types.ConfidenceLow},
+		{"an openapi document with a dialect", "api.json", `{"openapi": "3.1.0", "$schema": "https://json-schema.org/draft/2020-12/schema"}`, types.ConfidenceNone},
+		{"any other json", "order.json", `{"type": "object"}`, types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe(tt.source, []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		file       string
+		components []string
+		imports    []string
+	}{
+		{"customer.schema.json", []string{"Customer"}, nil},
+		{"event.json", []string{"Event", "OtherThing", "UserEvent"}, nil},
+		{"order.schema.json", []string{"LineItem", "Money", "Note", "Order", "ShipTo", "Status"}, []string{"customer.schema.json", "address.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			resp := loadertest.Load(t, Loader{}, tt.file)
+
+			if len(resp.Resources) > 0 || len(resp.Diagnostics) > 0 {
+				t.Errorf("%d resources and diagnostics %v, want none", len(resp.Resources), resp.Diagnostics)
+			}
+
+			components := loadertest.Names(resp.Components)
+			if !reflect.DeepEqual(components, tt.components) {
+				t.Errorf("components = %v, want %v", components, tt.components)
+			}
+
+			if !reflect.DeepEqual(resp.Imports, tt.imports) {
+				t.Errorf("imports = %v, want %v", resp.Imports, tt.imports)
+			}
+		})
+	}
+}
+
+func TestLoadReferences(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "order.schema.json")
+
+	order := resp.Components.FindComponentByName("Order")
+	if nil == order || order.Description != "An order event" {
+		t.Fatalf("the root schema is %+v, want the Order component", order)
+	}
+
+	tests := []struct {
+		property string
+		typ      string
+		ref      string
+	}{
+		{"billing", "object", "ShipTo"},    // a pointer in to the document
+		{"customer", "object", "Customer"}, // a relative file
+		{"items", "array", "LineItem"},     // a pointer to $defs
+		{"meta", "object", "string"},       // a map
+		{"note", "object", "Note"},         // an $id
+		{"shipTo", "object", "Street"},     // a pointer in to another file
+		{"status", "string", "Status"},     // an $anchor
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			prop := loadertest.Property(order, tt.property)
+			if nil == prop {
+				t.Fatalf("Order has no %s property", tt.property)
+			}
+
+			if ref := loadertest.RefName(prop.Ref); prop.Type != tt.typ || ref != tt.ref {
+				t.Errorf("the property is a %s of %s, want a %s of %s", prop.Type, ref, tt.typ, tt.ref)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", `{"properties": [`, "- a list\n- not a schema"} {
+		if _, err := (Loader{}).Load("order.schema.json", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package loaders holds what the source loaders built in to this repository have in common, such as decoding json or yaml
+// documents and converting JSON Schema in to Components. Each loader lives in its own package below this one and
+// implements pipeline.Loader.
+package loaders
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Decode
+//
+// This function decodes a json or yaml document in to v. Since json is a subset of yaml, every document goes through the
+// yaml decoder first and is then converted to json, so v only needs json struct tags.
+func Decode(data []byte, v any) error {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); nil != err {
+		return err
+	}
+
+	raw, err := json.Marshal(Normalize(doc))
+	if nil != err {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// Normalize
+//
+// This function converts the maps the yaml decoder produces (which may have non string keys, e.g. a 200 response code)
+// in to map[string]any so the value can be marshalled to json.
+func Normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = Normalize(val)
+		}
+		return t
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = Normalize(val)
+		}
+		return m
+	case []any:
+		for i, val := range t {
+			t[i] = Normalize(val)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// HeaderValue
+//
+// This function looks for a top level key in the header sample of a json or yaml document and returns its (unquoted)
+// value. It is meant for probing, e.g. HeaderValue(header, "openapi") returns 3.0.3 for a 3.0.3 definition.
+func HeaderValue(header []byte, key string) (string, bool) {
+	expr := regexp.MustCompile(`(?m)(?:^|[{,])\s*["']?` + regexp.QuoteMeta(key) + `["']?\s*:\s*["']?([^"',\s}]*)`)
+	match := expr.FindSubmatch(header)
+	if nil == match {
+		return "", false
+	}
+
+	return string(match[1]), true
+}
+
+// HasExtension returns true if the source path ends with one of the provided extensions (e.g. ".yaml"), ignoring case
+func HasExtension(source string, extensions ...string) bool {
+	ext := strings.ToLower(path.Ext(source))
+
+	for _, e := range extensions {
+		if ext == strings.ToLower(e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Example returns the json form of an example value, or an empty string if there is none
+func Example(v any) string {
+	if nil == v {
+		return ""
+	}
+
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	raw, err := json.Marshal(v)
+	if nil != err {
+		return ""
+	}
+
+	return string(raw)
+}
+
+// Bool returns a pointer to the provided value, for the *bool fields of Components and Properties
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Pointer
+//
+// This function escapes the provided tokens and joins them in to a json pointer, e.g. Pointer("paths", "/pets", "get")
+// returns /paths/~1pets/get. It is used for the Path of diagnostic locations.
+func Pointer(tokens ...string) string {
+	var b strings.Builder
+
+	for _, t := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+
+	return b.String()
+}
+
+// SortedKeys returns the keys of a map in order, so loaders create resources and components in a stable order
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,73 @@
+// Package loadertest holds what the tests of the built in loaders have in common: loading a file of the testdata
+// directory of the package under test and summarizing the reply so it can be compared with what a test expects.
+package loadertest
+
+import (
+	"github.com/spirefy/go-codegen/pipeline"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Load
+//
+// This function returns what the loader makes of a file in the testdata directory of the package under test. The test
+// fails right away when the file can not be read or loaded.
+func Load(t testing.TB, loader pipeline.Loader, name string) *types.LoadedResponse {
+	t.Helper()
+
+	source := filepath.Join("testdata", name)
+	data, err := os.ReadFile(source)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	resp, err := loader.Load(source, data)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return resp
+}
+
+// Names returns the name of every component, in order
+func Names(components types.Components) []string {
+	names := make([]string, 0, len(components))
+	for _, comp := range components {
+		names = append(names, comp.Name)
+	}
+
+	return names
+}
+
+// Codes returns the code of every diagnostic, in order
+func Codes(diagnostics types.Diagnostics) []string {
+	codes := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		codes = append(codes, d.Code)
+	}
+
+	return codes
+}
+
+// Property returns the property of a component with the provided name, or nil if it has none
+func Property(comp *types.Component, name string) *types.Property {
+	for _, prop := range comp.Properties {
+		if prop.Name == name {
+			return prop
+		}
+	}
+
+	return nil
+}
+
+// RefName returns the name of the component a Ref points to, or the primitive it names
+func RefName(ref any) string {
+	if comp, ok := ref.(*types.Component); ok {
+		return comp.Name
+	}
+
+	name, _ := ref.(string)
+	return name
+}
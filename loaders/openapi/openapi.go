@@ -0,0 +1,466 @@
+// Package openapi is the built in loader for OpenAPI 3.0 and 3.1 definitions (json or yaml). Every operation becomes an
+// HTTP Resource and every schema a Component.
+package openapi
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"net/url"
+	"strings"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "openapi"
+
+// The path item fields holding operations, in the order resources are created
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type document struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	} `json:"info"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]*pathItem `json:"paths"`
+	Components struct {
+		Schemas       map[string]*loaders.Schema `json:"schemas"`
+		Parameters    map[string]*parameter      `json:"parameters"`
+		RequestBodies map[string]*requestBody    `json:"requestBodies"`
+		Responses     map[string]*response       `json:"responses"`
+	} `json:"components"`
+}
+
+type pathItem struct {
+	Ref         string       `json:"$ref"`
+	Summary     string       `json:"summary"`
+	Description string       `json:"description"`
+	Parameters  []*parameter `json:"parameters"`
+	Get         *operation   `json:"get"`
+	Put         *operation   `json:"put"`
+	Post        *operation   `json:"post"`
+	Delete      *operation   `json:"delete"`
+	Options     *operation   `json:"options"`
+	Head        *operation   `json:"head"`
+	Patch       *operation   `json:"patch"`
+	Trace       *operation   `json:"trace"`
+}
+
+func (p *pathItem) operation(method string) *operation {
+	switch method {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	case "trace":
+		return p.Trace
+	}
+
+	return nil
+}
+
+type operation struct {
+	OperationId string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref         string                `json:"$ref"`
+	Name        string                `json:"name"`
+	In          string                `json:"in"`
+	Description string                `json:"description"`
+	Required    bool                  `json:"required"`
+	Schema      *loaders.Schema       `json:"schema"`
+	Content     map[string]*mediaType `json:"content"`
+	Example     any                   `json:"example"`
+}
+
+type requestBody struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Required    bool                  `json:"required"`
+	Content     map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema   *loaders.Schema `json:"schema"`
+	Example  any             `json:"example"`
+	Examples map[string]struct {
+		Value any `json:"value"`
+	} `json:"examples"`
+}
+
+// example returns the example of a media type, or the first of its named examples
+func (m *mediaType) example() string {
+	if nil != m.Example {
+		return loaders.Example(m.Example)
+	}
+
+	for _, name := range loaders.SortedKeys(m.Examples) {
+		if ex := loaders.Example(m.Examples[name].Value); len(ex) > 0 {
+			return ex
+		}
+	}
+
+	if nil != m.Schema && nil != m.Schema.Example {
+		return loaders.Example(m.Schema.Example)
+	}
+
+	return ""
+}
+
+// Loader implements pipeline.Loader for OpenAPI 3.x
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	version, ok := loaders.HeaderValue(header, "openapi")
+	switch {
+	case ok && strings.HasPrefix(version, "3."):
+		return types.ConfidenceCertain
+	case ok:
+		return types.ConfidenceMedium
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	doc := &document{}
+	if err := loaders.Decode(data, doc); nil != err {
+		return nil, fmt.Errorf("%s is not a valid OpenAPI document: %w", source, err)
+	}
+
+	l := &loader{
+		doc:      doc,
+		source:   source,
+		response: types.NewLoadedResponse(),
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		l.response.Diagnostics.Warn("openapi-version", source, "OpenAPI version %q is not supported, loading it as 3.x", doc.OpenAPI)
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Version:     doc.Info.Version,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *document
+	source    string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+}
+
+func (l *loader) load() {
+	// defined components first, so everything referencing them can find them
+	for _, name := range loaders.SortedKeys(l.doc.Components.Schemas) {
+		if _, err := l.converter.Define(name, l.doc.Components.Schemas[name]); nil != err {
+			l.response.Diagnostics.Warn("openapi-schema", l.source, "problem loading schema %s: %s", name, err.Error()).Location = &types.Location{Path: loaders.Pointer("components", "schemas", name)}
+		}
+	}
+
+	for _, pth := range loaders.SortedKeys(l.doc.Paths) {
+		item := l.doc.Paths[pth]
+		if nil == item {
+			continue
+		}
+
+		if len(item.Ref) > 0 {
+			l.response.Diagnostics.Warn("openapi-unsupported", l.source, "path item $ref %s is not supported", item.Ref).Location = &types.Location{Path: loaders.Pointer("paths", pth)}
+			continue
+		}
+
+		for _, method := range methods {
+			if op := item.operation(method); nil != op {
+				l.resource(pth, method, item, op)
+			}
+		}
+	}
+}
+
+func (l *loader) resource(pth, method string, item *pathItem, op *operation) {
+	name := types.MakeResourceName(op.OperationId, method, pth, "")
+
+	summary := op.Summary
+	if len(summary) <= 0 {
+		summary = item.Summary
+	}
+
+	description := op.Description
+	if len(description) <= 0 {
+		description = item.Description
+	}
+
+	res, err := l.response.Resources.NewResource(pth, method, name, description, summary, Source, l.doc.Info.Version, l.doc.Info.Title, op.Deprecated, true, types.HTTP)
+	if nil != err {
+		l.response.Diagnostics.Warn("openapi-operation", l.source, "problem loading operation: %s", err.Error()).Location = &types.Location{Path: loaders.Pointer("paths", pth, method)}
+		return
+	}
+
+	res.SourceDoc = l.source
+
+	// keep the first server the same way the swagger loader keeps basePath, host and schemes
+	if len(l.doc.Servers) > 0 {
+		if u, err := url.Parse(l.doc.Servers[0].URL); nil == err {
+			if len(u.Path) > 0 && u.Path != "/" {
+				res.Variables["basePath"] = u.Path
+			}
+			if len(u.Host) > 0 {
+				res.Variables["host"] = u.Host
+			}
+			if len(u.Scheme) > 0 {
+				res.Variables["schemes"] = u.Scheme
+			}
+		}
+	}
+
+	components := make(types.Components, 0)
+	used := func(comp *types.Component) {
+		if nil != comp && nil == components.FindComponentById(comp.Id) {
+			components = append(components, comp)
+		}
+	}
+
+	// operation parameters override path item parameters with the same name and location
+	params := make([]*parameter, 0, len(item.Parameters)+len(op.Parameters))
+	for _, p := range append(append([]*parameter{}, op.Parameters...), item.Parameters...) {
+		p = l.resolveParameter(p)
+		if nil == p {
+			continue
+		}
+
+		duplicate := false
+		for _, existing := range params {
+			if existing.Name == p.Name && existing.In == p.In {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			params = append(params, p)
+		}
+	}
+
+	for _, p := range params {
+		param := l.parameter(name, p)
+		res.Parameters = append(res.Parameters, param)
+		for _, comp := range param.Components {
+			used(comp)
+		}
+	}
+
+	if body := l.resolveRequestBody(op.RequestBody); nil != body {
+		for i, contentType := range loaders.SortedKeys(body.Content) {
+			media := body.Content[contentType]
+			req := &types.Request{
+				Required:    body.Required,
+				ContentType: contentType,
+				Type:        types.ContentKind(contentType),
+				Default:     i == 0,
+			}
+
+			if len(op.RequestBody.Ref) > 0 {
+				req.Ref = op.RequestBody.Ref
+			}
+
+			if nil != media && nil != media.Schema {
+				rawName := name + "Request"
+				if i > 0 {
+					rawName += types.ToCamelCase(strings.ToLower(req.Type), true)
+				}
+
+				schema, ref, err := l.converter.Inline(rawName, media.Schema, types.SourceRequestBodyInline)
+				if nil != err {
+					l.response.Diagnostics.Warn("openapi-schema", l.source, "problem loading request body: %s", err.Error()).Location = &types.Location{Path: loaders.Pointer("paths", pth, method, "requestBody")}
+				}
+
+				req.Schema = schema
+				if len(ref) > 0 {
+					req.Ref = ref
+				}
+				used(schema)
+			}
+
+			res.Requests = append(res.Requests, req)
+		}
+	}
+
+	for _, status := range loaders.SortedKeys(op.Responses) {
+		resp := l.resolveResponse(op.Responses[status])
+		if nil == resp {
+			continue
+		}
+
+		r := &types.Response{
+			Status:         status,
+			Description:    resp.Description,
+			ResponseBodies: make(types.ResponseBodies, 0),
+		}
+
+		for i, mediaType := range loaders.SortedKeys(resp.Content) {
+			media := resp.Content[mediaType]
+			body := &types.ResponseBody{
+				MediaType: mediaType,
+				Default:   i == 0,
+			}
+
+			if len(op.Responses[status].Ref) > 0 {
+				body.Ref = op.Responses[status].Ref
+			}
+
+			if nil != media {
+				body.Example = media.example()
+
+				if nil != media.Schema {
+					rawName := name + types.ToCamelCase(status, true) + "Response"
+					if i > 0 {
+						rawName += types.ToCamelCase(strings.ToLower(types.ContentKind(mediaType)), true)
+					}
+
+					schema, ref, err := l.converter.Inline(rawName, media.Schema, types.SourceResponseBodyInline)
+					if nil != err {
+						l.response.Diagnostics.Warn("openapi-schema", l.source, "problem loading response body: %s", err.Error()).Location = &types.Location{Path: loaders.Pointer("paths", pth, method, "responses", status)}
+					}
+
+					body.Schema = schema
+					if len(ref) > 0 {
+						body.Ref = ref
+					}
+					used(schema)
+				}
+			}
+
+			r.ResponseBodies = append(r.ResponseBodies, body)
+		}
+
+		res.Responses = append(res.Responses, r)
+	}
+
+	res.Components = &components
+}
+
+func (l *loader) parameter(resourceName string, p *parameter) *types.Parameter {
+	param := &types.Parameter{
+		Name:        p.Name,
+		In:          types.QueryIn(p.In),
+		Description: p.Description,
+		Required:    p.Required || p.In == "path",
+		Value:       loaders.Example(p.Example),
+		Components:  make(types.Components, 0),
+	}
+
+	schema := p.Schema
+	if nil == schema {
+		// a parameter either has a schema or a content map with a single media type
+		for _, contentType := range loaders.SortedKeys(p.Content) {
+			if nil != p.Content[contentType] {
+				schema = p.Content[contentType].Schema
+				break
+			}
+		}
+	}
+
+	if nil == schema {
+		param.Type = "string"
+		return param
+	}
+
+	typ, format, _ := l.converter.TypeOf(l.converter.Flatten(schema))
+	param.Type = typ
+	param.Format = format
+
+	if typ == "object" || typ == "array" || len(schema.Ref) > 0 {
+		comp, _, err := l.converter.Inline(resourceName+types.ToCamelCase(p.Name, true)+"Param", schema, types.SourceParameter)
+		if nil != err {
+			l.response.Diagnostics.Warn("openapi-schema", l.source, "problem loading parameter %s: %s", p.Name, err.Error())
+		} else if nil != comp {
+			param.Components = append(param.Components, comp)
+		}
+	}
+
+	return param
+}
+
+// resolveSchema resolves a $ref to a schema in this document
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	const prefix = "#/components/schemas/"
+	if strings.HasPrefix(ref, prefix) {
+		name := loaders.RefName(ref)
+		return name, l.doc.Components.Schemas[name]
+	}
+
+	return loaders.RefName(ref), nil
+}
+
+func (l *loader) resolveParameter(p *parameter) *parameter {
+	if nil == p || len(p.Ref) <= 0 {
+		return p
+	}
+
+	found := l.doc.Components.Parameters[loaders.RefName(p.Ref)]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "parameter %s is not defined", p.Ref)
+	}
+
+	return found
+}
+
+func (l *loader) resolveRequestBody(b *requestBody) *requestBody {
+	if nil == b || len(b.Ref) <= 0 {
+		return b
+	}
+
+	found := l.doc.Components.RequestBodies[loaders.RefName(b.Ref)]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "request body %s is not defined", b.Ref)
+	}
+
+	return found
+}
+
+func (l *loader) resolveResponse(r *response) *response {
+	if nil == r || len(r.Ref) <= 0 {
+		return r
+	}
+
+	found := l.doc.Components.Responses[loaders.RefName(r.Ref)]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "response %s is not defined", r.Ref)
+	}
+
+	return found
+}
@@ -0,0 +1,192 @@
+package openapi
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"yaml 3.x", "openapi: 3.0.3\ninfo:\n  title: Petstore", types.ConfidenceCertain},
+		{"json 3.x", `{"openapi": "3.1.0", "info": {}}`, types.ConfidenceCertain},
+		{"another version", "openapi: 4.0.0", types.ConfidenceMedium},
+		{"swagger", `{"swagger": "2.0"}`, types.ConfidenceNone},
+		{"a key that only ends with openapi", "x-openapi: 3.0.0", types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe("api.yaml", []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		file       string
+		resources  []string // method and path
+		components []string
+		codes      []string
+	}{
+		{
+			file:       "petstore.yaml",
+			resources:  []string{"get /pets", "post /pets", "get /pets/{petId}"},
+			components: []string{"CreatePetRequest", "Error", "ListPets200Response", "Named", "Owner", "Pet"},
+			codes:      []string{},
+		},
+		{
+			file:       "colliding-names.yaml",
+			resources:  []string{"get /a"},
+			components: []string{"Pet", "Pet2"},
+			codes:      []string{"duplicate-schema-name"},
+		},
+		{
+			file:       "nested.yaml",
+			resources:  []string{},
+			components: []string{"Anything", "Cell", "CellsItem", "CubeItem", "CubeItemItem", "Grid", "LookupItem", "RowsItem"},
+			codes:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			resp := loadertest.Load(t, Loader{}, tt.file)
+
+			resources := make([]string, 0, len(resp.Resources))
+			for _, res := range resp.Resources {
+				resources = append(resources, res.Method+" "+res.Path)
+			}
+			if !reflect.DeepEqual(resources, tt.resources) {
+				t.Errorf("resources = %v, want %v", resources, tt.resources)
+			}
+
+			components := loadertest.Names(resp.Components)
+			if !reflect.DeepEqual(components, tt.components) {
+				t.Errorf("components = %v, want %v", components, tt.components)
+			}
+
+			codes := loadertest.Codes(resp.Diagnostics)
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("diagnostics = %v, want %v", codes, tt.codes)
+			}
+		})
+	}
+}
+
+func TestLoadOperations(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "petstore.yaml")
+
+	list := resp.Resources.FindResourceByName("listPets")
+	if nil == list {
+		t.Fatal("listPets is not loaded")
+	}
+
+	if len(list.Parameters) != 2 || list.Parameters[0].In != types.QUERY || list.Parameters[1].In != types.HEADER {
+		t.Errorf("the parameters of listPets are %+v, want the limit query and X-Trace header", list.Parameters)
+	}
+
+	if len(list.Responses) != 2 || list.Responses[0].Status != "200" || list.Responses[1].Status != "default" {
+		t.Fatalf("the responses of listPets are %+v, want 200 and default", list.Responses)
+	}
+
+	page := list.Responses[0].ResponseBodies[0]
+	if page.Schema.Type != "array" || loadertest.RefName(page.Schema.Ref) != "Pet" || len(page.Example) <= 0 {
+		t.Errorf("the 200 response of listPets is %+v, want an array of Pet with an example", page)
+	}
+
+	create := resp.Resources.FindResourceByName("createPet")
+	if len(create.Requests) != 1 || !create.Requests[0].Required || create.Requests[0].Type != "JSON" {
+		t.Errorf("the request body of createPet is %+v, want a required JSON body", create.Requests)
+	}
+
+	show := resp.Resources.FindResourceByName("showPetById")
+	if !show.Deprecated || len(show.Parameters) != 1 || show.Parameters[0].In != types.PATH || !show.Parameters[0].Required {
+		t.Errorf("showPetById is %+v, want it deprecated with the petId path parameter of the path item", show)
+	}
+}
+
+func TestLoadCollidingNames(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "colliding-names.yaml")
+
+	pet := resp.Components.FindComponentByName("Pet2")
+	if nil == pet || pet.RawName != "pet" {
+		t.Fatalf("the schema pet is %+v, want it named Pet2", pet)
+	}
+
+	body := resp.Resources[0].Responses[0].ResponseBodies[0].Schema
+	if body != pet {
+		t.Errorf("the response of getA is %s, want the component of the schema it references", body.Name)
+	}
+
+	if other := loadertest.Property(pet, "other"); nil == other || loadertest.RefName(other.Ref) != "Pet" {
+		t.Errorf("the other property of pet does not reference Pet")
+	}
+}
+
+func TestLoadNestedItems(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "nested.yaml")
+
+	tests := []struct {
+		property string
+		typ      string
+		refs     []string // the chain of refs down to the item type
+	}{
+		{"cells", "array", []string{"CellsItem", "int"}},
+		{"cube", "array", []string{"CubeItem", "CubeItemItem", "Cell"}},
+		{"free", "", []string{}},
+		{"lookup", "object", []string{"LookupItem", "string"}},
+		{"rows", "array", []string{"RowsItem", "object"}},
+	}
+
+	grid := resp.Components.FindComponentByName("Grid")
+	if nil == grid {
+		t.Fatal("Grid is not loaded")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			prop := loadertest.Property(grid, tt.property)
+			if nil == prop {
+				t.Fatalf("Grid has no %s property", tt.property)
+			}
+
+			if prop.Type != tt.typ {
+				t.Errorf("type = %q, want %q", prop.Type, tt.typ)
+			}
+
+			refs := make([]string, 0)
+			for ref := prop.Ref; nil != ref; {
+				refs = append(refs, loadertest.RefName(ref))
+
+				comp, ok := ref.(*types.Component)
+				if !ok || comp.Source != types.SourceProperty {
+					break
+				}
+				ref = comp.Ref
+			}
+			if !reflect.DeepEqual(refs, tt.refs) {
+				t.Errorf("refs = %v, want %v", refs, tt.refs)
+			}
+		})
+	}
+
+	if anything := resp.Components.FindComponentByName("Anything"); anything.Type != "" {
+		t.Errorf("the type of an empty schema is %q, want none", anything.Type)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", "paths: [/pets", "- a list\n- not a document"} {
+		if _, err := (Loader{}).Load("api.yaml", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
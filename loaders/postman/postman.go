@@ -0,0 +1,820 @@
+// Package postman is the built in loader for Postman v2.1 collections and Postman environments. Collection folders become
+// FOLDER resources holding the resources of their requests, collection variables end up in Resource.Variables and the
+// request and response Components are inferred from the example bodies, since a collection has no schemas. Requests with
+// the same method and url are merged in to a single Resource.
+//
+// An environment (exported from Postman as a separate file) is loaded as a source of its own. Its values are returned
+// as LoadedResponse.Variables, which are set on the resources of any collection of the same run that use them.
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "postman"
+
+// variableReference matches the {{variable}} references of a collection
+var variableReference = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+type document struct {
+	Info struct {
+		Name        string      `json:"name"`
+		Description description `json:"description"`
+		Version     any         `json:"version"`
+		Schema      string      `json:"schema"`
+	} `json:"info"`
+	Item     []*item     `json:"item"`
+	Variable []*keyValue `json:"variable"`
+
+	// environment documents
+	Name   string      `json:"name"`
+	Values []*keyValue `json:"values"`
+	Scope  string      `json:"_postman_variable_scope"`
+}
+
+// item is either a folder (it has items of its own) or a request
+type item struct {
+	Name        string      `json:"name"`
+	Description description `json:"description"`
+	Item        []*item     `json:"item"`
+	Request     *request    `json:"request"`
+	Response    []*response `json:"response"`
+}
+
+type request struct {
+	Method      string      `json:"method"`
+	Header      headers     `json:"header"`
+	Body        *body       `json:"body"`
+	URL         *requestURL `json:"url"`
+	Description description `json:"description"`
+}
+
+// UnmarshalJSON
+// A request may also be just the url of a GET request
+func (r *request) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); nil == err {
+		r.Method = "GET"
+		r.URL = &requestURL{Raw: raw}
+		return nil
+	}
+
+	type alias request
+	return json.Unmarshal(data, (*alias)(r))
+}
+
+type requestURL struct {
+	Raw      string      `json:"raw"`
+	Protocol string      `json:"protocol"`
+	Host     any         `json:"host"` // a string, or the list of host segments
+	Path     any         `json:"path"` // a string, or a list of segments which are strings or {type, value} objects
+	Query    []*keyValue `json:"query"`
+	Variable []*keyValue `json:"variable"`
+}
+
+// UnmarshalJSON
+// A url may also be just the raw url
+func (u *requestURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); nil == err {
+		u.Raw = raw
+		return nil
+	}
+
+	type alias requestURL
+	return json.Unmarshal(data, (*alias)(u))
+}
+
+type body struct {
+	Mode       string      `json:"mode"`
+	Raw        string      `json:"raw"`
+	URLEncoded []*keyValue `json:"urlencoded"`
+	FormData   []*keyValue `json:"formdata"`
+	Disabled   bool        `json:"disabled"`
+	Options    struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+type response struct {
+	Name            string  `json:"name"`
+	Status          string  `json:"status"`
+	Code            int     `json:"code"`
+	Header          headers `json:"header"`
+	Body            string  `json:"body"`
+	PreviewLanguage string  `json:"_postman_previewlanguage"`
+}
+
+// keyValue is a header, query parameter, form field or variable
+type keyValue struct {
+	Key         string      `json:"key"`
+	Value       any         `json:"value"`
+	Type        string      `json:"type"`
+	Description description `json:"description"`
+	Disabled    bool        `json:"disabled"`
+	Enabled     *bool       `json:"enabled"` // environments use enabled instead of disabled
+}
+
+func (kv *keyValue) active() bool {
+	return !kv.Disabled && (nil == kv.Enabled || *kv.Enabled)
+}
+
+type headers []*keyValue
+
+// UnmarshalJSON
+// The headers of a response may also be a single string (or null), which are ignored
+func (h *headers) UnmarshalJSON(data []byte) error {
+	if len(data) <= 0 || data[0] != '[' {
+		return nil
+	}
+
+	list := make([]*keyValue, 0)
+	if err := json.Unmarshal(data, &list); nil != err {
+		return err
+	}
+
+	*h = list
+	return nil
+}
+
+func (h headers) get(key string) string {
+	for _, kv := range h {
+		if nil != kv && kv.active() && strings.EqualFold(kv.Key, key) {
+			return loaders.Example(kv.Value)
+		}
+	}
+
+	return ""
+}
+
+type description string
+
+// UnmarshalJSON
+// A description is either a string or a {content, type} object
+func (d *description) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); nil == err {
+		*d = description(s)
+		return nil
+	}
+
+	aux := struct {
+		Content string `json:"content"`
+	}{}
+	if err := json.Unmarshal(data, &aux); nil != err {
+		return err
+	}
+
+	*d = description(aux.Content)
+	return nil
+}
+
+// Loader implements pipeline.Loader for Postman v2.1 collections and environments
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	h := string(header)
+	scope, _ := loaders.HeaderValue(header, "_postman_variable_scope")
+
+	switch {
+	case strings.Contains(h, "schema.getpostman.com/json/collection/v2.1"):
+		return types.ConfidenceCertain
+	case scope == "environment" || strings.HasSuffix(strings.ToLower(source), ".postman_environment.json"):
+		return types.ConfidenceCertain
+	case strings.Contains(h, "schema.getpostman.com/json/collection/"):
+		return types.ConfidenceMedium
+	case strings.Contains(h, "_postman_id"):
+		// exports start with the id, the schema may be further down than the header when there is a long description
+		return types.ConfidenceHigh
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	doc := &document{}
+	if err := loaders.Decode(data, doc); nil != err {
+		return nil, fmt.Errorf("%s is not a valid Postman collection: %w", source, err)
+	}
+
+	l := &loader{
+		doc:       doc,
+		source:    source,
+		response:  types.NewLoadedResponse(),
+		variables: make(map[string]string),
+		samples:   make(map[*types.Resource]*samples),
+	}
+
+	if nil == doc.Item && (doc.Scope == "environment" || nil != doc.Values) {
+		l.environment()
+		return l.response, nil
+	}
+
+	if len(doc.Info.Schema) > 0 && !strings.Contains(doc.Info.Schema, "/v2.1") {
+		l.response.Diagnostics.Warn("postman-version", source, "collection schema %s is not supported, loading it as v2.1", doc.Info.Schema)
+	}
+
+	l.version = version(doc.Info.Version)
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Version:     l.version,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *document
+	source    string
+	version   string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+
+	// the collection variables
+	variables map[string]string
+
+	// the example bodies of every request and response body, in the order the resources are created
+	resources []*types.Resource
+	samples   map[*types.Resource]*samples
+}
+
+type samples struct {
+	requests map[*types.Request][]any
+	bodies   map[*types.ResponseBody][]any
+}
+
+func (l *loader) environment() {
+	l.response.Variables = make(map[string]string, len(l.doc.Values))
+
+	for _, kv := range l.doc.Values {
+		if nil != kv && kv.active() && len(kv.Key) > 0 {
+			l.response.Variables[kv.Key] = loaders.Example(kv.Value)
+		}
+	}
+}
+
+func (l *loader) load() {
+	for _, kv := range l.doc.Variable {
+		if nil != kv && kv.active() && len(kv.Key) > 0 {
+			l.variables[kv.Key] = loaders.Example(kv.Value)
+		}
+	}
+
+	l.items(l.doc.Item, nil, nil, nil)
+
+	// now that every request has been seen, the components are inferred from all of its examples
+	for _, res := range l.resources {
+		l.components(res)
+	}
+}
+
+func (l *loader) items(items []*item, parent *types.Resource, folders []string, location []string) {
+	for i, it := range items {
+		if nil == it {
+			continue
+		}
+
+		loc := append(append([]string{}, location...), "item", strconv.Itoa(i))
+
+		var res *types.Resource
+		if nil == it.Request {
+			res = l.folder(it, folders, loc)
+			if nil != res {
+				l.items(it.Item, res, append(append([]string{}, folders...), it.Name), loc)
+			}
+		} else {
+			res = l.request(it, loc)
+		}
+
+		if nil != res && nil != parent && nil == parent.Resources.FindResourceByUuid(res.Id) {
+			parent.Resources = append(parent.Resources, res)
+		}
+	}
+}
+
+func (l *loader) folder(it *item, folders []string, location []string) *types.Resource {
+	pth := "/" + strings.Join(append(append([]string{}, folders...), it.Name), "/")
+
+	if existing := l.response.Resources.FindResource(pth, "folder", &l.doc.Info.Name); nil != existing {
+		return existing
+	}
+
+	res, err := l.response.Resources.NewResource(pth, "folder", types.MakeResourceName(it.Name, "", "", ""), string(it.Description), it.Name, Source, l.version, l.doc.Info.Name, false, true, types.FOLDER)
+	if nil != err {
+		l.response.Diagnostics.Warn("postman-item", l.source, "problem loading folder %s: %s", it.Name, err.Error()).Location = &types.Location{Path: loaders.Pointer(location...)}
+		return nil
+	}
+
+	res.SourceDoc = l.source
+	res.Resources = make(types.Resources, 0)
+	return res
+}
+
+func (l *loader) request(it *item, location []string) *types.Resource {
+	req := it.Request
+	method := strings.ToLower(req.Method)
+	if len(method) <= 0 {
+		method = "get"
+	}
+
+	if nil == req.URL {
+		req.URL = &requestURL{}
+	}
+
+	pth, params := l.path(req.URL)
+
+	res := l.response.Resources.FindResource(pth, method, &l.doc.Info.Name)
+	if nil != res && res.Source == Source {
+		l.response.Diagnostics.Info(types.CodeMergedResource, l.source, "request %s is a duplicate of %s %s and has been merged", it.Name, method, pth).Location = &types.Location{Path: loaders.Pointer(location...)}
+	} else {
+		description := string(it.Description)
+		if len(description) <= 0 {
+			description = string(req.Description)
+		}
+
+		var err error
+		res, err = l.response.Resources.NewResource(pth, method, types.MakeResourceName(it.Name, method, pth, ""), description, it.Name, Source, l.version, l.doc.Info.Name, false, true, types.HTTP)
+		if nil != err {
+			l.response.Diagnostics.Warn("postman-item", l.source, "problem loading request %s: %s", it.Name, err.Error()).Location = &types.Location{Path: loaders.Pointer(location...)}
+			return nil
+		}
+
+		res.SourceDoc = l.source
+		for k, v := range l.variables {
+			res.Variables[k] = v
+		}
+
+		l.resources = append(l.resources, res)
+		l.samples[res] = &samples{
+			requests: make(map[*types.Request][]any),
+			bodies:   make(map[*types.ResponseBody][]any),
+		}
+	}
+
+	if host := l.host(req.URL); len(host) > 0 {
+		res.Variables["host"] = host
+	}
+
+	if len(req.URL.Protocol) > 0 {
+		res.Variables["schemes"] = req.URL.Protocol
+	}
+
+	for _, kv := range req.URL.query() {
+		if nil != kv && len(kv.Key) > 0 {
+			params = append(params, l.parameter(kv, types.QUERY, false))
+		}
+	}
+
+	for _, kv := range req.Header {
+		if nil != kv && len(kv.Key) > 0 && !strings.EqualFold(kv.Key, "Content-Type") {
+			params = append(params, l.parameter(kv, types.HEADER, false))
+		}
+	}
+
+	for _, param := range params {
+		found := false
+		for _, p := range res.Parameters {
+			if p.Name == param.Name && p.In == param.In {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			res.Parameters = append(res.Parameters, param)
+		}
+	}
+
+	l.requestBody(res, req)
+
+	for _, r := range it.Response {
+		if nil != r {
+			l.responseBody(res, r)
+		}
+	}
+
+	// every variable the request uses is kept, even the ones without a value, so an environment can provide it
+	for _, name := range l.references(req) {
+		if _, ok := res.Variables[name]; !ok {
+			res.Variables[name] = l.variables[name]
+		}
+	}
+
+	return res
+}
+
+// path returns the templated path of a url along with its path parameters. Both :name segments and {{name}} variables
+// become {name}.
+func (l *loader) path(u *requestURL) (string, types.Parameters) {
+	params := make(types.Parameters, 0)
+	segments := make([]string, 0)
+
+	for _, segment := range u.segments() {
+		if len(segment) <= 0 {
+			continue
+		}
+
+		name := ""
+		param := &types.Parameter{In: types.PATH, Required: true, Type: "string"}
+
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+			for _, kv := range u.Variable {
+				if nil != kv && kv.Key == name {
+					param.Value = loaders.Example(kv.Value)
+					param.Description = string(kv.Description)
+				}
+			}
+		case variableReference.MatchString(segment) && variableReference.FindString(segment) == segment:
+			name = strings.TrimSpace(variableReference.FindStringSubmatch(segment)[1])
+			param.VariableNameValue = name
+			param.Value = l.variables[name]
+		default:
+			segments = append(segments, segment)
+			continue
+		}
+
+		param.Name = name
+		params = append(params, param)
+		segments = append(segments, "{"+name+"}")
+	}
+
+	return "/" + strings.Join(segments, "/"), params
+}
+
+func (l *loader) host(u *requestURL) string {
+	switch h := u.Host.(type) {
+	case string:
+		return h
+	case []any:
+		parts := make([]string, 0, len(h))
+		for _, p := range h {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		return strings.Join(parts, ".")
+	}
+
+	host, _ := splitRaw(u.Raw)
+	return host
+}
+
+// segments returns the path segments of a url, from the parsed path when there is one and otherwise from the raw url
+func (u *requestURL) segments() []string {
+	switch p := u.Path.(type) {
+	case string:
+		return strings.Split(p, "/")
+	case []any:
+		segments := make([]string, 0, len(p))
+		for _, s := range p {
+			switch v := s.(type) {
+			case string:
+				segments = append(segments, v)
+			case map[string]any:
+				segments = append(segments, fmt.Sprint(v["value"]))
+			}
+		}
+		return segments
+	}
+
+	_, pth := splitRaw(u.Raw)
+	return strings.Split(pth, "/")
+}
+
+// query returns the query parameters of a url, from the parsed query when there is one and otherwise from the raw url
+func (u *requestURL) query() []*keyValue {
+	if nil != u.Query {
+		return u.Query
+	}
+
+	query := make([]*keyValue, 0)
+	indx := strings.Index(u.Raw, "?")
+	if indx < 0 {
+		return query
+	}
+
+	raw := u.Raw[indx+1:]
+	if hash := strings.Index(raw, "#"); hash >= 0 {
+		raw = raw[:hash]
+	}
+
+	for _, pair := range strings.Split(raw, "&") {
+		if len(pair) > 0 {
+			kv := strings.SplitN(pair, "=", 2)
+			query = append(query, &keyValue{Key: kv[0], Value: strings.Join(kv[1:], "")})
+		}
+	}
+
+	return query
+}
+
+// splitRaw splits a raw url, e.g. {{baseUrl}}/pets/:petId?limit=10 or https://example.com/pets, in to its host and path
+func splitRaw(raw string) (string, string) {
+	if indx := strings.IndexAny(raw, "?#"); indx >= 0 {
+		raw = raw[:indx]
+	}
+
+	if indx := strings.Index(raw, "://"); indx >= 0 {
+		raw = raw[indx+3:]
+	} else if strings.HasPrefix(raw, "/") {
+		return "", raw
+	}
+
+	if indx := strings.Index(raw, "/"); indx >= 0 {
+		return raw[:indx], raw[indx:]
+	}
+
+	return raw, ""
+}
+
+func (l *loader) parameter(kv *keyValue, in types.QueryIn, required bool) *types.Parameter {
+	value := loaders.Example(kv.Value)
+	param := &types.Parameter{
+		Name:        kv.Key,
+		In:          in,
+		Description: string(kv.Description),
+		Required:    required,
+		Type:        "string",
+		Value:       value,
+	}
+
+	if match := variableReference.FindStringSubmatch(value); nil != match && match[0] == value {
+		param.VariableNameValue = strings.TrimSpace(match[1])
+		param.Value = l.variables[param.VariableNameValue]
+	}
+
+	return param
+}
+
+func (l *loader) requestBody(res *types.Resource, req *request) {
+	b := req.Body
+	if nil == b || b.Disabled || len(b.Mode) <= 0 {
+		return
+	}
+
+	contentType := req.Header.get("Content-Type")
+	var sample any
+
+	switch b.Mode {
+	case "raw":
+		if len(contentType) <= 0 {
+			contentType = languageContentType(b.Options.Raw.Language)
+		}
+
+		if v, ok := loaders.ParseExample(b.Raw); ok {
+			sample = v
+			if len(contentType) <= 0 {
+				contentType = "application/json"
+			}
+		}
+
+		if len(contentType) <= 0 {
+			contentType = "text/plain"
+		}
+	case "urlencoded", "formdata":
+		fields := b.URLEncoded
+		if b.Mode == "formdata" {
+			fields = b.FormData
+			if len(contentType) <= 0 || !strings.HasPrefix(contentType, "multipart/") {
+				contentType = "multipart/form-data"
+			}
+		} else if len(contentType) <= 0 {
+			contentType = "application/x-www-form-urlencoded"
+		}
+
+		form := make(map[string]any, len(fields))
+		for _, kv := range fields {
+			if nil != kv && kv.active() && len(kv.Key) > 0 {
+				form[kv.Key] = loaders.Example(kv.Value)
+			}
+		}
+		sample = form
+	case "graphql":
+		contentType = "application/json"
+	case "file":
+		if len(contentType) <= 0 {
+			contentType = "application/octet-stream"
+		}
+	default:
+		l.response.Diagnostics.Warn("postman-unsupported", l.source, "body mode %s of %s %s is not supported", b.Mode, res.Method, res.Path)
+		return
+	}
+
+	var request *types.Request
+	for _, r := range res.Requests {
+		if strings.EqualFold(r.ContentType, contentType) {
+			request = r
+		}
+	}
+
+	if nil == request {
+		request = &types.Request{
+			Required:    true,
+			ContentType: contentType,
+			Type:        types.ContentKind(contentType),
+			Default:     len(res.Requests) <= 0,
+		}
+		res.Requests = append(res.Requests, request)
+	}
+
+	if nil != sample {
+		l.samples[res].requests[request] = append(l.samples[res].requests[request], sample)
+	}
+}
+
+func (l *loader) responseBody(res *types.Resource, r *response) {
+	status := "default"
+	if r.Code > 0 {
+		status = strconv.Itoa(r.Code)
+	}
+
+	var resp *types.Response
+	for _, rs := range res.Responses {
+		if rs.Status == status {
+			resp = rs
+		}
+	}
+
+	if nil == resp {
+		description := r.Status
+		if len(description) <= 0 {
+			description = r.Name
+		}
+
+		resp = &types.Response{
+			Status:         status,
+			Description:    description,
+			ResponseBodies: make(types.ResponseBodies, 0),
+		}
+		res.Responses = append(res.Responses, resp)
+	}
+
+	if len(r.Body) <= 0 {
+		return
+	}
+
+	sample, isJson := loaders.ParseExample(r.Body)
+	mediaType := r.Header.get("Content-Type")
+	if len(mediaType) <= 0 {
+		mediaType = languageContentType(r.PreviewLanguage)
+	}
+	if len(mediaType) <= 0 && isJson {
+		mediaType = "application/json"
+	}
+	if len(mediaType) <= 0 {
+		mediaType = "text/plain"
+	}
+
+	// parameters such as charset are not part of the media type
+	mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+
+	var rb *types.ResponseBody
+	for _, b := range resp.ResponseBodies {
+		if strings.EqualFold(b.MediaType, mediaType) {
+			rb = b
+		}
+	}
+
+	if nil == rb {
+		rb = &types.ResponseBody{
+			MediaType: mediaType,
+			Default:   len(resp.ResponseBodies) <= 0,
+			Example:   r.Body,
+		}
+		resp.ResponseBodies = append(resp.ResponseBodies, rb)
+	}
+
+	if isJson {
+		l.samples[res].bodies[rb] = append(l.samples[res].bodies[rb], sample)
+	}
+}
+
+// components infers the components of the request and response bodies of a resource from the examples seen for them
+func (l *loader) components(res *types.Resource) {
+	components := make(types.Components, 0)
+	s := l.samples[res]
+
+	for i, req := range res.Requests {
+		examples := s.requests[req]
+		if len(examples) <= 0 {
+			continue
+		}
+
+		rawName := res.Name + "Request"
+		if i > 0 {
+			rawName += types.ToCamelCase(strings.ToLower(req.Type), true)
+		}
+
+		req.Schema = l.infer(rawName, examples, types.SourceRequestBodyInline)
+		if nil != req.Schema {
+			components = append(components, req.Schema)
+		}
+	}
+
+	for _, resp := range res.Responses {
+		for i, rb := range resp.ResponseBodies {
+			examples := s.bodies[rb]
+			if len(examples) <= 0 {
+				continue
+			}
+
+			rawName := res.Name + types.ToCamelCase(resp.Status, true) + "Response"
+			if i > 0 {
+				rawName += types.ToCamelCase(strings.ToLower(types.ContentKind(rb.MediaType)), true)
+			}
+
+			rb.Schema = l.infer(rawName, examples, types.SourceResponseBodyInline)
+			if nil != rb.Schema {
+				components = append(components, rb.Schema)
+			}
+		}
+	}
+
+	res.Components = &components
+}
+
+// infer creates the component for the examples of a body, merging all of them in to a single shape
+func (l *loader) infer(rawName string, examples []any, source types.ComponentSource) *types.Component {
+	comp, err := l.response.Components.InferComponent(loaders.ComponentName(rawName), rawName, l.version, source, examples...)
+	if nil != err {
+		l.response.Diagnostics.Warn("postman-schema", l.source, "problem inferring %s: %s", rawName, err.Error())
+		return nil
+	}
+
+	comp.SourceDoc = l.source
+	return comp
+}
+
+// references returns the names of the variables a request uses in its url, headers and body
+func (l *loader) references(req *request) []string {
+	text := []string{req.URL.Raw, l.host(req.URL)}
+	for _, kv := range req.Header {
+		if nil != kv {
+			text = append(text, loaders.Example(kv.Value))
+		}
+	}
+
+	if nil != req.Body {
+		text = append(text, req.Body.Raw)
+		for _, kv := range append(append([]*keyValue{}, req.Body.URLEncoded...), req.Body.FormData...) {
+			if nil != kv {
+				text = append(text, loaders.Example(kv.Value))
+			}
+		}
+	}
+
+	names := make([]string, 0)
+	for _, match := range variableReference.FindAllStringSubmatch(strings.Join(text, "\n"), -1) {
+		name := strings.TrimSpace(match[1])
+		// {{$guid}} and the like are dynamic variables Postman generates itself
+		if !strings.HasPrefix(name, "$") {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// languageContentType returns the content type of the language Postman uses to show a raw body
+func languageContentType(language string) string {
+	switch strings.ToLower(language) {
+	case "json":
+		return "application/json"
+	case "xml":
+		return "application/xml"
+	case "html":
+		return "text/html"
+	case "javascript":
+		return "application/javascript"
+	case "text":
+		return "text/plain"
+	default:
+		return ""
+	}
+}
+
+// version returns the version of a collection, which is either a string or a {major, minor, patch} object
+func version(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case map[string]any:
+		return fmt.Sprintf("%v.%v.%v", t["major"], t["minor"], t["patch"])
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -0,0 +1,131 @@
+package postman
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		header string
+		want   int
+	}{
+		{"a 2.1 collection", "pets.json", `{"info": {"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"}}`, types.ConfidenceCertain},
+		{"an environment", "dev.json", `{"id": "x", "_postman_variable_scope": "environment"}`, types.ConfidenceCertain},
+		{"an environment file", "dev.postman_environment.json", `{"id": "x"}`, types.ConfidenceCertain},
+		{"another collection version", "pets.json", `{"info": {"schema": "https://schema.getpostman.com/json/collection/v2.0.0/collection.json"}}`, types.ConfidenceMedium},
+		{"any other json", "pets.json", `{"info": {"name": "Pets"}}`, types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe(tt.source, []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "pets.postman_collection.json")
+
+	// List pets again is the same request as List pets
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Code != types.CodeMergedResource {
+		t.Errorf("diagnostics = %v, want the merged request", resp.Diagnostics)
+	}
+
+	components := loadertest.Names(resp.Components)
+	if want := []string{"CreatePetRequest", "GetPet200Response", "ListPets200Response"}; !reflect.DeepEqual(components, want) {
+		t.Errorf("components = %v, want %v", components, want)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		parameters []string // name and in
+		children   []string
+		responses  []string // status and schema
+	}{
+		{"pets", "folder", "/Pets", []string{}, []string{"listPets", "getPet", "createPet"}, []string{}},
+		{"listPets", "get", "/pets", []string{"limit query", "X-Api-Key header", "offset query"}, []string{}, []string{"200 ListPets200Response"}},
+		{"getPet", "get", "/pets/{petId}", []string{"petId path"}, []string{}, []string{"200 GetPet200Response"}},
+		{"createPet", "post", "/pets", []string{}, []string{}, []string{}},
+		{"again", "folder", "/Again", []string{}, []string{"listPets"}, []string{}},
+	}
+
+	if len(resp.Resources) != len(tests) {
+		t.Fatalf("%d resources, want %d", len(resp.Resources), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resp.Resources[i]
+			if res.Name != tt.name || res.Method != tt.method || res.Path != tt.path || res.Owner != "Pets API" {
+				t.Errorf("the resource is %s %s %s of %s", res.Name, res.Method, res.Path, res.Owner)
+			}
+
+			parameters := make([]string, 0)
+			for _, p := range res.Parameters {
+				parameters = append(parameters, p.Name+" "+string(p.In))
+			}
+			if !reflect.DeepEqual(parameters, tt.parameters) {
+				t.Errorf("parameters = %v, want %v", parameters, tt.parameters)
+			}
+
+			children := make([]string, 0)
+			for _, child := range res.Resources {
+				children = append(children, child.Name)
+			}
+			if !reflect.DeepEqual(children, tt.children) {
+				t.Errorf("children = %v, want %v", children, tt.children)
+			}
+
+			responses := make([]string, 0)
+			for _, r := range res.Responses {
+				for _, body := range r.ResponseBodies {
+					responses = append(responses, r.Status+" "+body.Schema.Name)
+				}
+			}
+			if !reflect.DeepEqual(responses, tt.responses) {
+				t.Errorf("responses = %v, want %v", responses, tt.responses)
+			}
+		})
+	}
+}
+
+func TestLoadEnvironment(t *testing.T) {
+	env := loadertest.Load(t, Loader{}, "dev.postman_environment.json")
+
+	want := map[string]string{"apiKey": "secret", "baseUrl": "http://localhost"}
+	if !reflect.DeepEqual(env.Variables, want) || len(env.Resources) > 0 {
+		t.Fatalf("variables = %v, want %v and no resources", env.Variables, want)
+	}
+
+	model := types.NewLoadedResponse()
+	model.Merge(env)
+	model.Merge(loadertest.Load(t, Loader{}, "pets.postman_collection.json"))
+
+	list := model.Resources.FindResourceByName("listPets")
+	if list.Variables["apiKey"] != "secret" || list.Variables["baseUrl"] != "http://localhost" {
+		t.Errorf("the variables of listPets are %v, want the values of the environment", list.Variables)
+	}
+
+	for _, p := range list.Parameters {
+		if p.Name == "X-Api-Key" && p.VariableNameValue != "apiKey" {
+			t.Errorf("the X-Api-Key header is not set from the apiKey variable")
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", `{"item": [`, `"a string"`} {
+		if _, err := (Loader{}).Load("pets.json", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
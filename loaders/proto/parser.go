@@ -0,0 +1,747 @@
+package proto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+
+	// the comments right before the token, which document the element the token starts
+	comment string
+}
+
+// lex splits a .proto file in to tokens. Comments are not tokens, they are attached to the token that follows them.
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0, len(src)/4)
+	runes := []rune(src)
+	line := 1
+	comments := make([]string, 0)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			start := i + 2
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			comments = append(comments, strings.TrimSpace(string(runes[start:i])))
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start := i + 2
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("line %d: comment is not closed", line)
+			}
+			comments = append(comments, blockComment(string(runes[start:i])))
+			i += 2
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' {
+					i++
+				}
+				if i < len(runes) && runes[i] == '\n' {
+					return nil, fmt.Errorf("line %d: string is not closed", line)
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("line %d: string is not closed", line)
+			}
+			i++
+			value, err := strconv.Unquote(`"` + strings.ReplaceAll(string(runes[start+1:i-1]), `"`, `\"`) + `"`)
+			if nil != err {
+				value = string(runes[start+1 : i-1])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, line: line, comment: strings.Join(comments, "\n")})
+			comments = comments[:0]
+		case isIdentStart(r) || (r == '.' && i+1 < len(runes) && isIdentStart(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (isIdentStart(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), line: line, comment: strings.Join(comments, "\n")})
+			comments = comments[:0]
+		case unicode.IsDigit(r) || ((r == '-' || r == '+' || r == '.') && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), line: line, comment: strings.Join(comments, "\n")})
+			comments = comments[:0]
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(r), line: line, comment: strings.Join(comments, "\n")})
+			comments = comments[:0]
+			i++
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// blockComment removes the leading * of every line of a /* */ comment
+func blockComment(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimSpace(l), "*")
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+type protoFile struct {
+	syntax   string
+	pkg      string
+	imports  []string
+	messages []*message
+	enums    []*enum
+	services []*service
+}
+
+type message struct {
+	name     string
+	comment  string
+	line     int
+	fields   []*field
+	oneofs   []*oneof
+	messages []*message
+	enums    []*enum
+	options  map[string]string
+}
+
+type field struct {
+	name    string
+	comment string
+	line    int
+	label   string // repeated, optional or required (proto2)
+	typ     string
+	keyType string // set for map fields, typ is then the value type
+	number  string
+	options map[string]string
+}
+
+type oneof struct {
+	name    string
+	comment string
+	fields  []*field
+}
+
+type enum struct {
+	name    string
+	comment string
+	line    int
+	values  []*enumValue
+}
+
+type enumValue struct {
+	name    string
+	comment string
+	number  string
+}
+
+type service struct {
+	name    string
+	comment string
+	line    int
+	rpcs    []*rpc
+}
+
+type rpc struct {
+	name            string
+	comment         string
+	line            int
+	input           string
+	output          string
+	clientStreaming bool
+	serverStreaming bool
+	options         map[string]string
+}
+
+// parser is a recursive descent parser for the parts of the protobuf language the loader needs
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses a .proto file, proto2 files are parsed as far as they share the proto3 syntax
+func parse(src string) (*protoFile, error) {
+	tokens, err := lex(src)
+	if nil != err {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	f := &protoFile{syntax: "proto2"}
+
+	for p.peek().kind != tokenEOF {
+		t := p.next()
+
+		switch t.text {
+		case "syntax", "edition":
+			if err = p.expect("="); nil != err {
+				return nil, err
+			}
+			value := p.next()
+			f.syntax = value.text
+			if err = p.expect(";"); nil != err {
+				return nil, err
+			}
+		case "package":
+			f.pkg = p.next().text
+			if err = p.expect(";"); nil != err {
+				return nil, err
+			}
+		case "import":
+			if p.peek().text == "public" || p.peek().text == "weak" {
+				p.next()
+			}
+			imp := p.next()
+			if imp.kind != tokenString {
+				return nil, p.errorf(imp, "an import path")
+			}
+			f.imports = append(f.imports, imp.text)
+			if err = p.expect(";"); nil != err {
+				return nil, err
+			}
+		case "option":
+			if _, _, err = p.option(); nil != err {
+				return nil, err
+			}
+		case "message":
+			m, err := p.message(t)
+			if nil != err {
+				return nil, err
+			}
+			f.messages = append(f.messages, m)
+		case "enum":
+			e, err := p.enum(t)
+			if nil != err {
+				return nil, err
+			}
+			f.enums = append(f.enums, e)
+		case "service":
+			s, err := p.service(t)
+			if nil != err {
+				return nil, err
+			}
+			f.services = append(f.services, s)
+		case "extend":
+			p.next()
+			if err = p.skipBlock(); nil != err {
+				return nil, err
+			}
+		case ";":
+		default:
+			return nil, p.errorf(t, "a top level definition")
+		}
+	}
+
+	return f, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.text != text {
+		return p.errorf(t, fmt.Sprintf("%q", text))
+	}
+
+	return nil
+}
+
+func (p *parser) errorf(t token, expected string) error {
+	found := t.text
+	if t.kind == tokenEOF {
+		found = "end of file"
+	}
+
+	return fmt.Errorf("line %d: expected %s but found %q", t.line, expected, found)
+}
+
+// skipBlock skips a { } block including any nested blocks
+func (p *parser) skipBlock() error {
+	if err := p.expect("{"); nil != err {
+		return err
+	}
+
+	for depth := 1; depth > 0; {
+		switch t := p.next(); {
+		case t.kind == tokenEOF:
+			return p.errorf(t, `"}"`)
+		case t.text == "{":
+			depth++
+		case t.text == "}":
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// skipStatement skips everything up to and including the next ;
+func (p *parser) skipStatement() error {
+	for {
+		switch t := p.next(); {
+		case t.kind == tokenEOF:
+			return p.errorf(t, `";"`)
+		case t.text == ";":
+			return nil
+		}
+	}
+}
+
+// optionName parses an option name, e.g. deprecated, (my.option) or (my.option).field
+func (p *parser) optionName() (string, error) {
+	var b strings.Builder
+
+	for {
+		t := p.next()
+		switch {
+		case t.text == "(":
+			name := p.next()
+			b.WriteString("(" + name.text + ")")
+			if err := p.expect(")"); nil != err {
+				return "", err
+			}
+		case t.kind == tokenIdent:
+			b.WriteString(t.text)
+		default:
+			return "", p.errorf(t, "an option name")
+		}
+
+		if next := p.peek(); next.kind == tokenIdent && strings.HasPrefix(next.text, ".") {
+			continue
+		}
+
+		return b.String(), nil
+	}
+}
+
+// constant parses an option value. Aggregate values ({ ... }) are skipped and returned as an empty string.
+func (p *parser) constant() (string, error) {
+	if p.peek().text == "{" {
+		return "", p.skipBlock()
+	}
+
+	t := p.next()
+	if t.kind == tokenSymbol && (t.text == "-" || t.text == "+") {
+		return t.text + p.next().text, nil
+	}
+
+	if t.kind == tokenEOF || t.kind == tokenSymbol {
+		return "", p.errorf(t, "a constant")
+	}
+
+	return t.text, nil
+}
+
+// option parses the rest of an option statement, after the option keyword
+func (p *parser) option() (string, string, error) {
+	name, err := p.optionName()
+	if nil != err {
+		return "", "", err
+	}
+
+	if err = p.expect("="); nil != err {
+		return "", "", err
+	}
+
+	value, err := p.constant()
+	if nil != err {
+		return "", "", err
+	}
+
+	return name, value, p.expect(";")
+}
+
+// fieldOptions parses the [name = value, ...] options of a field or enum value if there are any
+func (p *parser) fieldOptions() (map[string]string, error) {
+	options := make(map[string]string)
+	if p.peek().text != "[" {
+		return options, nil
+	}
+
+	p.next()
+	for {
+		name, err := p.optionName()
+		if nil != err {
+			return nil, err
+		}
+
+		if err = p.expect("="); nil != err {
+			return nil, err
+		}
+
+		value, err := p.constant()
+		if nil != err {
+			return nil, err
+		}
+
+		options[name] = value
+
+		t := p.next()
+		if t.text == "]" {
+			return options, nil
+		}
+
+		if t.text != "," {
+			return nil, p.errorf(t, `"," or "]"`)
+		}
+	}
+}
+
+func (p *parser) message(start token) (*message, error) {
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, p.errorf(name, "a message name")
+	}
+
+	m := &message{name: name.text, comment: start.comment, line: start.line, options: make(map[string]string)}
+	if err := p.expect("{"); nil != err {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+
+		switch t.text {
+		case "}":
+			p.next()
+			return m, nil
+		case ";":
+			p.next()
+		case "message":
+			p.next()
+			nested, err := p.message(t)
+			if nil != err {
+				return nil, err
+			}
+			m.messages = append(m.messages, nested)
+		case "enum":
+			p.next()
+			e, err := p.enum(t)
+			if nil != err {
+				return nil, err
+			}
+			m.enums = append(m.enums, e)
+		case "oneof":
+			p.next()
+			o, err := p.oneof(t)
+			if nil != err {
+				return nil, err
+			}
+			m.oneofs = append(m.oneofs, o)
+		case "option":
+			p.next()
+			name, value, err := p.option()
+			if nil != err {
+				return nil, err
+			}
+			m.options[name] = value
+		case "reserved", "extensions":
+			if err := p.skipStatement(); nil != err {
+				return nil, err
+			}
+		case "extend":
+			p.next()
+			p.next()
+			if err := p.skipBlock(); nil != err {
+				return nil, err
+			}
+		default:
+			if t.kind == tokenEOF {
+				return nil, p.errorf(t, `"}"`)
+			}
+
+			f, err := p.field()
+			if nil != err {
+				return nil, err
+			}
+			m.fields = append(m.fields, f)
+		}
+	}
+}
+
+func (p *parser) field() (*field, error) {
+	start := p.peek()
+	f := &field{comment: start.comment, line: start.line}
+
+	if t := p.peek(); t.text == "repeated" || t.text == "optional" || t.text == "required" {
+		// a field may be named like a label, e.g. "string optional = 1", so only a label if a type follows
+		if next := p.tokens[p.pos+1]; next.kind == tokenIdent {
+			f.label = p.next().text
+		}
+	}
+
+	typ := p.next()
+	if typ.kind != tokenIdent {
+		return nil, p.errorf(typ, "a field type")
+	}
+
+	f.typ = typ.text
+	if typ.text == "map" && p.peek().text == "<" {
+		p.next()
+		f.keyType = p.next().text
+		if err := p.expect(","); nil != err {
+			return nil, err
+		}
+		f.typ = p.next().text
+		if err := p.expect(">"); nil != err {
+			return nil, err
+		}
+	}
+
+	if typ.text == "group" {
+		return nil, p.errorf(typ, "a field type, proto2 groups are not supported")
+	}
+
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, p.errorf(name, "a field name")
+	}
+	f.name = name.text
+
+	if err := p.expect("="); nil != err {
+		return nil, err
+	}
+	f.number = p.next().text
+
+	options, err := p.fieldOptions()
+	if nil != err {
+		return nil, err
+	}
+	f.options = options
+
+	return f, p.expect(";")
+}
+
+func (p *parser) oneof(start token) (*oneof, error) {
+	name := p.next()
+	o := &oneof{name: name.text, comment: start.comment}
+
+	if err := p.expect("{"); nil != err {
+		return nil, err
+	}
+
+	for {
+		switch t := p.peek(); t.text {
+		case "}":
+			p.next()
+			return o, nil
+		case ";":
+			p.next()
+		case "option":
+			p.next()
+			if _, _, err := p.option(); nil != err {
+				return nil, err
+			}
+		default:
+			if t.kind == tokenEOF {
+				return nil, p.errorf(t, `"}"`)
+			}
+
+			f, err := p.field()
+			if nil != err {
+				return nil, err
+			}
+			o.fields = append(o.fields, f)
+		}
+	}
+}
+
+func (p *parser) enum(start token) (*enum, error) {
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, p.errorf(name, "an enum name")
+	}
+
+	e := &enum{name: name.text, comment: start.comment, line: start.line}
+	if err := p.expect("{"); nil != err {
+		return nil, err
+	}
+
+	for {
+		t := p.next()
+
+		switch {
+		case t.text == "}":
+			return e, nil
+		case t.text == ";":
+		case t.text == "option":
+			if _, _, err := p.option(); nil != err {
+				return nil, err
+			}
+		case t.text == "reserved":
+			if err := p.skipStatement(); nil != err {
+				return nil, err
+			}
+		case t.kind == tokenIdent:
+			if err := p.expect("="); nil != err {
+				return nil, err
+			}
+
+			v := &enumValue{name: t.text, comment: t.comment, number: p.next().text}
+			if v.number == "-" {
+				v.number += p.next().text
+			}
+
+			if _, err := p.fieldOptions(); nil != err {
+				return nil, err
+			}
+
+			if err := p.expect(";"); nil != err {
+				return nil, err
+			}
+
+			e.values = append(e.values, v)
+		default:
+			return nil, p.errorf(t, "an enum value")
+		}
+	}
+}
+
+func (p *parser) service(start token) (*service, error) {
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, p.errorf(name, "a service name")
+	}
+
+	s := &service{name: name.text, comment: start.comment, line: start.line}
+	if err := p.expect("{"); nil != err {
+		return nil, err
+	}
+
+	for {
+		t := p.next()
+
+		switch t.text {
+		case "}":
+			return s, nil
+		case ";":
+		case "option":
+			if _, _, err := p.option(); nil != err {
+				return nil, err
+			}
+		case "rpc":
+			r, err := p.rpc(t)
+			if nil != err {
+				return nil, err
+			}
+			s.rpcs = append(s.rpcs, r)
+		default:
+			return nil, p.errorf(t, "an rpc")
+		}
+	}
+}
+
+func (p *parser) rpc(start token) (*rpc, error) {
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, p.errorf(name, "an rpc name")
+	}
+
+	r := &rpc{name: name.text, comment: start.comment, line: start.line, options: make(map[string]string)}
+
+	messageType := func() (string, bool, error) {
+		if err := p.expect("("); nil != err {
+			return "", false, err
+		}
+
+		streaming := false
+		if p.peek().text == "stream" && p.tokens[p.pos+1].text != ")" {
+			p.next()
+			streaming = true
+		}
+
+		typ := p.next()
+		if typ.kind != tokenIdent {
+			return "", false, p.errorf(typ, "a message type")
+		}
+
+		return typ.text, streaming, p.expect(")")
+	}
+
+	var err error
+	if r.input, r.clientStreaming, err = messageType(); nil != err {
+		return nil, err
+	}
+
+	if err = p.expect("returns"); nil != err {
+		return nil, err
+	}
+
+	if r.output, r.serverStreaming, err = messageType(); nil != err {
+		return nil, err
+	}
+
+	switch t := p.next(); t.text {
+	case ";":
+		return r, nil
+	case "{":
+		for {
+			switch t := p.next(); t.text {
+			case "}":
+				return r, nil
+			case ";":
+			case "option":
+				name, value, err := p.option()
+				if nil != err {
+					return nil, err
+				}
+				r.options[name] = value
+			default:
+				return nil, p.errorf(t, `"option" or "}"`)
+			}
+		}
+	default:
+		return nil, p.errorf(t, `";" or "{"`)
+	}
+}
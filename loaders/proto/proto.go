@@ -0,0 +1,452 @@
+// Package proto is the built in loader for protobuf (.proto) files. Every rpc of a service becomes a GRPC Resource with
+// the gRPC path (/package.Service/Method) as Path, and messages and enums become defined Components. The streaming mode of
+// an rpc is kept in Resource.Variables under "streaming" as unary, client, server or bidi.
+//
+// The properties of a message are named after the JSON mapping of its fields, their json_name or otherwise their name in
+// lowerCamelCase. The members of a oneof are optional (null) properties of the message, next to its other fields.
+//
+// Files a .proto file imports are returned as LoadedResponse.Imports so the pipeline loads them as well, the types they
+// define are then linked when the sources are merged. The google/protobuf well known types are mapped to their JSON
+// representation instead.
+package proto
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "proto"
+
+// ContentType is the content type of the Requests and ResponseBodies of the resources created by this loader
+const ContentType = "application/grpc"
+
+const (
+	Unary           = "unary"
+	ClientStreaming = "client"
+	ServerStreaming = "server"
+	BidiStreaming   = "bidi"
+)
+
+// typeRef is the prefix of the $refs the loader uses between the schemas it builds for messages and enums
+const typeRef = "#/types/"
+
+var syntax = regexp.MustCompile(`syntax\s*=\s*["'](proto[23])["']`)
+
+// packageVersion matches the version segment packages commonly end with, e.g. acme.users.v1
+var packageVersion = regexp.MustCompile(`^v\d+(?:(?:alpha|beta)\d*)?$`)
+
+// scalars maps the protobuf scalar types to schemas, following the protobuf JSON mapping except for 64 bit integers
+// which are kept as numbers
+var scalars = map[string]loaders.Schema{
+	"double":   {Type: "number", Format: "double"},
+	"float":    {Type: "number", Format: "float"},
+	"int32":    {Type: "integer", Format: "int32"},
+	"sint32":   {Type: "integer", Format: "int32"},
+	"sfixed32": {Type: "integer", Format: "int32"},
+	"uint32":   {Type: "integer", Format: "int64"},
+	"fixed32":  {Type: "integer", Format: "int64"},
+	"int64":    {Type: "integer", Format: "int64"},
+	"sint64":   {Type: "integer", Format: "int64"},
+	"sfixed64": {Type: "integer", Format: "int64"},
+	"uint64":   {Type: "integer", Format: "int64"},
+	"fixed64":  {Type: "integer", Format: "int64"},
+	"bool":     {Type: "boolean"},
+	"string":   {Type: "string"},
+	"bytes":    {Type: "string", Format: "byte"},
+}
+
+// wellKnown maps the google/protobuf well known types to the schema of their JSON representation
+var wellKnown = map[string]loaders.Schema{
+	"google.protobuf.Timestamp":   {Type: "string", Format: "date-time"},
+	"google.protobuf.Duration":    {Type: "string", Format: "duration"},
+	"google.protobuf.FieldMask":   {Type: "string"},
+	"google.protobuf.Empty":       {Type: "object"},
+	"google.protobuf.Struct":      {Type: "object"},
+	"google.protobuf.Any":         {Type: "object"},
+	"google.protobuf.Value":       {},
+	"google.protobuf.ListValue":   {Type: "array", Items: &loaders.Schema{}},
+	"google.protobuf.DoubleValue": {Type: "number", Format: "double", Nullable: true},
+	"google.protobuf.FloatValue":  {Type: "number", Format: "float", Nullable: true},
+	"google.protobuf.Int64Value":  {Type: "integer", Format: "int64", Nullable: true},
+	"google.protobuf.UInt64Value": {Type: "integer", Format: "int64", Nullable: true},
+	"google.protobuf.Int32Value":  {Type: "integer", Format: "int32", Nullable: true},
+	"google.protobuf.UInt32Value": {Type: "integer", Format: "int64", Nullable: true},
+	"google.protobuf.BoolValue":   {Type: "boolean", Nullable: true},
+	"google.protobuf.StringValue": {Type: "string", Nullable: true},
+	"google.protobuf.BytesValue":  {Type: "string", Format: "byte", Nullable: true},
+}
+
+// Loader implements pipeline.Loader for protobuf files
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	match := syntax.FindSubmatch(header)
+
+	switch {
+	case nil != match && string(match[1]) == "proto3":
+		return types.ConfidenceCertain
+	case nil != match:
+		return types.ConfidenceMedium
+	case loaders.HasExtension(source, ".proto"):
+		// syntax is optional (and then means proto2), but a .proto file is hard to mistake
+		return types.ConfidenceHigh
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	file, err := parse(string(data))
+	if nil != err {
+		return nil, fmt.Errorf("%s is not a valid proto file: %w", source, err)
+	}
+
+	l := &loader{
+		file:     file,
+		source:   source,
+		response: types.NewLoadedResponse(),
+		schemas:  make(map[string]*loaders.Schema),
+		names:    make([]string, 0),
+		lines:    make(map[string]int),
+	}
+
+	if file.syntax != "proto3" {
+		l.response.Diagnostics.Warn("proto-syntax", source, "%s is not supported, loading it as proto3", file.syntax)
+	}
+
+	// the version of an api is usually the last segment of its package
+	segments := strings.Split(file.pkg, ".")
+	if last := segments[len(segments)-1]; packageVersion.MatchString(last) {
+		l.version = last
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Version:     l.version,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	file      *protoFile
+	source    string
+	version   string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+
+	// the schemas of every message and enum of the file, keyed on their name within the package (e.g. Outer.Inner)
+	schemas map[string]*loaders.Schema
+	names   []string
+	lines   map[string]int
+}
+
+func (l *loader) load() {
+	for _, imp := range l.file.imports {
+		if !strings.HasPrefix(imp, "google/protobuf/") {
+			l.response.Imports = append(l.response.Imports, imp)
+		}
+	}
+
+	// every name has to be known before fields can be resolved against them
+	l.declare("", l.file.messages, l.file.enums)
+
+	for _, m := range l.file.messages {
+		l.message("", m)
+	}
+
+	for _, e := range l.file.enums {
+		l.enum("", e)
+	}
+
+	for _, name := range l.names {
+		if _, err := l.converter.Define(name, l.schemas[name]); nil != err {
+			l.response.Diagnostics.Warn("proto-schema", l.source, "problem loading %s: %s", name, err.Error()).Location = &types.Location{Line: l.lines[name]}
+		}
+	}
+
+	for _, s := range l.file.services {
+		for _, r := range s.rpcs {
+			l.resource(s, r)
+		}
+	}
+}
+
+func (l *loader) declare(scope string, messages []*message, enums []*enum) {
+	for _, m := range messages {
+		name := qualify(scope, m.name)
+		l.schemas[name] = &loaders.Schema{}
+		l.names = append(l.names, name)
+		l.lines[name] = m.line
+		l.declare(name, m.messages, m.enums)
+	}
+
+	for _, e := range enums {
+		name := qualify(scope, e.name)
+		l.schemas[name] = &loaders.Schema{}
+		l.names = append(l.names, name)
+		l.lines[name] = e.line
+	}
+}
+
+func (l *loader) message(scope string, m *message) {
+	name := qualify(scope, m.name)
+	schema := l.schemas[name]
+	schema.Type = "object"
+	schema.Description = m.comment
+	schema.Deprecated = m.options["deprecated"] == "true"
+	schema.Properties = make(map[string]*loaders.Schema, len(m.fields)+len(m.oneofs))
+
+	for _, f := range m.fields {
+		schema.Properties[jsonName(f)] = l.field(name, f)
+		if f.label == "required" {
+			schema.Required = append(schema.Required, jsonName(f))
+		}
+	}
+
+	// the members of a oneof are fields of the message as well, only one of which is set
+	for _, o := range m.oneofs {
+		for _, f := range o.fields {
+			member := l.field(name, f)
+			member.Nullable = true
+			schema.Properties[jsonName(f)] = member
+		}
+	}
+
+	for _, nested := range m.messages {
+		l.message(name, nested)
+	}
+
+	for _, e := range m.enums {
+		l.enum(name, e)
+	}
+}
+
+func (l *loader) enum(scope string, e *enum) {
+	schema := l.schemas[qualify(scope, e.name)]
+	schema.Type = "string"
+	schema.Description = e.comment
+
+	for _, v := range e.values {
+		schema.Enum = append(schema.Enum, v.name)
+	}
+}
+
+func (l *loader) field(scope string, f *field) *loaders.Schema {
+	schema := l.typeSchema(scope, f.typ)
+
+	switch {
+	case len(f.keyType) > 0:
+		schema = &loaders.Schema{Type: "object", AdditionalProperties: schema}
+	case f.label == "repeated":
+		schema = &loaders.Schema{Type: "array", Items: schema}
+	case f.label == "optional":
+		// proto3 optional fields track presence, so they can be told apart from a zero value
+		flat := *schema
+		flat.Nullable = true
+		schema = &flat
+	}
+
+	if len(f.comment) > 0 || f.options["deprecated"] == "true" {
+		described := *schema
+		described.Description = f.comment
+		described.Deprecated = f.options["deprecated"] == "true"
+		schema = &described
+	}
+
+	return schema
+}
+
+// jsonName returns the name of a field in the JSON mapping of protobuf: its json_name option, or otherwise its name in
+// lowerCamelCase (each underscore dropped and the letter following it upper cased)
+func jsonName(f *field) string {
+	if name := f.options["json_name"]; len(name) > 0 {
+		return name
+	}
+
+	var b strings.Builder
+	upper := false
+	for _, r := range f.name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// typeSchema returns the schema of a field type. Messages and enums are $refs to their schemas, types that are not
+// defined in this file (imported) are $refs as well and are linked once the imported file is loaded.
+func (l *loader) typeSchema(scope, typ string) *loaders.Schema {
+	if s, ok := scalars[typ]; ok {
+		return &s
+	}
+
+	if s, ok := wellKnown[strings.TrimPrefix(typ, ".")]; ok {
+		return &s
+	}
+
+	return &loaders.Schema{Ref: typeRef + l.resolve(scope, typ)}
+}
+
+// resolve
+//
+// This method returns the name within the package of the message or enum a type refers to, following the protobuf scoping
+// rules: the innermost scope is searched first. Types that are not defined in this file are assumed to be imported and
+// their name without the package is returned.
+func (l *loader) resolve(scope, typ string) string {
+	pkg := l.file.pkg + "."
+
+	if strings.HasPrefix(typ, ".") {
+		typ = strings.TrimPrefix(typ, ".")
+		if strings.HasPrefix(typ, pkg) {
+			if _, ok := l.schemas[strings.TrimPrefix(typ, pkg)]; ok {
+				return strings.TrimPrefix(typ, pkg)
+			}
+		}
+	} else {
+		for s := scope; ; {
+			if _, ok := l.schemas[qualify(s, typ)]; ok {
+				return qualify(s, typ)
+			}
+
+			if len(s) <= 0 {
+				break
+			}
+
+			if indx := strings.LastIndex(s, "."); indx >= 0 {
+				s = s[:indx]
+			} else {
+				s = ""
+			}
+		}
+
+		if _, ok := l.schemas[strings.TrimPrefix(typ, pkg)]; ok && strings.HasPrefix(typ, pkg) {
+			return strings.TrimPrefix(typ, pkg)
+		}
+	}
+
+	// imported.. packages are lower case by convention, so the type name starts at the first upper case segment
+	segments := strings.Split(typ, ".")
+	for i, s := range segments {
+		if len(s) > 0 && strings.ToUpper(s[:1]) == s[:1] {
+			return strings.Join(segments[i:], ".")
+		}
+	}
+
+	return segments[len(segments)-1]
+}
+
+func (l *loader) resource(s *service, r *rpc) {
+	service := qualify(l.file.pkg, s.name)
+	pth := "/" + service + "/" + r.name
+
+	description := r.comment
+	if len(description) <= 0 {
+		description = s.comment
+	}
+
+	owner := l.file.pkg
+	if len(owner) <= 0 {
+		owner = l.source
+	}
+
+	res, err := l.response.Resources.NewResource(pth, "post", types.MakeResourceName(r.name, "", "", ""), description, "", Source, l.version, owner, r.options["deprecated"] == "true", true, types.GRPC)
+	if nil != err {
+		l.response.Diagnostics.Warn("proto-rpc", l.source, "problem loading rpc %s: %s", r.name, err.Error()).Location = &types.Location{Line: r.line}
+		return
+	}
+
+	res.SourceDoc = l.source
+	res.Variables["package"] = l.file.pkg
+	res.Variables["service"] = s.name
+	res.Variables["rpc"] = r.name
+
+	switch {
+	case r.clientStreaming && r.serverStreaming:
+		res.Variables["streaming"] = BidiStreaming
+	case r.clientStreaming:
+		res.Variables["streaming"] = ClientStreaming
+	case r.serverStreaming:
+		res.Variables["streaming"] = ServerStreaming
+	default:
+		res.Variables["streaming"] = Unary
+	}
+
+	components := make(types.Components, 0)
+
+	input, inputRef := l.messageComponent(r.input)
+	res.Requests = append(res.Requests, &types.Request{
+		Required:    true,
+		ContentType: ContentType,
+		Type:        types.ContentKind(ContentType),
+		Default:     true,
+		Ref:         inputRef,
+		Schema:      input,
+	})
+
+	output, outputRef := l.messageComponent(r.output)
+	res.Responses = append(res.Responses, &types.Response{
+		Status:      "OK",
+		Description: strings.TrimPrefix(r.output, "."),
+		ResponseBodies: types.ResponseBodies{{
+			MediaType: ContentType,
+			Ref:       outputRef,
+			Default:   true,
+			Schema:    output,
+		}},
+	})
+
+	for _, comp := range []*types.Component{input, output} {
+		if nil != comp && nil == components.FindComponentById(comp.Id) {
+			components = append(components, comp)
+		}
+	}
+
+	res.Components = &components
+}
+
+// messageComponent returns the component of the input or output type of an rpc, along with the name of the component
+// as Ref. Imported types have no component yet, the Ref is used to link them once every source is loaded.
+func (l *loader) messageComponent(typ string) (*types.Component, string) {
+	if s, ok := wellKnown[strings.TrimPrefix(typ, ".")]; ok {
+		comp, _, err := l.converter.Inline(strings.TrimPrefix(typ, "."), &s, types.SourceInline)
+		if nil != err {
+			return nil, strings.TrimPrefix(typ, ".")
+		}
+
+		return comp, strings.TrimPrefix(typ, ".")
+	}
+
+	name := l.resolve("", typ)
+	return l.converter.Defined(name), l.converter.Name(name)
+}
+
+// resolveSchema resolves the $refs between the schemas of messages and enums
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	name := strings.TrimPrefix(ref, typeRef)
+	return name, l.schemas[name]
+}
+
+func qualify(scope, name string) string {
+	if len(scope) <= 0 {
+		return name
+	}
+
+	return scope + "." + name
+}
@@ -0,0 +1,152 @@
+package proto
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		header string
+		want   int
+	}{
+		{"proto3", "users.proto", "syntax = \"proto3\";\npackage acme;", types.ConfidenceCertain},
+		{"proto2", "users.txt", "syntax = 'proto2';", types.ConfidenceMedium},
+		{"no syntax", "users.proto", "package acme;\nmessage User {}", types.ConfidenceHigh},
+		{"anything else", "users.txt", "message User {}", types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe(tt.source, []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "acme/users/v1/users.proto")
+
+	if len(resp.Diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", resp.Diagnostics)
+	}
+
+	if want := []string{"acme/common/v1/money.proto"}; !reflect.DeepEqual(resp.Imports, want) {
+		t.Errorf("imports = %v, want %v, without the well known types", resp.Imports, want)
+	}
+
+	components := loadertest.Names(resp.Components)
+	if want := []string{"GetUserRequest", "User", "UserAddress", "UserStatus"}; !reflect.DeepEqual(components, want) {
+		t.Errorf("components = %v, want %v", components, want)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		deprecated bool
+		request    string
+	}{
+		{"getUser", "/acme.users.v1.UserService/GetUser", false, "GetUserRequest"},
+		{"watchUsers", "/acme.users.v1.UserService/WatchUsers", true, "GetUserRequest"},
+		{"chat", "/acme.users.v1.UserService/Chat", false, "User"},
+	}
+
+	if len(resp.Resources) != len(tests) {
+		t.Fatalf("%d resources, want %d", len(resp.Resources), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resp.Resources[i]
+			if res.Name != tt.name || res.Path != tt.path || res.Method != "post" || res.ResourceType != types.GRPC {
+				t.Errorf("the resource is %s %s %s of type %d", res.Name, res.Method, res.Path, res.ResourceType)
+			}
+
+			if res.Deprecated != tt.deprecated {
+				t.Errorf("deprecated = %t, want %t", res.Deprecated, tt.deprecated)
+			}
+
+			if len(res.Requests) != 1 || res.Requests[0].Schema.Name != tt.request {
+				t.Errorf("the request is not a %s", tt.request)
+			}
+		})
+	}
+}
+
+func TestLoadFields(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "acme/users/v1/users.proto")
+
+	user := resp.Components.FindComponentByName("User")
+	if nil == user || user.Description != "A user of the system" {
+		t.Fatalf("User is %+v, want it with its comment", user)
+	}
+
+	tests := []struct {
+		field string
+		typ   string
+		ref   string
+		null  bool
+	}{
+		{"address", "object", "UserAddress", false}, // a nested message
+		{"age", "number", "", true},                 // optional
+		{"createdAt", "string", "", false},          // a well known type, named in lowerCamelCase
+		{"emails", "array", "string", false},
+		{"labels", "object", "string", false},   // a map
+		{"mail", "object", "UserAddress", true}, // a member of the contact oneof
+		{"nickname", "string", "", false},       // json_name
+		{"phone", "string", "", true},           // a member of the contact oneof
+		{"status", "string", "UserStatus", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			prop := loadertest.Property(user, tt.field)
+			if nil == prop {
+				t.Fatalf("User has no %s field", tt.field)
+			}
+
+			if ref := loadertest.RefName(prop.Ref); prop.Type != tt.typ || ref != tt.ref {
+				t.Errorf("the field is a %s of %q, want a %s of %q", prop.Type, ref, tt.typ, tt.ref)
+			}
+
+			if null := nil != prop.Null && *prop.Null; null != tt.null {
+				t.Errorf("null = %t, want %t", null, tt.null)
+			}
+		})
+	}
+
+	for _, name := range []string{"contact", "created_at", "display_name"} {
+		if nil != loadertest.Property(user, name) {
+			t.Errorf("User has a %s property", name)
+		}
+	}
+}
+
+func TestLoadImported(t *testing.T) {
+	model := types.NewLoadedResponse()
+	model.Merge(loadertest.Load(t, Loader{}, "acme/users/v1/users.proto"))
+	model.Merge(loadertest.Load(t, Loader{}, "acme/common/v1/money.proto"))
+
+	money := model.Components.FindComponentByName("Money")
+	if nil == money {
+		t.Fatal("Money is not loaded")
+	}
+
+	chat := model.Resources.FindResourceByName("chat")
+	if body := chat.Responses[0].ResponseBodies[0]; body.Schema != money {
+		t.Errorf("the response of chat is not the Money of the imported file")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", "message { string id = 1; }", "service Users { rpc Get(A) returns B; }"} {
+		if _, err := (Loader{}).Load("users.proto", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
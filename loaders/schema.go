@@ -0,0 +1,655 @@
+package loaders
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spirefy/go-codegen/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a JSON Schema, including the OpenAPI and Swagger dialects of it, as far as converting it in to Components
+// needs. Loaders of formats that are not JSON Schema based (protobuf, GraphQL, XSD, ...) build Schema values themselves
+// so that all of them end up converting types the same way.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Id          string             `json:"$id,omitempty"`
+	Anchor      string             `json:"$anchor,omitempty"`
+	Type        any                `json:"type,omitempty"` // a string, or a list of strings in JSON Schema and OpenAPI 3.1
+	Format      string             `json:"format,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Const       any                `json:"const,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`   // OpenAPI 3.0
+	XNullable   bool               `json:"x-nullable,omitempty"` // Swagger 2.0 vendor extension
+	Required    []string           `json:"required,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"-"`
+	AllOf       []*Schema          `json:"allOf,omitempty"`
+	OneOf       []*Schema          `json:"oneOf,omitempty"`
+	AnyOf       []*Schema          `json:"anyOf,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Definitions map[string]*Schema `json:"definitions,omitempty"` // draft-07 and earlier
+	Example     any                `json:"example,omitempty"`
+	Examples    any                `json:"examples,omitempty"`
+	Default     any                `json:"default,omitempty"`
+	Deprecated  bool               `json:"deprecated,omitempty"`
+
+	// The schema of the values of a map (additionalProperties as a schema). AdditionalPropertiesAllowed is set when
+	// additionalProperties is a boolean instead.
+	AdditionalProperties        *Schema `json:"-"`
+	AdditionalPropertiesAllowed *bool   `json:"-"`
+
+	// The json this schema was decoded from
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON
+// Keeps the raw json of the schema, and decodes the fields that can either be a schema or something else
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type schema Schema
+	aux := struct {
+		*schema
+		Items                json.RawMessage `json:"items,omitempty"`
+		AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	}{schema: (*schema)(s)}
+
+	// a schema may also be a plain boolean in JSON Schema 2020-12.. true allows anything, false nothing
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "true" || trimmed == "false" {
+		s.Raw = append(json.RawMessage{}, data...)
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &aux); nil != err {
+		return err
+	}
+
+	if len(aux.Items) > 0 {
+		if aux.Items[0] == '[' {
+			// tuple form of items (draft-04 to draft-07).. use the first item type
+			tuple := make([]*Schema, 0)
+			if err := json.Unmarshal(aux.Items, &tuple); nil != err {
+				return err
+			}
+			if len(tuple) > 0 {
+				s.Items = tuple[0]
+			}
+		} else {
+			s.Items = &Schema{}
+			if err := json.Unmarshal(aux.Items, s.Items); nil != err {
+				return err
+			}
+		}
+	}
+
+	if len(aux.AdditionalProperties) > 0 {
+		switch strings.TrimSpace(string(aux.AdditionalProperties)) {
+		case "true":
+			s.AdditionalPropertiesAllowed = Bool(true)
+		case "false":
+			s.AdditionalPropertiesAllowed = Bool(false)
+		default:
+			s.AdditionalProperties = &Schema{}
+			if err := json.Unmarshal(aux.AdditionalProperties, s.AdditionalProperties); nil != err {
+				return err
+			}
+		}
+	}
+
+	s.Raw = append(json.RawMessage{}, data...)
+	return nil
+}
+
+// MarshalJSON
+// Writes the schema back out including the fields that are decoded by hand
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	aux := struct {
+		schema
+		Items                *Schema `json:"items,omitempty"`
+		AdditionalProperties any     `json:"additionalProperties,omitempty"`
+	}{schema: schema(s), Items: s.Items}
+
+	if nil != s.AdditionalProperties {
+		aux.AdditionalProperties = s.AdditionalProperties
+	} else if nil != s.AdditionalPropertiesAllowed {
+		aux.AdditionalProperties = *s.AdditionalPropertiesAllowed
+	}
+
+	return json.Marshal(aux)
+}
+
+// Types returns the type (or types) of the schema
+func (s *Schema) Types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if str, ok := v.(string); ok {
+				types = append(types, str)
+			}
+		}
+		return types
+	case []string:
+		return t
+	}
+
+	return nil
+}
+
+// ComponentName returns the name a Component should have for the provided raw (source document) name
+func ComponentName(rawName string) string {
+	return types.ToCamelCase(rawName, true)
+}
+
+// RefName
+//
+// This function returns the raw name of the definition a $ref points to, which is the last segment of its json pointer
+// (#/components/schemas/Pet is Pet) or the file name without extension for a ref to a whole document (pet.json is pet).
+func RefName(ref string) string {
+	if indx := strings.LastIndex(ref, "#"); indx >= 0 && indx < len(ref)-1 {
+		fragment := ref[indx+1:]
+		segment := fragment[strings.LastIndex(fragment, "/")+1:]
+		return strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+
+	file := strings.TrimSuffix(ref, "#")
+	file = file[strings.LastIndex(file, "/")+1:]
+	if indx := strings.Index(file, "."); indx > 0 {
+		file = file[:indx]
+	}
+
+	return file
+}
+
+// SchemaConverter
+//
+// This converts Schema values in to Components and Properties. All components it creates are added to Components and
+// get SourceDoc and Version set.
+//
+// Referenced schemas ($ref) are never inlined. A property (or array item, map value) referencing a schema gets the name of
+// the defined component as its Ref, a placeholder that is resolved once all components are loaded (see
+// types.Components.ResolveRef). For arrays and maps of primitive types, Ref holds the primitive type of the items/values.
+type SchemaConverter struct {
+	SourceDoc   string
+	Version     string
+	Components  *types.Components
+	Diagnostics *types.Diagnostics
+
+	// Resolve returns the raw name of the defined component a $ref points to and its schema. When nil, or when it
+	// returns a nil schema, RefName is used and the referenced type is assumed to be an object.
+	Resolve func(ref string) (string, *Schema)
+
+	// names holds the component name of every defined schema by raw name
+	names map[string]string
+}
+
+func (c *SchemaConverter) resolve(ref string) (string, *Schema) {
+	if nil != c.Resolve {
+		if name, schema := c.Resolve(ref); nil != schema {
+			return name, schema
+		}
+	}
+
+	return RefName(ref), nil
+}
+
+// Define
+//
+// This method creates a defined component (types.SourceComponent) from a named schema, e.g. one in
+// #/components/schemas. Define every named schema of a document before converting anything referencing them. Raw names
+// that only differ in case or separators (pet and Pet, pet_id and petId) would get the same component name, so a name
+// that is already taken by another schema of the source gets a number appended and is reported.
+func (c *SchemaConverter) Define(rawName string, schema *Schema) (*types.Component, error) {
+	if nil == c.names {
+		c.names = make(map[string]string)
+	}
+
+	name, ok := c.names[rawName]
+	if !ok {
+		taken := make(map[string]bool, len(c.names))
+		for _, n := range c.names {
+			taken[strings.ToLower(n)] = true
+		}
+
+		name = ComponentName(rawName)
+		for i := 2; taken[strings.ToLower(name)]; i++ {
+			name = ComponentName(rawName) + strconv.Itoa(i)
+		}
+
+		if name != ComponentName(rawName) && nil != c.Diagnostics {
+			c.Diagnostics.Warn("duplicate-schema-name", c.SourceDoc, "schema %s is named %s, the name %s is taken by another schema", rawName, name, ComponentName(rawName))
+		}
+		c.names[rawName] = name
+	}
+
+	return c.component(name, rawName, schema, types.SourceComponent)
+}
+
+// Name returns the component name of the defined schema with the provided raw name
+func (c *SchemaConverter) Name(rawName string) string {
+	if name, ok := c.names[rawName]; ok {
+		return name
+	}
+
+	return ComponentName(rawName)
+}
+
+// Inline
+//
+// This method returns the component for a schema used in place, e.g. a request body. If the schema is a $ref, the defined
+// component it references is returned along with the $ref itself, otherwise a new component with the provided source
+// (SourceInline, SourceRequestBodyInline, SourceResponseBodyInline or SourceParameter) is created.
+func (c *SchemaConverter) Inline(rawName string, schema *Schema, source types.ComponentSource) (*types.Component, string, error) {
+	if nil == schema {
+		return nil, "", nil
+	}
+
+	if len(schema.Ref) > 0 {
+		name, _ := c.resolve(schema.Ref)
+		comp := c.Defined(name)
+		if nil == comp && nil != c.Diagnostics {
+			c.Diagnostics.Warn("unresolved-ref", c.SourceDoc, "%s references %s which is not defined", rawName, schema.Ref)
+		}
+
+		return comp, schema.Ref, nil
+	}
+
+	comp, err := c.component(ComponentName(rawName), rawName, schema, source)
+	return comp, "", err
+}
+
+// Defined returns the defined component of this source with the provided raw name, or nil if there is none
+func (c *SchemaConverter) Defined(rawName string) *types.Component {
+	name := c.Name(rawName)
+
+	for _, comp := range *c.Components {
+		if comp.Source == types.SourceComponent && comp.SourceDoc == c.SourceDoc && strings.EqualFold(comp.Name, name) {
+			return comp
+		}
+	}
+
+	return nil
+}
+
+func (c *SchemaConverter) component(name, rawName string, schema *Schema, source types.ComponentSource) (*types.Component, error) {
+	if nil == schema {
+		schema = &Schema{}
+	}
+
+	flat := c.Flatten(schema)
+	typ, format, null := c.TypeOf(flat)
+
+	comp, err := c.Components.NewComponent(name, rawName, typ, description(flat), format, c.Version, nil, Bool(null), true, enums(flat), source, c.RefOf(name, flat), schema.Raw, false)
+	if nil != err {
+		return nil, err
+	}
+
+	comp.SourceDoc = c.SourceDoc
+	comp.Properties = c.Properties(flat)
+	return comp, nil
+}
+
+// Properties
+//
+// This method returns the properties of an (inline) object schema, sorted by name. For an array or map of inline objects,
+// the properties of the items/values are returned. Referenced schemas have no properties of their own here, they belong
+// to the defined component.
+func (c *SchemaConverter) Properties(schema *Schema) types.Properties {
+	props := make(types.Properties, 0)
+
+	if nil == schema || len(schema.Ref) > 0 {
+		return props
+	}
+
+	schema = c.Flatten(schema)
+
+	if len(schema.Properties) <= 0 {
+		if nil != schema.Items && len(schema.Items.Ref) <= 0 {
+			return c.Properties(schema.Items)
+		}
+
+		if nil != schema.AdditionalProperties && len(schema.AdditionalProperties.Ref) <= 0 {
+			return c.Properties(schema.AdditionalProperties)
+		}
+
+		return props
+	}
+
+	for name, s := range schema.Properties {
+		if prop := c.Property(name, s, contains(schema.Required, name)); nil != prop {
+			props = append(props, prop)
+		}
+	}
+
+	sort.Sort(props)
+	return props
+}
+
+// Property creates the property with the provided name for a schema, including its own nested properties
+func (c *SchemaConverter) Property(name string, schema *Schema, required bool) *types.Property {
+	if nil == schema {
+		schema = &Schema{}
+	}
+
+	flat := c.Flatten(schema)
+	typ, format, null := c.TypeOf(flat)
+
+	prop, err := types.NewProperty(name, name, typ, description(flat), format, c.Version, Bool(required), Bool(null), true, enums(flat), c.RefOf(name, flat), schema.Raw)
+	if nil != err {
+		return nil
+	}
+
+	prop.RawName = name
+	prop.Properties = c.Properties(flat)
+	return prop
+}
+
+// TypeOf
+//
+// This method returns the Component/Property type of a schema along with its format and whether it can be null. Types
+// follow JSON Schema, except integer which becomes number with a format of int, int32 or int64 and number which gets a
+// format of float32 or float64. A $ref gets the type of the schema it references.
+func (c *SchemaConverter) TypeOf(schema *Schema) (typ, format string, null bool) {
+	return c.typeOf(schema, 0)
+}
+
+func (c *SchemaConverter) typeOf(schema *Schema, depth int) (typ, format string, null bool) {
+	if nil == schema {
+		return "", "", false
+	}
+
+	null = schema.Nullable || schema.XNullable
+
+	if len(schema.Ref) > 0 {
+		_, target := c.resolve(schema.Ref)
+		if nil == target || depth > 8 {
+			return "object", "", null
+		}
+
+		typ, format, refNull := c.typeOf(c.Flatten(target), depth+1)
+		return typ, format, null || refNull
+	}
+
+	for _, t := range schema.Types() {
+		if t == "null" {
+			null = true
+		} else if len(typ) <= 0 {
+			typ = t
+		}
+	}
+
+	if len(typ) <= 0 {
+		switch {
+		case len(schema.Properties) > 0 || nil != schema.AdditionalProperties:
+			typ = "object"
+		case nil != schema.Items:
+			typ = "array"
+		case len(schema.Enum) > 0 || nil != schema.Const:
+			typ = primitiveOf(append(schema.Enum, schema.Const)...)
+		}
+	}
+
+	format = schema.Format
+
+	switch typ {
+	case "integer":
+		typ = "number"
+		if format != "int32" && format != "int64" {
+			format = "int"
+		}
+	case "number":
+		switch format {
+		case "float", "float32":
+			format = "float32"
+		case "int", "int32", "int64":
+		default:
+			format = "float64"
+		}
+	case "object":
+		if len(schema.Properties) <= 0 && nil != schema.AdditionalProperties {
+			format = "map"
+		}
+	}
+
+	return typ, format, null
+}
+
+// RefOf
+//
+// This method returns what the Ref of a component/property created from the schema should be: the name of the defined
+// component for a $ref, and for arrays and maps the component name or primitive type of their items/values. Items that
+// are arrays or maps themselves get a component of their own (see items), named after the provided name.
+func (c *SchemaConverter) RefOf(name string, schema *Schema) any {
+	if nil == schema {
+		return nil
+	}
+
+	if len(schema.Ref) > 0 {
+		name, _ := c.resolve(schema.Ref)
+		return c.Name(name)
+	}
+
+	var items *Schema
+	switch typ, format, _ := c.TypeOf(schema); {
+	case typ == "array":
+		items = schema.Items
+	case typ == "object" && format == "map":
+		items = schema.AdditionalProperties
+	default:
+		return nil
+	}
+
+	if nil == items {
+		return "object"
+	}
+
+	if len(items.Ref) > 0 {
+		name, _ := c.resolve(items.Ref)
+		return c.Name(name)
+	}
+
+	typ, format, _ := c.TypeOf(c.Flatten(items))
+	switch {
+	case typ == "array" || (typ == "object" && format == "map"):
+		return c.items(name, items)
+	case typ == "number":
+		return format
+	}
+
+	return typ
+}
+
+// items
+//
+// This method returns the component of items (or values) that are arrays or maps themselves, so the items of those are
+// known as well. It is a types.SourceProperty component, which generators write in place rather than as a type of its
+// own, with a name that is unique in the source. Nil is returned when it can not be created.
+func (c *SchemaConverter) items(name string, items *Schema) *types.Component {
+	base := ComponentName(name) + "Item"
+	unique := base
+	for i := 2; c.taken(unique); i++ {
+		unique = base + strconv.Itoa(i)
+	}
+
+	comp, err := c.component(unique, unique, items, types.SourceProperty)
+	if nil != err {
+		return nil
+	}
+
+	return comp
+}
+
+// taken returns true when a component of this source that is not defined already has the provided name
+func (c *SchemaConverter) taken(name string) bool {
+	for _, comp := range *c.Components {
+		if comp.Source == types.SourceProperty && comp.SourceDoc == c.SourceDoc && strings.EqualFold(comp.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Flatten
+//
+// This method merges the schemas of allOf in to a single schema. The properties of oneOf and anyOf alternatives are merged
+// as well (none of them required) since the model has no notion of a union, unless all alternatives are the same
+// primitive type. The provided schema is not modified.
+func (c *SchemaConverter) Flatten(schema *Schema) *Schema {
+	if nil == schema || (len(schema.AllOf) <= 0 && len(schema.OneOf) <= 0 && len(schema.AnyOf) <= 0) {
+		return schema
+	}
+
+	flat := *schema
+	flat.AllOf, flat.OneOf, flat.AnyOf = nil, nil, nil
+	flat.Properties = make(map[string]*Schema, len(schema.Properties))
+	flat.Required = append([]string{}, schema.Required...)
+	for k, v := range schema.Properties {
+		flat.Properties[k] = v
+	}
+
+	var merge func(sub *Schema, required bool, depth int)
+	merge = func(sub *Schema, required bool, depth int) {
+		if nil == sub || depth > 8 {
+			return
+		}
+
+		if len(sub.Ref) > 0 {
+			_, target := c.resolve(sub.Ref)
+			merge(target, required, depth+1)
+			return
+		}
+
+		sub = c.Flatten(sub)
+
+		if nil == flat.Type && nil != sub.Type {
+			flat.Type = sub.Type
+		}
+		if len(flat.Description) <= 0 {
+			flat.Description = sub.Description
+		}
+		if nil == flat.Items {
+			flat.Items = sub.Items
+		}
+		if nil == flat.AdditionalProperties {
+			flat.AdditionalProperties = sub.AdditionalProperties
+		}
+		if sub.Nullable || sub.XNullable {
+			flat.Nullable = true
+		}
+
+		for k, v := range sub.Properties {
+			if _, ok := flat.Properties[k]; !ok {
+				flat.Properties[k] = v
+			}
+		}
+
+		if required {
+			flat.Required = append(flat.Required, sub.Required...)
+		}
+	}
+
+	for _, sub := range schema.AllOf {
+		merge(sub, true, 0)
+	}
+
+	alternatives := append(append([]*Schema{}, schema.OneOf...), schema.AnyOf...)
+	if len(alternatives) > 0 {
+		primitive := ""
+		for _, alt := range alternatives {
+			if altTypes := alt.Types(); len(altTypes) == 1 && altTypes[0] == "null" {
+				flat.Nullable = true
+				continue
+			}
+
+			typ, _, _ := c.TypeOf(c.Flatten(alt))
+			if len(primitive) <= 0 || typ == primitive {
+				primitive = typ
+			} else {
+				primitive = "object"
+			}
+		}
+
+		if primitive != "object" && primitive != "array" && len(flat.Properties) <= 0 && nil == flat.Type {
+			flat.Type = primitive
+		} else {
+			for _, alt := range alternatives {
+				merge(alt, false, 0)
+			}
+		}
+	}
+
+	return &flat
+}
+
+func description(schema *Schema) string {
+	if len(schema.Description) > 0 {
+		return schema.Description
+	}
+
+	return schema.Title
+}
+
+func enums(schema *Schema) []string {
+	values := schema.Enum
+	if len(values) <= 0 && nil != schema.Const {
+		values = []any{schema.Const}
+	}
+
+	if len(values) <= 0 {
+		return nil
+	}
+
+	e := make([]string, 0, len(values))
+	for _, v := range values {
+		if nil != v {
+			e = append(e, fmt.Sprint(v))
+		}
+	}
+
+	return e
+}
+
+// primitiveOf returns the json type of the provided values if they all have the same one, string otherwise
+func primitiveOf(values ...any) string {
+	typ := ""
+
+	for _, v := range values {
+		t := "string"
+		switch v.(type) {
+		case nil:
+			continue
+		case bool:
+			t = "boolean"
+		case float64, int, int64:
+			t = "number"
+		}
+
+		if len(typ) > 0 && typ != t {
+			return "string"
+		}
+		typ = t
+	}
+
+	if len(typ) <= 0 {
+		return "string"
+	}
+
+	return typ
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
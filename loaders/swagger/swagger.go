@@ -0,0 +1,437 @@
+// Package swagger is the built in loader for Swagger 2.0 definitions (json or yaml). It maps them on to the same Resources,
+// Requests, ResponseBodies and Components the openapi loader creates for OpenAPI 3.x, so generators do not need to know
+// which version a source was.
+package swagger
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"strings"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "swagger"
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+type document struct {
+	Swagger string `json:"swagger"`
+	Info    struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	} `json:"info"`
+	Host        string                     `json:"host"`
+	BasePath    string                     `json:"basePath"`
+	Schemes     []string                   `json:"schemes"`
+	Consumes    []string                   `json:"consumes"`
+	Produces    []string                   `json:"produces"`
+	Paths       map[string]*pathItem       `json:"paths"`
+	Definitions map[string]*loaders.Schema `json:"definitions"`
+	Parameters  map[string]*parameter      `json:"parameters"`
+	Responses   map[string]*response       `json:"responses"`
+}
+
+type pathItem struct {
+	Ref        string       `json:"$ref"`
+	Parameters []*parameter `json:"parameters"`
+	Get        *operation   `json:"get"`
+	Put        *operation   `json:"put"`
+	Post       *operation   `json:"post"`
+	Delete     *operation   `json:"delete"`
+	Options    *operation   `json:"options"`
+	Head       *operation   `json:"head"`
+	Patch      *operation   `json:"patch"`
+}
+
+func (p *pathItem) operation(method string) *operation {
+	switch method {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	}
+
+	return nil
+}
+
+type operation struct {
+	OperationId string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+	Consumes    []string             `json:"consumes"`
+	Produces    []string             `json:"produces"`
+	Parameters  []*parameter         `json:"parameters"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+// parameter is a Swagger 2.0 parameter. Body parameters have a Schema, all others describe their type in place.
+type parameter struct {
+	Ref         string          `json:"$ref"`
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description"`
+	Required    bool            `json:"required"`
+	Schema      *loaders.Schema `json:"schema"`
+	Type        string          `json:"type"`
+	Format      string          `json:"format"`
+	Items       *loaders.Schema `json:"items"`
+	Enum        []any           `json:"enum"`
+	Default     any             `json:"default"`
+}
+
+// schema returns the type of a non body parameter as a Schema
+func (p *parameter) schema() *loaders.Schema {
+	if nil != p.Schema {
+		return p.Schema
+	}
+
+	typ, format := p.Type, p.Format
+	if typ == "file" {
+		typ, format = "string", "binary"
+	}
+
+	return &loaders.Schema{
+		Type:        typ,
+		Format:      format,
+		Description: p.Description,
+		Items:       p.Items,
+		Enum:        p.Enum,
+		Default:     p.Default,
+	}
+}
+
+type response struct {
+	Ref         string          `json:"$ref"`
+	Description string          `json:"description"`
+	Schema      *loaders.Schema `json:"schema"`
+	Examples    map[string]any  `json:"examples"`
+}
+
+// Loader implements pipeline.Loader for Swagger 2.0
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	version, ok := loaders.HeaderValue(header, "swagger")
+	switch {
+	case ok && strings.HasPrefix(version, "2."):
+		return types.ConfidenceCertain
+	case ok:
+		return types.ConfidenceMedium
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	doc := &document{}
+	if err := loaders.Decode(data, doc); nil != err {
+		return nil, fmt.Errorf("%s is not a valid Swagger document: %w", source, err)
+	}
+
+	l := &loader{
+		doc:      doc,
+		source:   source,
+		response: types.NewLoadedResponse(),
+	}
+
+	if !strings.HasPrefix(doc.Swagger, "2.") {
+		l.response.Diagnostics.Warn("swagger-version", source, "Swagger version %q is not supported, loading it as 2.0", doc.Swagger)
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Version:     doc.Info.Version,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *document
+	source    string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+}
+
+func (l *loader) load() {
+	// defined components first, so everything referencing them can find them
+	for _, name := range loaders.SortedKeys(l.doc.Definitions) {
+		if _, err := l.converter.Define(name, l.doc.Definitions[name]); nil != err {
+			l.response.Diagnostics.Warn("swagger-schema", l.source, "problem loading definition %s: %s", name, err.Error()).Location = &types.Location{Path: loaders.Pointer("definitions", name)}
+		}
+	}
+
+	for _, pth := range loaders.SortedKeys(l.doc.Paths) {
+		item := l.doc.Paths[pth]
+		if nil == item {
+			continue
+		}
+
+		if len(item.Ref) > 0 {
+			l.response.Diagnostics.Warn("swagger-unsupported", l.source, "path item $ref %s is not supported", item.Ref).Location = &types.Location{Path: loaders.Pointer("paths", pth)}
+			continue
+		}
+
+		for _, method := range methods {
+			if op := item.operation(method); nil != op {
+				l.resource(pth, method, item, op)
+			}
+		}
+	}
+}
+
+func (l *loader) resource(pth, method string, item *pathItem, op *operation) {
+	name := types.MakeResourceName(op.OperationId, method, pth, "")
+
+	res, err := l.response.Resources.NewResource(pth, method, name, op.Description, op.Summary, Source, l.doc.Info.Version, l.doc.Info.Title, op.Deprecated, true, types.HTTP)
+	if nil != err {
+		l.response.Diagnostics.Warn("swagger-operation", l.source, "problem loading operation: %s", err.Error()).Location = &types.Location{Path: loaders.Pointer("paths", pth, method)}
+		return
+	}
+
+	res.SourceDoc = l.source
+
+	// basePath, host and schemes have no place of their own on a Resource, keep them for generators that need them
+	if len(l.doc.BasePath) > 0 {
+		res.Variables["basePath"] = l.doc.BasePath
+	}
+	if len(l.doc.Host) > 0 {
+		res.Variables["host"] = l.doc.Host
+	}
+	if len(l.doc.Schemes) > 0 {
+		res.Variables["schemes"] = strings.Join(l.doc.Schemes, ",")
+	}
+
+	components := make(types.Components, 0)
+	used := func(comp *types.Component) {
+		if nil != comp && nil == components.FindComponentById(comp.Id) {
+			components = append(components, comp)
+		}
+	}
+
+	consumes := op.Consumes
+	if len(consumes) <= 0 {
+		consumes = l.doc.Consumes
+	}
+
+	produces := op.Produces
+	if len(produces) <= 0 {
+		produces = l.doc.Produces
+	}
+	if len(produces) <= 0 {
+		produces = []string{"application/json"}
+	}
+
+	// operation parameters override path item parameters with the same name and location
+	params := make([]*parameter, 0, len(item.Parameters)+len(op.Parameters))
+	for _, p := range append(append([]*parameter{}, op.Parameters...), item.Parameters...) {
+		p = l.resolveParameter(p)
+		if nil == p {
+			continue
+		}
+
+		duplicate := false
+		for _, existing := range params {
+			if existing.Name == p.Name && existing.In == p.In {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			params = append(params, p)
+		}
+	}
+
+	// formData parameters together make up a single request body
+	form := &loaders.Schema{Type: "object", Properties: make(map[string]*loaders.Schema)}
+	multipart := false
+
+	for _, p := range params {
+		switch p.In {
+		case "body":
+			contentTypes := consumes
+			if len(contentTypes) <= 0 {
+				contentTypes = []string{"application/json"}
+			}
+
+			schema, ref, err := l.converter.Inline(name+"Request", p.Schema, types.SourceRequestBodyInline)
+			if nil != err {
+				l.response.Diagnostics.Warn("swagger-schema", l.source, "problem loading body parameter %s: %s", p.Name, err.Error()).Location = &types.Location{Path: loaders.Pointer("paths", pth, method)}
+			}
+			used(schema)
+
+			for i, contentType := range contentTypes {
+				res.Requests = append(res.Requests, &types.Request{
+					Required:    p.Required,
+					ContentType: contentType,
+					Type:        types.ContentKind(contentType),
+					Ref:         ref,
+					Default:     i == 0,
+					Schema:      schema,
+				})
+			}
+		case "formData":
+			if p.Type == "file" {
+				multipart = true
+			}
+
+			form.Properties[p.Name] = p.schema()
+			if p.Required {
+				form.Required = append(form.Required, p.Name)
+			}
+		default:
+			param := l.parameter(name, p)
+			res.Parameters = append(res.Parameters, param)
+			for _, comp := range param.Components {
+				used(comp)
+			}
+		}
+	}
+
+	if len(form.Properties) > 0 {
+		contentType := "application/x-www-form-urlencoded"
+		for _, ct := range consumes {
+			if strings.HasPrefix(ct, "multipart/") || (!multipart && strings.Contains(ct, "x-www-form-urlencoded")) {
+				contentType = ct
+				break
+			}
+		}
+		if multipart && !strings.HasPrefix(contentType, "multipart/") {
+			contentType = "multipart/form-data"
+		}
+
+		schema, _, err := l.converter.Inline(name+"Request", form, types.SourceRequestBodyInline)
+		if nil != err {
+			l.response.Diagnostics.Warn("swagger-schema", l.source, "problem loading form parameters: %s", err.Error()).Location = &types.Location{Path: loaders.Pointer("paths", pth, method)}
+		}
+		used(schema)
+
+		res.Requests = append(res.Requests, &types.Request{
+			Required:    len(form.Required) > 0,
+			ContentType: contentType,
+			Type:        types.ContentKind(contentType),
+			Default:     len(res.Requests) <= 0,
+			Schema:      schema,
+		})
+	}
+
+	for _, status := range loaders.SortedKeys(op.Responses) {
+		resp := l.resolveResponse(op.Responses[status])
+		if nil == resp {
+			continue
+		}
+
+		r := &types.Response{
+			Status:         status,
+			Description:    resp.Description,
+			ResponseBodies: make(types.ResponseBodies, 0),
+		}
+
+		if nil != resp.Schema {
+			schema, ref, err := l.converter.Inline(name+types.ToCamelCase(status, true)+"Response", resp.Schema, types.SourceResponseBodyInline)
+			if nil != err {
+				l.response.Diagnostics.Warn("swagger-schema", l.source, "problem loading response: %s", err.Error()).Location = &types.Location{Path: loaders.Pointer("paths", pth, method, "responses", status)}
+			}
+			used(schema)
+
+			if len(op.Responses[status].Ref) > 0 {
+				ref = op.Responses[status].Ref
+			}
+
+			for i, mediaType := range produces {
+				r.ResponseBodies = append(r.ResponseBodies, &types.ResponseBody{
+					MediaType: mediaType,
+					Ref:       ref,
+					Default:   i == 0,
+					Schema:    schema,
+					Example:   loaders.Example(resp.Examples[mediaType]),
+				})
+			}
+		}
+
+		res.Responses = append(res.Responses, r)
+	}
+
+	res.Components = &components
+}
+
+func (l *loader) parameter(resourceName string, p *parameter) *types.Parameter {
+	param := &types.Parameter{
+		Name:        p.Name,
+		In:          types.QueryIn(p.In),
+		Description: p.Description,
+		Required:    p.Required || p.In == "path",
+		Value:       loaders.Example(p.Default),
+		Components:  make(types.Components, 0),
+	}
+
+	schema := p.schema()
+	typ, format, _ := l.converter.TypeOf(schema)
+	param.Type = typ
+	param.Format = format
+
+	if typ == "object" || typ == "array" {
+		comp, _, err := l.converter.Inline(resourceName+types.ToCamelCase(p.Name, true)+"Param", schema, types.SourceParameter)
+		if nil != err {
+			l.response.Diagnostics.Warn("swagger-schema", l.source, "problem loading parameter %s: %s", p.Name, err.Error())
+		} else if nil != comp {
+			param.Components = append(param.Components, comp)
+		}
+	}
+
+	return param
+}
+
+// resolveSchema resolves a $ref to a definition in this document
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	name := loaders.RefName(ref)
+	if strings.HasPrefix(ref, "#/definitions/") {
+		return name, l.doc.Definitions[name]
+	}
+
+	return name, nil
+}
+
+func (l *loader) resolveParameter(p *parameter) *parameter {
+	if nil == p || len(p.Ref) <= 0 {
+		return p
+	}
+
+	found := l.doc.Parameters[loaders.RefName(p.Ref)]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "parameter %s is not defined", p.Ref)
+	}
+
+	return found
+}
+
+func (l *loader) resolveResponse(r *response) *response {
+	if nil == r || len(r.Ref) <= 0 {
+		return r
+	}
+
+	found := l.doc.Responses[loaders.RefName(r.Ref)]
+	if nil == found {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "response %s is not defined", r.Ref)
+	}
+
+	return found
+}
@@ -0,0 +1,125 @@
+package swagger
+
+import (
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"json 2.0", `{"swagger":"2.0","info":{"title":"Legacy"}}`, types.ConfidenceCertain},
+		{"yaml 2.0", "swagger: '2.0'\ninfo:\n  title: Legacy", types.ConfidenceCertain},
+		{"another version", `{"swagger": "1.2"}`, types.ConfidenceMedium},
+		{"openapi", "openapi: 3.0.3", types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe("api.json", []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "users.json")
+
+	components := loadertest.Names(resp.Components)
+	want := []string{"GetUsersSearch200Response", "GetUsersSearchTagsParam", "PostUsersIdAvatarRequest", "User"}
+	if !reflect.DeepEqual(components, want) {
+		t.Errorf("components = %v, want %v", components, want)
+	}
+
+	if len(resp.Diagnostics) > 0 {
+		t.Errorf("diagnostics = %v, want none", resp.Diagnostics)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		parameters []string // name and in
+		request    string   // content type of the request body
+		responses  []string // status and media types
+	}{
+		{
+			name:      "createUser",
+			method:    "post",
+			path:      "/users",
+			request:   "application/json",
+			responses: []string{"200 [application/json application/xml]"},
+		},
+		{
+			name:       "getUsersSearch",
+			method:     "get",
+			path:       "/users/search",
+			parameters: []string{"tags query"},
+			responses:  []string{"200 [application/json application/xml]"},
+		},
+		{
+			name:       "postUsersIdAvatar",
+			method:     "post",
+			path:       "/users/{id}/avatar",
+			parameters: []string{"id path"},
+			request:    "multipart/form-data",
+			responses:  []string{"204 []"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resp.Resources.FindResourceByName(tt.name)
+			if nil == res {
+				t.Fatalf("%s is not loaded", tt.name)
+			}
+
+			if res.Method != tt.method || res.Path != tt.path || res.Version != "2.1" || res.Owner != "Legacy" {
+				t.Errorf("the resource is %s %s version %s of %s", res.Method, res.Path, res.Version, res.Owner)
+			}
+
+			parameters := make([]string, 0)
+			for _, p := range res.Parameters {
+				parameters = append(parameters, p.Name+" "+string(p.In))
+			}
+			if fmt.Sprint(parameters) != fmt.Sprint(tt.parameters) {
+				t.Errorf("parameters = %v, want %v", parameters, tt.parameters)
+			}
+
+			request := ""
+			if len(res.Requests) > 0 {
+				request = res.Requests[0].ContentType
+			}
+			if request != tt.request {
+				t.Errorf("request body = %q, want %q", request, tt.request)
+			}
+
+			responses := make([]string, 0)
+			for _, r := range res.Responses {
+				media := make([]string, 0)
+				for _, body := range r.ResponseBodies {
+					media = append(media, body.MediaType)
+				}
+				responses = append(responses, r.Status+" "+fmt.Sprint(media))
+			}
+			if !reflect.DeepEqual(responses, tt.responses) {
+				t.Errorf("responses = %v, want %v", responses, tt.responses)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", `{"paths": [`, "- a list\n- not a document"} {
+		if _, err := (Loader{}).Load("api.json", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
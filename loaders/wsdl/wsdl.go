@@ -0,0 +1,494 @@
+// Package wsdl is the built in loader for WSDL 1.1 documents and XML Schema (XSD) files. Every operation of a SOAP binding
+// becomes a SOAP Resource, the messages of the operation become its Requests and Responses and the XSD types and elements
+// become defined Components. The SOAPAction, style, use and the binding the operation belongs to are kept in
+// Resource.Variables.
+//
+// Schemas the document imports (wsdl:import, xsd:import and xsd:include with a location) are returned as
+// LoadedResponse.Imports so the pipeline loads them as well. A loaded XSD file only contributes Components.
+package wsdl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/spirefy/go-codegen/loaders"
+	"github.com/spirefy/go-codegen/types"
+	"net/url"
+	"strings"
+)
+
+// Source is the value of Resource.Source for resources created by this loader
+const Source = "wsdl"
+
+const (
+	// WSDL is the namespace of WSDL 1.1
+	WSDL = "http://schemas.xmlsoap.org/wsdl/"
+
+	SOAP11 = "http://schemas.xmlsoap.org/wsdl/soap/"
+	SOAP12 = "http://schemas.xmlsoap.org/wsdl/soap12/"
+)
+
+// The content types of SOAP 1.1 and SOAP 1.2 envelopes
+const (
+	ContentTypeSOAP11 = "text/xml"
+	ContentTypeSOAP12 = "application/soap+xml"
+)
+
+// schemaRef is the prefix of the $refs the loader uses between the schemas it builds for types, elements and messages
+const schemaRef = "#/types/"
+
+type definitions struct {
+	XMLName         xml.Name
+	Name            string        `xml:"name,attr"`
+	TargetNamespace string        `xml:"targetNamespace,attr"`
+	Documentation   string        `xml:"documentation"`
+	Imports         []*wsdlImport `xml:"import"`
+	Types           struct {
+		Schemas []*xsdSchema `xml:"schema"`
+	} `xml:"types"`
+	Messages  []*message  `xml:"message"`
+	PortTypes []*portType `xml:"portType"`
+	Bindings  []*binding  `xml:"binding"`
+	Services  []*service  `xml:"service"`
+}
+
+type wsdlImport struct {
+	Namespace string `xml:"namespace,attr"`
+	Location  string `xml:"location,attr"`
+}
+
+type message struct {
+	Name          string  `xml:"name,attr"`
+	Documentation string  `xml:"documentation"`
+	Parts         []*part `xml:"part"`
+}
+
+type part struct {
+	Name    string `xml:"name,attr"`
+	Element string `xml:"element,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+type portType struct {
+	Name          string       `xml:"name,attr"`
+	Documentation string       `xml:"documentation"`
+	Operations    []*operation `xml:"operation"`
+}
+
+type operation struct {
+	Name          string              `xml:"name,attr"`
+	Documentation string              `xml:"documentation"`
+	Input         *operationMessage   `xml:"input"`
+	Output        *operationMessage   `xml:"output"`
+	Faults        []*operationMessage `xml:"fault"`
+}
+
+type operationMessage struct {
+	Name    string `xml:"name,attr"`
+	Message string `xml:"message,attr"`
+}
+
+type binding struct {
+	Name        string              `xml:"name,attr"`
+	Type        string              `xml:"type,attr"`
+	SOAPBinding *soapBinding        `xml:"binding"`
+	Operations  []*bindingOperation `xml:"operation"`
+}
+
+type soapBinding struct {
+	XMLName   xml.Name
+	Style     string `xml:"style,attr"`
+	Transport string `xml:"transport,attr"`
+}
+
+type bindingOperation struct {
+	Name          string `xml:"name,attr"`
+	SOAPOperation *struct {
+		SOAPAction string `xml:"soapAction,attr"`
+		Style      string `xml:"style,attr"`
+	} `xml:"operation"`
+	Input *struct {
+		Body *struct {
+			Use       string `xml:"use,attr"`
+			Namespace string `xml:"namespace,attr"`
+		} `xml:"body"`
+	} `xml:"input"`
+}
+
+type service struct {
+	Name          string  `xml:"name,attr"`
+	Documentation string  `xml:"documentation"`
+	Ports         []*port `xml:"port"`
+}
+
+type port struct {
+	Name    string `xml:"name,attr"`
+	Binding string `xml:"binding,attr"`
+	Address *struct {
+		Location string `xml:"location,attr"`
+	} `xml:"address"`
+}
+
+// Loader implements pipeline.Loader for WSDL 1.1 documents and XSD files
+type Loader struct{}
+
+func (Loader) Probe(source string, header []byte) int {
+	switch {
+	case bytes.Contains(header, []byte(WSDL)) && bytes.Contains(header, []byte("definitions")):
+		return types.ConfidenceCertain
+	case loaders.HasExtension(source, ".wsdl"):
+		return types.ConfidenceHigh
+	case bytes.Contains(header, []byte(XMLSchema)) && bytes.Contains(header, []byte("schema")):
+		if loaders.HasExtension(source, ".xsd") {
+			return types.ConfidenceCertain
+		}
+		return types.ConfidenceMedium
+	case loaders.HasExtension(source, ".xsd"):
+		return types.ConfidenceMedium
+	default:
+		return types.ConfidenceNone
+	}
+}
+
+func (Loader) Load(source string, data []byte) (*types.LoadedResponse, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+
+	if err := xml.Unmarshal(data, &root); nil != err {
+		return nil, fmt.Errorf("%s is not a valid WSDL or XSD document: %w", source, err)
+	}
+
+	doc := &definitions{}
+	switch root.XMLName.Local {
+	case "definitions":
+		if err := xml.Unmarshal(data, doc); nil != err {
+			return nil, fmt.Errorf("%s is not a valid WSDL document: %w", source, err)
+		}
+	case "schema":
+		schema := &xsdSchema{}
+		if err := xml.Unmarshal(data, schema); nil != err {
+			return nil, fmt.Errorf("%s is not a valid XSD document: %w", source, err)
+		}
+		doc.Types.Schemas = append(doc.Types.Schemas, schema)
+	case "description":
+		return nil, fmt.Errorf("%s is a WSDL 2.0 document, only WSDL 1.1 is supported", source)
+	default:
+		return nil, fmt.Errorf("%s is not a WSDL or XSD document, found a %s element", source, root.XMLName.Local)
+	}
+
+	l := &loader{
+		doc:      doc,
+		source:   source,
+		response: types.NewLoadedResponse(),
+		schemas:  make(map[string]*loaders.Schema),
+		elements: make(map[string]string),
+		messages: make(map[string]string),
+		names:    make([]string, 0),
+		reported: make(map[string]bool),
+	}
+
+	if len(root.XMLName.Space) > 0 && root.XMLName.Local == "definitions" && root.XMLName.Space != WSDL {
+		l.response.Diagnostics.Warn("wsdl-version", source, "%s is not the WSDL 1.1 namespace, loading it as WSDL 1.1", root.XMLName.Space)
+	}
+
+	l.converter = &loaders.SchemaConverter{
+		SourceDoc:   source,
+		Components:  &l.response.Components,
+		Diagnostics: &l.response.Diagnostics,
+		Resolve:     l.resolveSchema,
+	}
+
+	l.load()
+	return l.response, nil
+}
+
+type loader struct {
+	doc       *definitions
+	source    string
+	response  *types.LoadedResponse
+	converter *loaders.SchemaConverter
+
+	// the schemas of every type, element and message, keyed on their (component) name
+	schemas map[string]*loaders.Schema
+	names   []string
+
+	// the schema names of top level elements and messages, keyed on their name in the document
+	elements map[string]string
+	messages map[string]string
+
+	// the names of the types that are referenced but not defined, these are reported once
+	reported map[string]bool
+}
+
+func (l *loader) load() {
+	for _, imp := range l.doc.Imports {
+		l.addImport(imp.Location)
+	}
+
+	for _, schema := range l.doc.Types.Schemas {
+		for _, imp := range append(schema.Imports, schema.Includes...) {
+			l.addImport(imp.SchemaLocation)
+		}
+	}
+
+	// every name has to be known before anything can be resolved against them
+	l.declare(l.doc.Types.Schemas)
+	for _, m := range l.doc.Messages {
+		name := m.Name
+		if _, ok := l.schemas[name]; ok || len(l.elements[name]) > 0 {
+			name += "Message"
+		}
+
+		l.messages[m.Name] = name
+		l.schemas[name] = &loaders.Schema{}
+		l.names = append(l.names, name)
+	}
+
+	l.define(l.doc.Types.Schemas)
+	for _, m := range l.doc.Messages {
+		l.message(m)
+	}
+
+	for _, name := range l.names {
+		if _, err := l.converter.Define(name, l.schemas[name]); nil != err {
+			l.response.Diagnostics.Warn("wsdl-schema", l.source, "problem loading %s: %s", name, err.Error())
+		}
+	}
+
+	for _, b := range l.doc.Bindings {
+		l.binding(b)
+	}
+}
+
+func (l *loader) addImport(location string) {
+	if len(location) <= 0 {
+		return
+	}
+
+	for _, imp := range l.response.Imports {
+		if imp == location {
+			return
+		}
+	}
+
+	l.response.Imports = append(l.response.Imports, location)
+}
+
+// message fills in the schema of a message, an object with a property per part
+func (l *loader) message(m *message) {
+	schema := l.schemas[l.messages[m.Name]]
+	schema.Type = "object"
+	schema.Description = strings.TrimSpace(m.Documentation)
+	schema.Properties = make(map[string]*loaders.Schema, len(m.Parts))
+
+	for _, p := range m.Parts {
+		if len(p.Element) > 0 {
+			schema.Properties[p.Name] = l.elementRef(p.Element)
+		} else {
+			schema.Properties[p.Name] = l.typeSchema(p.Type)
+		}
+
+		schema.Required = append(schema.Required, p.Name)
+	}
+}
+
+func (l *loader) binding(b *binding) {
+	if nil == b.SOAPBinding || (b.SOAPBinding.XMLName.Space != SOAP11 && b.SOAPBinding.XMLName.Space != SOAP12) {
+		l.response.Diagnostics.Warn("wsdl-unsupported", l.source, "binding %s is not a SOAP binding, only SOAP bindings are supported", b.Name)
+		return
+	}
+
+	var pt *portType
+	for _, p := range l.doc.PortTypes {
+		if p.Name == localName(b.Type) {
+			pt = p
+		}
+	}
+
+	if nil == pt {
+		l.response.Diagnostics.Warn("unresolved-ref", l.source, "binding %s references port type %s which is not defined", b.Name, b.Type)
+		return
+	}
+
+	for _, bop := range b.Operations {
+		var op *operation
+		for _, o := range pt.Operations {
+			if o.Name == bop.Name {
+				op = o
+			}
+		}
+
+		if nil == op {
+			l.response.Diagnostics.Warn("unresolved-ref", l.source, "operation %s of binding %s is not an operation of port type %s", bop.Name, b.Name, pt.Name)
+			continue
+		}
+
+		l.resource(b, bop, pt, op)
+	}
+}
+
+func (l *loader) resource(b *binding, bop *bindingOperation, pt *portType, op *operation) {
+	pth := "/" + pt.Name + "/" + op.Name
+
+	owner := l.doc.Name
+	if len(owner) <= 0 {
+		owner = l.source
+	}
+
+	// a port type is often bound more than once (SOAP 1.1 and 1.2), the first binding of an operation is used
+	if nil != l.response.Resources.FindResource(pth, "post", &owner) {
+		return
+	}
+
+	description := strings.TrimSpace(op.Documentation)
+	if len(description) <= 0 {
+		description = strings.TrimSpace(pt.Documentation)
+	}
+
+	res, err := l.response.Resources.NewResource(pth, "post", types.MakeResourceName(op.Name, "", "", ""), description, "", Source, "", owner, false, true, types.SOAP)
+	if nil != err {
+		l.response.Diagnostics.Warn("wsdl-schema", l.source, "problem loading operation %s: %s", op.Name, err.Error())
+		return
+	}
+
+	res.SourceDoc = l.source
+	res.Variables["binding"] = b.Name
+	res.Variables["portType"] = pt.Name
+	res.Variables["operation"] = op.Name
+	res.Variables["namespace"] = l.doc.TargetNamespace
+	res.Variables["transport"] = b.SOAPBinding.Transport
+
+	contentType := ContentTypeSOAP11
+	res.Variables["soapVersion"] = "1.1"
+	if b.SOAPBinding.XMLName.Space == SOAP12 {
+		contentType = ContentTypeSOAP12
+		res.Variables["soapVersion"] = "1.2"
+	}
+
+	style := b.SOAPBinding.Style
+	if nil != bop.SOAPOperation {
+		res.Variables["soapAction"] = bop.SOAPOperation.SOAPAction
+		if len(bop.SOAPOperation.Style) > 0 {
+			style = bop.SOAPOperation.Style
+		}
+	}
+
+	if len(style) <= 0 {
+		style = "document"
+	}
+	res.Variables["style"] = style
+
+	res.Variables["use"] = "literal"
+	if nil != bop.Input && nil != bop.Input.Body && len(bop.Input.Body.Use) > 0 {
+		res.Variables["use"] = bop.Input.Body.Use
+	}
+
+	l.address(res, b)
+
+	// SOAP 1.1 sends the action as a http header, SOAP 1.2 as a parameter of the content type
+	if action := res.Variables["soapAction"]; len(action) > 0 && contentType == ContentTypeSOAP11 {
+		res.Parameters = append(res.Parameters, &types.Parameter{
+			Name:     "SOAPAction",
+			In:       types.HEADER,
+			Required: true,
+			Type:     "string",
+			Value:    action,
+		})
+	}
+
+	components := make(types.Components, 0)
+	add := func(comp *types.Component) {
+		if nil != comp && nil == components.FindComponentById(comp.Id) {
+			components = append(components, comp)
+		}
+	}
+
+	if nil != op.Input {
+		comp, ref := l.messageComponent(op.Input.Message)
+		res.Requests = append(res.Requests, &types.Request{
+			Required:    true,
+			ContentType: contentType,
+			Type:        types.ContentKind(contentType),
+			Default:     true,
+			Ref:         ref,
+			Schema:      comp,
+		})
+		add(comp)
+	}
+
+	if nil != op.Output {
+		comp, ref := l.messageComponent(op.Output.Message)
+		res.Responses = append(res.Responses, &types.Response{
+			Status:      "200",
+			Description: localName(op.Output.Message),
+			ResponseBodies: types.ResponseBodies{{
+				MediaType: contentType,
+				Ref:       ref,
+				Default:   true,
+				Schema:    comp,
+			}},
+		})
+		add(comp)
+	}
+
+	// SOAP faults are returned with a 500 status
+	if len(op.Faults) > 0 {
+		faults := &types.Response{Status: "500", Description: "SOAP fault"}
+		for i, f := range op.Faults {
+			comp, ref := l.messageComponent(f.Message)
+			faults.ResponseBodies = append(faults.ResponseBodies, &types.ResponseBody{
+				MediaType: contentType,
+				Ref:       ref,
+				Default:   i == 0,
+				Schema:    comp,
+			})
+			add(comp)
+		}
+		res.Responses = append(res.Responses, faults)
+	}
+
+	res.Components = &components
+}
+
+// address sets the service, port and address of the first port using the binding, along with its host, basePath and schemes
+func (l *loader) address(res *types.Resource, b *binding) {
+	for _, s := range l.doc.Services {
+		for _, p := range s.Ports {
+			if localName(p.Binding) != b.Name || nil == p.Address {
+				continue
+			}
+
+			res.Variables["service"] = s.Name
+			res.Variables["port"] = p.Name
+			res.Variables["address"] = p.Address.Location
+
+			if u, err := url.Parse(p.Address.Location); nil == err && len(u.Host) > 0 {
+				res.Variables["host"] = u.Host
+				res.Variables["schemes"] = u.Scheme
+				if len(u.Path) > 0 && u.Path != "/" {
+					res.Variables["basePath"] = u.Path
+				}
+			}
+
+			return
+		}
+	}
+}
+
+// messageComponent returns the component of a message along with its name as Ref
+func (l *loader) messageComponent(qname string) (*types.Component, string) {
+	name, ok := l.messages[localName(qname)]
+	if !ok {
+		l.unresolved(localName(qname))
+		return nil, loaders.ComponentName(localName(qname))
+	}
+
+	return l.converter.Defined(name), l.converter.Name(name)
+}
+
+// resolveSchema resolves the $refs between the schemas of types, elements and messages
+func (l *loader) resolveSchema(ref string) (string, *loaders.Schema) {
+	name := strings.TrimPrefix(ref, schemaRef)
+	return name, l.schemas[name]
+}
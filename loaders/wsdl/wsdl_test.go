@@ -0,0 +1,171 @@
+package wsdl
+
+import (
+	"github.com/spirefy/go-codegen/loaders/loadertest"
+	"github.com/spirefy/go-codegen/types"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		header string
+		want   int
+	}{
+		{"wsdl definitions", "users.xml", `<wsdl:definitions xmlns:wsdl="` + WSDL + `">`, types.ConfidenceCertain},
+		{"a .wsdl file", "users.wsdl", `<?xml version="1.0"?>`, types.ConfidenceHigh},
+		{"an xsd file", "common.xsd", `<xs:schema xmlns:xs="` + XMLSchema + `">`, types.ConfidenceCertain},
+		{"a schema in another file", "common.xml", `<xs:schema xmlns:xs="` + XMLSchema + `">`, types.ConfidenceMedium},
+		{"a .xsd file", "common.xsd", `<?xml version="1.0"?>`, types.ConfidenceMedium},
+		{"any other xml", "pom.xml", `<project>`, types.ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Loader{}).Probe(tt.source, []byte(tt.header)); got != tt.want {
+				t.Errorf("Probe() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		file       string
+		resources  []string // name and path
+		components []string
+		imports    []string
+	}{
+		{
+			file:       "users.wsdl",
+			resources:  []string{"getUser /UserPort/GetUser", "saveUser /UserPort/SaveUser"},
+			components: []string{"Admin", "GetUser", "GetUserMessage", "GetUserResponse", "GetUserResponseMessage", "Role", "SaveUserRequest", "User", "UserFault", "UserFaultMessage"},
+			imports:    []string{"common.xsd"},
+		},
+		{
+			file:       "common.xsd",
+			resources:  []string{},
+			components: []string{"Address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			resp := loadertest.Load(t, Loader{}, tt.file)
+
+			if len(resp.Diagnostics) > 0 {
+				t.Errorf("diagnostics = %v, want none", resp.Diagnostics)
+			}
+
+			resources := make([]string, 0, len(resp.Resources))
+			for _, res := range resp.Resources {
+				resources = append(resources, res.Name+" "+res.Path)
+			}
+			if !reflect.DeepEqual(resources, tt.resources) {
+				t.Errorf("resources = %v, want %v", resources, tt.resources)
+			}
+
+			components := loadertest.Names(resp.Components)
+			if !reflect.DeepEqual(components, tt.components) {
+				t.Errorf("components = %v, want %v", components, tt.components)
+			}
+
+			if !reflect.DeepEqual(resp.Imports, tt.imports) {
+				t.Errorf("imports = %v, want %v", resp.Imports, tt.imports)
+			}
+		})
+	}
+}
+
+func TestLoadOperations(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "users.wsdl")
+
+	tests := []struct {
+		name      string
+		request   string
+		responses []string // status and schema
+	}{
+		{"getUser", "GetUserMessage", []string{"200 GetUserResponseMessage", "500 UserFaultMessage"}},
+		{"saveUser", "SaveUserRequest", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resp.Resources.FindResourceByName(tt.name)
+			if nil == res {
+				t.Fatalf("%s is not loaded", tt.name)
+			}
+
+			if res.Method != "post" || res.ResourceType != types.SOAP || res.Owner != "UserService" {
+				t.Errorf("the resource is a %s of type %d of %s", res.Method, res.ResourceType, res.Owner)
+			}
+
+			if len(res.Requests) != 1 || res.Requests[0].Schema.Name != tt.request || res.Requests[0].ContentType != "text/xml" {
+				t.Errorf("the request is not a text/xml %s", tt.request)
+			}
+
+			responses := make([]string, 0)
+			for _, r := range res.Responses {
+				for _, body := range r.ResponseBodies {
+					responses = append(responses, r.Status+" "+body.Schema.Name)
+				}
+			}
+			if !reflect.DeepEqual(responses, tt.responses) {
+				t.Errorf("responses = %v, want %v", responses, tt.responses)
+			}
+		})
+	}
+}
+
+func TestLoadTypes(t *testing.T) {
+	resp := loadertest.Load(t, Loader{}, "users.wsdl")
+
+	if role := resp.Components.FindComponentByName("Role"); !reflect.DeepEqual(role.Enums, []string{"admin", "user"}) {
+		t.Errorf("the enums of Role are %v", role.Enums)
+	}
+
+	tests := []struct {
+		component string
+		property  string
+		typ       string
+		ref       string
+		required  bool
+	}{
+		{"User", "id", "number", "", true},
+		{"User", "email", "string", "", false},
+		{"User", "roles", "array", "Role", false},
+		{"User", "address", "object", "Address", false}, // a type of the imported xsd
+		{"User", "version", "number", "", true},         // an attribute
+		{"Admin", "name", "string", "", true},           // of the base type
+		{"Admin", "level", "number", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.component+"."+tt.property, func(t *testing.T) {
+			comp := resp.Components.FindComponentByName(tt.component)
+
+			prop := loadertest.Property(comp, tt.property)
+			if nil == prop {
+				t.Fatalf("%s has no %s property", tt.component, tt.property)
+			}
+
+			if ref := loadertest.RefName(prop.Ref); prop.Type != tt.typ || ref != tt.ref {
+				t.Errorf("the property is a %s of %q, want a %s of %q", prop.Type, ref, tt.typ, tt.ref)
+			}
+
+			if nil == prop.Required || *prop.Required != tt.required {
+				t.Errorf("required = %v, want %t", prop.Required, tt.required)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{"{", "<definitions", "not xml at all"} {
+		if _, err := (Loader{}).Load("users.wsdl", []byte(data)); nil == err {
+			t.Errorf("%q loaded without an error", data)
+		}
+	}
+}
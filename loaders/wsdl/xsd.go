@@ -0,0 +1,492 @@
+package wsdl
+
+import (
+	"github.com/spirefy/go-codegen/loaders"
+	"strconv"
+	"strings"
+)
+
+// XMLSchema is the namespace of XML Schema (XSD)
+const XMLSchema = "http://www.w3.org/2001/XMLSchema"
+
+// builtins maps the XML Schema built in types to schemas
+var builtins = map[string]loaders.Schema{
+	"string":             {Type: "string"},
+	"normalizedString":   {Type: "string"},
+	"token":              {Type: "string"},
+	"language":           {Type: "string"},
+	"Name":               {Type: "string"},
+	"NCName":             {Type: "string"},
+	"NMTOKEN":            {Type: "string"},
+	"ID":                 {Type: "string"},
+	"IDREF":              {Type: "string"},
+	"QName":              {Type: "string"},
+	"anyURI":             {Type: "string", Format: "uri"},
+	"boolean":            {Type: "boolean"},
+	"byte":               {Type: "integer", Format: "int32"},
+	"short":              {Type: "integer", Format: "int32"},
+	"int":                {Type: "integer", Format: "int32"},
+	"integer":            {Type: "integer"},
+	"long":               {Type: "integer", Format: "int64"},
+	"unsignedByte":       {Type: "integer", Format: "int32"},
+	"unsignedShort":      {Type: "integer", Format: "int32"},
+	"unsignedInt":        {Type: "integer", Format: "int64"},
+	"unsignedLong":       {Type: "integer", Format: "int64"},
+	"positiveInteger":    {Type: "integer"},
+	"nonNegativeInteger": {Type: "integer"},
+	"negativeInteger":    {Type: "integer"},
+	"nonPositiveInteger": {Type: "integer"},
+	"decimal":            {Type: "number", Format: "double"},
+	"double":             {Type: "number", Format: "double"},
+	"float":              {Type: "number", Format: "float"},
+	"date":               {Type: "string", Format: "date"},
+	"dateTime":           {Type: "string", Format: "date-time"},
+	"time":               {Type: "string", Format: "time"},
+	"duration":           {Type: "string", Format: "duration"},
+	"gYear":              {Type: "string"},
+	"gYearMonth":         {Type: "string"},
+	"base64Binary":       {Type: "string", Format: "byte"},
+	"hexBinary":          {Type: "string", Format: "binary"},
+	"anyType":            {},
+	"anySimpleType":      {Type: "string"},
+}
+
+type xsdSchema struct {
+	TargetNamespace string            `xml:"targetNamespace,attr"`
+	Imports         []*xsdImport      `xml:"import"`
+	Includes        []*xsdImport      `xml:"include"`
+	Elements        []*xsdElement     `xml:"element"`
+	ComplexTypes    []*xsdComplexType `xml:"complexType"`
+	SimpleTypes     []*xsdSimpleType  `xml:"simpleType"`
+}
+
+type xsdImport struct {
+	Namespace      string `xml:"namespace,attr"`
+	SchemaLocation string `xml:"schemaLocation,attr"`
+}
+
+type xsdAnnotation struct {
+	Documentation []string `xml:"documentation"`
+}
+
+func (a *xsdAnnotation) String() string {
+	if nil == a {
+		return ""
+	}
+
+	docs := make([]string, 0, len(a.Documentation))
+	for _, d := range a.Documentation {
+		if d = strings.TrimSpace(d); len(d) > 0 {
+			docs = append(docs, d)
+		}
+	}
+
+	return strings.Join(docs, "\n")
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Type        string          `xml:"type,attr"`
+	Ref         string          `xml:"ref,attr"`
+	MinOccurs   string          `xml:"minOccurs,attr"`
+	MaxOccurs   string          `xml:"maxOccurs,attr"`
+	Nillable    bool            `xml:"nillable,attr"`
+	Default     string          `xml:"default,attr"`
+	Annotation  *xsdAnnotation  `xml:"annotation"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+	SimpleType  *xsdSimpleType  `xml:"simpleType"`
+}
+
+type xsdComplexType struct {
+	Name           string          `xml:"name,attr"`
+	Annotation     *xsdAnnotation  `xml:"annotation"`
+	Sequence       *xsdGroup       `xml:"sequence"`
+	All            *xsdGroup       `xml:"all"`
+	Choice         *xsdGroup       `xml:"choice"`
+	Attributes     []*xsdAttribute `xml:"attribute"`
+	ComplexContent *xsdContent     `xml:"complexContent"`
+	SimpleContent  *xsdContent     `xml:"simpleContent"`
+}
+
+// xsdGroup is a sequence, all or choice model group
+type xsdGroup struct {
+	MinOccurs string        `xml:"minOccurs,attr"`
+	MaxOccurs string        `xml:"maxOccurs,attr"`
+	Elements  []*xsdElement `xml:"element"`
+	Sequences []*xsdGroup   `xml:"sequence"`
+	Choices   []*xsdGroup   `xml:"choice"`
+}
+
+type xsdContent struct {
+	Extension   *xsdDerivation `xml:"extension"`
+	Restriction *xsdDerivation `xml:"restriction"`
+}
+
+// xsdDerivation is the extension or restriction of a base type
+type xsdDerivation struct {
+	Base         string          `xml:"base,attr"`
+	Sequence     *xsdGroup       `xml:"sequence"`
+	All          *xsdGroup       `xml:"all"`
+	Choice       *xsdGroup       `xml:"choice"`
+	Attributes   []*xsdAttribute `xml:"attribute"`
+	Enumerations []*xsdFacet     `xml:"enumeration"`
+	Pattern      *xsdFacet       `xml:"pattern"`
+}
+
+type xsdFacet struct {
+	Value string `xml:"value,attr"`
+}
+
+type xsdSimpleType struct {
+	Name        string         `xml:"name,attr"`
+	Annotation  *xsdAnnotation `xml:"annotation"`
+	Restriction *xsdDerivation `xml:"restriction"`
+	List        *struct {
+		ItemType string `xml:"itemType,attr"`
+	} `xml:"list"`
+	Union *struct {
+		MemberTypes string `xml:"memberTypes,attr"`
+	} `xml:"union"`
+}
+
+type xsdAttribute struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Ref        string         `xml:"ref,attr"`
+	Use        string         `xml:"use,attr"`
+	Default    string         `xml:"default,attr"`
+	Annotation *xsdAnnotation `xml:"annotation"`
+	SimpleType *xsdSimpleType `xml:"simpleType"`
+}
+
+// localName returns a QName without its namespace prefix, e.g. User for tns:User
+func localName(qname string) string {
+	if indx := strings.LastIndex(qname, ":"); indx >= 0 {
+		return qname[indx+1:]
+	}
+
+	return qname
+}
+
+// declare adds every named type and top level element of the schemas to the schemas of the loader. Types and elements
+// are in different symbol spaces in XSD, so an element that shares the name of a type gets its own name, unless it is
+// simply an element of that type.
+func (l *loader) declare(schemas []*xsdSchema) {
+	for _, schema := range schemas {
+		for _, t := range schema.ComplexTypes {
+			l.declareType(t.Name)
+		}
+
+		for _, t := range schema.SimpleTypes {
+			l.declareType(t.Name)
+		}
+	}
+
+	for _, schema := range schemas {
+		for _, e := range schema.Elements {
+			if _, ok := l.elements[e.Name]; ok || len(e.Name) <= 0 {
+				continue
+			}
+
+			if _, ok := l.schemas[e.Name]; isAlias(e) {
+				// the type may be defined in an imported schema, the element is linked to it either way
+				l.elements[e.Name] = e.Name
+				continue
+			} else if ok {
+				l.elements[e.Name] = e.Name + "Element"
+			} else {
+				l.elements[e.Name] = e.Name
+			}
+
+			l.schemas[l.elements[e.Name]] = &loaders.Schema{}
+			l.names = append(l.names, l.elements[e.Name])
+		}
+	}
+}
+
+func (l *loader) declareType(name string) {
+	if _, ok := l.schemas[name]; ok || len(name) <= 0 {
+		return
+	}
+
+	l.schemas[name] = &loaders.Schema{}
+	l.names = append(l.names, name)
+}
+
+// define fills in the schemas declared for the schemas
+func (l *loader) define(schemas []*xsdSchema) {
+	for _, schema := range schemas {
+		for _, t := range schema.ComplexTypes {
+			if len(t.Name) > 0 {
+				*l.schemas[t.Name] = *l.complexType(t)
+			}
+		}
+
+		for _, t := range schema.SimpleTypes {
+			if len(t.Name) > 0 {
+				*l.schemas[t.Name] = *l.simpleType(t)
+			}
+		}
+
+		for _, e := range schema.Elements {
+			if name, ok := l.elements[e.Name]; ok && !(name == e.Name && isAlias(e)) {
+				l.topElement(name, e)
+			}
+		}
+	}
+}
+
+func (l *loader) topElement(name string, e *xsdElement) {
+	s := l.elementType(e)
+	if len(s.Ref) > 0 {
+		// an element of a named type has the same shape as the type
+		s = &loaders.Schema{AllOf: []*loaders.Schema{s}}
+	}
+
+	if description := e.Annotation.String(); len(description) > 0 {
+		s.Description = description
+	}
+
+	*l.schemas[name] = *s
+}
+
+// isAlias returns true for a top level element of the named type with the same name, e.g. <element name="User" type="tns:User"/>
+func isAlias(e *xsdElement) bool {
+	return localName(e.Type) == e.Name && nil == e.ComplexType && nil == e.SimpleType
+}
+
+// typeSchema returns the schema of a QName type: built in types are inlined, named types are $refs
+func (l *loader) typeSchema(qname string) *loaders.Schema {
+	name := localName(qname)
+
+	if _, defined := l.schemas[name]; !defined || strings.HasPrefix(qname, "xs:") || strings.HasPrefix(qname, "xsd:") {
+		if s, ok := builtins[name]; ok {
+			return &s
+		}
+	}
+
+	if _, defined := l.schemas[name]; !defined {
+		l.unresolved(name)
+	}
+
+	return &loaders.Schema{Ref: schemaRef + name}
+}
+
+// elementRef returns the schema of a reference to a top level element
+func (l *loader) elementRef(qname string) *loaders.Schema {
+	name := localName(qname)
+
+	if key, ok := l.elements[name]; ok {
+		return &loaders.Schema{Ref: schemaRef + key}
+	}
+
+	l.unresolved(name)
+	return &loaders.Schema{Ref: schemaRef + name}
+}
+
+// unresolved reports a name that is not defined, unless the document imports other documents which may define it
+func (l *loader) unresolved(name string) {
+	if len(l.response.Imports) > 0 || l.reported[name] {
+		return
+	}
+
+	l.reported[name] = true
+	l.response.Diagnostics.Warn("unresolved-ref", l.source, "%s is not defined in this document", name)
+}
+
+// elementType returns the schema of the type of an element, without its occurrence
+func (l *loader) elementType(e *xsdElement) *loaders.Schema {
+	var s *loaders.Schema
+
+	switch {
+	case len(e.Ref) > 0:
+		s = l.elementRef(e.Ref)
+	case nil != e.ComplexType:
+		s = l.complexType(e.ComplexType)
+	case nil != e.SimpleType:
+		s = l.simpleType(e.SimpleType)
+	case len(e.Type) > 0:
+		s = l.typeSchema(e.Type)
+	default:
+		s = &loaders.Schema{}
+	}
+
+	if len(e.Default) > 0 {
+		s.Default = e.Default
+	}
+
+	return s
+}
+
+// element returns the schema of an element used as a property, an element that may occur more than once is an array
+func (l *loader) element(e *xsdElement, group *xsdGroup) *loaders.Schema {
+	s := l.elementType(e)
+
+	if repeated(e.MaxOccurs) || (nil != group && repeated(group.MaxOccurs)) {
+		s = &loaders.Schema{Type: "array", Items: s}
+	}
+
+	if e.Nillable {
+		s.Nullable = true
+	}
+
+	if description := e.Annotation.String(); len(description) > 0 {
+		s.Description = description
+	}
+
+	return s
+}
+
+func (l *loader) complexType(t *xsdComplexType) *loaders.Schema {
+	s := &loaders.Schema{
+		Type:        "object",
+		Description: t.Annotation.String(),
+		Properties:  make(map[string]*loaders.Schema),
+	}
+
+	l.group(s, t.Sequence, false)
+	l.group(s, t.All, false)
+	l.group(s, t.Choice, true)
+	l.attributes(s, t.Attributes)
+
+	var content *xsdContent
+	if nil != t.ComplexContent {
+		content = t.ComplexContent
+	} else if nil != t.SimpleContent {
+		content = t.SimpleContent
+	}
+
+	if nil == content {
+		return s
+	}
+
+	derivation := content.Extension
+	if nil == derivation {
+		derivation = content.Restriction
+	}
+
+	if nil == derivation {
+		return s
+	}
+
+	l.group(s, derivation.Sequence, false)
+	l.group(s, derivation.All, false)
+	l.group(s, derivation.Choice, true)
+	l.attributes(s, derivation.Attributes)
+
+	base := l.typeSchema(derivation.Base)
+	if nil != t.SimpleContent {
+		// the text of the element is its value, next to its attributes
+		s.Properties["value"] = base
+		s.Required = append(s.Required, "value")
+	} else if nil != content.Extension && len(base.Ref) > 0 {
+		s.AllOf = []*loaders.Schema{base}
+	}
+
+	return s
+}
+
+// group adds the elements of a model group, and the groups nested in it, as properties. The elements of a choice, or of
+// an optional group, are not required.
+func (l *loader) group(s *loaders.Schema, g *xsdGroup, choice bool) {
+	if nil == g {
+		return
+	}
+
+	optional := choice || g.MinOccurs == "0"
+
+	for _, e := range g.Elements {
+		name := e.Name
+		if len(name) <= 0 {
+			name = localName(e.Ref)
+		}
+
+		if len(name) <= 0 {
+			continue
+		}
+
+		prop := l.element(e, g)
+		if choice {
+			prop.Nullable = true
+		}
+
+		s.Properties[name] = prop
+		if !optional && e.MinOccurs != "0" {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	for _, nested := range g.Sequences {
+		l.group(s, nested, optional)
+	}
+
+	for _, nested := range g.Choices {
+		l.group(s, nested, true)
+	}
+}
+
+func (l *loader) attributes(s *loaders.Schema, attributes []*xsdAttribute) {
+	for _, a := range attributes {
+		name := a.Name
+		if len(name) <= 0 {
+			name = localName(a.Ref)
+		}
+
+		if len(name) <= 0 {
+			continue
+		}
+
+		var prop *loaders.Schema
+		switch {
+		case nil != a.SimpleType:
+			prop = l.simpleType(a.SimpleType)
+		case len(a.Type) > 0:
+			prop = l.typeSchema(a.Type)
+		default:
+			prop = &loaders.Schema{Type: "string"}
+		}
+
+		prop.Description = a.Annotation.String()
+		if len(a.Default) > 0 {
+			prop.Default = a.Default
+		}
+
+		s.Properties[name] = prop
+		if a.Use == "required" {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func (l *loader) simpleType(t *xsdSimpleType) *loaders.Schema {
+	var s *loaders.Schema
+
+	switch {
+	case nil != t.Restriction:
+		// a restriction of another simple type is a $ref to it, which gives it the type of its base
+		s = l.typeSchema(t.Restriction.Base)
+		for _, e := range t.Restriction.Enumerations {
+			s.Enum = append(s.Enum, e.Value)
+		}
+	case nil != t.List:
+		s = &loaders.Schema{Type: "array", Items: l.typeSchema(t.List.ItemType)}
+	default:
+		s = &loaders.Schema{Type: "string"}
+	}
+
+	if description := t.Annotation.String(); len(description) > 0 {
+		s.Description = description
+	}
+
+	return s
+}
+
+// repeated returns true if a maxOccurs allows more than one occurrence
+func repeated(maxOccurs string) bool {
+	if maxOccurs == "unbounded" {
+		return true
+	}
+
+	n, err := strconv.Atoi(maxOccurs)
+	return nil == err && n > 1
+}
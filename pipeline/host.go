@@ -0,0 +1,33 @@
+package pipeline
+
+// The extension points the codegen plugin declares in plugin.yaml
+const (
+	LoadersExtensionPoint    = "spirefy.plugins.codegen.loaders"
+	GeneratorsExtensionPoint = "spirefy.plugins.codegen.generators"
+)
+
+// Extension describes an extension contributed to one of the codegen extension points
+type Extension struct {
+	Id             string
+	Name           string
+	ExtensionPoint string
+	Func           string
+}
+
+// Host
+//
+// This is everything the pipeline needs from whatever it runs in. Inside the Spirefy host it is backed by the host
+// functions of the plugin engine, natively it is backed by a Registry of in-process extensions and the local file system.
+type Host interface {
+	// LoadFile returns the content of the source at the provided path/url
+	LoadFile(path string) ([]byte, error)
+
+	// GetExtensionsForExtensionPoint returns every extension contributed to the provided extension point
+	GetExtensionsForExtensionPoint(extensionPoint string) ([]Extension, error)
+
+	// CallExtension calls the function of the extension with the provided id, passing it input, and returns its output
+	CallExtension(id string, input []byte) ([]byte, error)
+
+	// Log writes a debug message
+	Log(message string)
+}
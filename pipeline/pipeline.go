@@ -0,0 +1,371 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"github.com/spirefy/go-codegen/types"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// probe
+//
+// This function will pass the header sample of a source to every loader extension and return the extension that
+// replied with the highest confidence that it can parse the source. If no loader can parse it, nil is returned.
+func probe(host Host, extensions []Extension, src string, header []byte) *Extension {
+	var best *Extension
+	bestConfidence := types.ConfidenceNone
+
+	input, err := json.Marshal(types.LoaderRequest{Action: types.LoaderProbe, Source: src, Data: header})
+	if nil != err {
+		host.Log("Problem marshalling probe request: " + err.Error())
+		return nil
+	}
+
+	for i, ext := range extensions {
+		extResp, err := host.CallExtension(ext.Id, input)
+		if nil != err {
+			host.Log("error probing extension " + ext.Id + ": " + err.Error())
+			continue
+		}
+
+		if nil == extResp || len(extResp) <= 0 {
+			continue
+		}
+
+		resp := types.ProbeResponse{}
+		if err = json.Unmarshal(extResp, &resp); nil != err {
+			host.Log("Problem unmarshalling probe response from " + ext.Id + ": " + err.Error())
+			continue
+		}
+
+		host.Log("extension " + ext.Id + " probed " + src + " with confidence " + strconv.Itoa(resp.Confidence))
+
+		if resp.Confidence > bestConfidence {
+			best = &extensions[i]
+			bestConfidence = resp.Confidence
+		}
+	}
+
+	return best
+}
+
+// load
+//
+// This function will load every source of the request, hand it to the loader extension best suited to parse it and merge
+// the resulting resources, components and workflows in to the provided run wide model. Sources that can not be loaded
+// are skipped and reported as warnings (which fail the run if the request is strict).
+func load(host Host, request *types.CodegenRequest, model *types.LoadedResponse, diagnostics *types.Diagnostics) {
+	// now loop through loader extensions, and pass the first bytes of data to them to determine if they can load it or not
+	extensions, err := host.GetExtensionsForExtensionPoint(LoadersExtensionPoint)
+	if nil != err {
+		diagnostics.Error(types.CodeInternal, "", "problem getting loader extensions: %s", err.Error())
+		return
+	}
+
+	if nil == extensions || len(extensions) <= 0 {
+		diagnostics.Error(types.CodeNoLoaders, "", "no loader extensions are contributed to "+LoadersExtensionPoint)
+		return
+	}
+
+	// imports of sources are added to the sources as they are found, each source is only loaded once
+	sources := make([]string, 0, len(request.Sources))
+	loaded := make(map[string]bool, len(request.Sources))
+	for _, src := range request.Sources {
+		src = strings.TrimSpace(src)
+		if !loaded[src] {
+			loaded[src] = true
+			sources = append(sources, src)
+		}
+	}
+
+	// an import can be relative to the importing source or to any of its parent directories (protobuf imports are relative
+	// to the root of the proto files for example), these are the locations to try for each import
+	imports := make(map[string]*sourceImport)
+
+	for i := 0; i < len(sources); i++ {
+		src := sources[i]
+		data, err := host.LoadFile(src)
+
+		if imp, ok := imports[src]; ok {
+			for _, alt := range imp.locations[1:] {
+				if nil == err {
+					break
+				}
+
+				if data, err = host.LoadFile(alt); nil == err {
+					src = alt
+				}
+			}
+
+			if nil != err && len(imp.locations) > 1 {
+				diagnostics.Warn(types.CodeSourceUnreadable, imp.by, "problem loading %s imported by %s, it is at none of %s: %s", imp.name, imp.by, strings.Join(imp.locations, ", "), err.Error())
+				continue
+			}
+		}
+
+		if nil != err {
+			diagnostics.Warn(types.CodeSourceUnreadable, src, "problem loading source: %s", err.Error())
+			continue
+		}
+
+		if nil == data || len(data) <= 0 {
+			diagnostics.Warn(types.CodeSourceUnreadable, src, "source is empty")
+			continue
+		}
+
+		header := data
+		if len(header) > types.ProbeHeaderSize {
+			header = header[:types.ProbeHeaderSize]
+		}
+
+		ext := probe(host, extensions, src, header)
+		if nil == ext {
+			diagnostics.Warn(types.CodeNoLoader, src, "no loader extension can parse this source")
+			continue
+		}
+
+		host.Log("Loading " + src + " with extension: " + ext.Id)
+		input, err := json.Marshal(types.LoaderRequest{Action: types.LoaderLoad, Source: src, Data: data})
+		if nil != err {
+			diagnostics.Error(types.CodeInternal, src, "problem marshalling load request: %s", err.Error())
+			continue
+		}
+
+		extResp, err := host.CallExtension(ext.Id, input)
+		if nil != err {
+			diagnostics.Warn(types.CodeLoaderFailed, src, "loader %s failed: %s", ext.Id, err.Error())
+			continue
+		}
+
+		if nil == extResp || len(extResp) <= 0 {
+			diagnostics.Warn(types.CodeLoaderFailed, src, "loader %s returned nothing for %s", ext.Id, src)
+			continue
+		}
+
+		resp := types.LoadedResponse{}
+		if err = json.Unmarshal(extResp, &resp); nil != err {
+			diagnostics.Warn(types.CodeLoaderFailed, src, "problem unmarshalling response of loader %s: %s", ext.Id, err.Error())
+			continue
+		}
+
+		for _, diagnostic := range resp.Diagnostics {
+			if nil != diagnostic {
+				if len(diagnostic.SourceDoc) <= 0 {
+					diagnostic.SourceDoc = src
+				}
+				*diagnostics = append(*diagnostics, diagnostic)
+			}
+		}
+		resp.Diagnostics = nil
+
+		for _, imp := range resp.Imports {
+			locations := importLocations(src, imp)
+			if !loaded[locations[0]] {
+				host.Log(src + " imports " + imp)
+				loaded[locations[0]] = true
+				sources = append(sources, locations[0])
+				imports[locations[0]] = &sourceImport{name: imp, by: src, locations: locations}
+			}
+		}
+
+		host.Log("Loaded " + strconv.Itoa(len(resp.Resources)) + " resources, " + strconv.Itoa(len(resp.Components)) +
+			" components and " + strconv.Itoa(len(resp.Workflows)) + " workflows from " + src)
+		*diagnostics = append(*diagnostics, model.Merge(&resp)...)
+	}
+}
+
+// sourceImport is an import of a source: the import as written, the source importing it and the locations it may be at
+type sourceImport struct {
+	name      string
+	by        string
+	locations []string
+}
+
+// importLocations
+//
+// This function returns the locations a source imported by another source may be at, in the order to try them: relative
+// to the directory (or url) of the importing source first and then relative to each of its parent directories. An
+// absolute import only has one location.
+func importLocations(src, imp string) []string {
+	if strings.Contains(imp, "://") || strings.HasPrefix(imp, "/") {
+		return []string{imp}
+	}
+
+	prefix := ""
+	if indx := strings.Index(src, "://"); indx >= 0 {
+		// path.Join would collapse the // of the scheme
+		prefix, src = src[:indx+3], src[indx+3:]
+	}
+
+	locations := make([]string, 0)
+	for dir := path.Dir(src); ; dir = path.Dir(dir) {
+		locations = append(locations, prefix+path.Join(dir, imp))
+		if dir == "." || dir == "/" || (len(prefix) > 0 && !strings.Contains(dir, "/")) {
+			return locations
+		}
+	}
+}
+
+// findGenerator
+//
+// This function will return the generator extension that matches the provided target name. A target matches an extension
+// when it is equal (ignoring case) to the extension name or to the last segment of the extension id, e.g. the target
+// go-models matches an extension with id acme.codegen.generators.go-models.
+func findGenerator(extensions []Extension, target string) *Extension {
+	for i, ext := range extensions {
+		id := ext.Id
+		if indx := strings.LastIndex(id, "."); indx >= 0 {
+			id = id[indx+1:]
+		}
+
+		if strings.EqualFold(ext.Name, target) || strings.EqualFold(id, target) {
+			return &extensions[i]
+		}
+	}
+
+	return nil
+}
+
+// generate
+//
+// This function will pass the model to the generator extension of every target of the request and return all the files
+// they produced. Targets that do not match any generator extension, or whose generator fails, are reported as errors. A
+// file whose path was already generated by an earlier target is reported as an error and dropped instead of overwriting it.
+func generate(host Host, request *types.CodegenRequest, model *types.LoadedResponse, diagnostics *types.Diagnostics) []types.GeneratedFile {
+	files := make([]types.GeneratedFile, 0)
+
+	// the target that generated each path
+	generated := make(map[string]string)
+
+	extensions, err := host.GetExtensionsForExtensionPoint(GeneratorsExtensionPoint)
+	if nil != err {
+		diagnostics.Error(types.CodeInternal, "", "problem getting generator extensions: %s", err.Error())
+		return files
+	}
+
+	for _, tgt := range request.Targets {
+		tgt = strings.TrimSpace(tgt)
+		host.Log("Target: " + tgt)
+
+		ext := findGenerator(extensions, tgt)
+		if nil == ext {
+			available := make([]string, 0, len(extensions))
+			for _, e := range extensions {
+				available = append(available, e.Name)
+			}
+
+			diagnostics.Error(types.CodeUnknownTarget, tgt, "unknown target, available targets are: %s", strings.Join(available, ", "))
+			continue
+		}
+
+		input, err := json.Marshal(types.GeneratorRequest{Target: tgt, Options: request.TargetOptions(tgt), Model: model})
+		if nil != err {
+			diagnostics.Error(types.CodeInternal, tgt, "problem marshalling model: %s", err.Error())
+			continue
+		}
+
+		extResp, err := host.CallExtension(ext.Id, input)
+		if nil != err {
+			diagnostics.Error(types.CodeGeneratorFailed, tgt, "generator %s failed: %s", ext.Id, err.Error())
+			continue
+		}
+
+		resp := types.GeneratorResponse{}
+		if nil != extResp && len(extResp) > 0 {
+			if err = json.Unmarshal(extResp, &resp); nil != err {
+				diagnostics.Error(types.CodeGeneratorFailed, tgt, "problem unmarshalling response of generator %s: %s", ext.Id, err.Error())
+				continue
+			}
+		}
+
+		for _, diagnostic := range resp.Diagnostics {
+			if nil != diagnostic {
+				if len(diagnostic.SourceDoc) <= 0 {
+					diagnostic.SourceDoc = tgt
+				}
+				*diagnostics = append(*diagnostics, diagnostic)
+			}
+		}
+
+		host.Log("Target " + tgt + " generated " + strconv.Itoa(len(resp.Files)) + " files")
+
+		for _, file := range resp.Files {
+			if len(request.OutputDir) > 0 {
+				file.Path = path.Join(request.OutputDir, file.Path)
+			}
+
+			if other, ok := generated[path.Clean(file.Path)]; ok {
+				diagnostics.Error(types.CodeDuplicateFile, tgt, "%s was already generated by target %s", file.Path, other)
+				continue
+			}
+			generated[path.Clean(file.Path)] = tgt
+
+			if request.DryRun {
+				file.Content = nil
+			}
+
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// Run
+//
+// This function runs the whole codegen pipeline for the provided request using the provided host: every source is
+// loaded with the best suited loader extension, merged in to a single model, filtered, and handed to the generator
+// extension of every target. The returned result holds all the generated files and diagnostics.
+func Run(host Host, request *types.CodegenRequest) *types.CodegenResult {
+	result := &types.CodegenResult{
+		Version:     types.CodegenRequestVersion,
+		Files:       make([]types.GeneratedFile, 0),
+		Diagnostics: make(types.Diagnostics, 0),
+	}
+
+	model := types.NewLoadedResponse()
+	load(host, request, model, &result.Diagnostics)
+	result.Diagnostics = append(result.Diagnostics, model.ResolveWorkflows()...)
+
+	request.Filter(model)
+	host.Log("Model has " + strconv.Itoa(len(model.Resources)) + " resources, " + strconv.Itoa(len(model.Components)) +
+		" components and " + strconv.Itoa(len(model.Workflows)) + " workflows")
+
+	// do not generate from a model that is known to be broken
+	if len(request.Targets) > 0 && !result.Diagnostics.Failed(request.Strict) {
+		result.Files = generate(host, request, model, &result.Diagnostics)
+	}
+
+	for _, diagnostic := range result.Diagnostics {
+		host.Log(diagnostic.String())
+	}
+
+	return result
+}
+
+// RunInput
+//
+// This function will parse the provided input with types.ParseCodegenRequest and Run it. Input that can not be parsed
+// results in a single CodeInvalidRequest diagnostic. The returned bool is true when the run failed, see Diagnostics.Failed.
+func RunInput(host Host, input []byte) (*types.CodegenResult, bool) {
+	request, err := types.ParseCodegenRequest(input)
+	if nil != err {
+		result := &types.CodegenResult{
+			Version:     types.CodegenRequestVersion,
+			Files:       make([]types.GeneratedFile, 0),
+			Diagnostics: make(types.Diagnostics, 0),
+		}
+
+		diagnostic := result.Diagnostics.Error(types.CodeInvalidRequest, "", "%s", err.Error())
+		if reqErr, ok := err.(*types.RequestError); ok && len(reqErr.Field) > 0 {
+			diagnostic.Location = &types.Location{Path: reqErr.Field}
+		}
+
+		host.Log(diagnostic.String())
+		return result, true
+	}
+
+	result := Run(host, request)
+	return result, result.Diagnostics.Failed(request.Strict)
+}
@@ -0,0 +1,388 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/spirefy/go-codegen/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// stubLoader is sure it can parse sources starting with prefix, and loads them as a single resource named after the loader
+type stubLoader struct {
+	name       string
+	prefix     string
+	confidence int
+	empty      bool // the extension replies to a load with nothing, which only a plugin can do
+	err        error
+	imports    []string
+}
+
+func (l *stubLoader) Probe(_ string, header []byte) int {
+	if strings.HasPrefix(string(header), l.prefix) {
+		return l.confidence
+	}
+
+	return types.ConfidenceNone
+}
+
+func (l *stubLoader) Load(source string, _ []byte) (*types.LoadedResponse, error) {
+	if nil != l.err {
+		return nil, l.err
+	}
+
+	resp := types.NewLoadedResponse()
+	resp.Imports = l.imports
+
+	res := &types.Resource{Id: len(source), Name: l.name, Path: "/" + filepath.Base(source), Method: "get", Latest: true}
+	res.ResourceId = resp.Resources.MakeUniqueId(res.Path, res.Method)
+	resp.Resources = append(resp.Resources, res)
+	return resp, nil
+}
+
+// register adds the loader to the registry
+func (l *stubLoader) register(registry *Registry) {
+	if !l.empty {
+		registry.RegisterLoader("test.loaders."+l.name, l.name, l)
+		return
+	}
+
+	registry.Register(Extension{Id: "test.loaders." + l.name, Name: l.name, ExtensionPoint: LoadersExtensionPoint}, func(input []byte) ([]byte, error) {
+		request := types.LoaderRequest{}
+		if err := json.Unmarshal(input, &request); nil != err || request.Action != types.LoaderProbe {
+			return nil, err
+		}
+
+		return ServeLoader(l, input)
+	})
+}
+
+// stubGenerator writes a file per path, holding the names of the resources of the model, and remembers the last request
+type stubGenerator struct {
+	paths   []string
+	err     error
+	request *types.GeneratorRequest
+}
+
+func (g *stubGenerator) Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error) {
+	g.request = request
+	if nil != g.err {
+		return nil, g.err
+	}
+
+	names := make([]string, 0, len(request.Model.Resources))
+	for _, res := range request.Model.Resources {
+		names = append(names, res.Name)
+	}
+
+	resp := &types.GeneratorResponse{}
+	for _, p := range g.paths {
+		resp.Files = append(resp.Files, types.GeneratedFile{Path: p, Content: []byte(strings.Join(names, ","))})
+	}
+	return resp, nil
+}
+
+// source writes a source file in dir and returns its path
+func source(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); nil != err {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+// codes returns the severity and code of every diagnostic
+func codes(diagnostics types.Diagnostics) []string {
+	list := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		list = append(list, string(d.Severity)+":"+d.Code)
+	}
+
+	return list
+}
+
+func TestRun(t *testing.T) {
+	loadFailed := errors.New("broken")
+
+	tests := []struct {
+		name      string
+		loaders   []*stubLoader
+		sources   map[string]string // file name to content
+		run       []string          // the names of the sources of the run, every source when empty
+		request   types.CodegenRequest
+		generator *stubGenerator
+		other     *stubGenerator // registered as the "other" target
+		files     map[string]string
+		codes     []string
+		failed    bool
+	}{
+		{
+			name: "the loader with the highest confidence loads the source",
+			loaders: []*stubLoader{
+				{name: "low", prefix: "api", confidence: types.ConfidenceLow},
+				{name: "high", prefix: "api", confidence: types.ConfidenceHigh},
+			},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{"out.txt": "high"},
+			codes:     []string{},
+		},
+		{
+			name: "the first loader wins a tie",
+			loaders: []*stubLoader{
+				{name: "first", prefix: "api", confidence: types.ConfidenceHigh},
+				{name: "second", prefix: "api", confidence: types.ConfidenceHigh},
+			},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{"out.txt": "first"},
+			codes:     []string{},
+		},
+		{
+			name:      "a source no loader can parse is skipped with a warning",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources:   map[string]string{"a.txt": "api", "b.txt": "other"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{"out.txt": "api"},
+			codes:     []string{"warning:" + types.CodeNoLoader},
+		},
+		{
+			name:      "strict fails on a source no loader can parse and generates nothing",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources:   map[string]string{"a.txt": "api", "b.txt": "other"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}, Strict: true},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{},
+			codes:     []string{"warning:" + types.CodeNoLoader},
+			failed:    true,
+		},
+		{
+			name:      "a loader that fails is reported",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh, err: loadFailed}},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{"out.txt": ""},
+			codes:     []string{"warning:" + types.CodeLoaderFailed},
+		},
+		{
+			name:      "a loader that replies with nothing is reported",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh, empty: true}},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{"out.txt": ""},
+			codes:     []string{"warning:" + types.CodeLoaderFailed},
+		},
+		{
+			name:    "an empty source is reported",
+			loaders: []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources: map[string]string{"a.txt": ""},
+			request: types.CodegenRequest{},
+			files:   map[string]string{},
+			codes:   []string{"warning:" + types.CodeSourceUnreadable},
+		},
+		{
+			name:      "imports are loaded relative to the importing source",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh, imports: []string{"b.txt"}}},
+			sources:   map[string]string{"a.txt": "api", "b.txt": "api"},
+			run:       []string{"a.txt"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{"out.txt": "api,api"},
+			codes:     []string{},
+		},
+		{
+			name:      "files are made relative to the output directory",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}, OutputDir: "gen"},
+			generator: &stubGenerator{paths: []string{"out.txt", "sub/more.txt"}},
+			files:     map[string]string{"gen/out.txt": "api", "gen/sub/more.txt": "api"},
+			codes:     []string{},
+		},
+		{
+			name:      "a dry run only returns the paths",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}, OutputDir: "gen", DryRun: true},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{"gen/out.txt": ""},
+			codes:     []string{},
+		},
+		{
+			name:      "an unknown target is an error",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"nope"}},
+			generator: &stubGenerator{paths: []string{"out.txt"}},
+			files:     map[string]string{},
+			codes:     []string{"error:" + types.CodeUnknownTarget},
+			failed:    true,
+		},
+		{
+			name:      "a generator that fails is an error",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub"}},
+			generator: &stubGenerator{err: errors.New("broken")},
+			files:     map[string]string{},
+			codes:     []string{"error:" + types.CodeGeneratorFailed},
+			failed:    true,
+		},
+		{
+			name:      "a path generated by two targets is an error and is not overwritten",
+			loaders:   []*stubLoader{{name: "api", prefix: "api", confidence: types.ConfidenceHigh}},
+			sources:   map[string]string{"a.txt": "api"},
+			request:   types.CodegenRequest{Targets: []string{"stub", "other"}},
+			generator: &stubGenerator{paths: []string{"out.txt", "stub.txt"}},
+			other:     &stubGenerator{paths: []string{"./out.txt", "other.txt"}},
+			files:     map[string]string{"out.txt": "api", "stub.txt": "api", "other.txt": "api"},
+			codes:     []string{"error:" + types.CodeDuplicateFile},
+			failed:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			registry := NewRegistry()
+			for _, l := range tt.loaders {
+				l.register(registry)
+			}
+			if nil != tt.generator {
+				registry.RegisterGenerator("test.generators.stub", "stub", tt.generator)
+			}
+			if nil != tt.other {
+				registry.RegisterGenerator("test.generators.other", "other", tt.other)
+			}
+
+			run := tt.run
+			if len(run) <= 0 {
+				run = make([]string, 0, len(tt.sources))
+				for name := range tt.sources {
+					run = append(run, name)
+				}
+				sort.Strings(run)
+			}
+
+			paths := make(map[string]string, len(tt.sources))
+			for name, content := range tt.sources {
+				paths[name] = source(t, dir, name, content)
+			}
+
+			request := tt.request
+			for _, name := range run {
+				request.Sources = append(request.Sources, paths[name])
+			}
+
+			result := Run(registry, &request)
+
+			files := make(map[string]string, len(result.Files))
+			for _, f := range result.Files {
+				files[f.Path] = string(f.Content)
+			}
+			if !reflect.DeepEqual(files, tt.files) {
+				t.Errorf("files = %v, want %v", files, tt.files)
+			}
+
+			if got := codes(result.Diagnostics); !reflect.DeepEqual(got, tt.codes) {
+				t.Errorf("diagnostics = %v, want %v", result.Diagnostics, tt.codes)
+			}
+
+			if failed := result.Diagnostics.Failed(request.Strict); failed != tt.failed {
+				t.Errorf("failed = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestRunReportsMissingImports(t *testing.T) {
+	dir := t.TempDir()
+
+	registry := NewRegistry()
+	registry.RegisterLoader("test.loaders.api", "api", &stubLoader{name: "api", prefix: "api", confidence: types.ConfidenceHigh, imports: []string{"common/missing.txt"}})
+
+	src := source(t, dir, "a.txt", "api")
+	result := Run(registry, &types.CodegenRequest{Sources: []string{src}})
+
+	if got := codes(result.Diagnostics); !reflect.DeepEqual(got, []string{"warning:" + types.CodeSourceUnreadable}) {
+		t.Fatalf("diagnostics = %v, want the import to be unreadable", result.Diagnostics)
+	}
+
+	d := result.Diagnostics[0]
+	if d.SourceDoc != src || !strings.Contains(d.Message, "common/missing.txt imported by "+src) {
+		t.Errorf("the diagnostic %q of %s does not name the import as written and the source importing it", d.Message, d.SourceDoc)
+	}
+
+	locations := importLocations(src, "common/missing.txt")
+	if len(locations) < 2 {
+		t.Fatalf("the import has the locations %v, want more than one", locations)
+	}
+	for _, location := range locations {
+		if !strings.Contains(d.Message, location) {
+			t.Errorf("the diagnostic %q does not list the location %s", d.Message, location)
+		}
+	}
+}
+
+func TestRunPassesTargetOptions(t *testing.T) {
+	dir := t.TempDir()
+	generator := &stubGenerator{}
+
+	registry := NewRegistry()
+	registry.RegisterLoader("test.loaders.api", "api", &stubLoader{name: "api", prefix: "api", confidence: types.ConfidenceHigh})
+	registry.RegisterGenerator("acme.generators.stub", "stub", generator)
+
+	result := Run(registry, &types.CodegenRequest{
+		Sources: []string{source(t, dir, "a.txt", "api")},
+		Targets: []string{"STUB"},
+		Options: map[string]map[string]string{"stub": {"package": "petstore"}, "other": {"package": "other"}},
+	})
+
+	if len(result.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics %v", result.Diagnostics)
+	}
+
+	if nil == generator.request {
+		t.Fatal("the generator was not called")
+	}
+
+	if generator.request.Options["package"] != "petstore" || len(generator.request.Options) != 1 {
+		t.Errorf("options = %v, want the options of the stub target", generator.request.Options)
+	}
+
+	if len(generator.request.Model.Resources) != 1 {
+		t.Errorf("the model has %d resources, want 1", len(generator.request.Model.Resources))
+	}
+}
+
+func TestImportLocations(t *testing.T) {
+	tests := []struct {
+		src, imp string
+		want     []string
+	}{
+		{"protos/api/v1/service.proto", "common.proto", []string{"protos/api/v1/common.proto", "protos/api/common.proto", "protos/common.proto", "common.proto"}},
+		{"/specs/api.yaml", "pet.yaml", []string{"/specs/pet.yaml", "/pet.yaml"}},
+		{"https://example.com/specs/api.yaml", "pet.yaml", []string{"https://example.com/specs/pet.yaml", "https://example.com/pet.yaml"}},
+		{"api.yaml", "/abs/pet.yaml", []string{"/abs/pet.yaml"}},
+		{"api.yaml", "https://example.com/pet.yaml", []string{"https://example.com/pet.yaml"}},
+	}
+
+	for _, tt := range tests {
+		if got := importLocations(tt.src, tt.imp); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("importLocations(%q, %q) = %v, want %v", tt.src, tt.imp, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spirefy/go-codegen/types"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// httpClient fetches the http(s) sources of a Registry, the timeout keeps an unresponsive server from hanging a run
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// ExtensionFunc is the Go equivalent of an exported extension function of a plugin. It takes the json input of the call
+// and returns the json output.
+type ExtensionFunc func(input []byte) ([]byte, error)
+
+// Loader is implemented by loaders that run in-process, see ServeLoader
+type Loader interface {
+	// Probe returns how confident the loader is that it can parse the source, given the first types.ProbeHeaderSize bytes
+	// of it. See types.ConfidenceNone through types.ConfidenceCertain.
+	Probe(source string, header []byte) int
+
+	// Load parses the full source
+	Load(source string, data []byte) (*types.LoadedResponse, error)
+}
+
+// Generator is implemented by generators that run in-process, see ServeGenerator
+type Generator interface {
+	Generate(request *types.GeneratorRequest) (*types.GeneratorResponse, error)
+}
+
+// ServeLoader
+//
+// This function handles a single call of the loader extension protocol (a types.LoaderRequest) with the provided loader.
+// It is used by the in-process Registry as well as by plugin exports wrapping a Loader.
+func ServeLoader(loader Loader, input []byte) ([]byte, error) {
+	request := types.LoaderRequest{}
+	if err := json.Unmarshal(input, &request); nil != err {
+		return nil, fmt.Errorf("problem unmarshalling loader request: %w", err)
+	}
+
+	switch request.Action {
+	case types.LoaderProbe:
+		return json.Marshal(types.ProbeResponse{Confidence: loader.Probe(request.Source, request.Data)})
+	case types.LoaderLoad:
+		resp, err := loader.Load(request.Source, request.Data)
+		if nil != err {
+			return nil, err
+		}
+
+		return json.Marshal(resp)
+	default:
+		return nil, fmt.Errorf("unknown loader action %q", request.Action)
+	}
+}
+
+// ServeGenerator
+//
+// This function handles a single call of the generator extension protocol (a types.GeneratorRequest) with the provided
+// generator. It is used by the in-process Registry as well as by plugin exports wrapping a Generator.
+func ServeGenerator(generator Generator, input []byte) ([]byte, error) {
+	request := types.GeneratorRequest{}
+	if err := json.Unmarshal(input, &request); nil != err {
+		return nil, fmt.Errorf("problem unmarshalling generator request: %w", err)
+	}
+
+	if nil == request.Model {
+		request.Model = types.NewLoadedResponse()
+	}
+
+	resp, err := generator.Generate(&request)
+	if nil != err {
+		return nil, err
+	}
+
+	return json.Marshal(resp)
+}
+
+type registered struct {
+	extension Extension
+	fn        ExtensionFunc
+}
+
+// Registry
+//
+// This is a Host that runs the pipeline natively, with extensions registered in-process instead of being contributed by
+// plugins. Sources are read from the local file system, or fetched when they are http(s) urls. Extensions are returned
+// in the order they were registered.
+type Registry struct {
+	extensions []registered
+	Verbose    bool // When true, Log writes to the standard logger
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{extensions: make([]registered, 0)}
+}
+
+// Register
+//
+// This method adds an extension with the provided function to the registry. An extension with the same id replaces the
+// one already registered.
+func (r *Registry) Register(extension Extension, fn ExtensionFunc) {
+	for i, ext := range r.extensions {
+		if ext.extension.Id == extension.Id {
+			r.extensions[i] = registered{extension: extension, fn: fn}
+			return
+		}
+	}
+
+	r.extensions = append(r.extensions, registered{extension: extension, fn: fn})
+}
+
+// RegisterLoader registers the provided loader as an extension of the loaders extension point
+func (r *Registry) RegisterLoader(id, name string, loader Loader) {
+	r.Register(Extension{Id: id, Name: name, ExtensionPoint: LoadersExtensionPoint, Func: name}, func(input []byte) ([]byte, error) {
+		return ServeLoader(loader, input)
+	})
+}
+
+// RegisterGenerator registers the provided generator as an extension of the generators extension point. The name is the
+// target it generates for.
+func (r *Registry) RegisterGenerator(id, name string, generator Generator) {
+	r.Register(Extension{Id: id, Name: name, ExtensionPoint: GeneratorsExtensionPoint, Func: name}, func(input []byte) ([]byte, error) {
+		return ServeGenerator(generator, input)
+	})
+}
+
+func (r *Registry) LoadFile(path string) ([]byte, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		resp, err := httpClient.Get(path)
+		if nil != err {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, fmt.Errorf("fetching %s returned status %s", path, resp.Status)
+		}
+
+		return io.ReadAll(resp.Body)
+	}
+
+	return os.ReadFile(path)
+}
+
+func (r *Registry) GetExtensionsForExtensionPoint(extensionPoint string) ([]Extension, error) {
+	extensions := make([]Extension, 0)
+
+	for _, ext := range r.extensions {
+		if ext.extension.ExtensionPoint == extensionPoint {
+			extensions = append(extensions, ext.extension)
+		}
+	}
+
+	return extensions, nil
+}
+
+func (r *Registry) CallExtension(id string, input []byte) ([]byte, error) {
+	for _, ext := range r.extensions {
+		if ext.extension.Id == id {
+			return ext.fn(input)
+		}
+	}
+
+	return nil, fmt.Errorf("no extension with id %s", id)
+}
+
+func (r *Registry) Log(message string) {
+	if r.Verbose {
+		log.Println(message)
+	}
+}
@@ -45,6 +45,33 @@ type Component struct {
 	Properties  Properties      // if this component has any associated properties, this contains the slice of those properties
 }
 
+// componentRef is what a *Component Ref is written as when a component is marshalled to json. Components can reference
+// themselves (or each other in a cycle) through Ref, so only enough of the referenced component to find it again with
+// ResolveRef is written.
+type componentRef struct {
+	Id        int
+	Name      string
+	SourceDoc string
+	Source    ComponentSource
+}
+
+func refToJson(ref any) any {
+	if c, ok := ref.(*Component); ok && nil != c {
+		return componentRef{Id: c.Id, Name: c.Name, SourceDoc: c.SourceDoc, Source: c.Source}
+	}
+
+	return ref
+}
+
+// MarshalJSON
+// Writes a *Component Ref as a componentRef to avoid endless recursion on self referencing components
+func (c Component) MarshalJSON() ([]byte, error) {
+	type component Component
+	cp := component(c)
+	cp.Ref = refToJson(c.Ref)
+	return json.Marshal(cp)
+}
+
 // Len
 // Part of the sorting interface implementation for custom sorting Components
 func (c Components) Len() int {
@@ -144,6 +171,66 @@ func (c Components) FindComponentByComparison(component *Component) *Component {
 	return nil
 }
 
+// ResolveRef
+//
+// A Ref can take a few forms depending on where a component has been. Loaders may set it to the *Component itself, or to the
+// name of a defined component as a placeholder until all sources are loaded. Once a model has been passed to (or from) an
+// extension as json, a *Component Ref becomes a map of its fields. This method will return the receiver component that the
+// provided ref points to in any of those forms, or nil if it can not be resolved (e.g. the Ref of an array holding the
+// primitive type of its items).
+func (c Components) ResolveRef(ref any) *Component {
+	return c.ResolveRefFrom(ref, "")
+}
+
+// ResolveRefFrom
+//
+// This method resolves a ref the way ResolveRef does, for a component or resource of the provided source document. A name
+// is looked up among the defined components of that source first, so that a ref is not bound to a component of another
+// source that happens to have the same name. Only when the source defines no such component (e.g. it is imported from
+// another file) is the name looked up among the defined components of every source.
+func (c Components) ResolveRefFrom(ref any, sourceDoc string) *Component {
+	switch r := ref.(type) {
+	case *Component:
+		if nil != r {
+			if comp := c.FindComponentById(r.Id); nil != comp {
+				return comp
+			}
+
+			return r
+		}
+	case Component:
+		return c.ResolveRefFrom(&r, sourceDoc)
+	case string:
+		defined := c.GetDefinedComponents()
+		if len(sourceDoc) > 0 {
+			for _, comp := range defined {
+				if strings.EqualFold(comp.Name, r) && strings.EqualFold(comp.SourceDoc, sourceDoc) {
+					return comp
+				}
+			}
+		}
+
+		if comp := defined.FindComponentByName(r); nil != comp {
+			return comp
+		}
+	case map[string]any:
+		// ids are large numbers that do not survive being decoded as a float64.. so match on the name and source doc instead
+		name, _ := r["Name"].(string)
+		sourceDoc, _ := r["SourceDoc"].(string)
+		source, _ := r["Source"].(float64)
+
+		for _, comp := range c {
+			if strings.EqualFold(comp.Name, name) && strings.EqualFold(comp.SourceDoc, sourceDoc) && comp.Source == ComponentSource(source) {
+				return comp
+			}
+		}
+
+		return c.ResolveRefFrom(name, sourceDoc)
+	}
+
+	return nil
+}
+
 // GetDefinedComponents
 //
 // This method will return any Component objects that have a type of SourceComponent as Source.. which indicates
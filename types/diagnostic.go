@@ -0,0 +1,122 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The run can not produce a correct result
+	SeverityWarning Severity = "warning" // Something was skipped or guessed, the result may be incomplete. Fails the run when it is strict
+	SeverityInfo    Severity = "info"    // Worth knowing about, e.g. two sources provided the same resource and they were merged
+)
+
+// Diagnostic codes used by the codegen plugin itself. Loaders and generators are free to use their own codes, ideally
+// prefixed with their own name to keep them apart (e.g. openapi-invalid-ref).
+const (
+	CodeInvalidRequest    = "invalid-request"    // The input of the plugin could not be parsed or validated
+	CodeNoLoaders         = "no-loaders"         // No loader extensions are contributed
+	CodeSourceUnreadable  = "source-unreadable"  // The source could not be read or is empty
+	CodeNoLoader          = "no-loader"          // No loader can parse the source
+	CodeLoaderFailed      = "loader-failed"      // The loader returned an error or a reply that could not be used
+	CodeMergedResource    = "merged-resource"    // The same version of a resource was provided more than once and merged
+	CodeSupersededVersion = "superseded-version" // An older version of a resource was loaded next to a newer one
+	CodeDuplicateWorkflow = "duplicate-workflow" // A workflow with the same id was already loaded and was ignored
+	CodeUnresolvedStep    = "unresolved-step"    // A workflow step references a resource that is not in the model
+	CodeUnknownTarget     = "unknown-target"     // A target does not match any generator
+	CodeGeneratorFailed   = "generator-failed"   // The generator returned an error or a reply that could not be used
+	CodeDuplicateFile     = "duplicate-file"     // Two targets generated a file with the same path
+	CodeInternal          = "internal"           // Anything else that went wrong in the plugin itself
+)
+
+// Location points to where in a source document a diagnostic applies. Loaders fill in whatever they know about, a line
+// and column for text formats and/or a path such as a json pointer (/paths/~1pets/get) for structured ones.
+type Location struct {
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// Diagnostic is a single problem (or piece of information) reported by the plugin, a loader or a generator
+type Diagnostic struct {
+	Severity  Severity  `json:"severity"`
+	Code      string    `json:"code"`
+	Message   string    `json:"message"`
+	SourceDoc string    `json:"sourceDoc,omitempty"` // The source (or target for generators) the diagnostic is about
+	Location  *Location `json:"location,omitempty"`
+}
+
+type Diagnostics []*Diagnostic
+
+func (d *Diagnostic) String() string {
+	s := string(d.Severity) + " [" + d.Code + "]"
+
+	if len(d.SourceDoc) > 0 {
+		s += " " + d.SourceDoc
+		if nil != d.Location {
+			if d.Location.Line > 0 {
+				s += ":" + strconv.Itoa(d.Location.Line)
+				if d.Location.Column > 0 {
+					s += ":" + strconv.Itoa(d.Location.Column)
+				}
+			}
+			if len(d.Location.Path) > 0 {
+				s += " (" + d.Location.Path + ")"
+			}
+		}
+	}
+
+	return s + ": " + d.Message
+}
+
+// Add
+//
+// This method will append a new Diagnostic to the receiver and return it, so callers can fill in a Location if they have one.
+func (d *Diagnostics) Add(severity Severity, code, sourceDoc, format string, args ...any) *Diagnostic {
+	diagnostic := &Diagnostic{
+		Severity:  severity,
+		Code:      code,
+		Message:   fmt.Sprintf(format, args...),
+		SourceDoc: sourceDoc,
+	}
+
+	*d = append(*d, diagnostic)
+	return diagnostic
+}
+
+// Error adds a SeverityError diagnostic
+func (d *Diagnostics) Error(code, sourceDoc, format string, args ...any) *Diagnostic {
+	return d.Add(SeverityError, code, sourceDoc, format, args...)
+}
+
+// Warn adds a SeverityWarning diagnostic
+func (d *Diagnostics) Warn(code, sourceDoc, format string, args ...any) *Diagnostic {
+	return d.Add(SeverityWarning, code, sourceDoc, format, args...)
+}
+
+// Info adds a SeverityInfo diagnostic
+func (d *Diagnostics) Info(code, sourceDoc, format string, args ...any) *Diagnostic {
+	return d.Add(SeverityInfo, code, sourceDoc, format, args...)
+}
+
+// Failed
+//
+// This method returns true if any of the diagnostics is an error, or when strict is true, a warning.
+func (d Diagnostics) Failed(strict bool) bool {
+	for _, diagnostic := range d {
+		if diagnostic.Severity == SeverityError || (strict && diagnostic.Severity == SeverityWarning) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CodegenResult is the output of the loadAndGenerate plugin function
+type CodegenResult struct {
+	Version     string          `json:"version"` // The CodegenRequestVersion of the plugin that produced this result
+	Files       []GeneratedFile `json:"files"`
+	Diagnostics Diagnostics     `json:"diagnostics"`
+}
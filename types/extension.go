@@ -0,0 +1,60 @@
+package types
+
+// LoaderAction indicates what the codegen plugin is asking of a loader extension when it is called
+type LoaderAction string
+
+const (
+	// LoaderProbe asks the loader to inspect the header sample of a source and report how confident it is that it can parse it
+	LoaderProbe LoaderAction = "probe"
+	// LoaderLoad asks the loader to parse the full source document and reply with a LoadedResponse
+	LoaderLoad LoaderAction = "load"
+)
+
+// ProbeHeaderSize is the maximum number of leading bytes of a source that are handed to loaders during the probe step.
+// It should be enough for a loader to sniff things like the openapi/swagger/asyncapi version key, a Postman schema url, etc.
+const ProbeHeaderSize = 1024
+
+// Confidence values loaders can reply with when probed. Any value in between can be used as well, the loader with the
+// highest confidence (greater than ConfidenceNone) is the one that will be asked to parse the full source.
+const (
+	ConfidenceNone    = 0   // The loader can not parse this source at all
+	ConfidenceLow     = 25  // The source may be parseable, e.g. the file extension matches but nothing in the content does
+	ConfidenceMedium  = 50  // The content looks right but the loader could not find a definitive marker
+	ConfidenceHigh    = 75  // The content has a marker the loader understands, but possibly not the exact version
+	ConfidenceCertain = 100 // The content has a marker and version the loader fully supports
+)
+
+// LoaderRequest is the input passed to every loader extension contributed to the spirefy.plugins.codegen.loaders extension point.
+//
+// When Action is LoaderProbe, Data only holds the first ProbeHeaderSize bytes of the source and the loader must reply with a
+// ProbeResponse. When Action is LoaderLoad, Data holds the entire source and the loader must reply with a LoadedResponse.
+type LoaderRequest struct {
+	Action LoaderAction `json:"action"`
+	Source string       `json:"source"` // The path/url of the source as provided in the sources list. Useful for file extension checks and as the SourceDoc
+	Data   []byte       `json:"data"`
+}
+
+// ProbeResponse is the reply of a loader extension to a LoaderProbe request
+type ProbeResponse struct {
+	Confidence int `json:"confidence"`
+}
+
+// GeneratorRequest is the input passed to the generator extension contributed to the spirefy.plugins.codegen.generators
+// extension point that matches one of the requested targets.
+type GeneratorRequest struct {
+	Target  string            `json:"target"`
+	Options map[string]string `json:"options,omitempty"`
+	Model   *LoadedResponse   `json:"model"`
+}
+
+// GeneratedFile is a single file produced by a generator. Path is relative to the output location of the run.
+type GeneratedFile struct {
+	Path    string `json:"path"`
+	Content []byte `json:"content"`
+}
+
+// GeneratorResponse is the reply of a generator extension to a GeneratorRequest
+type GeneratorResponse struct {
+	Files       []GeneratedFile `json:"files"`
+	Diagnostics Diagnostics     `json:"diagnostics,omitempty"` // Diagnostics without a SourceDoc are assumed to be about the target
+}
@@ -0,0 +1,216 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// The kinds of json values an inferred shape has seen
+const (
+	kindNull = 1 << iota
+	kindBoolean
+	kindInteger
+	kindFloat
+	kindString
+	kindObject
+	kindArray
+)
+
+// shape is what all the examples seen in one place (the document, a property or the items of an array) have in common
+type shape struct {
+	kinds int
+
+	// the number of objects seen, and the properties of all of them along with how many objects had each property
+	objects    int
+	properties map[string]*shape
+	present    map[string]int
+
+	// the items of every array seen
+	items *shape
+}
+
+func newShape() *shape {
+	return &shape{properties: make(map[string]*shape), present: make(map[string]int)}
+}
+
+func (s *shape) add(v any) {
+	switch t := v.(type) {
+	case nil:
+		s.kinds |= kindNull
+	case bool:
+		s.kinds |= kindBoolean
+	case json.Number:
+		// the literal text tells 10.0 from 10, the value does not
+		if strings.ContainsAny(t.String(), ".eE") {
+			s.kinds |= kindFloat
+		} else {
+			s.kinds |= kindInteger
+		}
+	case float64:
+		// decoded without UseNumber, only the value is left
+		if t == float64(int64(t)) {
+			s.kinds |= kindInteger
+		} else {
+			s.kinds |= kindFloat
+		}
+	case string:
+		s.kinds |= kindString
+	case map[string]any:
+		s.kinds |= kindObject
+		s.objects++
+		for name, value := range t {
+			p, ok := s.properties[name]
+			if !ok {
+				p = newShape()
+				s.properties[name] = p
+			}
+
+			p.add(value)
+			s.present[name]++
+		}
+	case []any:
+		s.kinds |= kindArray
+		if nil == s.items {
+			s.items = newShape()
+		}
+
+		for _, item := range t {
+			s.items.add(item)
+		}
+	}
+}
+
+// typeOf returns the Component/Property type and format of a shape. Integers and floats widen to a float64 number and null
+// is not a type of its own. Any other mix of kinds, and a shape that was only ever null, is untyped (any).
+func (s *shape) typeOf() (typ, format string) {
+	switch s.kinds &^ kindNull {
+	case kindBoolean:
+		return "boolean", ""
+	case kindInteger:
+		return "number", "int"
+	case kindFloat, kindInteger | kindFloat:
+		return "number", "float64"
+	case kindString:
+		return "string", ""
+	case kindArray:
+		return "array", ""
+	case kindObject:
+		return "object", ""
+	default:
+		return "", ""
+	}
+}
+
+// ref returns the Ref of an array shape: the type (or number format) of its items when they are all of a single kind,
+// none when the items are untyped
+func (s *shape) ref() any {
+	typ, _ := s.typeOf()
+	if typ != "array" || nil == s.items {
+		return nil
+	}
+
+	typ, format := s.items.typeOf()
+	switch typ {
+	case "":
+		return nil
+	case "number":
+		return format
+	default:
+		return typ
+	}
+}
+
+// props returns the properties of an object shape (or the items of an array of objects), sorted by name. A property is
+// required when every object seen had it.
+func (s *shape) props() Properties {
+	props := make(Properties, 0)
+
+	kinds := s.kinds &^ kindNull
+	if kinds == kindArray && nil != s.items {
+		return s.items.props()
+	}
+
+	// an untyped shape has no properties, even when some of its examples were objects
+	if kinds != kindObject {
+		return props
+	}
+
+	for name, p := range s.properties {
+		typ, format := p.typeOf()
+		prop, err := NewProperty(name, name, typ, "", format, "", boolPtr(s.present[name] >= s.objects), boolPtr(p.kinds&kindNull != 0), true, nil, p.ref(), nil)
+		if nil != err {
+			continue
+		}
+
+		prop.RawName = name
+		prop.Properties = p.props()
+		props = append(props, prop)
+	}
+
+	sort.Sort(props)
+	return props
+}
+
+// InferComponent
+//
+// This method creates a component from one or more decoded json examples (values as a json.Decoder with UseNumber
+// decodes them in to an any, so integers can be told from floats) and adds it to the receiver, for sources that have
+// examples but no schemas such as collections and captured traffic. The examples are merged in to a single shape:
+//
+//   - an integer in one example and a float in another widens to a float64 number
+//   - a null value makes a component or property Null, one that was only ever null is untyped
+//   - any other mix of kinds is untyped
+//   - a property missing from some of the examples is not Required
+//   - an array whose items are all of one kind gets that type as Ref, the properties of arrays of objects are merged
+//
+// The first example is kept as the Raw json of the component.
+func (c *Components) InferComponent(name, rawName, version string, source ComponentSource, examples ...any) (*Component, error) {
+	s := newShape()
+	for _, example := range examples {
+		s.add(example)
+	}
+
+	var raw json.RawMessage
+	if len(examples) > 0 {
+		raw, _ = json.Marshal(examples[0])
+	}
+
+	typ, format := s.typeOf()
+	comp, err := c.NewComponent(name, rawName, typ, "", format, version, nil, boolPtr(s.kinds&kindNull != 0), true, nil, source, s.ref(), raw, false)
+	if nil != err {
+		return nil, err
+	}
+
+	comp.Properties = s.props()
+	return comp, nil
+}
+
+// InferComponentFromJson
+//
+// This method is InferComponent for examples that are still json text, e.g. ResponseBody.Example. Examples that are not
+// valid json are ignored, nil is returned when none of them are.
+func (c *Components) InferComponentFromJson(name, rawName, version string, source ComponentSource, examples ...string) (*Component, error) {
+	values := make([]any, 0, len(examples))
+
+	for _, example := range examples {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(example)))
+		decoder.UseNumber()
+
+		var v any
+		if err := decoder.Decode(&v); nil == err {
+			values = append(values, v)
+		}
+	}
+
+	if len(values) <= 0 {
+		return nil, nil
+	}
+
+	return c.InferComponent(name, rawName, version, source, values...)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
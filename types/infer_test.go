@@ -0,0 +1,144 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// describe returns the type, format, nullability and Ref of an inferred component or property
+func describe(typ, format string, null *bool, ref any) string {
+	r, _ := ref.(string)
+	return fmt.Sprintf("%s %s null=%t ref=%s", typ, format, nil != null && *null, r)
+}
+
+func TestInferComponent(t *testing.T) {
+	tests := []struct {
+		name       string
+		examples   []string
+		want       string
+		properties []string // each property as described, along with whether it is required
+	}{
+		{
+			name:       "an object",
+			examples:   []string{`{"id": 1, "name": "Ann", "active": true}`},
+			want:       "object  null=false ref=",
+			properties: []string{"active: boolean  null=false ref= required=true", "id: number int null=false ref= required=true", "name: string  null=false ref= required=true"},
+		},
+		{
+			name:       "objects that do not all have a property",
+			examples:   []string{`{"id": 1, "name": "Ann"}`, `{"id": 2}`},
+			want:       "object  null=false ref=",
+			properties: []string{"id: number int null=false ref= required=true", "name: string  null=false ref= required=false"},
+		},
+		{
+			name:       "a nested object",
+			examples:   []string{`{"owner": {"id": 1}}`},
+			want:       "object  null=false ref=",
+			properties: []string{"owner: object  null=false ref= required=true"},
+		},
+		{
+			name:       "an array of strings",
+			examples:   []string{`["a", "b"]`},
+			want:       "array  null=false ref=string",
+			properties: []string{},
+		},
+		{
+			name:       "an array of integers and floats",
+			examples:   []string{`[1, 2.5]`},
+			want:       "array  null=false ref=float64",
+			properties: []string{},
+		},
+		{
+			name:       "an array of objects",
+			examples:   []string{`[{"id": 1}, {"id": 2, "tag": "x"}]`},
+			want:       "array  null=false ref=object",
+			properties: []string{"id: number int null=false ref= required=true", "tag: string  null=false ref= required=false"},
+		},
+		{
+			name:       "an empty array",
+			examples:   []string{`[]`},
+			want:       "array  null=false ref=",
+			properties: []string{},
+		},
+		{
+			name:       "an array of mixed kinds",
+			examples:   []string{`[1, "a"]`},
+			want:       "array  null=false ref=",
+			properties: []string{},
+		},
+		{
+			name:       "a property that is null in some examples",
+			examples:   []string{`{"email": "a@b.c"}`, `{"email": null}`},
+			want:       "object  null=false ref=",
+			properties: []string{"email: string  null=true ref= required=true"},
+		},
+		{
+			name:       "a property that is only ever null",
+			examples:   []string{`{"email": null}`},
+			want:       "object  null=false ref=",
+			properties: []string{"email:   null=true ref= required=true"},
+		},
+		{
+			name:       "a null example",
+			examples:   []string{`null`},
+			want:       "  null=true ref=",
+			properties: []string{},
+		},
+		{
+			name:       "a property of mixed kinds",
+			examples:   []string{`{"id": 1}`, `{"id": "a"}`},
+			want:       "object  null=false ref=",
+			properties: []string{"id:   null=false ref= required=true"},
+		},
+		{
+			name:       "examples of mixed kinds",
+			examples:   []string{`{"id": 1}`, `[1]`},
+			want:       "  null=false ref=",
+			properties: []string{},
+		},
+		{
+			name:       "numbers",
+			examples:   []string{`{"count": 10, "price": 10.0, "ratio": 0.5, "big": 1e3, "negative": -3}`},
+			want:       "object  null=false ref=",
+			properties: []string{"big: number float64 null=false ref= required=true", "count: number int null=false ref= required=true", "negative: number int null=false ref= required=true", "price: number float64 null=false ref= required=true", "ratio: number float64 null=false ref= required=true"},
+		},
+		{
+			name:       "an integer that is a float in another example",
+			examples:   []string{`{"amount": 10}`, `{"amount": 10.5}`},
+			want:       "object  null=false ref=",
+			properties: []string{"amount: number float64 null=false ref= required=true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comps := make(Components, 0)
+			comp, err := comps.InferComponentFromJson("Example", "example", "", SourceComponent, tt.examples...)
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			if got := describe(comp.Type, comp.Format, comp.Null, comp.Ref); got != tt.want {
+				t.Errorf("component = %q, want %q", got, tt.want)
+			}
+
+			properties := make([]string, 0, len(comp.Properties))
+			for _, prop := range comp.Properties {
+				required := nil != prop.Required && *prop.Required
+				properties = append(properties, fmt.Sprintf("%s: %s required=%t", prop.Name, describe(prop.Type, prop.Format, prop.Null, prop.Ref), required))
+			}
+			if !reflect.DeepEqual(properties, tt.properties) {
+				t.Errorf("properties = %v, want %v", properties, tt.properties)
+			}
+		})
+	}
+}
+
+func TestInferComponentFromInvalidJson(t *testing.T) {
+	comps := make(Components, 0)
+	comp, err := comps.InferComponentFromJson("Example", "example", "", SourceComponent, "{", "not json")
+	if nil != comp || nil != err {
+		t.Errorf("InferComponentFromJson() = %v, %v, want nothing for examples that are not json", comp, err)
+	}
+}
@@ -1,7 +1,436 @@
 package types
 
+import "strings"
+
 type LoadedResponse struct {
 	Resources  Resources  `json:"resources"`
 	Components Components `json:"components"`
 	Workflows  Workflows  `json:"workflows"`
+
+	// Values for the Variables of resources that a source provides on its own, e.g. a Postman environment. Merge sets them
+	// on every resource using a variable of the same name, regardless of the order the sources are loaded in.
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// Other sources this source depends on, e.g. the files a .proto file imports, relative to the source. The pipeline loads
+	// every import that is not a source of the run already.
+	Imports []string `json:"imports,omitempty"`
+
+	// Any problems the loader ran in to while parsing the source. Diagnostics without a SourceDoc are assumed to be about
+	// the source that was loaded.
+	Diagnostics Diagnostics `json:"diagnostics,omitempty"`
+}
+
+// NewLoadedResponse
+//
+// This function returns an empty LoadedResponse with all slices initialized. It is typically used to create the single
+// run wide model that the response of every loader is merged in to.
+func NewLoadedResponse() *LoadedResponse {
+	return &LoadedResponse{
+		Resources:  make(Resources, 0),
+		Components: make(Components, 0),
+		Workflows:  make(Workflows, 0),
+	}
+}
+
+// Merge
+//
+// This method will merge the provided LoadedResponse (typically the reply of a single loader for a single source) in to
+// the receiver. Components are de-duplicated using FindComponentByComparison, resources using FindResources and workflows
+// using AddWorkflow. Any reference to a component or resource that was dropped as a duplicate is re-pointed to the one
+// already in the receiver so that the merged model only ever references its own objects.
+//
+// The diagnostics of other are not merged, but any diagnostics about the merge itself are returned.
+func (l *LoadedResponse) Merge(other *LoadedResponse) Diagnostics {
+	diagnostics := make(Diagnostics, 0)
+
+	if nil == l || nil == other {
+		return diagnostics
+	}
+
+	// maps the id of every incoming component to the component it ended up as in the merged model
+	components := make(map[int]*Component, len(other.Components))
+
+	for _, comp := range other.Components {
+		if nil == comp {
+			continue
+		}
+
+		existing := l.Components.FindComponentByComparison(comp)
+		if nil == existing {
+			l.Components = append(l.Components, comp)
+			existing = comp
+		} else if len(existing.Properties) <= 0 && len(comp.Properties) > 0 {
+			// the existing component is a less complete version of the same one.. keep the more complete properties
+			existing.Properties = comp.Properties
+		}
+
+		components[comp.Id] = existing
+	}
+
+	for _, comp := range l.Components {
+		components[comp.Id] = comp
+	}
+
+	// now that all components are known, make sure refs point to components within the merged model
+	for _, comp := range l.Components {
+		relinkComponent(comp, components, l.Components)
+	}
+
+	// maps the id of every incoming resource to the resource it ended up as in the merged model
+	resources := make(map[int]*Resource, len(other.Resources))
+
+	for _, res := range other.Resources {
+		if nil == res {
+			continue
+		}
+
+		relinkResource(res, components)
+
+		// every version of the resource loaded so far, the one with the same version (if any) and the latest one
+		versions := l.Resources.FindResources(res.Path, res.Method, &res.Owner)
+		var existing, latest *Resource
+		for _, v := range versions {
+			if nil == existing && v.Version == res.Version {
+				existing = v
+			}
+			if v.Latest && (nil == latest || CompareVersions(v.Version, latest.Version) > 0) {
+				latest = v
+			}
+		}
+
+		// without a version flagged Latest (e.g. a loader leaving it unset) the highest version is the latest one
+		if nil == latest {
+			for _, v := range versions {
+				if nil == latest || CompareVersions(v.Version, latest.Version) > 0 {
+					latest = v
+				}
+			}
+		}
+
+		switch {
+		case nil != existing:
+			existing.Merge(res)
+			diagnostics.Info(CodeMergedResource, res.SourceDoc, "%s %s of %s was already loaded from %s and has been merged", res.Method, res.Path, res.Owner, existing.SourceDoc)
+		case nil == latest:
+			l.Resources = append(l.Resources, res)
+			existing = res
+		default:
+			// Same resource from a different version of the same API.. keep both but only one can be the latest
+			if CompareVersions(res.Version, latest.Version) > 0 {
+				latest.Latest = false
+				res.Latest = true
+				diagnostics.Info(CodeSupersededVersion, latest.SourceDoc, "%s %s version %s is superseded by version %s", res.Method, res.Path, latest.Version, res.Version)
+			} else {
+				latest.Latest = true
+				res.Latest = false
+				diagnostics.Info(CodeSupersededVersion, res.SourceDoc, "%s %s version %s is superseded by version %s", res.Method, res.Path, res.Version, latest.Version)
+			}
+
+			l.Resources = append(l.Resources, res)
+			existing = res
+		}
+
+		resources[res.Id] = existing
+	}
+
+	// bodies of a source that only know the name of a component defined in another source (e.g. an imported proto message)
+	for _, res := range l.Resources {
+		relinkNamedSchemas(res, l.Components)
+	}
+
+	// folder children that were merged in to an existing resource are re-pointed to it as well
+	for _, res := range l.Resources {
+		if len(res.Resources) <= 0 {
+			continue
+		}
+
+		children := make(Resources, 0, len(res.Resources))
+		for _, child := range res.Resources {
+			if found, ok := resources[child.Id]; ok {
+				child = found
+			}
+
+			if nil == children.FindResourceByUuid(child.Id) {
+				children = append(children, child)
+			}
+		}
+
+		res.Resources = children
+	}
+
+	if len(other.Variables) > 0 && nil == l.Variables {
+		l.Variables = make(map[string]string, len(other.Variables))
+	}
+
+	for k, v := range other.Variables {
+		l.Variables[k] = v
+	}
+
+	for _, res := range l.Resources {
+		for k := range res.Variables {
+			if v, ok := l.Variables[k]; ok {
+				res.Variables[k] = v
+			}
+		}
+	}
+
+	for _, wf := range other.Workflows {
+		if nil == wf {
+			continue
+		}
+
+		for i, input := range wf.Inputs {
+			if c, ok := components[input.Id]; ok {
+				wf.Inputs[i] = c
+			}
+		}
+
+		if nil != l.Workflows.FindWorkflow(wf.Id) {
+			diagnostics.Warn(CodeDuplicateWorkflow, "", "workflow with id %s already exists and has been ignored", wf.Id)
+			continue
+		}
+
+		for _, step := range wf.Steps {
+			if nil != step && nil != step.Resource {
+				if res, ok := resources[step.Resource.Id]; ok {
+					step.Resource = res
+				} else if res = l.Resources.FindResourceByUuid(step.Resource.Id); nil != res {
+					step.Resource = res
+				}
+			}
+		}
+
+		l.Workflows.AddWorkflow(wf)
+	}
+
+	return diagnostics
+}
+
+// ResolveWorkflows
+//
+// Workflow sources (e.g. Arazzo) are loaded on their own, so the Resource of a step is often only a placeholder holding
+// what the workflow source knows about the operation: a Name (made from the operationId with MakeResourceName) and/or a
+// Path and Method. Once every source is merged, this method points each step to the matching Resource of the model, and
+// returns a warning diagnostic for every step that could not be resolved.
+func (l *LoadedResponse) ResolveWorkflows() Diagnostics {
+	diagnostics := make(Diagnostics, 0)
+
+	for _, wf := range l.Workflows {
+		for _, step := range wf.Steps {
+			if nil == step || nil == step.Resource {
+				continue
+			}
+
+			placeholder := step.Resource
+			if res := l.Resources.FindResourceByUuid(placeholder.Id); nil != res {
+				step.Resource = res
+				continue
+			}
+
+			var found *Resource
+			if len(placeholder.Path) > 0 && len(placeholder.Method) > 0 {
+				id := l.Resources.MakeUniqueId(placeholder.Path, strings.ToLower(placeholder.Method))
+				for _, res := range *l.Resources.GetLatestResources() {
+					if strings.EqualFold(res.ResourceId, id) && (len(placeholder.Owner) <= 0 || res.Owner == placeholder.Owner) {
+						found = res
+						break
+					}
+				}
+			}
+
+			if nil == found && len(placeholder.Name) > 0 {
+				for _, res := range *l.Resources.GetLatestResources() {
+					if strings.EqualFold(res.Name, placeholder.Name) && (len(placeholder.Owner) <= 0 || res.Owner == placeholder.Owner) {
+						found = res
+						break
+					}
+				}
+			}
+
+			if nil != found {
+				step.Resource = found
+			} else {
+				operation := placeholder.Name
+				if len(operation) <= 0 {
+					operation = placeholder.Method + " " + placeholder.Path
+				}
+
+				diagnostics.Warn(CodeUnresolvedStep, placeholder.SourceDoc, "step %s of workflow %s references operation %s which is not loaded", step.Id, wf.Id, operation)
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// Merge
+//
+// This method will add any parameters, requests, responses, variables and children of the provided resource that the
+// receiver does not have yet. This is used when two sources provide the same version of the same resource, and by
+// loaders that see the same resource more than once (e.g. a Postman collection with the same request in two folders).
+func (r *Resource) Merge(other *Resource) {
+	for _, param := range other.Parameters {
+		found := false
+		for _, p := range r.Parameters {
+			if p.Name == param.Name && p.In == param.In {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			r.Parameters = append(r.Parameters, param)
+		}
+	}
+
+	for _, req := range other.Requests {
+		found := false
+		for _, rq := range r.Requests {
+			if rq.ContentType == req.ContentType {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			r.Requests = append(r.Requests, req)
+		}
+	}
+
+	for _, resp := range other.Responses {
+		found := false
+		for _, rs := range r.Responses {
+			if rs.Status == resp.Status {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			r.Responses = append(r.Responses, resp)
+		}
+	}
+
+	if nil == r.Variables {
+		r.Variables = make(map[string]string, len(other.Variables))
+	}
+
+	for k, v := range other.Variables {
+		if _, ok := r.Variables[k]; !ok {
+			r.Variables[k] = v
+		}
+	}
+
+	for _, child := range other.Resources {
+		if nil == r.Resources.FindResourceByUuid(child.Id) {
+			r.Resources = append(r.Resources, child)
+		}
+	}
+
+	if len(r.Description) <= 0 {
+		r.Description = other.Description
+	}
+
+	if len(r.Summary) <= 0 {
+		r.Summary = other.Summary
+	}
+}
+
+// relinkComponent re-points the Ref of a component and its properties to the merged model components when possible
+func relinkComponent(comp *Component, byId map[int]*Component, all Components) {
+	if nil == comp {
+		return
+	}
+
+	if c := resolveRef(comp.Ref, byId, all, comp.SourceDoc); nil != c {
+		comp.Ref = c
+	}
+
+	relinkProperties(comp.Properties, byId, all, comp.SourceDoc)
+}
+
+// relinkProperties re-points the Ref of the properties of a component of the provided source document
+func relinkProperties(props Properties, byId map[int]*Component, all Components, sourceDoc string) {
+	for _, prop := range props {
+		if nil == prop {
+			continue
+		}
+
+		if c := resolveRef(prop.Ref, byId, all, sourceDoc); nil != c {
+			prop.Ref = c
+		}
+
+		relinkProperties(prop.Properties, byId, all, sourceDoc)
+	}
+}
+
+// relinkResource re-points every schema/component of a resource to the merged model components when possible
+func relinkResource(res *Resource, components map[int]*Component) {
+	for _, param := range res.Parameters {
+		for i, c := range param.Components {
+			if nil != c {
+				if found, ok := components[c.Id]; ok {
+					param.Components[i] = found
+				}
+			}
+		}
+	}
+
+	for _, req := range res.Requests {
+		if nil != req.Schema {
+			if found, ok := components[req.Schema.Id]; ok {
+				req.Schema = found
+			}
+		}
+	}
+
+	for _, resp := range res.Responses {
+		for _, body := range resp.ResponseBodies {
+			if nil != body.Schema {
+				if found, ok := components[body.Schema.Id]; ok {
+					body.Schema = found
+				}
+			}
+		}
+	}
+
+	if nil != res.Components {
+		for i, c := range *res.Components {
+			if nil != c {
+				if found, ok := components[c.Id]; ok {
+					(*res.Components)[i] = found
+				}
+			}
+		}
+	}
+}
+
+// relinkNamedSchemas sets the Schema of requests and response bodies that have none, but a Ref naming a defined component
+func relinkNamedSchemas(res *Resource, all Components) {
+	for _, req := range res.Requests {
+		if nil == req.Schema && len(req.Ref) > 0 {
+			req.Schema = all.ResolveRefFrom(req.Ref, res.SourceDoc)
+		}
+	}
+
+	for _, resp := range res.Responses {
+		for _, body := range resp.ResponseBodies {
+			if nil == body.Schema && len(body.Ref) > 0 {
+				body.Schema = all.ResolveRefFrom(body.Ref, res.SourceDoc)
+			}
+		}
+	}
+}
+
+// resolveRef will return the merged model component a Ref value of the provided source document points to. Refs that are
+// components are looked up by their id first since the component may have been dropped as a duplicate, anything else is
+// resolved with ResolveRefFrom.
+func resolveRef(ref any, byId map[int]*Component, all Components, sourceDoc string) *Component {
+	if r, ok := ref.(*Component); ok && nil != r {
+		if c, ok := byId[r.Id]; ok {
+			return c
+		}
+	}
+
+	return all.ResolveRefFrom(ref, sourceDoc)
 }
@@ -0,0 +1,274 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+// resource returns a Latest resource as a loader would
+func resource(path, method, owner, version string) *Resource {
+	res := &Resource{
+		Id:        generateUniqueInt(),
+		Path:      path,
+		Method:    method,
+		Owner:     owner,
+		Version:   version,
+		SourceDoc: owner + "-" + version + ".yaml",
+		Latest:    true,
+	}
+	res.ResourceId = (&Resources{}).MakeUniqueId(path, method)
+	return res
+}
+
+// loaded returns the reply of a loader holding the provided resources
+func loaded(resources ...*Resource) *LoadedResponse {
+	resp := NewLoadedResponse()
+	resp.Resources = append(resp.Resources, resources...)
+	return resp
+}
+
+func TestMergeVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		latest   []bool // of every merged resource, in the order they were loaded
+		codes    []string
+	}{
+		{"a single version is the latest", []string{"1.0.0"}, []bool{true}, []string{}},
+		{"a newer version supersedes the latest", []string{"1.0.0", "2.0.0"}, []bool{false, true}, []string{CodeSupersededVersion}},
+		{"an older version does not supersede the latest", []string{"2.0.0", "1.0.0"}, []bool{true, false}, []string{CodeSupersededVersion}},
+		{
+			"a version between two loaded ones is compared with the latest",
+			[]string{"1.0.0", "3.0.0", "2.0.0"},
+			[]bool{false, true, false},
+			[]string{CodeSupersededVersion, CodeSupersededVersion},
+		},
+		{
+			"the newest of many versions is the latest",
+			[]string{"2.0.0", "1.0.0", "10.0.0", "3.0.0"},
+			[]bool{false, false, true, false},
+			[]string{CodeSupersededVersion, CodeSupersededVersion, CodeSupersededVersion},
+		},
+		{"the same version is merged", []string{"1.0.0", "1.0.0"}, []bool{true}, []string{CodeMergedResource}},
+		{
+			"the same version is merged with the version it equals",
+			[]string{"1.0.0", "2.0.0", "1.0.0"},
+			[]bool{false, true},
+			[]string{CodeSupersededVersion, CodeMergedResource},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewLoadedResponse()
+			diagnostics := make(Diagnostics, 0)
+
+			for _, version := range tt.versions {
+				diagnostics = append(diagnostics, model.Merge(loaded(resource("/pets", "get", "acme", version)))...)
+			}
+
+			latest := make([]bool, 0, len(model.Resources))
+			for _, res := range model.Resources {
+				latest = append(latest, res.Latest)
+			}
+			if !reflect.DeepEqual(latest, tt.latest) {
+				t.Errorf("latest = %v, want %v", latest, tt.latest)
+			}
+
+			codes := make([]string, 0, len(diagnostics))
+			for _, d := range diagnostics {
+				codes = append(codes, d.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("diagnostics = %v, want %v", codes, tt.codes)
+			}
+		})
+	}
+}
+
+func TestMergeResources(t *testing.T) {
+	first := resource("/pets", "get", "acme", "1.0.0")
+	first.Parameters = []*Parameter{{Name: "limit", In: QUERY}}
+
+	same := resource("/pets", "get", "acme", "1.0.0")
+	same.Parameters = []*Parameter{{Name: "limit", In: QUERY}, {Name: "offset", In: QUERY}}
+
+	other := resource("/pets", "get", "other", "1.0.0")
+	post := resource("/pets", "post", "acme", "1.0.0")
+
+	model := NewLoadedResponse()
+	model.Merge(loaded(first))
+	model.Merge(loaded(same, other, post))
+
+	if len(model.Resources) != 3 {
+		t.Fatalf("the model has %d resources, want 3", len(model.Resources))
+	}
+
+	if len(first.Parameters) != 2 {
+		t.Errorf("the merged resource has %d parameters, want 2", len(first.Parameters))
+	}
+
+	for _, res := range model.Resources {
+		if !res.Latest {
+			t.Errorf("%s %s of %s is not the latest", res.Method, res.Path, res.Owner)
+		}
+	}
+}
+
+func TestMergeComponents(t *testing.T) {
+	pet := &Component{Id: 1, Name: "Pet", Type: "object", Source: SourceComponent, SourceDoc: "a.yaml"}
+	again := &Component{Id: 2, Name: "Pet", Type: "object", Source: SourceComponent, SourceDoc: "a.yaml", Properties: Properties{{Name: "name", Type: "string"}}}
+	elsewhere := &Component{Id: 3, Name: "Pet", Type: "object", Source: SourceComponent, SourceDoc: "b.yaml"}
+	list := &Component{Id: 4, Name: "Pets", Type: "array", Source: SourceComponent, SourceDoc: "a.yaml", Ref: again}
+
+	model := NewLoadedResponse()
+	model.Merge(&LoadedResponse{Components: Components{pet}})
+	model.Merge(&LoadedResponse{Components: Components{again, elsewhere, list}})
+
+	if len(model.Components) != 3 {
+		t.Fatalf("the model has %d components, want 3", len(model.Components))
+	}
+
+	if len(pet.Properties) != 1 {
+		t.Errorf("the merged component has %d properties, want the 1 of the more complete one", len(pet.Properties))
+	}
+
+	if list.Ref != pet {
+		t.Errorf("the ref of a dropped duplicate is not pointed to the component of the model")
+	}
+}
+
+func TestMergeNamedRefs(t *testing.T) {
+	// a and b both define Pet, b refers to its own Pet and an Owner that only a defines
+	petA := &Component{Id: 1, Name: "Pet", Type: "object", Source: SourceComponent, SourceDoc: "a.yaml"}
+	owner := &Component{Id: 2, Name: "Owner", Type: "object", Source: SourceComponent, SourceDoc: "a.yaml"}
+	petB := &Component{Id: 3, Name: "Pet", Type: "object", Source: SourceComponent, SourceDoc: "b.yaml", Properties: Properties{{Name: "id", Type: "integer"}}}
+	holder := &Component{Id: 4, Name: "Holder", Type: "object", Source: SourceComponent, SourceDoc: "b.yaml", Properties: Properties{
+		{Name: "pet", Type: "object", Ref: "Pet"},
+		{Name: "owner", Type: "object", Ref: "Owner"},
+	}}
+	pets := &Component{Id: 5, Name: "Pets", Type: "array", Source: SourceComponent, SourceDoc: "b.yaml", Ref: "Pet"}
+
+	res := resource("/pets", "get", "b", "1.0.0")
+	res.SourceDoc = "b.yaml"
+	res.Requests = Requests{{Ref: "Pet"}}
+
+	model := NewLoadedResponse()
+	model.Merge(&LoadedResponse{Components: Components{petA, owner}})
+	model.Merge(&LoadedResponse{Components: Components{holder, petB, pets}, Resources: Resources{res}})
+
+	tests := []struct {
+		name string
+		ref  any
+		want *Component
+	}{
+		{"a property is the component of its own source", holder.Properties[0].Ref, petB},
+		{"a component is the component of its own source", pets.Ref, petB},
+		{"a request body is the component of its own source", res.Requests[0].Schema, petB},
+		{"a name the source does not define is the component of another source", holder.Properties[1].Ref, owner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.ref.(*Component)
+			if got != tt.want {
+				t.Errorf("the ref is %+v, want %s of %s", tt.ref, tt.want.Name, tt.want.SourceDoc)
+			}
+		})
+	}
+}
+
+func TestMergeWorkflows(t *testing.T) {
+	res := resource("/pets", "get", "acme", "1.0.0")
+	dup := resource("/pets", "get", "acme", "1.0.0")
+
+	model := NewLoadedResponse()
+	model.Merge(loaded(res))
+
+	wf := &Workflow{Id: "list", Steps: Steps{{Id: "first", Resource: dup}}}
+	diagnostics := model.Merge(&LoadedResponse{Resources: Resources{dup}, Workflows: Workflows{wf}})
+	diagnostics = append(diagnostics, model.Merge(&LoadedResponse{Workflows: Workflows{{Id: "list"}}})...)
+
+	if wf.Steps[0].Resource != res {
+		t.Errorf("the step of a merged resource is not pointed to the resource of the model")
+	}
+
+	if len(model.Workflows) != 1 {
+		t.Errorf("the model has %d workflows, want 1", len(model.Workflows))
+	}
+
+	if last := diagnostics[len(diagnostics)-1]; last.Code != CodeDuplicateWorkflow || last.Severity != SeverityWarning {
+		t.Errorf("a duplicate workflow is reported as %s", last)
+	}
+}
+
+func TestMergeVariables(t *testing.T) {
+	res := resource("/pets", "get", "acme", "1.0.0")
+	res.Variables = map[string]string{"baseUrl": "", "token": "default"}
+
+	model := NewLoadedResponse()
+	model.Merge(loaded(res))
+	model.Merge(&LoadedResponse{Variables: map[string]string{"baseUrl": "https://example.com"}})
+
+	want := map[string]string{"baseUrl": "https://example.com", "token": "default"}
+	if !reflect.DeepEqual(res.Variables, want) {
+		t.Errorf("variables = %v, want %v", res.Variables, want)
+	}
+}
+
+func TestResolveWorkflows(t *testing.T) {
+	old := resource("/pets", "get", "acme", "1.0.0")
+	old.Name = "ListPets"
+	current := resource("/pets", "get", "acme", "2.0.0")
+	current.Name = "ListPets"
+	create := resource("/pets", "post", "acme", "2.0.0")
+	create.Name = "CreatePet"
+
+	model := NewLoadedResponse()
+	model.Merge(loaded(old, current, create))
+
+	tests := []struct {
+		name        string
+		placeholder *Resource
+		want        *Resource
+	}{
+		{"a resource of the model", create, create},
+		{"by path and method, the latest version", &Resource{Id: -1, Path: "/pets", Method: "GET"}, current},
+		{"by path, method and owner", &Resource{Id: -2, Path: "/pets", Method: "post", Owner: "acme"}, create},
+		{"by name", &Resource{Id: -3, Name: "createpet"}, create},
+		{"not with another owner", &Resource{Id: -4, Name: "CreatePet", Owner: "other"}, nil},
+		{"not an operation that is not loaded", &Resource{Id: -5, Name: "DeletePet"}, nil},
+	}
+
+	wf := &Workflow{Id: "pets"}
+	for _, tt := range tests {
+		wf.Steps = append(wf.Steps, &Step{Id: tt.name, Resource: tt.placeholder})
+	}
+	model.Workflows = append(model.Workflows, wf)
+
+	diagnostics := model.ResolveWorkflows()
+
+	unresolved := 0
+	for i, tt := range tests {
+		step := wf.Steps[i]
+		switch {
+		case nil == tt.want:
+			unresolved++
+			if step.Resource != tt.placeholder {
+				t.Errorf("%s: the step was resolved to %s %s", tt.name, step.Resource.Method, step.Resource.Path)
+			}
+		case step.Resource != tt.want:
+			t.Errorf("%s: the step was resolved to %s %s version %s", tt.name, step.Resource.Method, step.Resource.Path, step.Resource.Version)
+		}
+	}
+
+	if len(diagnostics) != unresolved {
+		t.Fatalf("%d diagnostics, want one for each of the %d unresolved steps", len(diagnostics), unresolved)
+	}
+
+	for _, d := range diagnostics {
+		if d.Code != CodeUnresolvedStep || d.Severity != SeverityWarning {
+			t.Errorf("an unresolved step is reported as %s", d)
+		}
+	}
+}
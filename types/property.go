@@ -26,6 +26,15 @@ type Property struct {
 	Ref         any             // This is "any" so that if the type is Object this would either be a Component ref or a string name placeholder (until can be resolved after all components are processed by all loaders). If type is an array, this is a string that holds the primitive type of array
 }
 
+// MarshalJSON
+// Writes a *Component Ref as a componentRef to avoid endless recursion on self referencing components
+func (p Property) MarshalJSON() ([]byte, error) {
+	type property Property
+	cp := property(p)
+	cp.Ref = refToJson(p.Ref)
+	return json.Marshal(cp)
+}
+
 // Len
 // Part of the sorting interface implementation for custom sorting Properties
 func (p Properties) Len() int {
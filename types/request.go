@@ -0,0 +1,325 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CodegenRequestVersion is the current version of the CodegenRequest contract. It must be bumped whenever a change is made
+// to CodegenRequest that an older plugin would not understand.
+const CodegenRequestVersion = "1"
+
+// CodegenRequest
+//
+// This is the input of the loadAndGenerate plugin function.
+//
+//	{
+//	  "version": "1",
+//	  "sources": ["./petstore.yaml", "./collection.json"],
+//	  "targets": ["go-models"],
+//	  "outputDir": "./gen",
+//	  "options": {"go-models": {"package": "petstore"}},
+//	  "include": ["/pets*"],
+//	  "exclude": ["*/internal/*"],
+//	  "dryRun": false,
+//	  "strict": true
+//	}
+//
+// The older form of the input, a json array of {"Name": "sources", "Value": "a,b"} pairs as sent by the host CLI, is still
+// accepted by ParseCodegenRequest and converted in to this structure.
+type CodegenRequest struct {
+	// Version of the request contract. When empty, CodegenRequestVersion is assumed.
+	Version string `json:"version"`
+
+	// Paths/urls of the sources to load. At least one source is required.
+	Sources []string `json:"sources"`
+
+	// Names of the generators to run against the loaded model. Can be empty to only load (and validate) the sources.
+	Targets []string `json:"targets,omitempty"`
+
+	// Directory every generated file path is made relative to. When empty, paths are returned as the generators provide them.
+	OutputDir string `json:"outputDir,omitempty"`
+
+	// Options per target, keyed on the target name. The options of a target are passed to its generator as is.
+	Options map[string]map[string]string `json:"options,omitempty"`
+
+	// Glob patterns (* matches any run of characters, ? a single one) a resource path or name must match to be kept in the
+	// model. When empty, every resource is kept.
+	Include []string `json:"include,omitempty"`
+
+	// Glob patterns of resource paths or names to remove from the model. Exclude wins over Include.
+	Exclude []string `json:"exclude,omitempty"`
+
+	// When true, generators run but no file content is returned, only the paths of the files that would have been written.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// When true, any problem (a source no loader can parse, a loader that fails, etc.) fails the run instead of being skipped.
+	Strict bool `json:"strict,omitempty"`
+}
+
+// RequestError is returned when a CodegenRequest can not be parsed or is not valid. Field is the json name of the field
+// that is at fault, if any.
+type RequestError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *RequestError) Error() string {
+	if len(e.Field) > 0 {
+		return "invalid codegen request: " + e.Field + ": " + e.Message
+	}
+
+	return "invalid codegen request: " + e.Message
+}
+
+// legacyInput is a single name/value pair of the array form of the request
+type legacyInput struct {
+	Name  string
+	Value string
+}
+
+// ParseCodegenRequest
+//
+// This function will decode the provided input in to a CodegenRequest and validate it. Both the versioned object form
+// and the older array of name/value pairs are accepted. A *RequestError is returned if the input can not be used.
+func ParseCodegenRequest(input []byte) (*CodegenRequest, error) {
+	trimmed := bytes.TrimSpace(input)
+	if len(trimmed) <= 0 {
+		return nil, &RequestError{Message: "no input provided"}
+	}
+
+	request := &CodegenRequest{}
+
+	if trimmed[0] == '[' {
+		inputs := make([]legacyInput, 0)
+		if err := json.Unmarshal(trimmed, &inputs); nil != err {
+			return nil, &RequestError{Message: "malformed input: " + err.Error()}
+		}
+
+		for _, in := range inputs {
+			if err := request.setOption(in.Name, in.Value); nil != err {
+				return nil, err
+			}
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(request); nil != err {
+			return nil, &RequestError{Message: "malformed input: " + err.Error()}
+		}
+	}
+
+	if err := request.Validate(); nil != err {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// setOption will apply a single name/value pair of the array form of the request
+func (r *CodegenRequest) setOption(name, value string) error {
+	var err error
+
+	switch name {
+	case "sources":
+		r.Sources = splitList(value)
+	case "targets":
+		r.Targets = splitList(value)
+	case "output", "outputDir":
+		r.OutputDir = strings.TrimSpace(value)
+	case "include":
+		r.Include = splitList(value)
+	case "exclude":
+		r.Exclude = splitList(value)
+	case "dryRun":
+		r.DryRun, err = parseBool(value)
+	case "strict":
+		r.Strict, err = parseBool(value)
+	case "options":
+		// options are provided as a comma separated list of target.key=value
+		for _, opt := range splitList(value) {
+			target, kv, ok := strings.Cut(opt, ".")
+			key, val, ok2 := strings.Cut(kv, "=")
+			if !ok || !ok2 {
+				return &RequestError{Field: "options", Message: fmt.Sprintf("%q must be in the form target.key=value", opt)}
+			}
+
+			if nil == r.Options {
+				r.Options = make(map[string]map[string]string)
+			}
+			if nil == r.Options[target] {
+				r.Options[target] = make(map[string]string)
+			}
+
+			r.Options[target][key] = val
+		}
+	default:
+		// the host may pass along options of other plugins.. they are none of our business
+	}
+
+	if nil != err {
+		return &RequestError{Field: name, Message: fmt.Sprintf("%q is not a boolean", value)}
+	}
+
+	return nil
+}
+
+// Validate
+//
+// This method will check that the request can be run, returning a *RequestError describing the first problem found.
+func (r *CodegenRequest) Validate() error {
+	if len(r.Version) <= 0 {
+		r.Version = CodegenRequestVersion
+	}
+
+	if r.Version != CodegenRequestVersion {
+		return &RequestError{Field: "version", Message: fmt.Sprintf("unsupported version %q, this plugin supports version %q", r.Version, CodegenRequestVersion)}
+	}
+
+	if len(r.Sources) <= 0 {
+		return &RequestError{Field: "sources", Message: "at least one source is required"}
+	}
+
+	for i, src := range r.Sources {
+		if len(strings.TrimSpace(src)) <= 0 {
+			return &RequestError{Field: "sources", Message: fmt.Sprintf("source %d is empty", i)}
+		}
+	}
+
+	for i, tgt := range r.Targets {
+		if len(strings.TrimSpace(tgt)) <= 0 {
+			return &RequestError{Field: "targets", Message: fmt.Sprintf("target %d is empty", i)}
+		}
+	}
+
+	for target := range r.Options {
+		found := false
+		for _, tgt := range r.Targets {
+			if strings.EqualFold(tgt, target) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return &RequestError{Field: "options", Message: fmt.Sprintf("options provided for %q which is not one of the targets", target)}
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, r.Include...), r.Exclude...) {
+		if len(strings.TrimSpace(pattern)) <= 0 {
+			return &RequestError{Field: "include/exclude", Message: "patterns can not be empty"}
+		}
+	}
+
+	return nil
+}
+
+// TargetOptions returns the options provided for the given target, or nil if there are none
+func (r *CodegenRequest) TargetOptions(target string) map[string]string {
+	for t, opts := range r.Options {
+		if strings.EqualFold(t, target) {
+			return opts
+		}
+	}
+
+	return nil
+}
+
+// Keeps
+//
+// This method returns true if a resource with the provided path and name passes the Include and Exclude filters of the
+// request.
+func (r *CodegenRequest) Keeps(path, name string) bool {
+	for _, pattern := range r.Exclude {
+		if globMatch(pattern, path) || globMatch(pattern, name) {
+			return false
+		}
+	}
+
+	if len(r.Include) <= 0 {
+		return true
+	}
+
+	for _, pattern := range r.Include {
+		if globMatch(pattern, path) || globMatch(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Filter
+//
+// This method will remove every resource of the model that does not pass the Include and Exclude filters of the request.
+func (r *CodegenRequest) Filter(model *LoadedResponse) {
+	if nil == model || (len(r.Include) <= 0 && len(r.Exclude) <= 0) {
+		return
+	}
+
+	resources := make(Resources, 0, len(model.Resources))
+	for _, res := range model.Resources {
+		if r.Keeps(res.Path, res.Name) {
+			resources = append(resources, res)
+		}
+	}
+
+	model.Resources = resources
+
+	// folders only keep the children that are still part of the model
+	for _, res := range model.Resources {
+		if len(res.Resources) <= 0 {
+			continue
+		}
+
+		children := make(Resources, 0, len(res.Resources))
+		for _, child := range res.Resources {
+			if nil != resources.FindResourceByUuid(child.Id) {
+				children = append(children, child)
+			}
+		}
+
+		res.Resources = children
+	}
+}
+
+// globMatch matches value against a pattern where * matches any run of characters (including /) and ? a single character
+func globMatch(pattern, value string) bool {
+	if len(value) <= 0 {
+		return false
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+
+	matched, err := regexp.MatchString("^"+expr+"$", value)
+	return nil == err && matched
+}
+
+// parseBool will parse a boolean option, an empty (or blank) value being false
+func parseBool(value string) (bool, error) {
+	if value = strings.TrimSpace(value); len(value) <= 0 {
+		return false, nil
+	}
+
+	return strconv.ParseBool(value)
+}
+
+func splitList(value string) []string {
+	list := make([]string, 0)
+
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
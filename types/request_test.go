@@ -0,0 +1,80 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCodegenRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *CodegenRequest
+		field string // of the *RequestError, when the input is not valid
+	}{
+		{
+			name:  "the object form",
+			input: `{"sources": ["a.yaml"], "targets": ["go-models"], "outputDir": "gen", "dryRun": true}`,
+			want:  &CodegenRequest{Version: CodegenRequestVersion, Sources: []string{"a.yaml"}, Targets: []string{"go-models"}, OutputDir: "gen", DryRun: true},
+		},
+		{
+			name:  "the array form",
+			input: `[{"Name": "sources", "Value": "a.yaml, b.json"}, {"Name": "targets", "Value": "go-models"}, {"Name": "options", "Value": "go-models.package=pets"}, {"Name": "strict", "Value": "true"}]`,
+			want: &CodegenRequest{
+				Version: CodegenRequestVersion,
+				Sources: []string{"a.yaml", "b.json"},
+				Targets: []string{"go-models"},
+				Options: map[string]map[string]string{"go-models": {"package": "pets"}},
+				Strict:  true,
+			},
+		},
+		{
+			name:  "empty booleans of the array form are false",
+			input: `[{"Name": "sources", "Value": "a.yaml"}, {"Name": "dryRun", "Value": ""}, {"Name": "strict", "Value": " "}]`,
+			want:  &CodegenRequest{Version: CodegenRequestVersion, Sources: []string{"a.yaml"}},
+		},
+		{
+			name:  "a boolean that does not parse",
+			input: `[{"Name": "sources", "Value": "a.yaml"}, {"Name": "dryRun", "Value": "maybe"}]`,
+			field: "dryRun",
+		},
+		{
+			name:  "an option that is not target.key=value",
+			input: `[{"Name": "sources", "Value": "a.yaml"}, {"Name": "options", "Value": "package=pets"}]`,
+			field: "options",
+		},
+		{
+			name:  "no sources",
+			input: `{"targets": ["go-models"]}`,
+			field: "sources",
+		},
+		{
+			name:  "another version",
+			input: `{"version": "2", "sources": ["a.yaml"]}`,
+			field: "version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := ParseCodegenRequest([]byte(tt.input))
+
+			if len(tt.field) > 0 {
+				reqErr := &RequestError{}
+				if !errors.As(err, &reqErr) || reqErr.Field != tt.field {
+					t.Fatalf("error = %v, want a *RequestError for %s", err, tt.field)
+				}
+				return
+			}
+
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(request, tt.want) {
+				t.Errorf("request = %+v, want %+v", request, tt.want)
+			}
+		})
+	}
+}
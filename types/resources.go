@@ -91,6 +91,10 @@ type Resource struct {
 	// An array of components this resource references
 	Components *Components `json:"components,omitempty"`
 
+	// The children of a FOLDER resource, e.g. the requests and sub folders of a Postman collection folder. Every child is
+	// also part of the Resources of the LoadedResponse, so generators that have no use for folders can ignore them.
+	Resources Resources `json:"resources,omitempty"`
+
 	// A Map of key/value pairs, where the key is a variable name found in the corresponding source of the resource object and the
 	// value is whatever the particular source loader deems as such. In the case of a Collection, a value might be found in an
 	// environment associated with the collection.
@@ -142,11 +146,14 @@ const (
 	HEADER QueryIn = "header"
 	COOKIE QueryIn = "cookie"
 	QUERY  QueryIn = "query"
+
+	// ARGUMENT is a field argument of a GraphQL operation, passed in the variables of the request
+	ARGUMENT QueryIn = "argument"
 )
 
 type Parameter struct {
 	Name              string     // The original json parameter name, eg param_name
-	In                QueryIn    // Where the parameter is defined - path, header, cookie, query
+	In                QueryIn    // Where the parameter is defined - path, header, cookie, query, argument
 	Description       string     // description of this parameter
 	Required          bool       // Is this a required parameter
 	Type              string     // the type of parameter (string, int, number, etc)
@@ -170,6 +177,29 @@ type Request struct {
 	Schema  *Component
 }
 
+// ContentKind
+//
+// This function returns the simpler kind of content a media type is, as used for Request.Type: JSON, XML, FORM,
+// MULTIPART, TEXT or BINARY.
+func ContentKind(contentType string) string {
+	ct := strings.ToLower(contentType)
+
+	switch {
+	case strings.Contains(ct, "json"):
+		return "JSON"
+	case strings.Contains(ct, "xml"):
+		return "XML"
+	case strings.Contains(ct, "x-www-form-urlencoded"):
+		return "FORM"
+	case strings.HasPrefix(ct, "multipart/"):
+		return "MULTIPART"
+	case strings.HasPrefix(ct, "text/"):
+		return "TEXT"
+	default:
+		return "BINARY"
+	}
+}
+
 // This describes a request response
 type Response struct {
 	Status         string
@@ -373,6 +403,23 @@ func (r Resources) FindResource(path, method string, owner *string) *Resource {
 	return nil
 }
 
+// FindResources
+//
+// This method returns every resource with the provided path, method and owner, i.e. every version of the same resource,
+// in the order they were added.
+func (r Resources) FindResources(path, method string, owner *string) Resources {
+	found := make(Resources, 0)
+	id := r.MakeUniqueId(path, method)
+
+	for _, res := range r {
+		if nil != res && res.ResourceId == id && res.Owner == *owner {
+			found = append(found, res)
+		}
+	}
+
+	return found
+}
+
 func (r Resources) FindResourceByUuid(id int) *Resource {
 	if nil == r {
 		return nil
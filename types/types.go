@@ -2,6 +2,7 @@ package types
 
 import (
 	"math/rand"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -71,3 +72,48 @@ func RemoveWhiteSpaceAndCaps(str string) string {
 
 	return b.String()
 }
+
+// CompareVersions
+//
+// This function compares two version strings such as 1.2.3, v2.0 or 2024-01-15 segment by segment. Numeric segments are
+// compared as numbers, anything else as strings, and missing segments count as 0. It returns 1 if a is newer than b, -1
+// if a is older than b and 0 if they are the same version.
+func CompareVersions(a, b string) int {
+	split := func(v string) []string {
+		v = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(v), "v"), "V")
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == '.' || r == '-' || r == '_' || r == '+'
+		})
+	}
+
+	as, bs := split(a), split(b)
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		sa, sb := "0", "0"
+		if i < len(as) {
+			sa = as[i]
+		}
+		if i < len(bs) {
+			sb = bs[i]
+		}
+
+		na, errA := strconv.Atoi(sa)
+		nb, errB := strconv.Atoi(sb)
+
+		switch {
+		case nil == errA && nil == errB:
+			if na != nb {
+				if na > nb {
+					return 1
+				}
+				return -1
+			}
+		default:
+			if c := strings.Compare(sa, sb); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return 0
+}
@@ -14,6 +14,12 @@ type Input struct {
 type Expression struct {
 	Id   string `json:"id"`
 	Text string `json:"text"`
+
+	// The kind of expression when it is not a simple one, e.g. regex, jsonpath or xpath for Arazzo success criteria
+	Type string `json:"type,omitempty"`
+
+	// The runtime expression (e.g. $response.body) Text applies to when Type is not simple
+	Context string `json:"context,omitempty"`
 }
 
 // This is the structure of an individual output
@@ -33,6 +39,24 @@ type WorkflowParameter struct {
 type Action struct {
 	// Unique string representing this Action. This should be unique across the entire workflow.
 	Id string `json:"id"`
+
+	// What to do: end the workflow, goto another step (or workflow), or retry the current step
+	Type string `json:"type,omitempty"`
+
+	// The step to go to when Type is goto
+	StepId string `json:"stepId,omitempty"`
+
+	// The workflow to go to when Type is goto
+	WorkflowId string `json:"workflowId,omitempty"`
+
+	// Seconds to wait before retrying when Type is retry
+	RetryAfter float64 `json:"retryAfter,omitempty"`
+
+	// Maximum number of retries when Type is retry
+	RetryLimit int `json:"retryLimit,omitempty"`
+
+	// All of these must be met for this action to be taken
+	Criteria []Expression `json:"criteria,omitempty"`
 }
 
 // Step
@@ -82,6 +106,9 @@ type Workflow struct {
 	Inputs      Components         `json:"inputs"`
 	Steps       Steps              `json:"steps"`
 	Outputs     map[string]*Output `json:"outputs"`
+
+	// Ids of other workflows that must complete before this one can run
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 type (
@@ -89,6 +116,19 @@ type (
 	WorkflowParameters map[string]WorkflowParameter
 )
 
+// FindWorkflow
+//
+// This method will return the workflow with the provided id, or nil if there is none
+func (wf Workflows) FindWorkflow(id string) *Workflow {
+	for _, w := range wf {
+		if w.Id == id {
+			return w
+		}
+	}
+
+	return nil
+}
+
 // AddWorkflow
 //
 // This method will add a workflow to the slice of Workflows provided by receiver *wf